@@ -26,6 +26,7 @@ import (
 
 	"github.com/coreos/go-systemd/daemon"
 	"github.com/yunify/hostnic-cni/pkg/ipam"
+	"github.com/yunify/hostnic-cni/pkg/k8sclient"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/klog"
@@ -37,14 +38,17 @@ func init() {
 }
 func main() {
 	stopCh := make(chan struct{})
-	stopSignal := make(chan os.Signal)
+	stopSignal := make(chan os.Signal, 1)
 	signal.Notify(stopSignal, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
 	daemon.SdNotify(false, "READY=1")
 	go func() {
-		defer close(stopSignal)
-		for range stopSignal {
-			stopCh <- struct{}{}
-		}
+		sig := <-stopSignal
+		klog.V(1).Infof("Received signal %v, shutting down", sig)
+		daemon.SdNotify(false, "STOPPING=1")
+		close(stopCh)
+		// a second signal stops it at once
+		<-stopSignal
+		klog.Fatalf("Received signal again, exit without shutting down")
 	}()
 
 	var err error
@@ -57,9 +61,32 @@ func main() {
 	if err != nil {
 		klog.Fatalf("Failed to get k8s clientset, err:%v", err)
 	}
-	err = ipam.Start(clientset, stopCh)
+	if flag.Arg(0) == "uninstall" {
+		uninstall(clientset, flag.Args()[1:], stopCh)
+		return
+	}
+	nodeIPPools, err := k8sclient.NewNodeIPPoolClient(config)
+	if err != nil {
+		klog.Errorf("Failed to get NodeIPPool client, the pool of the node will not be published, err:%v", err)
+	}
+	err = ipam.Start(clientset, nodeIPPools, stopCh)
 	if err != nil {
-		klog.Fatalf("Failed to start ipamd, err:%v", err)
+		klog.Fatalf("Failed to run ipamd, err:%v", err)
+	}
+	klog.V(1).Infoln("IPAMD is stopped")
+	klog.Flush()
+}
+
+// uninstall removes hostnic from the node, it is run by `hostnic-agent uninstall` after the daemon stops
+func uninstall(clientset kubernetes.Interface, args []string, stopCh chan struct{}) {
+	flags := flag.NewFlagSet("uninstall", flag.ExitOnError)
+	var opts ipam.UninstallOptions
+	flags.BoolVar(&opts.Force, "force", false, "uninstall even if pods on the node are using hostnic")
+	flags.BoolVar(&opts.DeleteNICs, "delete-nics", false, "detach and delete the nics of hostnic")
+	flags.BoolVar(&opts.ReleaseVxNet, "release-vxnet", false, "delete the vxnet created for the node, it requires --delete-nics")
+	flags.Parse(args)
+	if err := ipam.Uninstall(clientset, opts, stopCh); err != nil {
+		klog.Fatalf("Failed to uninstall hostnic, err:%v", err)
 	}
-	select {}
+	klog.V(1).Infoln("Hostnic is uninstalled from the node")
 }
@@ -29,6 +29,7 @@ import (
 	"net"
 	"os"
 	"runtime"
+	"time"
 
 	"github.com/containernetworking/cni/pkg/skel"
 	"github.com/containernetworking/cni/pkg/types"
@@ -36,18 +37,39 @@ import (
 	"github.com/containernetworking/cni/pkg/version"
 	"github.com/pkg/errors"
 	"github.com/yunify/hostnic-cni/pkg/driver"
+	hostnicerrors "github.com/yunify/hostnic-cni/pkg/errors"
 	"github.com/yunify/hostnic-cni/pkg/networkutils"
+	"github.com/yunify/hostnic-cni/pkg/retry"
 	"github.com/yunify/hostnic-cni/pkg/rpc"
 	"github.com/yunify/hostnic-cni/pkg/rpcwrapper"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"k8s.io/klog"
 )
 
 const (
 	ipamDAddress       = "127.0.0.1:41080"
 	defaultLogFilePath = "/tmp/hostnic.log"
+
+	// the deadlines of the calls to ipamd, so that the plugin fails instead of hanging when ipamd hangs
+	readyTimeout = 2 * time.Second
+	addTimeout   = 30 * time.Second
+	delTimeout   = 30 * time.Second
 )
 
+// ipamdStartingBackoff retries the calls to ipamd for at most 20 seconds when it is starting, which covers the
+// restart of ipamd when it is upgraded. It is well under the timeout of kubelet for a cni call, so that the plugin
+// fails with the reason instead of being killed.
+var ipamdStartingBackoff = retry.Backoff{
+	Duration:       500 * time.Millisecond,
+	Factor:         2,
+	Jitter:         0.2,
+	Cap:            5 * time.Second,
+	MaxElapsedTime: 20 * time.Second,
+	Retryable:      isIPAMDStarting,
+}
+
 type NetConf struct {
 	// CNIVersion is the plugin version
 	CNIVersion string `json:"cniVersion,omitempty"`
@@ -82,15 +104,16 @@ type K8sArgs struct {
 }
 
 func init() {
+	runtime.LockOSThread()
+}
+
+func main() {
+	// the flags are parsed here instead of in init, so that the tests of the plugin are able to parse their own
 	klog.InitFlags(nil)
 	flag.Set("logtostderr", "false")
 	flag.Set("alsologtostderr", "false")
 	flag.Set("v", "2")
 	flag.Parse()
-	runtime.LockOSThread()
-}
-
-func main() {
 	f, err := os.OpenFile(defaultLogFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
 	if err != nil {
 		log.Print("Error openlog file ", err)
@@ -144,35 +167,43 @@ func add(args *skel.CmdArgs, driverClient driver.NetworkAPIs, grpcW rpcwrapper.G
 	}
 	defer conn.Close()
 	c := rpcW.NewCNIBackendClient(conn)
-	r, err := c.AddNetwork(context.Background(),
-		&rpc.AddNetworkRequest{
-			Netns:                      args.Netns,
-			K8S_POD_NAME:               string(k8sArgs.K8S_POD_NAME),
-			K8S_POD_NAMESPACE:          string(k8sArgs.K8S_POD_NAMESPACE),
-			K8S_POD_INFRA_CONTAINER_ID: string(k8sArgs.K8S_POD_INFRA_CONTAINER_ID),
-			IfName:                     args.IfName})
-
-	if err != nil {
-		klog.Errorf("Error received from AddNetwork grpc call for pod %s namespace %s container %s: %v",
+	if err = waitIPAMDReady(c); err != nil {
+		klog.Errorf("Ipamd is not ready for pod %s namespace %s container %s: %v",
 			string(k8sArgs.K8S_POD_NAME),
 			string(k8sArgs.K8S_POD_NAMESPACE),
 			string(k8sArgs.K8S_POD_INFRA_CONTAINER_ID),
 			err)
-		return err
+		return cniError("add cmd: ipamd not ready", err)
 	}
+	// ipamd has answered it is ready, so the call is not retried
+	var r *rpc.AddNetworkReply
+	err = callIPAMDOnce(addTimeout, func(ctx context.Context) (callErr error) {
+		r, callErr = c.AddNetwork(ctx,
+			&rpc.AddNetworkRequest{
+				Netns:                      args.Netns,
+				K8S_POD_NAME:               string(k8sArgs.K8S_POD_NAME),
+				K8S_POD_NAMESPACE:          string(k8sArgs.K8S_POD_NAMESPACE),
+				K8S_POD_INFRA_CONTAINER_ID: string(k8sArgs.K8S_POD_INFRA_CONTAINER_ID),
+				IfName:                     args.IfName,
+				APIVersion:                 rpc.APIVersion})
+		if callErr == nil && !r.Success {
+			callErr = hostnicerrors.FromReply(r.ErrorType, r.Message)
+		}
+		return callErr
+	})
 
-	if !r.Success {
-		klog.Errorf("Failed to assign an IP address to pod %s, namespace %s container %s,err: %s",
+	if err != nil {
+		klog.Errorf("Error received from AddNetwork grpc call for pod %s namespace %s container %s: %v",
 			string(k8sArgs.K8S_POD_NAME),
 			string(k8sArgs.K8S_POD_NAMESPACE),
 			string(k8sArgs.K8S_POD_INFRA_CONTAINER_ID),
-			r.Message)
-		return fmt.Errorf("add cmd: failed to assign an IP address to container, err: %s", r.Message)
+			err)
+		return cniError("add cmd: failed to assign an IP address to container", err)
 	}
 
-	klog.V(1).Infof("Received add network response for pod %s namespace %s container %s: %s, table %d, external-SNAT: %v, vpcCIDR: %v",
+	klog.V(1).Infof("Received add network response for pod %s namespace %s container %s: %s, table %d, mtu %d, external-SNAT: %v, per-NIC-SNAT: %v, vpcCIDR: %v",
 		string(k8sArgs.K8S_POD_NAME), string(k8sArgs.K8S_POD_NAMESPACE), string(k8sArgs.K8S_POD_INFRA_CONTAINER_ID),
-		r.IPv4Addr, r.DeviceNumber, r.UseExternalSNAT, r.VPCcidrs)
+		r.IPv4Addr, r.DeviceNumber, r.MTU, r.UseExternalSNAT, r.PerNICSNAT, r.VPCcidrs)
 
 	addr := &net.IPNet{
 		IP:   net.ParseIP(r.IPv4Addr),
@@ -181,47 +212,119 @@ func add(args *skel.CmdArgs, driverClient driver.NetworkAPIs, grpcW rpcwrapper.G
 	// build hostVethName
 	// Note: the maximum length for linux interface name is 15
 	hostVethName := generateHostVethName(conf.VethPrefix, string(k8sArgs.K8S_POD_NAMESPACE), string(k8sArgs.K8S_POD_NAME))
-	err = driverClient.SetupNS(hostVethName, args.IfName, args.Netns, addr, int(r.DeviceNumber), r.VPCcidrs, networkutils.GetVPNNet(r.IPv4Addr), r.UseExternalSNAT)
+	err = driverClient.SetupNS(hostVethName, args.IfName, args.Netns, addr, int(r.DeviceNumber), int(r.MTU), r.VPCcidrs, networkutils.GetVPNNet(r.IPv4Addr), r.UseExternalSNAT, r.PerNICSNAT)
 
 	if err != nil {
 		klog.Errorf("Failed SetupPodNetwork for pod %s namespace %s container %s: %v",
 			string(k8sArgs.K8S_POD_NAME), string(k8sArgs.K8S_POD_NAMESPACE), string(k8sArgs.K8S_POD_INFRA_CONTAINER_ID), err)
 
 		// return allocated IP back to IP pool
-		r, delErr := c.DelNetwork(context.Background(),
-			&rpc.DelNetworkRequest{
-				K8S_POD_NAME:               string(k8sArgs.K8S_POD_NAME),
-				K8S_POD_NAMESPACE:          string(k8sArgs.K8S_POD_NAMESPACE),
-				K8S_POD_INFRA_CONTAINER_ID: string(k8sArgs.K8S_POD_INFRA_CONTAINER_ID),
-				IPv4Addr:                   r.IPv4Addr,
-				Reason:                     "SetupNSFailed"})
+		var delReply *rpc.DelNetworkReply
+		delErr := callIPAMDOnce(delTimeout, func(ctx context.Context) (callErr error) {
+			delReply, callErr = c.DelNetwork(ctx,
+				&rpc.DelNetworkRequest{
+					K8S_POD_NAME:               string(k8sArgs.K8S_POD_NAME),
+					K8S_POD_NAMESPACE:          string(k8sArgs.K8S_POD_NAMESPACE),
+					K8S_POD_INFRA_CONTAINER_ID: string(k8sArgs.K8S_POD_INFRA_CONTAINER_ID),
+					IPv4Addr:                   r.IPv4Addr,
+					Reason:                     "SetupNSFailed",
+					APIVersion:                 rpc.APIVersion})
+			if callErr == nil && !delReply.Success {
+				callErr = hostnicerrors.FromReply(delReply.ErrorType, delReply.Message)
+			}
+			return callErr
+		})
 
 		if delErr != nil {
-			klog.Errorf("Error received from DelNetwork grpc call for pod %s namespace %s container %s: %v",
-				string(k8sArgs.K8S_POD_NAME), string(k8sArgs.K8S_POD_NAMESPACE), string(k8sArgs.K8S_POD_INFRA_CONTAINER_ID), delErr)
-		}
-
-		if !r.Success {
 			klog.Errorf("Failed to release IP of pod %s namespace %s container %s: %v",
 				string(k8sArgs.K8S_POD_NAME), string(k8sArgs.K8S_POD_NAMESPACE), string(k8sArgs.K8S_POD_INFRA_CONTAINER_ID), delErr)
 		}
 		return errors.Wrap(err, "add command: failed to setup network")
 	}
 
+	// Report the container interface, so that chained plugins can find the veth and its MTU
+	interfaceIndex := 0
 	ips := []*current.IPConfig{
 		{
-			Version: "4",
-			Address: *addr,
+			Version:   "4",
+			Interface: &interfaceIndex,
+			Address:   *addr,
 		},
 	}
 
 	result := &current.Result{
+		Interfaces: []*current.Interface{
+			{
+				Name:    args.IfName,
+				Sandbox: args.Netns,
+			},
+		},
 		IPs: ips,
 	}
 
 	return types.PrintResult(result, cniVersion)
 }
 
+// waitIPAMDReady asks ipamd whether it is able to assign ips, it fails with an IPAMDNotReady error if ipamd is
+// still not ready after retries
+func waitIPAMDReady(c rpc.CNIBackendClient) error {
+	return callIPAMD(readyTimeout, func(ctx context.Context) error {
+		r, err := c.Ready(ctx, &rpc.ReadyRequest{APIVersion: rpc.APIVersion})
+		if status.Code(err) == codes.Unimplemented {
+			// ipamd of an old version, which serves pods once it listens
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !r.Ready {
+			return hostnicerrors.NewIPAMDNotReadyError(r.Message)
+		}
+		return nil
+	})
+}
+
+// callIPAMD calls ipamd with a deadline, and retries if ipamd is starting. If ipamd is still not reachable after
+// retries, an IPAMDNotReady error is returned instead of the error of grpc.
+func callIPAMD(timeout time.Duration, call func(ctx context.Context) error) error {
+	err := retry.DoWithBackoff(context.Background(), ipamdStartingBackoff, func() error {
+		return callIPAMDOnce(timeout, call)
+	})
+	if err == nil {
+		return nil
+	}
+	last := errors.Cause(err)
+	if retry.IsMaxRetries(err) && !hostnicerrors.IsIPAMDNotReady(last) {
+		return hostnicerrors.NewIPAMDNotReadyError(fmt.Sprintf("ipamd is not reachable at %s: %v", ipamDAddress, last))
+	}
+	return last
+}
+
+// callIPAMDOnce calls ipamd with a deadline without retrying
+func callIPAMDOnce(timeout time.Duration, call func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return call(ctx)
+}
+
+// isIPAMDStarting tells whether a call fails because ipamd is not listening or not ready yet. The other errors replied
+// by ipamd are not retried.
+func isIPAMDStarting(err error) bool {
+	if hostnicerrors.TypeOf(err) != "" {
+		return hostnicerrors.IsIPAMDNotReady(err)
+	}
+	return status.Code(err) == codes.Unavailable
+}
+
+// cniError converts the error of a call to ipamd to a cni error, whose code tells the runtime why it fails
+func cniError(msg string, err error) *types.Error {
+	return &types.Error{
+		Code:    hostnicerrors.CNICode(err),
+		Msg:     msg,
+		Details: err.Error(),
+	}
+}
+
 // generateHostVethName returns a name to be used on the host-side veth device.
 func generateHostVethName(prefix, namespace, podname string) string {
 	h := sha1.New()
@@ -266,19 +369,22 @@ func del(args *skel.CmdArgs, driverClient driver.NetworkAPIs, grpcW rpcwrapper.G
 		K8S_POD_NAMESPACE:          string(k8sArgs.K8S_POD_NAMESPACE),
 		K8S_POD_INFRA_CONTAINER_ID: string(k8sArgs.K8S_POD_INFRA_CONTAINER_ID),
 		Reason:                     "PodDeleted",
+		APIVersion:                 rpc.APIVersion,
 	}
-	r, err := c.DelNetwork(context.Background(), request)
+	var r *rpc.DelNetworkReply
+	err = callIPAMD(delTimeout, func(ctx context.Context) (callErr error) {
+		r, callErr = c.DelNetwork(ctx, request)
+		if callErr == nil && !r.Success {
+			callErr = hostnicerrors.FromReply(r.ErrorType, r.Message)
+		}
+		return callErr
+	})
 	if err != nil {
 		klog.Errorf("Error received from DelNetwork grpc call for pod %s namespace %s container %s: %v",
 			string(k8sArgs.K8S_POD_NAME), string(k8sArgs.K8S_POD_NAMESPACE), string(k8sArgs.K8S_POD_INFRA_CONTAINER_ID), err)
-		return err
+		return cniError("del cmd: failed to process delete request", err)
 	}
 
-	if !r.Success {
-		klog.Errorf("Failed to process delete request for pod %s namespace %s container %s: %v",
-			string(k8sArgs.K8S_POD_NAME), string(k8sArgs.K8S_POD_NAMESPACE), string(k8sArgs.K8S_POD_INFRA_CONTAINER_ID), err)
-		return errors.Wrap(err, "del cmd: failed to process delete request")
-	}
 	if r.IPv4Addr == "" {
 		klog.Warningf("Try to delete a pod %s namespace %swith noip", string(k8sArgs.K8S_POD_NAME), string(k8sArgs.K8S_POD_NAMESPACE))
 		return nil
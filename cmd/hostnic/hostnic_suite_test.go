@@ -0,0 +1,13 @@
+package main
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestHostnic(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Hostnic Suite")
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/containernetworking/cni/pkg/skel"
+	"github.com/containernetworking/cni/pkg/types"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	hostnicerrors "github.com/yunify/hostnic-cni/pkg/errors"
+	"github.com/yunify/hostnic-cni/pkg/retry"
+	"github.com/yunify/hostnic-cni/pkg/rpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeIPAMD answers the calls of the plugin with the functions, and counts the calls
+type fakeIPAMD struct {
+	addNetwork func(ctx context.Context) (*rpc.AddNetworkReply, error)
+	delNetwork func(ctx context.Context) (*rpc.DelNetworkReply, error)
+	ready      func(ctx context.Context) (*rpc.ReadyReply, error)
+
+	addCalls, delCalls, readyCalls int32
+}
+
+func (f *fakeIPAMD) AddNetwork(ctx context.Context, in *rpc.AddNetworkRequest, opts ...grpc.CallOption) (*rpc.AddNetworkReply, error) {
+	atomic.AddInt32(&f.addCalls, 1)
+	return f.addNetwork(ctx)
+}
+
+func (f *fakeIPAMD) DelNetwork(ctx context.Context, in *rpc.DelNetworkRequest, opts ...grpc.CallOption) (*rpc.DelNetworkReply, error) {
+	atomic.AddInt32(&f.delCalls, 1)
+	return f.delNetwork(ctx)
+}
+
+func (f *fakeIPAMD) Ready(ctx context.Context, in *rpc.ReadyRequest, opts ...grpc.CallOption) (*rpc.ReadyReply, error) {
+	atomic.AddInt32(&f.readyCalls, 1)
+	return f.ready(ctx)
+}
+
+func readyReply(ctx context.Context) (*rpc.ReadyReply, error) {
+	return &rpc.ReadyReply{Ready: true, APIVersion: rpc.APIVersion}, nil
+}
+
+// fakeDialer dials nothing, the client it gives is the fake ipamd
+type fakeDialer struct {
+	ipamd *fakeIPAMD
+}
+
+func (d *fakeDialer) Dial(target string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	return grpc.Dial(target, opts...)
+}
+
+func (d *fakeDialer) NewCNIBackendClient(cc *grpc.ClientConn) rpc.CNIBackendClient {
+	return d.ipamd
+}
+
+var _ = Describe("Hostnic", func() {
+	var backoff retry.Backoff
+
+	BeforeEach(func() {
+		backoff = ipamdStartingBackoff
+		ipamdStartingBackoff.Duration = 10 * time.Millisecond
+		ipamdStartingBackoff.Cap = 50 * time.Millisecond
+		ipamdStartingBackoff.MaxElapsedTime = 500 * time.Millisecond
+	})
+
+	AfterEach(func() {
+		ipamdStartingBackoff = backoff
+	})
+
+	It("Should wait for ipamd until it is ready", func() {
+		ipamd := &fakeIPAMD{}
+		ipamd.ready = func(ctx context.Context) (*rpc.ReadyReply, error) {
+			if atomic.LoadInt32(&ipamd.readyCalls) < 3 {
+				return &rpc.ReadyReply{Ready: false, Message: "stuck at stage Starting"}, nil
+			}
+			return readyReply(ctx)
+		}
+		Expect(waitIPAMDReady(ipamd)).ShouldNot(HaveOccurred())
+		Expect(ipamd.readyCalls).To(BeEquivalentTo(3))
+
+		// ipamd of an old version has no Ready, it is ready once it answers
+		ipamd = &fakeIPAMD{ready: func(ctx context.Context) (*rpc.ReadyReply, error) {
+			return nil, status.Error(codes.Unimplemented, "unknown method Ready")
+		}}
+		Expect(waitIPAMDReady(ipamd)).ShouldNot(HaveOccurred())
+		Expect(ipamd.readyCalls).To(BeEquivalentTo(1))
+	})
+
+	It("Should fail with IPAMDNotReady if ipamd is not ready in time", func() {
+		ipamd := &fakeIPAMD{ready: func(ctx context.Context) (*rpc.ReadyReply, error) {
+			return &rpc.ReadyReply{Ready: false, Message: "stuck at stage Starting"}, nil
+		}}
+		err := waitIPAMDReady(ipamd)
+		Expect(hostnicerrors.IsIPAMDNotReady(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("stuck at stage Starting"))
+		Expect(cniError("add cmd: ipamd not ready", err).Code).To(Equal(hostnicerrors.CNICodeIPAMDNotReady))
+		Expect(ipamd.readyCalls).To(BeNumerically(">", 1))
+
+		// the errors of grpc are replaced, so that the runtime knows ipamd is not reachable
+		ipamd = &fakeIPAMD{ready: func(ctx context.Context) (*rpc.ReadyReply, error) {
+			return nil, status.Error(codes.Unavailable, "connection refused")
+		}}
+		err = waitIPAMDReady(ipamd)
+		Expect(hostnicerrors.IsIPAMDNotReady(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("connection refused"))
+		Expect(ipamd.readyCalls).To(BeNumerically(">", 1))
+	})
+
+	It("Should give up a call to ipamd at its deadline", func() {
+		start := time.Now()
+		err := callIPAMD(100*time.Millisecond, func(ctx context.Context) error {
+			<-ctx.Done()
+			return status.FromContextError(ctx.Err()).Err()
+		})
+		Expect(status.Code(err)).To(Equal(codes.DeadlineExceeded))
+		// a call which hangs is not retried
+		Expect(time.Since(start)).To(BeNumerically("<", 300*time.Millisecond))
+	})
+
+	It("Should only retry the errors replied by ipamd when it is not ready", func() {
+		calls := 0
+		err := callIPAMD(time.Second, func(ctx context.Context) error {
+			calls++
+			return hostnicerrors.FromReply(string(hostnicerrors.PoolExhausted), "no free ip")
+		})
+		Expect(calls).To(Equal(1))
+		Expect(hostnicerrors.IsPoolExhausted(err)).To(BeTrue())
+
+		calls = 0
+		err = callIPAMD(time.Second, func(ctx context.Context) error {
+			calls++
+			if calls < 3 {
+				return hostnicerrors.FromReply(string(hostnicerrors.IPAMDNotReady), "restoring")
+			}
+			return nil
+		})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(calls).To(Equal(3))
+	})
+
+	It("Should not retry AddNetwork once ipamd is ready", func() {
+		ipamd := &fakeIPAMD{
+			ready: readyReply,
+			addNetwork: func(ctx context.Context) (*rpc.AddNetworkReply, error) {
+				return nil, status.Error(codes.Unavailable, "transport is closing")
+			},
+		}
+		args := &skel.CmdArgs{
+			ContainerID: "container1",
+			Netns:       "/proc/1/ns/net",
+			IfName:      "eth0",
+			Args:        "K8S_POD_NAME=pod1;K8S_POD_NAMESPACE=ns1;K8S_POD_INFRA_CONTAINER_ID=container1",
+			StdinData:   []byte(`{"cniVersion":"0.3.1","name":"hostnic","type":"hostnic"}`),
+		}
+		dialer := &fakeDialer{ipamd: ipamd}
+		err := add(args, nil, dialer, dialer)
+		Expect(err).Should(HaveOccurred())
+		Expect(ipamd.addCalls).To(BeEquivalentTo(1))
+
+		// the reason of a failure replied by ipamd is told to the runtime
+		ipamd.addNetwork = func(ctx context.Context) (*rpc.AddNetworkReply, error) {
+			return &rpc.AddNetworkReply{
+				Success:   false,
+				Message:   "[PoolExhausted] no free ip",
+				ErrorType: string(hostnicerrors.PoolExhausted),
+			}, nil
+		}
+		err = add(args, nil, dialer, dialer)
+		Expect(err).To(BeAssignableToTypeOf(&types.Error{}))
+		Expect(err.(*types.Error).Code).To(Equal(hostnicerrors.CNICodePoolExhausted))
+		Expect(err.(*types.Error).Details).To(Equal("[PoolExhausted] no free ip"))
+		Expect(ipamd.addCalls).To(BeEquivalentTo(2))
+	})
+
+	It("Should retry DelNetwork while ipamd is restarting", func() {
+		ipamd := &fakeIPAMD{}
+		ipamd.delNetwork = func(ctx context.Context) (*rpc.DelNetworkReply, error) {
+			if atomic.LoadInt32(&ipamd.delCalls) < 3 {
+				return nil, status.Error(codes.Unavailable, "connection refused")
+			}
+			// the pod has no ip, so there is nothing to tear down
+			return &rpc.DelNetworkReply{Success: true}, nil
+		}
+		args := &skel.CmdArgs{
+			ContainerID: "container1",
+			Netns:       "/proc/1/ns/net",
+			IfName:      "eth0",
+			Args:        "K8S_POD_NAME=pod1;K8S_POD_NAMESPACE=ns1;K8S_POD_INFRA_CONTAINER_ID=container1",
+			StdinData:   []byte(`{"cniVersion":"0.3.1","name":"hostnic","type":"hostnic"}`),
+		}
+		dialer := &fakeDialer{ipamd: ipamd}
+		Expect(del(args, nil, dialer, dialer)).ShouldNot(HaveOccurred())
+		Expect(ipamd.delCalls).To(BeEquivalentTo(3))
+	})
+})
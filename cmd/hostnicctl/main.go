@@ -0,0 +1,259 @@
+//
+// =========================================================================
+// Copyright (C) 2017 by Yunify, Inc...
+// -------------------------------------------------------------------------
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this work except in compliance with the License.
+// You may obtain a copy of the License in the LICENSE file, or at:
+//
+//  http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// =========================================================================
+//
+
+// hostnicctl looks into ipamd on the node and fixes it, through the introspection api served on the socket of ipamd.
+// The cluster command reads the NodeIPPools published by all nodes from k8s instead.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/yunify/hostnic-cni/pkg/apis/hostnic/v1alpha1"
+	"github.com/yunify/hostnic-cni/pkg/k8sclient"
+	"github.com/yunify/hostnic-cni/pkg/rpc"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"k8s.io/client-go/rest"
+)
+
+const usage = `Usage: hostnicctl [--address ADDRESS] [--timeout TIMEOUT] COMMAND [ARGS]
+
+Commands:
+  pods                          list the pods and their ips
+  nics                          list the nics, their ips and route tables
+  pool                          show the status of the ip pool
+  release-ip [--force] IP       release an ip leaked by a deleted pod
+  reconcile                     reconcile the pool and the host network now
+  rules                         dump the expected and the installed ip rules of pods
+  verify NAMESPACE/NAME         verify the data path of a pod
+  cluster                       show the utilization of each vxnet summed over the NodeIPPools of all nodes
+`
+
+func main() {
+	address := flag.String("address", "127.0.0.1:41080", "address of ipamd")
+	timeout := flag.Duration("timeout", 30*time.Second, "timeout of the call to ipamd")
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	command, args := flag.Arg(0), flag.Args()[1:]
+	if command == "cluster" {
+		if err := clusterUtilization(*timeout); err != nil {
+			fatalf("%v", err)
+		}
+		return
+	}
+
+	conn, err := grpc.Dial(*address, grpc.WithInsecure())
+	if err != nil {
+		fatalf("failed to connect to ipamd at %s: %v", *address, err)
+	}
+	defer conn.Close()
+	client := rpc.NewIntrospectionClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	switch command {
+	case "pods":
+		err = listPods(ctx, client)
+	case "nics":
+		err = listNICs(ctx, client)
+	case "pool":
+		err = poolStatus(ctx, client)
+	case "release-ip":
+		err = releaseIP(ctx, client, args)
+	case "reconcile":
+		_, err = client.Reconcile(ctx, &rpc.ReconcileRequest{})
+		if err == nil {
+			fmt.Println("Reconciled")
+		}
+	case "rules":
+		err = dumpRules(ctx, client)
+	case "verify":
+		err = verifyPod(ctx, client, args)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fatalf("%v", err)
+	}
+}
+
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
+
+func newTable() *tabwriter.Writer {
+	return tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+}
+
+func listPods(ctx context.Context, client rpc.IntrospectionClient) error {
+	reply, err := client.ListPods(ctx, &rpc.ListPodsRequest{})
+	if err != nil {
+		return err
+	}
+	w := newTable()
+	fmt.Fprintln(w, "NAMESPACE\tNAME\tIP\tNIC\tTABLE\tEGRESS")
+	for _, pod := range reply.Pods {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\n", pod.Namespace, pod.Name, pod.IP, pod.NIC, pod.DeviceNumber, orNone(pod.Egress))
+	}
+	return w.Flush()
+}
+
+func listNICs(ctx context.Context, client rpc.IntrospectionClient) error {
+	reply, err := client.ListNICs(ctx, &rpc.ListNICsRequest{})
+	if err != nil {
+		return err
+	}
+	w := newTable()
+	fmt.Fprintln(w, "NIC\tTABLE\tPRIMARY\tVXNET\tSECURITY GROUP\tRESERVED\tIPS")
+	for _, nic := range reply.NICs {
+		fmt.Fprintf(w, "%s\t%d\t%t\t%s\t%s\t%t\t%d\n", nic.ID, nic.DeviceNumber, nic.Primary, nic.VxNet, orNone(nic.SecurityGroup), nic.Reserved, len(nic.Addresses))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	for _, nic := range reply.NICs {
+		fmt.Printf("\nNIC %s (table %d)\n", nic.ID, nic.DeviceNumber)
+		for _, addr := range nic.Addresses {
+			kind := "primary"
+			if addr.Secondary {
+				kind = "secondary"
+			}
+			fmt.Printf("  %s %s %s\n", addr.IP, kind, orNone(addr.Pod))
+		}
+		for _, route := range nic.Routes {
+			fmt.Printf("  route %s\n", route)
+		}
+	}
+	return nil
+}
+
+func poolStatus(ctx context.Context, client rpc.IntrospectionClient) error {
+	reply, err := client.PoolStatus(ctx, &rpc.PoolStatusRequest{})
+	if err != nil {
+		return err
+	}
+	w := newTable()
+	fmt.Fprintf(w, "Ready:\t%t\n", reply.Ready)
+	fmt.Fprintf(w, "Stage:\t%s\n", reply.Stage)
+	fmt.Fprintf(w, "Message:\t%s\n", orNone(reply.Message))
+	fmt.Fprintf(w, "VxNet:\t%s\n", orNone(reply.VxNet))
+	fmt.Fprintf(w, "IPs:\t%d assigned / %d total\n", reply.AssignedIPs, reply.TotalIPs)
+	fmt.Fprintf(w, "Pool size:\t%d (max %d)\n", reply.PoolSize, reply.MaxPoolSize)
+	fmt.Fprintf(w, "NICs:\t%d (max %d, %d ips each)\n", reply.NICs, reply.MaxNICs, reply.MaxIPsPerNIC)
+	fmt.Fprintf(w, "Allocation error:\t%s\n", orNone(reply.AllocationError))
+	return w.Flush()
+}
+
+func releaseIP(ctx context.Context, client rpc.IntrospectionClient, args []string) error {
+	flags := flag.NewFlagSet("release-ip", flag.ExitOnError)
+	force := flags.Bool("force", false, "release the ip even if the pod using it still exists")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: hostnicctl release-ip [--force] IP")
+	}
+	reply, err := client.ReleaseIP(ctx, &rpc.ReleaseIPRequest{IP: flags.Arg(0), Force: *force})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Released %s of pod %s\n", reply.IP, reply.Pod)
+	return nil
+}
+
+func dumpRules(ctx context.Context, client rpc.IntrospectionClient) error {
+	reply, err := client.DumpRules(ctx, &rpc.DumpRulesRequest{})
+	if err != nil {
+		return err
+	}
+	w := newTable()
+	fmt.Fprintln(w, "RULE\tOWNER\tEXPECTED\tINSTALLED")
+	for _, rule := range reply.Rules {
+		fmt.Fprintf(w, "%s\t%s\t%t\t%t\n", rule.Rule, orNone(rule.Owner), rule.Expected, rule.Installed)
+	}
+	return w.Flush()
+}
+
+func verifyPod(ctx context.Context, client rpc.IntrospectionClient, args []string) error {
+	if len(args) != 1 || !strings.Contains(args[0], "/") {
+		return fmt.Errorf("usage: hostnicctl verify NAMESPACE/NAME")
+	}
+	parts := strings.SplitN(args[0], "/", 2)
+	reply, err := client.VerifyPod(ctx, &rpc.VerifyPodRequest{Namespace: parts[0], Name: parts[1]})
+	if err != nil {
+		return err
+	}
+	w := newTable()
+	for _, check := range reply.Checks {
+		result := "OK"
+		if !check.OK {
+			result = "FAILED"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", check.Name, result, check.Message)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if !reply.OK {
+		return fmt.Errorf("data path of pod %s is broken", args[0])
+	}
+	return nil
+}
+
+// clusterUtilization lists the NodeIPPools with the service account of the pod hostnicctl runs in
+func clusterUtilization(timeout time.Duration) error {
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		return fmt.Errorf("failed to get k8s config: %v", err)
+	}
+	config.Timeout = timeout
+	client, err := k8sclient.NewNodeIPPoolClient(config)
+	if err != nil {
+		return err
+	}
+	pools, err := client.List()
+	if err != nil {
+		return fmt.Errorf("failed to list NodeIPPools: %v", err)
+	}
+	w := newTable()
+	fmt.Fprintln(w, "VXNET\tNODES\tNICS\tASSIGNED\tTOTAL\tUTILIZATION")
+	for _, u := range v1alpha1.UtilizationByVxNet(pools.Items) {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%.0f%%\n", orNone(u.VxNet), u.Nodes, u.NICs, u.AssignedIPs, u.TotalIPs, u.Utilization()*100)
+	}
+	return w.Flush()
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "<none>"
+	}
+	return s
+}
@@ -0,0 +1,4 @@
+// Package v1alpha1 contains the custom resources of hostnic in group hostnic.io
+// +k8s:deepcopy-gen=package
+// +groupName=hostnic.io
+package v1alpha1
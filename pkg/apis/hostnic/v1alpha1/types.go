@@ -0,0 +1,87 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NodeIPPoolResource is the plural name of NodeIPPool
+const NodeIPPoolResource = "nodeippools"
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// NodeIPPool is the ip pool of a node, it is named after the node and kept up to date by the ipamd on the node
+type NodeIPPool struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NodeIPPoolSpec   `json:"spec,omitempty"`
+	Status NodeIPPoolStatus `json:"status,omitempty"`
+}
+
+// NodeIPPoolSpec is the warm pool targets of ipamd
+type NodeIPPoolSpec struct {
+	// PoolSize is the number of free ips ipamd keeps
+	PoolSize int `json:"poolSize"`
+	// MaxPoolSize is the number of free ips above which ipamd releases ips
+	MaxPoolSize int `json:"maxPoolSize"`
+	// MaxIPsPerNIC is the max number of ips of a nic, including the secondary ips
+	MaxIPsPerNIC int `json:"maxIPsPerNIC"`
+	// MaxNICs is the max number of nics for pods
+	MaxNICs int `json:"maxNICs"`
+}
+
+// NodeIPPoolStatus is the nics of a node and the ips of them
+type NodeIPPoolStatus struct {
+	// InstanceID is the id of the instance of the node
+	InstanceID string `json:"instanceID,omitempty"`
+	// VxNet is the id of the vxnet of the nics for pods
+	VxNet string `json:"vxnet,omitempty"`
+	// TotalIPs is the number of ips in the pool
+	TotalIPs int `json:"totalIPs"`
+	// AssignedIPs is the number of ips assigned to pods
+	AssignedIPs int `json:"assignedIPs"`
+	// NICs are the nics of the node, ordered by device number
+	NICs []NICStatus `json:"nics,omitempty"`
+	// LastUpdateTime is when ipamd updated the status
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
+}
+
+// NICStatus is a nic of the node
+type NICStatus struct {
+	// ID is the id of the nic, which is its mac address
+	ID string `json:"id"`
+	// DeviceNumber is the device number of the nic, it is also the route table of the nic
+	DeviceNumber int `json:"deviceNumber"`
+	// Primary is true for the primary nic of the node, whose address is never assigned to pods
+	Primary bool `json:"primary,omitempty"`
+	// VxNet is the id of the vxnet of the nic
+	VxNet string `json:"vxnet,omitempty"`
+	// SecurityGroup is the security group of the nic
+	SecurityGroup string `json:"securityGroup,omitempty"`
+	// Reserved is true if the nic is reserved, e.g. by a pod using it as egress
+	Reserved bool `json:"reserved,omitempty"`
+	// Addresses are the ips of the nic, ordered by ip
+	Addresses []AddressStatus `json:"addresses,omitempty"`
+}
+
+// AddressStatus is an ip of a nic
+type AddressStatus struct {
+	// IP is the address
+	IP string `json:"ip"`
+	// Secondary is true for a secondary private ip of the nic
+	Secondary bool `json:"secondary,omitempty"`
+	// Pod is namespace/name of the pod the ip is assigned to, empty if it is free
+	Pod string `json:"pod,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// NodeIPPoolList is a list of NodeIPPool
+type NodeIPPoolList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []NodeIPPool `json:"items"`
+}
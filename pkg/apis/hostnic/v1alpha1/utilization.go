@@ -0,0 +1,58 @@
+package v1alpha1
+
+import "sort"
+
+// VxNetUtilization is the usage of the ips of a vxnet summed over the nodes
+type VxNetUtilization struct {
+	VxNet       string
+	Nodes       int
+	NICs        int
+	TotalIPs    int
+	AssignedIPs int
+}
+
+// Utilization returns the ratio of the assigned ips, it is 0 if there is no ip
+func (u *VxNetUtilization) Utilization() float64 {
+	if u.TotalIPs == 0 {
+		return 0
+	}
+	return float64(u.AssignedIPs) / float64(u.TotalIPs)
+}
+
+// UtilizationByVxNet sums the ips of the nics for pods in the pools by vxnet, the primary nics are not counted.
+// The result is ordered by vxnet.
+func UtilizationByVxNet(pools []NodeIPPool) []VxNetUtilization {
+	byVxNet := make(map[string]*VxNetUtilization)
+	for _, pool := range pools {
+		seen := make(map[string]bool)
+		for _, nic := range pool.Status.NICs {
+			if nic.Primary {
+				continue
+			}
+			u, ok := byVxNet[nic.VxNet]
+			if !ok {
+				u = &VxNetUtilization{VxNet: nic.VxNet}
+				byVxNet[nic.VxNet] = u
+			}
+			if !seen[nic.VxNet] {
+				seen[nic.VxNet] = true
+				u.Nodes++
+			}
+			u.NICs++
+			for _, addr := range nic.Addresses {
+				u.TotalIPs++
+				if addr.Pod != "" {
+					u.AssignedIPs++
+				}
+			}
+		}
+	}
+	result := make([]VxNetUtilization, 0, len(byVxNet))
+	for _, u := range byVxNet {
+		result = append(result, *u)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].VxNet < result[j].VxNet
+	})
+	return result
+}
@@ -0,0 +1,30 @@
+package v1alpha1
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Utilization", func() {
+	It("Should sum the ips of the nics for pods by vxnet", func() {
+		pools := []NodeIPPool{
+			{Status: NodeIPPoolStatus{NICs: []NICStatus{
+				{ID: "primary-1", Primary: true, VxNet: "vxnet-node", Addresses: []AddressStatus{{IP: "192.168.0.2"}}},
+				{ID: "nic-1", VxNet: "vxnet-b", Addresses: []AddressStatus{{IP: "192.168.2.2", Pod: "default/pod1"}, {IP: "192.168.2.3", Secondary: true}}},
+				{ID: "nic-2", VxNet: "vxnet-b", Addresses: []AddressStatus{{IP: "192.168.2.4", Pod: "default/pod2"}}},
+			}}},
+			{Status: NodeIPPoolStatus{NICs: []NICStatus{
+				{ID: "nic-3", VxNet: "vxnet-a", Addresses: []AddressStatus{{IP: "192.168.1.2"}}},
+				{ID: "nic-4", VxNet: "vxnet-b", Addresses: []AddressStatus{{IP: "192.168.2.5", Pod: "default/pod3"}}},
+			}}},
+		}
+		result := UtilizationByVxNet(pools)
+		Expect(result).To(Equal([]VxNetUtilization{
+			{VxNet: "vxnet-a", Nodes: 1, NICs: 1, TotalIPs: 1, AssignedIPs: 0},
+			{VxNet: "vxnet-b", Nodes: 2, NICs: 3, TotalIPs: 4, AssignedIPs: 3},
+		}))
+		Expect(result[0].Utilization()).To(BeZero())
+		Expect(result[1].Utilization()).To(BeNumerically("~", 0.75))
+		Expect((&VxNetUtilization{}).Utilization()).To(BeZero())
+	})
+})
@@ -0,0 +1,139 @@
+package cloudprovider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/yunify/hostnic-cni/pkg/cloudprovider/static"
+	"github.com/yunify/hostnic-cni/pkg/qcclient"
+	"github.com/yunify/hostnic-cni/pkg/types"
+)
+
+const (
+	// QingCloud is the provider managing nics and vxnets by the api of QingCloud
+	QingCloud = "qingcloud"
+	// Static is the provider handing out the interfaces pre-created on bare metal hosts
+	Static = "static"
+
+	// MaxNICsPerInstance is the max number of nics attached to a node, the primary one included. It is the limit of
+	// QingCloud, which is the strictest of the providers.
+	MaxNICsPerInstance = qcclient.MaxNICsPerInstance
+)
+
+// Interface is what hostnic needs from the backend of a node. A vpc is the network of the cluster and a vxnet is a
+// subnet in it, nics are attached to the node in the subnets, and each nic may have secondary private ips besides its
+// address. The resources created through the interface are labeled as the Config of the provider says.
+type Interface interface {
+	// GetInstanceID returns the id of the node in the backend
+	GetInstanceID() string
+
+	// GetNodeVPC returns the vpc of the node with its subnets, it may retry until ctx is done
+	GetNodeVPC(ctx context.Context) (*types.VPC, error)
+	GetVPC(ctx context.Context, id string) (*types.VPC, error)
+	GetVPCVxNets(vpcID string) ([]*types.VxNet, error)
+	GetVxNet(id string) (*types.VxNet, error)
+	GetVxNets(ids []string) ([]*types.VxNet, error)
+	GetVxNetByName(name string) (*types.VxNet, error)
+	CreateVxNet(name string) (*types.VxNet, error)
+	DeleteVxNet(id string) error
+	// JoinVPC adds the subnet to the vpc with the network
+	JoinVPC(network, vxnetID, vpcID string) error
+	LeaveVPC(vxnetID, vpcID string) error
+
+	GetPrimaryNIC() (*types.HostNic, error)
+	GetNics(ids []string) ([]*types.HostNic, error)
+	// GetAttachedNICs returns the nics of hostnic in the subnet which are attached to the node
+	GetAttachedNICs(vxnetID string) ([]*types.HostNic, error)
+	// CreateNic creates a nic in the subnet and attaches it to the node
+	CreateNic(ctx context.Context, vxnetID string) (*types.HostNic, error)
+	// CreateNics creates count nics in the subnet and attaches them to the node in one batch. If some nics fail,
+	// the others are returned along with the error. It waits for the nics until ctx is done.
+	CreateNics(ctx context.Context, vxnetID string, count int) ([]*types.HostNic, error)
+	// DeleteNic detaches the nic from the node and deletes it
+	DeleteNic(nicID string) error
+	DeleteNics(nicIDs []string) error
+
+	// AssignPrivateIPs assigns count secondary private ips to a nic, and returns the new ips
+	AssignPrivateIPs(nicID string, count int) ([]string, error)
+	// UnassignPrivateIPs releases secondary private ips of a nic
+	UnassignPrivateIPs(nicID string, ips ...string) error
+}
+
+// SecurityGroups is implemented by the providers which bind nics to security groups
+type SecurityGroups interface {
+	// ApplySecurityGroup binds nics to a security group, replacing the one they are bound to
+	ApplySecurityGroup(securityGroupID string, nicIDs ...string) error
+}
+
+// EIPs is implemented by the providers which have elastic ips bound to nodes or nics
+type EIPs interface {
+	GetEIP(eipID string) (*types.EIP, error)
+}
+
+var (
+	_ Interface      = qcclient.QingCloudAPI(nil)
+	_ SecurityGroups = qcclient.QingCloudAPI(nil)
+	_ EIPs           = qcclient.QingCloudAPI(nil)
+	_ Interface      = &static.Provider{}
+)
+
+// Config tells the provider how to label the resources it creates for the cluster, they are not labeled if
+// ClusterName is empty
+type Config struct {
+	ClusterName string
+	ExtraLabels []string
+	// MaxIPsPerNIC is the max number of private ips of a nic, the primary one included
+	MaxIPsPerNIC int
+}
+
+// Factory creates a provider, config is nil for the defaults
+type Factory func(config *Config) (Interface, error)
+
+var providers = map[string]Factory{
+	QingCloud: newQingCloud,
+	Static:    newStatic,
+}
+
+// New creates the provider of the name
+func New(name string, config *Config) (Interface, error) {
+	factory, ok := providers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown cloud provider %q, the providers are %v", name, Names())
+	}
+	return factory(config)
+}
+
+// Names returns the names of all providers
+func Names() []string {
+	result := make([]string, 0, len(providers))
+	for name := range providers {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// newQingCloud labels the vxnets and nics with tags of QingCloud
+func newQingCloud(config *Config) (Interface, error) {
+	if config == nil {
+		return qcclient.NewQingCloudClient(nil)
+	}
+	var labelConfig *qcclient.LabelResourceConfig
+	if config.ClusterName != "" {
+		labelConfig = &qcclient.LabelResourceConfig{
+			ClusterName: config.ClusterName,
+			ExtraLabels: config.ExtraLabels,
+		}
+	}
+	return qcclient.NewQingCloudClientWithOptions(labelConfig, qcclient.ClientOptions{MaxIPsPerNIC: config.MaxIPsPerNIC})
+}
+
+// newStatic ignores the config, the interfaces on the host are not created by hostnic, so they are not labeled
+func newStatic(_ *Config) (Interface, error) {
+	p, err := static.NewProvider()
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
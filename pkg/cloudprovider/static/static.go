@@ -0,0 +1,487 @@
+package static
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/yunify/hostnic-cni/pkg/errors"
+	"github.com/yunify/hostnic-cni/pkg/types"
+)
+
+const (
+	// envConfigFile is the path of the config file of the static provider
+	envConfigFile     = "HOSTNIC_STATIC_CONFIG"
+	defaultConfigFile = "/etc/hostnic/static.json"
+	// defaultStateFile is kept on the host like the checkpoint of ipamd
+	defaultStateFile = "/host/var/lib/hostnic/static.json"
+)
+
+// Config describes the network of a bare metal host. The nics are created in advance, the provider hands them out
+// to hostnic when it creates nics, and takes them back when it deletes nics.
+type Config struct {
+	// InstanceID is the id of the host, it is the hostname if empty
+	InstanceID string         `json:"instanceID"`
+	VPC        VPCConfig      `json:"vpc"`
+	Subnets    []SubnetConfig `json:"subnets"`
+	PrimaryNIC NICConfig      `json:"primaryNIC"`
+	NICs       []NICConfig    `json:"nics"`
+	// StateFile keeps the private ips assigned to the nics between restarts, so that the ones used by pods are not
+	// assigned again. It is defaultStateFile if the config is read from a file, otherwise nothing is kept.
+	StateFile string `json:"stateFile"`
+}
+
+// VPCConfig is the network all subnets are in
+type VPCConfig struct {
+	ID      string `json:"id"`
+	Network string `json:"network"`
+}
+
+// SubnetConfig is a subnet of the vpc, which is a vxnet to hostnic
+type SubnetConfig struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Network string `json:"network"`
+	Gateway string `json:"gateway"`
+}
+
+// NICConfig is an interface on the host
+type NICConfig struct {
+	MAC          string `json:"mac"`
+	Subnet       string `json:"subnet"`
+	Address      string `json:"address"`
+	DeviceNumber int    `json:"deviceNumber"`
+	// PrivateIPs are the addresses which can be assigned to the nic as secondary private ips
+	PrivateIPs []string `json:"privateIPs"`
+}
+
+var errNotSupported = fmt.Errorf("not supported by the static provider")
+
+// Provider manages the nics on a bare metal host by its config. It has no security groups, eips or labels.
+type Provider struct {
+	lock sync.Mutex
+
+	instanceID string
+	vpc        *types.VPC
+	subnets    map[string]*types.VxNet
+	primaryNic *types.HostNic
+	nics       map[string]*types.HostNic
+	// attached keeps the nics handed out to hostnic. All nics are handed out when the provider starts, because
+	// hostnic may have used them before it restarts.
+	attached map[string]bool
+	// freePrivateIPs keeps the private ips which can be assigned to each nic
+	freePrivateIPs map[string][]string
+	stateFile      string
+}
+
+// NewProvider creates a static provider by the config file
+func NewProvider() (*Provider, error) {
+	path := os.Getenv(envConfigFile)
+	if path == "" {
+		path = defaultConfigFile
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read config of static provider from %s, err: %v", path, err)
+	}
+	config := &Config{}
+	if err = json.Unmarshal(content, config); err != nil {
+		return nil, fmt.Errorf("Failed to parse config of static provider in %s, err: %v", path, err)
+	}
+	if config.StateFile == "" {
+		config.StateFile = defaultStateFile
+	}
+	return NewProviderFromConfig(config)
+}
+
+// NewProviderFromConfig creates a static provider by the config
+func NewProviderFromConfig(config *Config) (*Provider, error) {
+	p := &Provider{
+		instanceID:     config.InstanceID,
+		subnets:        make(map[string]*types.VxNet),
+		nics:           make(map[string]*types.HostNic),
+		attached:       make(map[string]bool),
+		freePrivateIPs: make(map[string][]string),
+		stateFile:      config.StateFile,
+	}
+	if p.instanceID == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, err
+		}
+		p.instanceID = hostname
+	}
+	_, vpcNetwork, err := net.ParseCIDR(config.VPC.Network)
+	if err != nil {
+		return nil, fmt.Errorf("invalid network %q of vpc, err: %v", config.VPC.Network, err)
+	}
+	p.vpc = &types.VPC{ID: config.VPC.ID, Network: vpcNetwork}
+	for _, subnet := range config.Subnets {
+		_, network, err := net.ParseCIDR(subnet.Network)
+		if err != nil {
+			return nil, fmt.Errorf("invalid network %q of subnet %s, err: %v", subnet.Network, subnet.ID, err)
+		}
+		p.subnets[subnet.ID] = &types.VxNet{
+			ID:       subnet.ID,
+			Name:     subnet.Name,
+			Network:  network,
+			GateWay:  subnet.Gateway,
+			RouterID: config.VPC.ID,
+		}
+	}
+	p.primaryNic, err = p.newNic(config.PrimaryNIC)
+	if err != nil {
+		return nil, err
+	}
+	p.primaryNic.IsPrimary = true
+	for _, c := range config.NICs {
+		nic, err := p.newNic(c)
+		if err != nil {
+			return nil, err
+		}
+		p.nics[nic.ID] = nic
+		p.attached[nic.ID] = true
+		p.freePrivateIPs[nic.ID] = append([]string{}, c.PrivateIPs...)
+	}
+	if err = p.loadState(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// loadState assigns the private ips in the state file to the nics again, the ones no longer in the config are
+// dropped
+func (p *Provider) loadState() error {
+	if p.stateFile == "" {
+		return nil
+	}
+	content, err := ioutil.ReadFile(p.stateFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to read state of static provider from %s, err: %v", p.stateFile, err)
+	}
+	assigned := make(map[string][]string)
+	if err = json.Unmarshal(content, &assigned); err != nil {
+		return fmt.Errorf("Failed to parse state of static provider in %s, err: %v", p.stateFile, err)
+	}
+	for id, ips := range assigned {
+		nic, ok := p.nics[id]
+		if !ok {
+			continue
+		}
+		for _, ip := range ips {
+			if free, ok := removeString(p.freePrivateIPs[id], ip); ok {
+				p.freePrivateIPs[id] = free
+				nic.SecondaryAddresses = append(nic.SecondaryAddresses, ip)
+			}
+		}
+	}
+	return nil
+}
+
+// saveState writes the private ips assigned to the nics to the state file, the file is replaced as a whole so that
+// it is never half written
+func (p *Provider) saveState() error {
+	if p.stateFile == "" {
+		return nil
+	}
+	assigned := make(map[string][]string)
+	for id, nic := range p.nics {
+		if len(nic.SecondaryAddresses) > 0 {
+			assigned[id] = nic.SecondaryAddresses
+		}
+	}
+	data, err := json.Marshal(assigned)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(p.stateFile)
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory of state %s, err: %v", p.stateFile, err)
+	}
+	f, err := ioutil.TempFile(dir, "."+filepath.Base(p.stateFile))
+	if err != nil {
+		return fmt.Errorf("failed to create state %s, err: %v", p.stateFile, err)
+	}
+	defer os.Remove(f.Name())
+	if _, err = f.Write(data); err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), p.stateFile)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write state %s, err: %v", p.stateFile, err)
+	}
+	return nil
+}
+
+// removeString removes the first s in list, it reports whether s is found
+func removeString(list []string, s string) ([]string, bool) {
+	for i, item := range list {
+		if item == s {
+			return append(list[:i:i], list[i+1:]...), true
+		}
+	}
+	return list, false
+}
+
+func (p *Provider) newNic(c NICConfig) (*types.HostNic, error) {
+	mac, err := net.ParseMAC(c.MAC)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mac %q of nic, err: %v", c.MAC, err)
+	}
+	subnet, ok := p.subnets[c.Subnet]
+	if !ok && c.Subnet != "" {
+		return nil, fmt.Errorf("unknown subnet %s of nic %s", c.Subnet, c.MAC)
+	}
+	if !ok {
+		subnet = &types.VxNet{}
+	}
+	if net.ParseIP(c.Address) == nil {
+		return nil, fmt.Errorf("invalid address %q of nic %s", c.Address, c.MAC)
+	}
+	return &types.HostNic{
+		ID:           mac.String(),
+		VxNet:        subnet,
+		HardwareAddr: mac.String(),
+		Address:      c.Address,
+		DeviceNumber: c.DeviceNumber,
+	}, nil
+}
+
+// copyNic copies a nic before it is returned, because hostnic modifies the nics it gets
+func copyNic(nic *types.HostNic) *types.HostNic {
+	result := *nic
+	result.SecondaryAddresses = append([]string(nil), nic.SecondaryAddresses...)
+	return &result
+}
+
+func (p *Provider) GetInstanceID() string {
+	return p.instanceID
+}
+
+func (p *Provider) CreateNic(ctx context.Context, vxnet string) (*types.HostNic, error) {
+	nics, err := p.CreateNics(ctx, vxnet, 1)
+	if err != nil {
+		return nil, err
+	}
+	return nics[0], nil
+}
+
+// CreateNics hands out the nics in the subnet which are not used by hostnic
+func (p *Provider) CreateNics(_ context.Context, vxnet string, count int) ([]*types.HostNic, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	result := make([]*types.HostNic, 0, count)
+	for _, nic := range p.nics {
+		if len(result) == count {
+			break
+		}
+		if nic.VxNet.ID == vxnet && !p.attached[nic.ID] {
+			p.attached[nic.ID] = true
+			result = append(result, copyNic(nic))
+		}
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("no free nic in subnet %s", vxnet)
+	}
+	if len(result) < count {
+		return result, fmt.Errorf("only %d free nics of %d in subnet %s", len(result), count, vxnet)
+	}
+	return result, nil
+}
+
+// DeleteNic takes back a nic, which stays on the host
+func (p *Provider) DeleteNic(nicID string) error {
+	return p.DeleteNics([]string{nicID})
+}
+
+func (p *Provider) DeleteNics(nicIDs []string) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for _, id := range nicIDs {
+		if _, ok := p.nics[id]; !ok {
+			return errors.NewResourceNotFoundError(types.ResourceTypeNic, id)
+		}
+	}
+	for _, id := range nicIDs {
+		delete(p.attached, id)
+	}
+	return nil
+}
+
+func (p *Provider) GetPrimaryNIC() (*types.HostNic, error) {
+	return copyNic(p.primaryNic), nil
+}
+
+func (p *Provider) GetNics(ids []string) ([]*types.HostNic, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	result := make([]*types.HostNic, 0, len(ids))
+	for _, id := range ids {
+		if nic, ok := p.nics[id]; ok {
+			result = append(result, copyNic(nic))
+		}
+	}
+	return result, nil
+}
+
+func (p *Provider) GetAttachedNICs(vxnet string) ([]*types.HostNic, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	result := make([]*types.HostNic, 0)
+	for _, nic := range p.nics {
+		if nic.VxNet.ID == vxnet && p.attached[nic.ID] {
+			result = append(result, copyNic(nic))
+		}
+	}
+	return result, nil
+}
+
+func (p *Provider) GetVxNet(vxNet string) (*types.VxNet, error) {
+	subnet, ok := p.subnets[vxNet]
+	if !ok {
+		return nil, errors.NewResourceNotFoundError(types.ResourceTypeVxnet, vxNet)
+	}
+	result := *subnet
+	return &result, nil
+}
+
+func (p *Provider) GetVxNets(ids []string) ([]*types.VxNet, error) {
+	result := make([]*types.VxNet, 0, len(ids))
+	for _, id := range ids {
+		if subnet, ok := p.subnets[id]; ok {
+			v := *subnet
+			result = append(result, &v)
+		}
+	}
+	return result, nil
+}
+
+func (p *Provider) GetVxNetByName(name string) (*types.VxNet, error) {
+	for _, subnet := range p.subnets {
+		if subnet.Name == name {
+			result := *subnet
+			return &result, nil
+		}
+	}
+	return nil, errors.NewResourceNotFoundError(types.ResourceTypeVxnet, name)
+}
+
+func (p *Provider) CreateVxNet(name string) (*types.VxNet, error) {
+	return nil, fmt.Errorf("failed to create subnet %s: %v, pls add it to the config", name, errNotSupported)
+}
+
+func (p *Provider) DeleteVxNet(id string) error {
+	return fmt.Errorf("failed to delete subnet %s: %v", id, errNotSupported)
+}
+
+func (p *Provider) GetVPC(ctx context.Context, id string) (*types.VPC, error) {
+	if id != p.vpc.ID {
+		return nil, errors.NewResourceNotFoundError(types.ResourceTypeVPC, id)
+	}
+	return p.GetNodeVPC(ctx)
+}
+
+func (p *Provider) GetNodeVPC(_ context.Context) (*types.VPC, error) {
+	vxnets, _ := p.GetVPCVxNets(p.vpc.ID)
+	return &types.VPC{
+		ID:      p.vpc.ID,
+		Network: p.vpc.Network,
+		VxNets:  vxnets,
+	}, nil
+}
+
+func (p *Provider) GetVPCVxNets(id string) ([]*types.VxNet, error) {
+	if id != p.vpc.ID {
+		return nil, errors.NewResourceNotFoundError(types.ResourceTypeVPC, id)
+	}
+	result := make([]*types.VxNet, 0, len(p.subnets))
+	for _, subnet := range p.subnets {
+		v := *subnet
+		result = append(result, &v)
+	}
+	return result, nil
+}
+
+// JoinVPC succeeds only for the subnets already in the vpc, all subnets in the config are
+func (p *Provider) JoinVPC(network, vxnetID, vpcID string) error {
+	subnet, ok := p.subnets[vxnetID]
+	if !ok || vpcID != p.vpc.ID || subnet.Network.String() != network {
+		return fmt.Errorf("failed to join subnet %s to vpc %s: %v", vxnetID, vpcID, errNotSupported)
+	}
+	return nil
+}
+
+func (p *Provider) LeaveVPC(vxnetID, vpcID string) error {
+	return fmt.Errorf("failed to remove subnet %s from vpc %s: %v", vxnetID, vpcID, errNotSupported)
+}
+
+// AssignPrivateIPs assigns the private ips of the nic in the config
+func (p *Provider) AssignPrivateIPs(nicID string, count int) ([]string, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	nic, ok := p.nics[nicID]
+	if !ok {
+		return nil, errors.NewResourceNotFoundError(types.ResourceTypeNic, nicID)
+	}
+	free := p.freePrivateIPs[nicID]
+	if len(free) < count {
+		return nil, fmt.Errorf("only %d free private ips of nic %s", len(free), nicID)
+	}
+	result := append([]string(nil), free[:count]...)
+	secondary := nic.SecondaryAddresses
+	p.freePrivateIPs[nicID] = free[count:]
+	nic.SecondaryAddresses = append(secondary[:len(secondary):len(secondary)], result...)
+	if err := p.saveState(); err != nil {
+		p.freePrivateIPs[nicID] = free
+		nic.SecondaryAddresses = secondary
+		return nil, err
+	}
+	return result, nil
+}
+
+func (p *Provider) UnassignPrivateIPs(nicID string, ips ...string) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	nic, ok := p.nics[nicID]
+	if !ok {
+		return errors.NewResourceNotFoundError(types.ResourceTypeNic, nicID)
+	}
+	released := make(map[string]bool, len(ips))
+	for _, ip := range ips {
+		released[ip] = true
+	}
+	remain := make([]string, 0, len(nic.SecondaryAddresses))
+	for _, addr := range nic.SecondaryAddresses {
+		if !released[addr] {
+			remain = append(remain, addr)
+		}
+	}
+	if len(nic.SecondaryAddresses)-len(remain) != len(released) {
+		return fmt.Errorf("ips %v are not all secondary private ips of nic %s", ips, nicID)
+	}
+	secondary, free := nic.SecondaryAddresses, p.freePrivateIPs[nicID]
+	nic.SecondaryAddresses = remain
+	// an ip is given back only once even if it is released twice in a request
+	p.freePrivateIPs[nicID] = free[:len(free):len(free)]
+	for ip := range released {
+		p.freePrivateIPs[nicID] = append(p.freePrivateIPs[nicID], ip)
+	}
+	if err := p.saveState(); err != nil {
+		nic.SecondaryAddresses, p.freePrivateIPs[nicID] = secondary, free
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package static
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/yunify/hostnic-cni/pkg/errors"
+)
+
+var _ = Describe("Static", func() {
+	var config *Config
+
+	BeforeEach(func() {
+		config = &Config{
+			InstanceID: "host-1",
+			VPC:        VPCConfig{ID: "vpc-1", Network: "192.168.0.0/16"},
+			Subnets: []SubnetConfig{
+				{ID: "subnet-1", Name: "pods", Network: "192.168.1.0/24", Gateway: "192.168.1.1"},
+			},
+			PrimaryNIC: NICConfig{MAC: "52:54:00:00:00:01", Subnet: "subnet-1", Address: "192.168.1.2"},
+			NICs: []NICConfig{
+				{MAC: "52:54:00:00:00:02", Subnet: "subnet-1", Address: "192.168.1.3", PrivateIPs: []string{"192.168.1.10", "192.168.1.11"}},
+				{MAC: "52:54:00:00:00:03", Subnet: "subnet-1", Address: "192.168.1.4"},
+			},
+		}
+	})
+
+	It("Should describe the network of the host", func() {
+		p, err := NewProviderFromConfig(config)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(p.GetInstanceID()).To(Equal("host-1"))
+
+		vpc, err := p.GetNodeVPC(context.Background())
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(vpc.ID).To(Equal("vpc-1"))
+		Expect(vpc.VxNets).To(HaveLen(1))
+		Expect(p.JoinVPC("192.168.1.0/24", "subnet-1", "vpc-1")).To(Succeed())
+
+		vxnet, err := p.GetVxNetByName("pods")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(vxnet.ID).To(Equal("subnet-1"))
+		Expect(vxnet.GateWay).To(Equal("192.168.1.1"))
+		_, err = p.GetVxNet("subnet-2")
+		Expect(errors.IsResourceNotFound(err)).To(BeTrue())
+		_, err = p.CreateVxNet("other")
+		Expect(err).Should(HaveOccurred())
+
+		primary, err := p.GetPrimaryNIC()
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(primary.IsPrimary).To(BeTrue())
+		Expect(primary.Address).To(Equal("192.168.1.2"))
+	})
+
+	It("Should hand out the nics on the host and take them back", func() {
+		p, err := NewProviderFromConfig(config)
+		Expect(err).ShouldNot(HaveOccurred())
+		nics, err := p.GetAttachedNICs("subnet-1")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(nics).To(HaveLen(2))
+		_, err = p.CreateNic(context.Background(), "subnet-1")
+		Expect(err).Should(HaveOccurred())
+
+		Expect(p.DeleteNics([]string{"52:54:00:00:00:02", "52:54:00:00:00:03"})).To(Succeed())
+		nics, _ = p.GetAttachedNICs("subnet-1")
+		Expect(nics).To(BeEmpty())
+
+		nics, err = p.CreateNics(context.Background(), "subnet-1", 3)
+		Expect(err).Should(HaveOccurred())
+		Expect(nics).To(HaveLen(2))
+		nics, _ = p.GetAttachedNICs("subnet-1")
+		Expect(nics).To(HaveLen(2))
+		Expect(p.DeleteNic("52:54:00:00:00:04")).ShouldNot(Succeed())
+	})
+
+	It("Should assign the private ips of the nics", func() {
+		p, err := NewProviderFromConfig(config)
+		Expect(err).ShouldNot(HaveOccurred())
+		ips, err := p.AssignPrivateIPs("52:54:00:00:00:02", 2)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(ips).To(ConsistOf("192.168.1.10", "192.168.1.11"))
+		_, err = p.AssignPrivateIPs("52:54:00:00:00:02", 1)
+		Expect(err).Should(HaveOccurred())
+		nics, _ := p.GetNics([]string{"52:54:00:00:00:02"})
+		Expect(nics[0].SecondaryAddresses).To(HaveLen(2))
+
+		Expect(p.UnassignPrivateIPs("52:54:00:00:00:02", "192.168.1.11")).To(Succeed())
+		Expect(p.UnassignPrivateIPs("52:54:00:00:00:02", "192.168.1.11")).ShouldNot(Succeed())
+		ips, err = p.AssignPrivateIPs("52:54:00:00:00:02", 1)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(ips).To(ConsistOf("192.168.1.11"))
+	})
+
+	It("Should keep the assigned private ips between restarts", func() {
+		dir, err := ioutil.TempDir("", "hostnic-static")
+		Expect(err).ShouldNot(HaveOccurred())
+		defer os.RemoveAll(dir)
+		config.StateFile = filepath.Join(dir, "state", "static.json")
+		p, err := NewProviderFromConfig(config)
+		Expect(err).ShouldNot(HaveOccurred())
+		_, err = p.AssignPrivateIPs("52:54:00:00:00:02", 2)
+		Expect(err).ShouldNot(HaveOccurred())
+		// an ip released twice in a request is only given back once
+		Expect(p.UnassignPrivateIPs("52:54:00:00:00:02", "192.168.1.10", "192.168.1.10")).To(Succeed())
+
+		p, err = NewProviderFromConfig(config)
+		Expect(err).ShouldNot(HaveOccurred())
+		nics, _ := p.GetNics([]string{"52:54:00:00:00:02"})
+		Expect(nics[0].SecondaryAddresses).To(Equal([]string{"192.168.1.11"}))
+		ips, err := p.AssignPrivateIPs("52:54:00:00:00:02", 1)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(ips).To(ConsistOf("192.168.1.10"))
+		_, err = p.AssignPrivateIPs("52:54:00:00:00:02", 1)
+		Expect(err).Should(HaveOccurred())
+	})
+
+	It("Should load the config from the file", func() {
+		dir, err := ioutil.TempDir("", "hostnic-static")
+		Expect(err).ShouldNot(HaveOccurred())
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "static.json")
+		config.StateFile = filepath.Join(dir, "state.json")
+		content, _ := json.Marshal(config)
+		Expect(ioutil.WriteFile(path, content, 0644)).To(Succeed())
+		os.Setenv(envConfigFile, path)
+		defer os.Unsetenv(envConfigFile)
+
+		p, err := NewProvider()
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(p.GetInstanceID()).To(Equal("host-1"))
+
+		config.Subnets[0].Network = "192.168.1.0"
+		_, err = NewProviderFromConfig(config)
+		Expect(err).Should(HaveOccurred())
+	})
+})
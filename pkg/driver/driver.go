@@ -19,16 +19,16 @@ const (
 	toContainerRulePriority = 512
 	// 1024 is reserved for (ip rule not to <vpc's subnet> table main)
 	fromContainerRulePriority = 1536
+	// 1600 is reserved for (ip rule from <podIP> table <egress nic>)
+	fromContainerNICRulePriority = 1700
 
 	// main routing table number
 	mainRouteTable = unix.RT_TABLE_MAIN
-	// MTU of veth - ENI MTU defined in pkg/networkutils/network.go
-	ethernetMTU = 9001
 )
 
 // NetworkAPIs defines network API calls
 type NetworkAPIs interface {
-	SetupNS(hostVethName string, contVethName string, netnsPath string, addr *net.IPNet, table int, vpcCIDRs []string, tunnelNet string, useExternalSNAT bool) error
+	SetupNS(hostVethName string, contVethName string, netnsPath string, addr *net.IPNet, table int, mtu int, vpcCIDRs []string, tunnelNet string, useExternalSNAT bool, perNICSNAT bool) error
 	TeardownNS(addr *net.IPNet, table int) error
 }
 
@@ -61,15 +61,17 @@ type createVethPairContext struct {
 	contVethName string
 	hostVethName string
 	addr         *net.IPNet
+	mtu          int
 	netLink      netlinkwrapper.NetLink
 	ip           ipwrapper.IP
 }
 
-func newCreateVethPairContext(contVethName string, hostVethName string, addr *net.IPNet, netLink netlinkwrapper.NetLink, ip ipwrapper.IP) *createVethPairContext {
+func newCreateVethPairContext(contVethName string, hostVethName string, addr *net.IPNet, mtu int, netLink netlinkwrapper.NetLink, ip ipwrapper.IP) *createVethPairContext {
 	return &createVethPairContext{
 		contVethName: contVethName,
 		hostVethName: hostVethName,
 		addr:         addr,
+		mtu:          mtu,
 		netLink:      netLink,
 		ip:           ip,
 	}
@@ -82,7 +84,7 @@ func (createVethContext *createVethPairContext) run(hostNS ns.NetNS) error {
 		LinkAttrs: netlink.LinkAttrs{
 			Name:   createVethContext.contVethName,
 			Flags:  net.FlagUp,
-			MTU:    ethernetMTU,
+			MTU:    createVethContext.mtu,
 			TxQLen: -1,
 		},
 		PeerName: createVethContext.hostVethName,
@@ -160,12 +162,12 @@ func (createVethContext *createVethPairContext) run(hostNS ns.NetNS) error {
 }
 
 // SetupNS wires up linux networking for a pod's network
-func (os *linuxNetwork) SetupNS(hostVethName string, contVethName string, netnsPath string, addr *net.IPNet, table int, vpcCIDRs []string, tunnelNet string, useExternalSNAT bool) error {
-	klog.V(2).Infof("SetupNS: hostVethName=%s,contVethName=%s, netnsPath=%s table=%d\n", hostVethName, contVethName, netnsPath, table)
-	return setupNS(hostVethName, contVethName, netnsPath, addr, table, vpcCIDRs, useExternalSNAT, tunnelNet, os.netLink, os.containerNetlink, os.ns, os.ip)
+func (os *linuxNetwork) SetupNS(hostVethName string, contVethName string, netnsPath string, addr *net.IPNet, table int, mtu int, vpcCIDRs []string, tunnelNet string, useExternalSNAT bool, perNICSNAT bool) error {
+	klog.V(2).Infof("SetupNS: hostVethName=%s,contVethName=%s, netnsPath=%s table=%d mtu=%d\n", hostVethName, contVethName, netnsPath, table, mtu)
+	return setupNS(hostVethName, contVethName, netnsPath, addr, table, mtu, vpcCIDRs, useExternalSNAT, perNICSNAT, tunnelNet, os.netLink, os.containerNetlink, os.ns, os.ip)
 }
 
-func setupNS(hostVethName string, contVethName string, netnsPath string, addr *net.IPNet, table int, vpcCIDRs []string, useExternalSNAT bool, tunnelNet string,
+func setupNS(hostVethName string, contVethName string, netnsPath string, addr *net.IPNet, table int, mtu int, vpcCIDRs []string, useExternalSNAT bool, perNICSNAT bool, tunnelNet string,
 	netLink netlinkwrapper.NetLink, containerNetlink netlinkwrapper.NetLink, ns nswrapper.NS, ip ipwrapper.IP) error {
 	// Clean up if hostVeth exists.
 	if oldHostVeth, err := netLink.LinkByName(hostVethName); err == nil {
@@ -175,7 +177,10 @@ func setupNS(hostVethName string, contVethName string, netnsPath string, addr *n
 		klog.V(2).Infof("Clean up old hostVeth: %v\n", hostVethName)
 	}
 
-	createVethContext := newCreateVethPairContext(contVethName, hostVethName, addr, containerNetlink, ip)
+	// The IP may be used by another pod before, its flows must not be delivered to the new pod
+	flushConntrack(netLink, addr.IP)
+
+	createVethContext := newCreateVethPairContext(contVethName, hostVethName, addr, mtu, containerNetlink, ip)
 	if err := ns.WithNetNSPath(netnsPath, createVethContext.run); err != nil {
 		klog.Errorf("Failed to setup NS network %v", err)
 		return errors.Wrap(err, "setupNS network: failed to setup NS network")
@@ -260,6 +265,15 @@ func setupNS(hostVethName string, contVethName string, netnsPath string, addr *n
 				}
 				klog.V(1).Infof("Successfully added pod rule[%v] to %s", podRule, toDst)
 			}
+			if perNICSNAT {
+				// add rule: 1700: from <podIP> use table <table>, the rest of traffic leaves through the NIC of pod
+				err = addContainerRule(netLink, false, addr, fromContainerNICRulePriority, table)
+				if err != nil {
+					klog.Errorf("Failed to add per-NIC SNAT rule for %s err: %v", addr.String(), err)
+					return errors.Wrap(err, "add NS network: failed to add per-NIC SNAT rule")
+				}
+				klog.V(1).Infof("Added rule priority %d from %s table %d", fromContainerNICRulePriority, addr.String(), table)
+			}
 		}
 	}
 	return nil
@@ -327,9 +341,22 @@ func tearDownNS(addr *net.IPNet, table int, netLink netlinkwrapper.NetLink, netw
 		Dst:   addrHostAddr}); err != nil {
 		klog.Errorf("delete NS network: failed to delete host route for %s, %v", addr.String(), err)
 	}
+
+	flushConntrack(netLink, addr.IP)
 	return nil
 }
 
+// flushConntrack deletes the conntrack entries which use the ip, so that the pod receiving the ip later does not
+// get stale NAT or UDP flows of the previous owner. Failures are only logged, since conntrack may be not loaded.
+func flushConntrack(netLink netlinkwrapper.NetLink, ip net.IP) {
+	deleted, err := netLink.ConntrackDeleteFilter(netlink.ConntrackTable, unix.AF_INET, &netlinkwrapper.IPConntrackFilter{IP: ip})
+	if err != nil {
+		klog.Warningf("Failed to delete conntrack entries of %s: %v", ip, err)
+		return
+	}
+	klog.V(2).Infof("Deleted %d conntrack entries of %s", deleted, ip)
+}
+
 func deleteRuleListBySrc(networkClient networkutils.NetworkAPIs, src net.IPNet) error {
 	return networkClient.DeleteRuleListBySrc(src)
 }
@@ -33,7 +33,7 @@ var _ = Describe("Driver", func() {
 		veth.Name = contVethName
 		fakeNetlink.LinkAdd(veth)
 
-		Expect(api.SetupNS(hostVethName, contVethName, nsPath, testIP, 2, cidrs, "", false)).NotTo(HaveOccurred(), fmt.Sprintf("%+v", fakeNetlink.Links))
+		Expect(api.SetupNS(hostVethName, contVethName, nsPath, testIP, 2, 1450, cidrs, "", false, false)).NotTo(HaveOccurred(), fmt.Sprintf("%+v", fakeNetlink.Links))
 		expectVeth := &netlink.Veth{
 			LinkAttrs: netlink.NewLinkAttrs(),
 			PeerName:  hostVethName,
@@ -41,7 +41,7 @@ var _ = Describe("Driver", func() {
 		dummyip := "169.254.1.1/32"
 		expectVeth.Name = contVethName
 		expectVeth.Flags = net.FlagUp
-		expectVeth.MTU = 9001
+		expectVeth.MTU = 1450
 		Expect(expectVeth).To(Equal(fakeNetlink.Links[contVethName]))
 		Expect(fakeNetlink.Routes).To(MatchAllKeys(Keys{
 			"<nil>+" + dummyip:         Not(BeNil()),
@@ -59,11 +59,46 @@ var _ = Describe("Driver", func() {
 		}))
 
 		//teardown ns
+		podFlow := &netlink.ConntrackFlow{}
+		podFlow.Forward.SrcIP = net.ParseIP("10.10.11.2")
+		podFlow.Forward.DstIP = net.ParseIP("10.10.12.10")
+		podFlow.Reverse.SrcIP = net.ParseIP("10.10.12.10")
+		podFlow.Reverse.DstIP = testIP.IP
+		otherFlow := &netlink.ConntrackFlow{}
+		otherFlow.Forward.SrcIP = net.ParseIP("10.10.11.2")
+		otherFlow.Forward.DstIP = net.ParseIP("10.10.11.3")
+		fakeNetlink.Conntrack = []*netlink.ConntrackFlow{podFlow, otherFlow}
 		Expect(api.TeardownNS(testIP, 2)).ShouldNot(HaveOccurred())
+		Expect(fakeNetlink.Conntrack).To(Equal([]*netlink.ConntrackFlow{otherFlow}))
 		Expect(fakeNetlink.Rules).To(HaveLen(0))
 		Expect(fakeNetlink.Routes).To(MatchAllKeys(Keys{
 			"<nil>+" + dummyip: Not(BeNil()),
 			"<nil>+default":    Not(BeNil()),
 		})) //there is 2 remain routes in container which will not delete in test
 	})
+
+	It("Should route all traffic of a pod through its nic in per-NIC SNAT mode", func() {
+		fakeNetlink := fake.NewFakeNetlink()
+		fakeNs := &nswrapper.FakeNsWrapper{}
+		networkAPI := networkutils.NewFakeNetworkAPI(fakeNetlink, nil, nil, nil)
+		api := newDriverNetworkAPI(fakeNetlink, fakeNetlink, networkAPI, fakeNs, fakeNetlink)
+		hostVethName := "nic1234"
+		contVethName := "nic5678"
+		_, testIP, _ := net.ParseCIDR("10.10.10.10/32")
+		veth := &netlink.Veth{
+			LinkAttrs: netlink.NewLinkAttrs(),
+			PeerName:  hostVethName,
+		}
+		veth.Name = contVethName
+		fakeNetlink.LinkAdd(veth)
+
+		Expect(api.SetupNS(hostVethName, contVethName, "/proc/1234/netns", testIP, 2, 1450, []string{"10.10.11.0/24"}, "", false, true)).NotTo(HaveOccurred())
+		Expect(fakeNetlink.Rules).To(MatchAllKeys(Keys{
+			"no-src+10.10.10.10/32":        Not(BeNil()),
+			"10.10.10.10/32+10.10.11.0/24": Not(BeNil()),
+			"10.10.10.10/32+no-dst":        MatchFields(IgnoreExtras, Fields{"Priority": Equal(fromContainerNICRulePriority)}),
+		}))
+		Expect(api.TeardownNS(testIP, 2)).ShouldNot(HaveOccurred())
+		Expect(fakeNetlink.Rules).To(HaveLen(0))
+	})
 })
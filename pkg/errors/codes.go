@@ -0,0 +1,51 @@
+package errors
+
+// The error codes of hostnic in the output of the cni plugin. The codes below 100 are reserved by the cni spec,
+// and 100 is used by the plugins for the errors without a code.
+const (
+	CNICodeUnknown          uint = 100
+	CNICodePoolExhausted    uint = 101
+	CNICodeQuotaExceeded    uint = 102
+	CNICodeUnknownPod       uint = 103
+	CNICodeCloudThrottled   uint = 104
+	CNICodeNICNotReady      uint = 105
+	CNICodeResourceNotFound uint = 106
+	CNICodeServerError      uint = 107
+	CNICodeIPAMDNotReady    uint = 108
+)
+
+var cniCodes = map[ErrorType]uint{
+	PoolExhausted:    CNICodePoolExhausted,
+	QuotaExceeded:    CNICodeQuotaExceeded,
+	UnknownPod:       CNICodeUnknownPod,
+	CloudThrottled:   CNICodeCloudThrottled,
+	NICNotReady:      CNICodeNICNotReady,
+	ResourceNotFound: CNICodeResourceNotFound,
+	ServerError:      CNICodeServerError,
+	IPAMDNotReady:    CNICodeIPAMDNotReady,
+}
+
+// ReplyError is an error which ipamd sends to the plugin in a reply, it keeps the message of the error on ipamd
+type ReplyError struct {
+	Type    ErrorType
+	Message string
+}
+
+func (e *ReplyError) Error() string {
+	return e.Message
+}
+
+// FromReply recovers the error sent by ipamd in a reply by its type and message, the type is empty for the errors
+// without a type and for the ipamd of old versions
+func FromReply(errorType, message string) error {
+	return &ReplyError{Type: ErrorType(errorType), Message: message}
+}
+
+// CNICode returns the cni error code of the type of the error
+func CNICode(e error) uint {
+	code, ok := cniCodes[TypeOf(e)]
+	if !ok {
+		return CNICodeUnknown
+	}
+	return code
+}
@@ -0,0 +1,58 @@
+package errors_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	hostnicerrors "github.com/yunify/hostnic-cni/pkg/errors"
+	"github.com/yunify/hostnic-cni/pkg/types"
+)
+
+var _ = Describe("Codes", func() {
+	It("Should give each type of error its code", func() {
+		cases := []struct {
+			err       error
+			errorType hostnicerrors.ErrorType
+			code      uint
+		}{
+			{hostnicerrors.NewPoolExhaustedError("no free ip"), hostnicerrors.PoolExhausted, hostnicerrors.CNICodePoolExhausted},
+			{hostnicerrors.NewQuotaExceededError(types.ResourceTypeNic, "CreateNics", "quota"), hostnicerrors.QuotaExceeded, hostnicerrors.CNICodeQuotaExceeded},
+			{hostnicerrors.NewUnknownPodError("pod1"), hostnicerrors.UnknownPod, hostnicerrors.CNICodeUnknownPod},
+			{hostnicerrors.NewCloudThrottledError("DescribeNics", "throttled"), hostnicerrors.CloudThrottled, hostnicerrors.CNICodeCloudThrottled},
+			{hostnicerrors.NewNICNotReadyError("nic1", "not attached"), hostnicerrors.NICNotReady, hostnicerrors.CNICodeNICNotReady},
+			{hostnicerrors.NewResourceNotFoundError(types.ResourceTypeNic, "nic1"), hostnicerrors.ResourceNotFound, hostnicerrors.CNICodeResourceNotFound},
+			{hostnicerrors.NewCommonServerError(types.ResourceTypeNic, "nic1", "CreateNics", "failed"), hostnicerrors.ServerError, hostnicerrors.CNICodeServerError},
+			{hostnicerrors.NewIPAMDNotReadyError("starting"), hostnicerrors.IPAMDNotReady, hostnicerrors.CNICodeIPAMDNotReady},
+		}
+		for _, c := range cases {
+			Expect(hostnicerrors.TypeOf(c.err)).To(Equal(c.errorType))
+			Expect(hostnicerrors.CNICode(c.err)).To(Equal(c.code), string(c.errorType))
+
+			// the wrapped ones keep the code of the error they wrap
+			wrapped := errors.Wrap(c.err, "failed to add network")
+			Expect(hostnicerrors.TypeOf(wrapped)).To(Equal(c.errorType))
+			Expect(hostnicerrors.CNICode(wrapped)).To(Equal(c.code), string(c.errorType))
+
+			// and so do the ones replied by ipamd
+			replied := hostnicerrors.FromReply(string(hostnicerrors.TypeOf(wrapped)), wrapped.Error())
+			Expect(replied.Error()).To(Equal(wrapped.Error()))
+			Expect(hostnicerrors.TypeOf(replied)).To(Equal(c.errorType))
+			Expect(hostnicerrors.CNICode(replied)).To(Equal(c.code), string(c.errorType))
+		}
+	})
+
+	It("Should give the plain errors the unknown code", func() {
+		err := fmt.Errorf("connection refused")
+		Expect(hostnicerrors.TypeOf(err)).To(BeEmpty())
+		Expect(hostnicerrors.CNICode(err)).To(Equal(hostnicerrors.CNICodeUnknown))
+		Expect(hostnicerrors.CNICode(errors.Wrap(err, "failed to add network"))).To(Equal(hostnicerrors.CNICodeUnknown))
+
+		// an ipamd of an old version replies the message without the type
+		replied := hostnicerrors.FromReply("", "no free ip")
+		Expect(replied.Error()).To(Equal("no free ip"))
+		Expect(hostnicerrors.TypeOf(replied)).To(BeEmpty())
+		Expect(hostnicerrors.CNICode(replied)).To(Equal(hostnicerrors.CNICodeUnknown))
+	})
+})
@@ -3,6 +3,7 @@ package errors
 import (
 	"fmt"
 
+	pkgerrors "github.com/pkg/errors"
 	"github.com/yunify/hostnic-cni/pkg/types"
 )
 
@@ -11,6 +12,18 @@ type ErrorType string
 const (
 	ResourceNotFound ErrorType = "ResourceNotFound"
 	ServerError      ErrorType = "CommonServerError"
+	// PoolExhausted means there is no free ip on the node for a pod
+	PoolExhausted ErrorType = "PoolExhausted"
+	// QuotaExceeded means the cloud refuses to create resources beyond the quota of the account
+	QuotaExceeded ErrorType = "QuotaExceeded"
+	// UnknownPod means there is no ip of the pod on the node
+	UnknownPod ErrorType = "UnknownPod"
+	// CloudThrottled means the requests to the cloud are still throttled after retries
+	CloudThrottled ErrorType = "CloudThrottled"
+	// NICNotReady means a nic is not attached to the node in time
+	NICNotReady ErrorType = "NICNotReady"
+	// IPAMDNotReady means ipamd is not able to serve the requests of the plugin yet
+	IPAMDNotReady ErrorType = "IPAMDNotReady"
 )
 
 // Error is an implementation of the 'error' interface, which represents an
@@ -25,6 +38,9 @@ type Error struct {
 
 //Error is method of error interface
 func (e *Error) Error() string {
+	if e.Action == "" && e.ResourceType == "" && e.ResouceName == "" {
+		return fmt.Sprintf("[%s] %s", e.Type, e.Message)
+	}
 	return fmt.Sprintf("[%s] happened when [%s] type: [%s] name: [%s], msg: [%s]", e.Type, e.Action, e.ResourceType, e.ResouceName, e.Message)
 }
 
@@ -41,11 +57,7 @@ func NewResourceNotFoundError(resource types.ResourceType, name string, message
 }
 
 func IsResourceNotFound(e error) bool {
-	er, ok := e.(*Error)
-	if ok && er.Type == ResourceNotFound {
-		return true
-	}
-	return false
+	return TypeOf(e) == ResourceNotFound
 }
 
 func NewCommonServerError(resource types.ResourceType, name, action, message string) error {
@@ -58,9 +70,76 @@ func NewCommonServerError(resource types.ResourceType, name, action, message str
 }
 
 func IsCommonServerError(e error) bool {
-	er, ok := e.(*Error)
-	if ok && er.Type == ServerError {
-		return true
+	return TypeOf(e) == ServerError
+}
+
+// TypeOf returns the type of the error or the error it wraps, it is empty if the error is neither an *Error nor a
+// *ReplyError
+func TypeOf(e error) ErrorType {
+	switch er := pkgerrors.Cause(e).(type) {
+	case *Error:
+		return er.Type
+	case *ReplyError:
+		return er.Type
+	}
+	return ""
+}
+
+func NewPoolExhaustedError(message string) error {
+	return &Error{Type: PoolExhausted, Message: message}
+}
+
+func IsPoolExhausted(e error) bool {
+	return TypeOf(e) == PoolExhausted
+}
+
+func NewQuotaExceededError(resource types.ResourceType, action, message string) error {
+	return &Error{
+		Type:         QuotaExceeded,
+		ResourceType: resource,
+		Message:      message,
+		Action:       action,
+	}
+}
+
+func IsQuotaExceeded(e error) bool {
+	return TypeOf(e) == QuotaExceeded
+}
+
+func NewUnknownPodError(message string) error {
+	return &Error{Type: UnknownPod, Message: message}
+}
+
+func IsUnknownPod(e error) bool {
+	return TypeOf(e) == UnknownPod
+}
+
+func NewCloudThrottledError(action, message string) error {
+	return &Error{Type: CloudThrottled, Message: message, Action: action}
+}
+
+func IsCloudThrottled(e error) bool {
+	return TypeOf(e) == CloudThrottled
+}
+
+func NewNICNotReadyError(name, message string) error {
+	return &Error{
+		Type:         NICNotReady,
+		ResourceType: types.ResourceTypeNic,
+		ResouceName:  name,
+		Message:      message,
+		Action:       "AttachNic",
 	}
-	return false
+}
+
+func IsNICNotReady(e error) bool {
+	return TypeOf(e) == NICNotReady
+}
+
+func NewIPAMDNotReadyError(message string) error {
+	return &Error{Type: IPAMDNotReady, Message: message}
+}
+
+func IsIPAMDNotReady(e error) bool {
+	return TypeOf(e) == IPAMDNotReady
 }
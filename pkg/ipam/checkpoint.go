@@ -0,0 +1,398 @@
+package ipam
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/yunify/hostnic-cni/pkg/ipam/datastore"
+	"github.com/yunify/hostnic-cni/pkg/k8sclient"
+	"github.com/yunify/hostnic-cni/pkg/retry"
+	"github.com/yunify/hostnic-cni/pkg/types"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog"
+)
+
+const (
+	// bootIDFile changes when the host reboots, the pods and the network of the host are gone then
+	bootIDFile = "/proc/sys/kernel/random/boot_id"
+
+	// envPodName and envPodNamespace locate the pod of ipamd, whose DaemonSet tells whether it restarts
+	envPodName      = "MY_POD_NAME"
+	envPodNamespace = "WATCH_NAMESPACE"
+)
+
+// checkpoint is the state ipamd hands over to the next one when it restarts, e.g. when it is upgraded. It is only
+// written on graceful shutdown, and removed once the next ipamd has verified the restored state against the cloud,
+// so that a stale one is never restored after a crash. It is rewritten while the state can not be verified.
+type checkpoint struct {
+	datastore.Checkpoint
+	BootID        string           `json:"bootID"`
+	InstanceID    string           `json:"instanceID"`
+	VPC           *types.VPC       `json:"vpc"`
+	VxNet         *types.VxNet     `json:"vxnet"`
+	PrimaryNIC    *types.HostNic   `json:"primaryNIC"`
+	SecurityGroup string           `json:"securityGroup,omitempty"`
+	AttachedNICs  []*types.HostNic `json:"attachedNICs"`
+}
+
+func bootID() string {
+	data, err := ioutil.ReadFile(bootIDFile)
+	if err != nil {
+		klog.Warningf("Failed to read boot id: %v", err)
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// takeCheckpoint returns the checkpoint of ipamd, nil if it is not set up yet
+func (s *IpamD) takeCheckpoint() *checkpoint {
+	if stage, _ := s.readiness.status(); stage < stageHostNetworkReady {
+		return nil
+	}
+	cp := &checkpoint{
+		Checkpoint:    *s.dataStore.Checkpoint(),
+		BootID:        bootID(),
+		InstanceID:    s.InstanceID,
+		VPC:           s.vpc,
+		VxNet:         s.vxnet,
+		PrimaryNIC:    s.primaryNic,
+		SecurityGroup: s.securityGroup,
+	}
+	s.nicLock.Lock()
+	for _, nic := range s.nics {
+		cp.AttachedNICs = append(cp.AttachedNICs, nic)
+	}
+	s.nicLock.Unlock()
+	return cp
+}
+
+// writeCheckpoint writes the checkpoint with writeFileAtomically, so that a partial checkpoint is never left behind
+func writeCheckpoint(path string, cp *checkpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal checkpoint")
+	}
+	return errors.Wrap(writeFileAtomically(path, data, 0600), "failed to write checkpoint")
+}
+
+// writeFileAtomically writes data to a temporary file in the same directory first, which is renamed to path then,
+// so that readers of path never see a partial file
+func writeFileAtomically(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return errors.Wrapf(err, "failed to create directory of %s", path)
+	}
+	f, err := ioutil.TempFile(dir, "."+filepath.Base(path))
+	if err != nil {
+		return errors.Wrapf(err, "failed to create %s", path)
+	}
+	defer os.Remove(f.Name())
+	if _, err = f.Write(data); err == nil {
+		err = f.Chmod(perm)
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to write %s", path)
+	}
+	if err = os.Rename(f.Name(), path); err != nil {
+		return errors.Wrapf(err, "failed to write %s", path)
+	}
+	return nil
+}
+
+// readCheckpoint reads the checkpoint, it returns nil if there is none. The checkpoint which can not be restored on
+// this host is removed.
+func readCheckpoint(path string) (*checkpoint, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read checkpoint %s", path)
+	}
+	cp := &checkpoint{}
+	if err = json.Unmarshal(data, cp); err != nil {
+		err = errors.Wrapf(err, "failed to unmarshal checkpoint %s", path)
+		cp = nil
+	}
+	switch {
+	case cp == nil:
+	case cp.Version != datastore.CheckpointVersion:
+		klog.Warningf("Ignore checkpoint of version %d, which is not %d", cp.Version, datastore.CheckpointVersion)
+		cp = nil
+	case cp.BootID == "" || cp.BootID != bootID():
+		klog.Warningf("Ignore checkpoint written before the host reboots")
+		cp = nil
+	case cp.VPC == nil || cp.VxNet == nil || cp.PrimaryNIC == nil:
+		err = errors.Errorf("checkpoint %s is incomplete", path)
+		cp = nil
+	}
+	if cp == nil {
+		removeCheckpoint(path)
+	}
+	return cp, err
+}
+
+// daemonSetDeleted tells whether the DaemonSet of ipamd is deleted, ipamd is removed from the node then instead of
+// restarted, and no checkpoint is left. It is false if the DaemonSet can not be found out.
+func daemonSetDeleted(clientset kubernetes.Interface) bool {
+	name, namespace := os.Getenv(envPodName), os.Getenv(envPodNamespace)
+	if name == "" || namespace == "" {
+		return false
+	}
+	pod, err := clientset.CoreV1().Pods(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		klog.Warningf("Failed to get pod %s/%s of ipamd: %v", namespace, name, err)
+		return false
+	}
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil || owner.Kind != "DaemonSet" {
+		return false
+	}
+	ds, err := clientset.AppsV1().DaemonSets(namespace).Get(owner.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return true
+	}
+	if err != nil {
+		klog.Warningf("Failed to get DaemonSet %s/%s of ipamd: %v", namespace, owner.Name, err)
+		return false
+	}
+	return ds.DeletionTimestamp != nil || ds.UID != owner.UID
+}
+
+func removeCheckpoint(path string) {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		klog.Errorf("Failed to remove checkpoint %s: %v", path, err)
+	}
+}
+
+// restore sets ipamd up with the checkpoint left by the last one instead of asking the cloud, so that it serves pods
+// as soon as it restarts. verifyRestored checks the restored state against the cloud and the host later. It returns
+// false if there is nothing restored, ipamd is only served after setup then.
+func (s *IpamD) restore() bool {
+	if s.checkpointPath == "" {
+		return false
+	}
+	cp, err := readCheckpoint(s.checkpointPath)
+	if err != nil {
+		klog.Errorf("Failed to restore checkpoint: %v", err)
+		return false
+	}
+	if cp == nil {
+		return false
+	}
+	klog.V(1).Infof("Restoring %d nics and %d pods from checkpoint", len(cp.AttachedNICs), len(cp.Pods))
+	if err = s.restoreCheckpoint(cp); err != nil {
+		klog.Errorf("Failed to restore checkpoint, set up from the cloud instead: %v", err)
+		removeCheckpoint(s.checkpointPath)
+		s.dataStore = datastore.NewDataStore()
+		s.nicLock.Lock()
+		s.nics = nil
+		s.nicLock.Unlock()
+		return false
+	}
+	s.readiness.advance(stageHostNetworkReady)
+	s.checkPoolWarmed()
+	klog.V(1).Infoln("IpamD: Everything is restored from checkpoint")
+	return true
+}
+
+// verifyRestoredUntilDone verifies the restored state until it succeeds or stopCh is closed. The checkpoint is
+// removed once it succeeds, and rewritten after each failure, since pods are served with the restored state
+// meanwhile.
+func (s *IpamD) verifyRestoredUntilDone(stopCh <-chan struct{}) {
+	ctx, cancel := retry.WithStopChannel(stopCh)
+	defer cancel()
+	err := retry.DoWithBackoff(ctx, retry.Backoff{
+		Duration: time.Second,
+		Factor:   2,
+		Jitter:   0.2,
+		Cap:      time.Minute,
+	}, func() error {
+		err := s.verifyRestored(ctx)
+		if err != nil {
+			klog.Errorf("Failed to verify the state restored from checkpoint, will retry: %v", err)
+			if flushErr := s.flushCheckpoint(); flushErr != nil {
+				klog.Errorf("Failed to rewrite checkpoint: %v", flushErr)
+			}
+		}
+		return err
+	})
+	if err != nil {
+		klog.Warningf("Stop verifying the state restored from checkpoint: %v", err)
+		return
+	}
+	removeCheckpoint(s.checkpointPath)
+	klog.V(1).Infoln("IpamD: The state restored from checkpoint is verified")
+}
+
+// verifyRestored checks the restored state against the cloud, sets up the nics attached and the pods created since
+// the last ipamd stopped, and removes the ones which are gone. The vpc, the vxnet, the primary nic and the security
+// group are read by the handlers of pods without a lock, so they are compared instead of replaced.
+func (s *IpamD) verifyRestored(ctx context.Context) error {
+	s.poolLock.Lock()
+	defer s.poolLock.Unlock()
+	s.hostNetworkLock.Lock()
+	defer s.hostNetworkLock.Unlock()
+
+	vpc, err := s.qcClient.GetNodeVPC(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get vpc of the node")
+	}
+	if vpc.ID != s.vpc.ID {
+		return errors.Errorf("the node is in vpc %s instead of the restored %s", vpc.ID, s.vpc.ID)
+	}
+	if _, err = s.qcClient.GetVxNet(s.vxnet.ID); err != nil {
+		return errors.Wrapf(err, "failed to get the restored vxnet %s", s.vxnet.ID)
+	}
+	primaryNic, err := s.qcClient.GetPrimaryNIC()
+	if err != nil {
+		return errors.Wrap(err, "failed to get primary nic")
+	}
+	if primaryNic == nil || primaryNic.ID != s.primaryNic.ID {
+		return errors.Errorf("the primary nic of the node is not the restored %s", s.primaryNic.ID)
+	}
+	attachedNICs, err := s.qcClient.GetAttachedNICs(s.vxnet.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get attached nics")
+	}
+	for _, nic := range attachedNICs {
+		if s.getNic(nic.ID) != nil {
+			continue
+		}
+		if err = s.setupNic(nic); err != nil {
+			return errors.Wrapf(err, "failed to set up nic %s", nic.ID)
+		}
+		klog.V(2).Infof("Set up nic %s attached after the checkpoint is taken", nic.ID)
+	}
+	if err = s.setupLocalPods(ctx); err != nil {
+		return err
+	}
+	if err = s.removeStalePods(); err != nil {
+		return err
+	}
+	if err = s.removeStaleNICs(attachedNICs); err != nil {
+		return err
+	}
+	s.checkPoolWarmed()
+	return nil
+}
+
+// removeStalePods releases the ips of the restored pods which are gone from the node, e.g. the ones deleted while
+// ipamd is restarting
+func (s *IpamD) removeStalePods() error {
+	pods, err := s.K8sClient.GetCurrentNodePods()
+	if err != nil {
+		return errors.Wrap(err, "failed to get pods of the node")
+	}
+	current := make(map[string]bool, len(pods))
+	for _, pod := range pods {
+		current[pod.Namespace+"/"+pod.Name] = true
+	}
+	for _, pod := range s.dataStore.GetPods() {
+		if current[pod.Namespace+"/"+pod.Name] {
+			continue
+		}
+		klog.Warningf("Release ip %s of pod %s/%s which is gone from the node", pod.IP, pod.Namespace, pod.Name)
+		_, _, err = s.dataStore.UnassignPodIPv4Address(&k8sclient.K8SPodInfo{
+			Name:      pod.Name,
+			Namespace: pod.Namespace,
+			Container: pod.Container,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to release ip %s of pod %s/%s", pod.IP, pod.Namespace, pod.Name)
+		}
+		if err = s.teardownPodEgress(pod.IP); err != nil {
+			klog.Errorf("Failed to tear down egress of pod %s/%s: %v", pod.Namespace, pod.Name, err)
+		}
+		if err = s.networkClient.DeleteRuleListBySrc(net.IPNet{IP: net.ParseIP(pod.IP), Mask: net.IPv4Mask(255, 255, 255, 255)}); err != nil {
+			klog.Errorf("Failed to delete rules of pod %s/%s: %v", pod.Namespace, pod.Name, err)
+		}
+	}
+	return nil
+}
+
+// removeStaleNICs removes the restored nics which are no longer attached to the node. The verification fails if
+// one of them is still used, so that it is retried after the pods on it are gone.
+func (s *IpamD) removeStaleNICs(attachedNICs []*types.HostNic) error {
+	attached := make(map[string]bool, len(attachedNICs))
+	for _, nic := range attachedNICs {
+		attached[nic.ID] = true
+	}
+	for _, nic := range s.getNics() {
+		if attached[nic.ID] {
+			continue
+		}
+		err := s.dataStore.RemoveNICFromDataStore(nic.ID)
+		if err != nil && err.Error() != datastore.UnknownNICError {
+			return errors.Wrapf(err, "failed to remove nic %s which is no longer attached", nic.ID)
+		}
+		klog.Warningf("Remove nic %s which is no longer attached to the node", nic.ID)
+		s.removeNic(nic.ID)
+		if err = s.networkClient.TeardownNICNetwork(nic.HardwareAddr); err != nil {
+			klog.Errorf("Failed to tear down network of nic %s: %v", nic.ID, err)
+		}
+	}
+	return nil
+}
+
+func (s *IpamD) restoreCheckpoint(cp *checkpoint) error {
+	s.parseEnv()
+	if err := s.newCloudClient(); err != nil {
+		return err
+	}
+	s.InstanceID = cp.InstanceID
+	s.vpc = cp.VPC
+	s.vxnet = cp.VxNet
+	s.primaryNic = cp.PrimaryNIC
+	s.securityGroup = cp.SecurityGroup
+
+	// the host network is kept as it is when ipamd stops, setting it up again only fills the network client
+	primaryIP := net.ParseIP(s.primaryNic.Address)
+	err := s.networkClient.SetupHostNetwork(s.vpc.Network, s.vpcSubnets(), s.primaryNic.HardwareAddr, &primaryIP)
+	if err != nil {
+		return errors.Wrap(err, "failed to set up host network")
+	}
+
+	// the data store keeps the secondary addresses which the nics have got since they were attached
+	secondaryAddresses := make(map[string][]string)
+	for _, nic := range cp.NICs {
+		for _, addr := range nic.Addresses {
+			if addr.Secondary {
+				secondaryAddresses[nic.ID] = append(secondaryAddresses[nic.ID], addr.IP)
+			}
+		}
+	}
+	for _, nic := range cp.AttachedNICs {
+		nic.SecondaryAddresses = secondaryAddresses[nic.ID]
+		if err = s.setupNic(nic); err != nil {
+			return errors.Wrapf(err, "failed to set up nic %s", nic.ID)
+		}
+	}
+	for _, pod := range cp.Pods {
+		_, _, err = s.dataStore.AssignPodIPv4Address(&k8sclient.K8SPodInfo{
+			Name:      pod.Name,
+			Namespace: pod.Namespace,
+			Container: pod.Container,
+			IP:        pod.IP,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to assign ip %s to pod %s/%s", pod.IP, pod.Namespace, pod.Name)
+		}
+	}
+	return nil
+}
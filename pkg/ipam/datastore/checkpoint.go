@@ -0,0 +1,85 @@
+package datastore
+
+import (
+	"sort"
+)
+
+// CheckpointVersion is the version of the layout of Checkpoint
+const CheckpointVersion = 1
+
+// Checkpoint is a snapshot of the nics, the addresses and the pods in the data store. ipamd keeps it on the host, so
+// that the ips assigned to pods are known after it restarts.
+type Checkpoint struct {
+	Version int             `json:"version"`
+	NICs    []CheckpointNIC `json:"nics"`
+	Pods    []CheckpointPod `json:"pods"`
+}
+
+// CheckpointNIC is a nic and its addresses in Checkpoint
+type CheckpointNIC struct {
+	ID            string              `json:"id"`
+	DeviceNumber  int                 `json:"deviceNumber"`
+	IsPrimary     bool                `json:"isPrimary,omitempty"`
+	SecurityGroup string              `json:"securityGroup,omitempty"`
+	Addresses     []CheckpointAddress `json:"addresses"`
+}
+
+// CheckpointAddress is an address of a nic in Checkpoint
+type CheckpointAddress struct {
+	IP        string `json:"ip"`
+	Secondary bool   `json:"secondary,omitempty"`
+}
+
+// CheckpointPod is a pod and its ip in Checkpoint
+type CheckpointPod struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Container string `json:"container"`
+	IP        string `json:"ip"`
+}
+
+// Checkpoint returns a snapshot of the data store, nics, addresses and pods are sorted
+func (ds *DataStore) Checkpoint() *Checkpoint {
+	ds.lock.RLock()
+	defer ds.lock.RUnlock()
+
+	checkpoint := &Checkpoint{
+		Version: CheckpointVersion,
+		NICs:    make([]CheckpointNIC, 0, len(ds.nicIPPools)),
+		Pods:    make([]CheckpointPod, 0, len(ds.podsIP)),
+	}
+	for _, nic := range ds.nicIPPools {
+		result := CheckpointNIC{
+			ID:            nic.ID,
+			DeviceNumber:  nic.DeviceNumber,
+			IsPrimary:     nic.IsPrimary,
+			SecurityGroup: nic.SecurityGroup,
+			Addresses:     make([]CheckpointAddress, 0, len(nic.IPv4Addresses)),
+		}
+		for _, addr := range nic.IPv4Addresses {
+			result.Addresses = append(result.Addresses, CheckpointAddress{IP: addr.Address, Secondary: addr.Secondary})
+		}
+		sort.Slice(result.Addresses, func(i, j int) bool { return result.Addresses[i].IP < result.Addresses[j].IP })
+		checkpoint.NICs = append(checkpoint.NICs, result)
+	}
+	sort.Slice(checkpoint.NICs, func(i, j int) bool { return checkpoint.NICs[i].DeviceNumber < checkpoint.NICs[j].DeviceNumber })
+	for key, info := range ds.podsIP {
+		checkpoint.Pods = append(checkpoint.Pods, CheckpointPod{
+			Name:      key.name,
+			Namespace: key.namespace,
+			Container: key.container,
+			IP:        info.IP,
+		})
+	}
+	sort.Slice(checkpoint.Pods, func(i, j int) bool {
+		a, b := checkpoint.Pods[i], checkpoint.Pods[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Container < b.Container
+	})
+	return checkpoint
+}
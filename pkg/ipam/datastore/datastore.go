@@ -1,11 +1,13 @@
 package datastore
 
 import (
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
+	hostnicerrors "github.com/yunify/hostnic-cni/pkg/errors"
 	k8sapi "github.com/yunify/hostnic-cni/pkg/k8sclient"
 	"k8s.io/klog"
 )
@@ -39,13 +41,25 @@ const (
 
 	// UnknownNICError is an error when caller tries to access an NIC which is unknown to datastore
 	UnknownNICError = "datastore: unknown NIC"
+
+	// NICNotReservedError is an error when caller tries to release an NIC which is not reserved
+	NICNotReservedError = "datastore: NIC is not reserved"
 )
 
 // ErrUnknownPod is an error when there is no pod in data store matching pod name, namespace, container id
-var ErrUnknownPod = errors.New("datastore: unknown pod")
+var ErrUnknownPod = hostnicerrors.NewUnknownPodError("datastore: unknown pod")
 
 // ErrUnknownPodIP is an error where pod's IP address is not found in data store
-var ErrUnknownPodIP = errors.New("datastore: pod using unknown IP address")
+var ErrUnknownPodIP = hostnicerrors.NewUnknownPodError("datastore: pod using unknown IP address")
+
+// ErrNoAvailableIP is an error when there is no IP address to assign to a pod
+var ErrNoAvailableIP = hostnicerrors.NewPoolExhaustedError("datastore: no available IP addresses")
+
+// ErrDuplicatedNIC is the error of DuplicatedNICError
+var ErrDuplicatedNIC = errors.New(DuplicatedNICError)
+
+// ErrDuplicateIP is the error of DuplicateIPError
+var ErrDuplicateIP = errors.New(DuplicateIPError)
 
 var (
 	nics = prometheus.NewGauge(
@@ -80,6 +94,11 @@ type NICIPPool struct {
 	DeviceNumber int
 	// AssignedIPv4Addresses is the number of IP addresses already been assigned
 	AssignedIPv4Addresses int
+	// Reserved is the number of users which reserve the NIC, e.g. pods using it as egress. The addresses of a
+	// reserved NIC are not assigned to pods and are not counted in the pool, and the NIC is never deleted.
+	Reserved int
+	// SecurityGroup is the security group of NIC, its addresses are only assigned to pods asking for it
+	SecurityGroup string
 	// IPv4Addresses shows whether each address is assigned, the key is IP address, which must
 	// be in dot-decimal notation with no leading zeros and no whitespace(eg: "10.1.0.253")
 	IPv4Addresses map[string]*AddressInfo
@@ -89,6 +108,7 @@ type NICIPPool struct {
 type AddressInfo struct {
 	Address        string
 	Assigned       bool // true if it is assigned to a pod
+	Secondary      bool // true if it is a secondary private IP of the NIC
 	UnassignedTime time.Time
 }
 
@@ -107,6 +127,14 @@ type PodIPInfo struct {
 	DeviceNumber int
 }
 
+// PodInfo is a pod and the ip assigned to it
+type PodInfo struct {
+	Name      string
+	Namespace string
+	Container string
+	PodIPInfo
+}
+
 // DataStore contains node level NIC/IP
 type DataStore struct {
 	total      int
@@ -159,7 +187,7 @@ func (ds *DataStore) AddNIC(nicID string, deviceNumber int, isPrimary bool) erro
 
 	_, ok := ds.nicIPPools[nicID]
 	if ok {
-		return errors.New(DuplicatedNICError)
+		return ErrDuplicatedNIC
 	}
 	ds.nicIPPools[nicID] = &NICIPPool{
 		createTime:    time.Now(),
@@ -171,8 +199,34 @@ func (ds *DataStore) AddNIC(nicID string, deviceNumber int, isPrimary bool) erro
 	return nil
 }
 
+// SetNICSecurityGroup sets the security group of an NIC, which partitions the IP pool
+func (ds *DataStore) SetNICSecurityGroup(nicID string, securityGroup string) error {
+	ds.lock.Lock()
+	defer ds.lock.Unlock()
+
+	nic, ok := ds.nicIPPools[nicID]
+	if !ok {
+		return errors.New(UnknownNICError)
+	}
+	if nic.SecurityGroup != securityGroup {
+		klog.V(1).Infof("Set security group of NIC %s from %q to %q", nicID, nic.SecurityGroup, securityGroup)
+		nic.SecurityGroup = securityGroup
+	}
+	return nil
+}
+
 // AddIPv4AddressFromStore add an IP of an NIC to data store
 func (ds *DataStore) AddIPv4AddressFromStore(nicID string, ipv4 string) error {
+	return ds.addIPv4Address(nicID, ipv4, false)
+}
+
+// AddSecondaryIPv4AddressFromStore add a secondary private IP of an NIC to data store, which can be released
+// without deleting the NIC
+func (ds *DataStore) AddSecondaryIPv4AddressFromStore(nicID string, ipv4 string) error {
+	return ds.addIPv4Address(nicID, ipv4, true)
+}
+
+func (ds *DataStore) addIPv4Address(nicID string, ipv4 string, secondary bool) error {
 	ds.lock.Lock()
 	defer ds.lock.Unlock()
 
@@ -186,14 +240,16 @@ func (ds *DataStore) AddIPv4AddressFromStore(nicID string, ipv4 string) error {
 
 	_, ok = curNIC.IPv4Addresses[ipv4]
 	if ok {
-		return errors.New(DuplicateIPError)
+		return ErrDuplicateIP
 	}
 
-	ds.total++
-	// Prometheus gauge
-	totalIPs.Set(float64(ds.total))
+	if curNIC.Reserved == 0 {
+		ds.total++
+		// Prometheus gauge
+		totalIPs.Set(float64(ds.total))
+	}
 
-	curNIC.IPv4Addresses[ipv4] = &AddressInfo{Address: ipv4, Assigned: false}
+	curNIC.IPv4Addresses[ipv4] = &AddressInfo{Address: ipv4, Assigned: false, Secondary: secondary}
 	klog.V(1).Infof("Added NIC(%s)'s IP %s to datastore", nicID, ipv4)
 	return nil
 }
@@ -219,9 +275,11 @@ func (ds *DataStore) DelIPv4AddressFromStore(nicID string, ipv4 string) error {
 		return errors.New(IPInUseError)
 	}
 
-	ds.total--
-	// Prometheus gauge
-	totalIPs.Set(float64(ds.total))
+	if curNIC.Reserved == 0 {
+		ds.total--
+		// Prometheus gauge
+		totalIPs.Set(float64(ds.total))
+	}
 
 	delete(curNIC.IPv4Addresses, ipv4)
 
@@ -232,6 +290,16 @@ func (ds *DataStore) DelIPv4AddressFromStore(nicID string, ipv4 string) error {
 // AssignPodIPv4Address assigns an IPv4 address to pod
 // It returns the assigned IPv4 address, device number, error
 func (ds *DataStore) AssignPodIPv4Address(k8sPod *k8sapi.K8SPodInfo) (string, int, error) {
+	return ds.assignPodIPv4Address(k8sPod, nil)
+}
+
+// AssignPodIPv4AddressInSecurityGroup assigns an IPv4 address of the NICs in a security group to pod
+// It returns the assigned IPv4 address, device number, error
+func (ds *DataStore) AssignPodIPv4AddressInSecurityGroup(k8sPod *k8sapi.K8SPodInfo, securityGroup string) (string, int, error) {
+	return ds.assignPodIPv4Address(k8sPod, InSecurityGroup(securityGroup))
+}
+
+func (ds *DataStore) assignPodIPv4Address(k8sPod *k8sapi.K8SPodInfo, filter NICFilter) (string, int, error) {
 	ds.lock.Lock()
 	defer ds.lock.Unlock()
 
@@ -255,11 +323,12 @@ func (ds *DataStore) AssignPodIPv4Address(k8sPod *k8sapi.K8SPodInfo) (string, in
 			ipAddr.IP, k8sPod.IP, k8sPod.Name, k8sPod.Namespace, k8sPod.Container)
 		return "", 0, errors.New("AssignPodIPv4Address: invalid pod with multiple IP addresses")
 	}
-	return ds.assignPodIPv4AddressUnsafe(k8sPod)
+	return ds.assignPodIPv4AddressUnsafe(k8sPod, filter)
 }
 
-// It returns the assigned IPv4 address, device number, error
-func (ds *DataStore) assignPodIPv4AddressUnsafe(k8sPod *k8sapi.K8SPodInfo) (string, int, error) {
+// It returns the assigned IPv4 address, device number, error. A new address is only assigned from the NICs
+// selected by filter, nil selects all of them.
+func (ds *DataStore) assignPodIPv4AddressUnsafe(k8sPod *k8sapi.K8SPodInfo, filter NICFilter) (string, int, error) {
 	podKey := PodKey{
 		name:      k8sPod.Name,
 		namespace: k8sPod.Namespace,
@@ -267,6 +336,14 @@ func (ds *DataStore) assignPodIPv4AddressUnsafe(k8sPod *k8sapi.K8SPodInfo) (stri
 	}
 	curTime := time.Now()
 	for _, nic := range ds.nicIPPools {
+		if k8sPod.IP == "" && nic.Reserved > 0 {
+			klog.V(2).Infof("AssignPodIPv4Address: Skip NIC %s that is reserved", nic.ID)
+			continue
+		}
+		if k8sPod.IP == "" && filter != nil && !filter(nic) {
+			klog.V(2).Infof("AssignPodIPv4Address: Skip NIC %s in security group %q", nic.ID, nic.SecurityGroup)
+			continue
+		}
 		if (k8sPod.IP == "") && (len(nic.IPv4Addresses) == nic.AssignedIPv4Addresses) {
 			// skip this NIC, since it has no available IP addresses
 			klog.V(2).Infof("AssignPodIPv4Address: Skip NIC %s that does not have available addresses", nic.ID)
@@ -294,7 +371,7 @@ func (ds *DataStore) assignPodIPv4AddressUnsafe(k8sPod *k8sapi.K8SPodInfo) (stri
 		}
 	}
 	klog.Errorf("DataStore has no available IP addresses")
-	return "", 0, errors.New("assignPodIPv4AddressUnsafe: no available IP addresses")
+	return "", 0, ErrNoAvailableIP
 }
 
 func incrementAssignedCount(ds *DataStore, nic *NICIPPool, addr *AddressInfo) {
@@ -310,12 +387,50 @@ func (ds *DataStore) GetStats() (int, int) {
 	return ds.total, ds.assigned
 }
 
-func (ds *DataStore) getDeletableNIC() *NICIPPool {
+// GetStatsOfSecurityGroup returns total number of IP addresses and number of assigned IP addresses of the NICs in
+// a security group
+func (ds *DataStore) GetStatsOfSecurityGroup(securityGroup string) (int, int) {
+	ds.lock.RLock()
+	defer ds.lock.RUnlock()
+
+	total, assigned := 0, 0
+	for _, nic := range ds.nicIPPools {
+		if nic.Reserved > 0 || nic.SecurityGroup != securityGroup {
+			continue
+		}
+		total += len(nic.IPv4Addresses)
+		assigned += nic.AssignedIPv4Addresses
+	}
+	return total, assigned
+}
+
+// NICFilter selects NICs of the data store
+type NICFilter func(nic *NICIPPool) bool
+
+// InSecurityGroup selects the NICs in a security group
+func InSecurityGroup(securityGroup string) NICFilter {
+	return func(nic *NICIPPool) bool {
+		return nic.SecurityGroup == securityGroup
+	}
+}
+
+// NotInSecurityGroup selects the NICs which are not in a security group
+func NotInSecurityGroup(securityGroup string) NICFilter {
+	return func(nic *NICIPPool) bool {
+		return nic.SecurityGroup != securityGroup
+	}
+}
+
+func (ds *DataStore) getDeletableNIC(filters []NICFilter) *NICIPPool {
 	for _, nic := range ds.nicIPPools {
 		if nic.IsPrimary {
 			continue
 		}
 
+		if !matchNIC(nic, filters) {
+			continue
+		}
+
 		if time.Now().Sub(nic.createTime) < minLifeTime {
 			continue
 		}
@@ -324,7 +439,7 @@ func (ds *DataStore) getDeletableNIC() *NICIPPool {
 			continue
 		}
 
-		if nic.AssignedIPv4Addresses != 0 {
+		if nic.AssignedIPv4Addresses != 0 || nic.Reserved != 0 {
 			continue
 		}
 
@@ -334,13 +449,20 @@ func (ds *DataStore) getDeletableNIC() *NICIPPool {
 	return nil
 }
 
-// GetNICNeedsIP finds an NIC in the datastore that needs more IP addresses allocated
-func (ds *DataStore) GetNICNeedsIP(maxIPperNIC int, skipPrimary bool) *NICIPPool {
+// GetNICNeedsIP finds an NIC selected by all filters in the datastore that needs more IP addresses allocated.
+// Reserved NICs are skipped, because their addresses are not given to pods.
+func (ds *DataStore) GetNICNeedsIP(maxIPperNIC int, skipPrimary bool, filters ...NICFilter) *NICIPPool {
+	ds.lock.RLock()
+	defer ds.lock.RUnlock()
+
 	for _, nic := range ds.nicIPPools {
 		if skipPrimary && nic.IsPrimary {
 			klog.V(2).Infof("Skip the primary NIC for need IP check")
 			continue
 		}
+		if nic.Reserved > 0 || !matchNIC(nic, filters) {
+			continue
+		}
 		if len(nic.IPv4Addresses) < maxIPperNIC {
 			klog.V(2).Infof("Found NIC %s that has less than the maximum number of IP addresses allocated: cur=%d, max=%d",
 				nic.ID, len(nic.IPv4Addresses), maxIPperNIC)
@@ -350,14 +472,23 @@ func (ds *DataStore) GetNICNeedsIP(maxIPperNIC int, skipPrimary bool) *NICIPPool
 	return nil
 }
 
-// RemoveUnusedNICFromStore removes a deletable NIC from the data store.
+func matchNIC(nic *NICIPPool, filters []NICFilter) bool {
+	for _, filter := range filters {
+		if !filter(nic) {
+			return false
+		}
+	}
+	return true
+}
+
+// RemoveUnusedNICFromStore removes a deletable NIC selected by all filters from the data store.
 // It returns the name of the NIC which has been removed from the data store and needs to be deleted,
 // or empty string if no NIC could be removed.
-func (ds *DataStore) RemoveUnusedNICFromStore() string {
+func (ds *DataStore) RemoveUnusedNICFromStore(filters ...NICFilter) string {
 	ds.lock.Lock()
 	defer ds.lock.Unlock()
 
-	deletableNIC := ds.getDeletableNIC()
+	deletableNIC := ds.getDeletableNIC(filters)
 	if deletableNIC == nil {
 		klog.V(2).Infof("No NIC can be deleted at this time")
 		return ""
@@ -376,6 +507,33 @@ func (ds *DataStore) RemoveUnusedNICFromStore() string {
 	return removableNIC
 }
 
+// RemoveUnusedSecondaryIPv4AddressFromStore removes an unassigned secondary IP of a NIC selected by all filters
+// from the data store. It returns the NIC and the IP which needs to be released, or empty strings if no IP could
+// be removed.
+func (ds *DataStore) RemoveUnusedSecondaryIPv4AddressFromStore(filters ...NICFilter) (string, string) {
+	ds.lock.Lock()
+	defer ds.lock.Unlock()
+
+	for _, nic := range ds.nicIPPools {
+		if nic.IsPrimary || nic.Reserved > 0 || !matchNIC(nic, filters) {
+			continue
+		}
+		for ipv4, addr := range nic.IPv4Addresses {
+			if !addr.Secondary || addr.Assigned || time.Now().Sub(addr.UnassignedTime) < addressCoolingPeriod {
+				continue
+			}
+			delete(nic.IPv4Addresses, ipv4)
+			ds.total--
+			klog.V(1).Infof("RemoveUnusedSecondaryIPv4AddressFromStore %s of NIC %s: IP address pool stats: total: %d, assigned: %d",
+				ipv4, nic.ID, ds.total, ds.assigned)
+			totalIPs.Set(float64(ds.total))
+			return nic.ID, ipv4
+		}
+	}
+	klog.V(2).Infof("No secondary IP can be removed at this time")
+	return "", ""
+}
+
 // RemoveNICFromDataStore removes an NIC from the datastore.  It return nil on success or an error.
 func (ds *DataStore) RemoveNICFromDataStore(nic string) error {
 	ds.lock.Lock()
@@ -387,7 +545,7 @@ func (ds *DataStore) RemoveNICFromDataStore(nic string) error {
 	}
 
 	// Only unused NICs can be deleted
-	if nicIPPool.AssignedIPv4Addresses != 0 {
+	if nicIPPool.AssignedIPv4Addresses != 0 || nicIPPool.Reserved != 0 {
 		return errors.New(NICInUseError)
 	}
 
@@ -401,6 +559,52 @@ func (ds *DataStore) RemoveNICFromDataStore(nic string) error {
 	return nil
 }
 
+// ReserveNIC takes an NIC out of the IP pool, so that it can be used by other means than the pod IPs,
+// e.g. as an egress of pods. It can be reserved more than once, and is put back after the same times of ReleaseNIC.
+func (ds *DataStore) ReserveNIC(nicID string) error {
+	ds.lock.Lock()
+	defer ds.lock.Unlock()
+
+	nic, ok := ds.nicIPPools[nicID]
+	if !ok {
+		return errors.New(UnknownNICError)
+	}
+	if nic.Reserved == 0 {
+		if nic.IsPrimary || nic.AssignedIPv4Addresses != 0 {
+			return errors.New(NICInUseError)
+		}
+		ds.total -= len(nic.IPv4Addresses)
+		totalIPs.Set(float64(ds.total))
+	}
+	nic.Reserved++
+	klog.V(1).Infof("ReserveNIC %s: reserved %d times, IP address pool stats: total: %d, assigned: %d",
+		nicID, nic.Reserved, ds.total, ds.assigned)
+	return nil
+}
+
+// ReleaseNIC puts an NIC reserved by ReserveNIC back to the IP pool
+func (ds *DataStore) ReleaseNIC(nicID string) error {
+	ds.lock.Lock()
+	defer ds.lock.Unlock()
+
+	nic, ok := ds.nicIPPools[nicID]
+	if !ok {
+		return errors.New(UnknownNICError)
+	}
+	if nic.Reserved == 0 {
+		return errors.New(NICNotReservedError)
+	}
+	nic.Reserved--
+	if nic.Reserved == 0 {
+		ds.total += len(nic.IPv4Addresses)
+		totalIPs.Set(float64(ds.total))
+		nic.lastUnassignedTime = time.Now()
+	}
+	klog.V(1).Infof("ReleaseNIC %s: reserved %d times, IP address pool stats: total: %d, assigned: %d",
+		nicID, nic.Reserved, ds.total, ds.assigned)
+	return nil
+}
+
 // UnassignPodIPv4Address a) find out the IP address based on PodName and PodNameSpace
 // b)  mark IP address as unassigned c) returns IP address, NIC's device number, error
 func (ds *DataStore) UnassignPodIPv4Address(k8sPod *k8sapi.K8SPodInfo) (string, int, error) {
@@ -460,6 +664,33 @@ func (ds *DataStore) GetPodInfos() *map[string]PodIPInfo {
 	return &podInfos
 }
 
+// GetPods returns the pods which have ips, ordered by namespace, name and container
+func (ds *DataStore) GetPods() []PodInfo {
+	ds.lock.RLock()
+	defer ds.lock.RUnlock()
+
+	result := make([]PodInfo, 0, len(ds.podsIP))
+	for key, info := range ds.podsIP {
+		result = append(result, PodInfo{
+			Name:      key.name,
+			Namespace: key.namespace,
+			Container: key.container,
+			PodIPInfo: info,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Container < b.Container
+	})
+	return result
+}
+
 // GetNICInfos provides NIC IP information to introspection endpoint
 func (ds *DataStore) GetNICInfos() *NICInfos {
 	ds.lock.Lock()
@@ -1,6 +1,7 @@
 package datastore
 
 import (
+	"encoding/json"
 	"time"
 
 	. "github.com/onsi/ginkgo"
@@ -170,4 +171,129 @@ var _ = Describe("Datastore", func() {
 		Expect(ds.RemoveUnusedNICFromStore()).Should(Equal("nic-2"))
 		Expect(ds.GetNICInfos().TotalIPs).To(Equal(2))
 	})
+
+	It("Should not assign or delete a reserved nic", func() {
+		Expect(ds.AddNIC("nic-1", 1, true)).ShouldNot(HaveOccurred())
+		Expect(ds.AddNIC("nic-2", 2, false)).ShouldNot(HaveOccurred())
+		Expect(ds.AddIPv4AddressFromStore("nic-2", "1.1.2.2")).ShouldNot(HaveOccurred())
+		Expect(ds.ReserveNIC("nic-1")).Should(HaveOccurred())
+		Expect(ds.ReserveNIC("nic-unknown")).Should(HaveOccurred())
+
+		Expect(ds.ReserveNIC("nic-2")).ShouldNot(HaveOccurred())
+		Expect(ds.ReserveNIC("nic-2")).ShouldNot(HaveOccurred())
+		Expect(ds.GetNICInfos().TotalIPs).To(Equal(0))
+		_, _, err := ds.AssignPodIPv4Address(&k8sclient.K8SPodInfo{Name: "pod-1", Namespace: "ns-1"})
+		Expect(err).Should(HaveOccurred())
+		ds.nicIPPools["nic-2"].createTime = time.Time{}
+		Expect(ds.RemoveUnusedNICFromStore()).Should(BeEmpty())
+		Expect(ds.RemoveNICFromDataStore("nic-2")).Should(HaveOccurred())
+
+		Expect(ds.ReleaseNIC("nic-2")).ShouldNot(HaveOccurred())
+		Expect(ds.GetNICInfos().TotalIPs).To(Equal(0))
+		Expect(ds.ReleaseNIC("nic-2")).ShouldNot(HaveOccurred())
+		Expect(ds.ReleaseNIC("nic-2")).Should(HaveOccurred())
+		Expect(ds.GetNICInfos().TotalIPs).To(Equal(1))
+		ip, deviceNum, err := ds.AssignPodIPv4Address(&k8sclient.K8SPodInfo{Name: "pod-1", Namespace: "ns-1"})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(ip).To(Equal("1.1.2.2"))
+		Expect(deviceNum).To(Equal(2))
+	})
+
+	It("Should keep pods in the nics of their security groups", func() {
+		Expect(ds.AddNIC("nic-1", 1, true)).ShouldNot(HaveOccurred())
+		Expect(ds.AddNIC("nic-2", 2, false)).ShouldNot(HaveOccurred())
+		Expect(ds.AddNIC("nic-3", 3, false)).ShouldNot(HaveOccurred())
+		Expect(ds.SetNICSecurityGroup("nic-2", "sg-default")).ShouldNot(HaveOccurred())
+		Expect(ds.SetNICSecurityGroup("nic-3", "sg-secure")).ShouldNot(HaveOccurred())
+		Expect(ds.SetNICSecurityGroup("nic-unknown", "sg-secure")).Should(HaveOccurred())
+		Expect(ds.AddIPv4AddressFromStore("nic-2", "1.1.2.2")).ShouldNot(HaveOccurred())
+		Expect(ds.AddIPv4AddressFromStore("nic-3", "1.1.3.3")).ShouldNot(HaveOccurred())
+
+		total, assigned := ds.GetStatsOfSecurityGroup("sg-secure")
+		Expect(total).To(Equal(1))
+		Expect(assigned).To(Equal(0))
+		ip, deviceNum, err := ds.AssignPodIPv4AddressInSecurityGroup(&k8sclient.K8SPodInfo{Name: "pod-1", Namespace: "ns-1"}, "sg-secure")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(ip).To(Equal("1.1.3.3"))
+		Expect(deviceNum).To(Equal(3))
+		_, _, err = ds.AssignPodIPv4AddressInSecurityGroup(&k8sclient.K8SPodInfo{Name: "pod-2", Namespace: "ns-1"}, "sg-secure")
+		Expect(err).To(Equal(ErrNoAvailableIP))
+		total, assigned = ds.GetStatsOfSecurityGroup("sg-secure")
+		Expect(total).To(Equal(1))
+		Expect(assigned).To(Equal(1))
+
+		ds.nicIPPools["nic-2"].createTime = time.Time{}
+		Expect(ds.RemoveUnusedNICFromStore(NotInSecurityGroup("sg-default"))).Should(BeEmpty())
+		Expect(ds.RemoveUnusedNICFromStore(InSecurityGroup("sg-default"))).Should(Equal("nic-2"))
+	})
+
+	It("Should add and release secondary ips of a nic", func() {
+		Expect(ds.AddNIC("nic-1", 1, true)).ShouldNot(HaveOccurred())
+		Expect(ds.AddNIC("nic-2", 2, false)).ShouldNot(HaveOccurred())
+		Expect(ds.AddIPv4AddressFromStore("nic-2", "1.1.2.2")).ShouldNot(HaveOccurred())
+		Expect(ds.GetNICNeedsIP(2, true).ID).To(Equal("nic-2"))
+		Expect(ds.GetNICNeedsIP(2, true, InSecurityGroup("sg-secure"))).To(BeNil())
+		Expect(ds.AddSecondaryIPv4AddressFromStore("nic-2", "1.1.2.3")).ShouldNot(HaveOccurred())
+		Expect(ds.AddSecondaryIPv4AddressFromStore("nic-2", "1.1.2.3")).Should(HaveOccurred())
+		Expect(ds.GetNICInfos().TotalIPs).To(Equal(2))
+		Expect(ds.GetNICNeedsIP(2, true)).To(BeNil())
+
+		ip, _, err := ds.AssignPodIPv4Address(&k8sclient.K8SPodInfo{Name: "pod-1", Namespace: "ns-1", IP: "1.1.2.3"})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(ip).To(Equal("1.1.2.3"))
+		nicID, ip := ds.RemoveUnusedSecondaryIPv4AddressFromStore()
+		Expect(nicID).To(BeEmpty())
+		Expect(ip).To(BeEmpty())
+
+		_, _, err = ds.UnassignPodIPv4Address(&k8sclient.K8SPodInfo{Name: "pod-1", Namespace: "ns-1"})
+		Expect(err).ShouldNot(HaveOccurred())
+		// the ip is cooling down after it is unassigned
+		nicID, ip = ds.RemoveUnusedSecondaryIPv4AddressFromStore()
+		Expect(ip).To(BeEmpty())
+		ds.nicIPPools["nic-2"].IPv4Addresses["1.1.2.3"].UnassignedTime = time.Time{}
+		nicID, ip = ds.RemoveUnusedSecondaryIPv4AddressFromStore()
+		Expect(nicID).To(Equal("nic-2"))
+		Expect(ip).To(Equal("1.1.2.3"))
+		Expect(ds.GetNICInfos().TotalIPs).To(Equal(1))
+		nicID, ip = ds.RemoveUnusedSecondaryIPv4AddressFromStore()
+		Expect(ip).To(BeEmpty())
+	})
+
+	It("Should take a checkpoint of the nics and the pods", func() {
+		Expect(ds.AddNIC("nic-2", 2, false)).ShouldNot(HaveOccurred())
+		Expect(ds.AddNIC("nic-1", 1, true)).ShouldNot(HaveOccurred())
+		Expect(ds.AddIPv4AddressFromStore("nic-2", "1.1.2.2")).ShouldNot(HaveOccurred())
+		Expect(ds.AddSecondaryIPv4AddressFromStore("nic-2", "1.1.2.3")).ShouldNot(HaveOccurred())
+		_, _, err := ds.AssignPodIPv4Address(&k8sclient.K8SPodInfo{Name: "pod-1", Namespace: "ns-1", Container: "c-1", IP: "1.1.2.3"})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		data, err := json.Marshal(ds.Checkpoint())
+		Expect(err).ShouldNot(HaveOccurred())
+		checkpoint := &Checkpoint{}
+		Expect(json.Unmarshal(data, checkpoint)).ShouldNot(HaveOccurred())
+		Expect(checkpoint.Version).To(Equal(CheckpointVersion))
+		Expect(checkpoint.NICs).To(HaveLen(2))
+		Expect(checkpoint.NICs[0].ID).To(Equal("nic-1"))
+		Expect(checkpoint.NICs[0].IsPrimary).To(BeTrue())
+		Expect(checkpoint.NICs[1].Addresses).To(Equal([]CheckpointAddress{
+			{IP: "1.1.2.2"},
+			{IP: "1.1.2.3", Secondary: true},
+		}))
+		Expect(checkpoint.Pods).To(Equal([]CheckpointPod{{Name: "pod-1", Namespace: "ns-1", Container: "c-1", IP: "1.1.2.3"}}))
+	})
+
+	It("Should list the pods with their names apart", func() {
+		Expect(ds.AddNIC("nic-1", 1, true)).ShouldNot(HaveOccurred())
+		Expect(ds.AddIPv4AddressFromStore("nic-1", "1.1.1.1")).ShouldNot(HaveOccurred())
+		Expect(ds.AddIPv4AddressFromStore("nic-1", "1.1.1.2")).ShouldNot(HaveOccurred())
+		_, _, err := ds.AssignPodIPv4Address(&k8sclient.K8SPodInfo{Name: "pod-2", Namespace: "ns-1", Container: "c-1", IP: "1.1.1.2"})
+		Expect(err).ShouldNot(HaveOccurred())
+		_, _, err = ds.AssignPodIPv4Address(&k8sclient.K8SPodInfo{Name: "pod-1", Namespace: "ns-1", Container: "c-1", IP: "1.1.1.1"})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(ds.GetPods()).To(Equal([]PodInfo{
+			{Name: "pod-1", Namespace: "ns-1", Container: "c-1", PodIPInfo: PodIPInfo{IP: "1.1.1.1", DeviceNumber: 1}},
+			{Name: "pod-2", Namespace: "ns-1", Container: "c-1", PodIPInfo: PodIPInfo{IP: "1.1.1.2", DeviceNumber: 1}},
+		}))
+	})
 })
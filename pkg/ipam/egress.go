@@ -0,0 +1,160 @@
+package ipam
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/yunify/hostnic-cni/pkg/cloudprovider"
+	"github.com/yunify/hostnic-cni/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog"
+)
+
+const (
+	// AnnotationEgress selects the egress identity of the non-VPC traffic of a pod. The value is the id of a nic
+	// attached to the node, or the id of an eip bound to the node or to one of its nics. It can be set on a pod
+	// or on a namespace, the annotation of the pod takes precedence.
+	AnnotationEgress = "hostnic.beta.kubernetes.io/egress"
+
+	eipPrefix = "eip-"
+)
+
+// egressOf returns the egress annotation of a pod, or of its namespace if the pod does not have one
+func (s *IpamD) egressOf(pod *corev1.Pod, namespace string) string {
+	return s.podOrNamespaceAnnotation(pod, namespace, AnnotationEgress)
+}
+
+// getPod returns the pod from the cache, so that it is got only once to set up its network. It is nil if the pod is
+// not found or fails to be got, the pod is then set up as if it had no annotation rather than failing.
+func (s *IpamD) getPod(namespace, name string) *corev1.Pod {
+	pod, err := s.K8sClient.GetPod(namespace, name)
+	if apierrors.IsNotFound(err) {
+		klog.Warningf("Pod %s/%s is not found, it has no annotation", namespace, name)
+		return nil
+	}
+	if err != nil {
+		klog.Warningf("Failed to get pod %s/%s, assume it has no annotation: %v", namespace, name, err)
+		return nil
+	}
+	return pod
+}
+
+// podOrNamespaceAnnotation returns the annotation of a pod, or of its namespace if the pod does not have one.
+// It is empty if neither of them has the annotation, the pod is nil, or the namespace fails to be got.
+func (s *IpamD) podOrNamespaceAnnotation(pod *corev1.Pod, namespace, key string) string {
+	if pod != nil {
+		if value, ok := pod.Annotations[key]; ok {
+			return strings.TrimSpace(value)
+		}
+	}
+	ns, err := s.K8sClient.GetNamespace(namespace)
+	if apierrors.IsNotFound(err) {
+		return ""
+	}
+	if err != nil {
+		klog.Warningf("Failed to get namespace %s, assume it has no annotation %s: %v", namespace, key, err)
+		return ""
+	}
+	return strings.TrimSpace(ns.Annotations[key])
+}
+
+// resolveEgress returns the nic selected by the value of the egress annotation, or nil if it is the primary nic,
+// whose traffic is already SNATed to the address of the node
+func (s *IpamD) resolveEgress(value string) (*types.HostNic, error) {
+	nicID := value
+	if strings.HasPrefix(value, eipPrefix) {
+		eips, ok := s.qcClient.(cloudprovider.EIPs)
+		if !ok {
+			return nil, errors.Errorf("failed to get eip %s: not supported by the cloud provider", value)
+		}
+		eip, err := eips.GetEIP(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get eip %s", value)
+		}
+		switch {
+		case eip.ResourceType == types.ResourceTypeInstance && eip.ResourceID == s.InstanceID:
+			return nil, nil
+		case eip.ResourceType == types.ResourceTypeNic:
+			nicID = eip.ResourceID
+		default:
+			return nil, fmt.Errorf("eip %s is not bound to instance %s or its nics", value, s.InstanceID)
+		}
+	}
+	if nicID == s.primaryNic.ID {
+		return nil, nil
+	}
+	nic := s.getNic(nicID)
+	if nic == nil {
+		return nil, fmt.Errorf("nic %s is not attached to instance %s", nicID, s.InstanceID)
+	}
+	return nic, nil
+}
+
+// setupPodEgress sends the non-VPC traffic of a pod out of the nic selected by its egress annotation, with the
+// address of the nic as source. The nic is reserved, so its address is not assigned to pods while it is an egress.
+func (s *IpamD) setupPodEgress(pod *corev1.Pod, namespace, name, podIP string) error {
+	value := s.egressOf(pod, namespace)
+
+	s.egressLock.Lock()
+	defer s.egressLock.Unlock()
+
+	if value == "" {
+		return s.teardownPodEgressUnsafe(podIP)
+	}
+	nic, err := s.resolveEgress(value)
+	if err != nil {
+		return errors.Wrapf(err, "invalid egress %s of pod %s/%s", value, namespace, name)
+	}
+	if nic == nil {
+		klog.V(1).Infof("Egress %s of pod %s/%s is the primary nic", value, namespace, name)
+		return s.teardownPodEgressUnsafe(podIP)
+	}
+	if s.egress[podIP] == nic.ID {
+		return nil
+	}
+	if err = s.teardownPodEgressUnsafe(podIP); err != nil {
+		return err
+	}
+
+	if err = s.dataStore.ReserveNIC(nic.ID); err != nil {
+		return errors.Wrapf(err, "failed to reserve nic %s as egress of pod %s/%s", nic.ID, namespace, name)
+	}
+	err = s.networkClient.SetupPodEgress(net.ParseIP(podIP), net.ParseIP(nic.Address), nic.DeviceNumber)
+	if err != nil {
+		if e := s.dataStore.ReleaseNIC(nic.ID); e != nil {
+			klog.Errorf("Failed to release nic %s: %v", nic.ID, e)
+		}
+		return err
+	}
+	if s.egress == nil {
+		s.egress = make(map[string]string)
+	}
+	s.egress[podIP] = nic.ID
+	klog.V(1).Infof("Set up egress of pod %s/%s through nic %s", namespace, name, nic.ID)
+	return nil
+}
+
+// teardownPodEgress removes the egress of a pod and puts back the nic if no other pod uses it
+func (s *IpamD) teardownPodEgress(podIP string) error {
+	s.egressLock.Lock()
+	defer s.egressLock.Unlock()
+	return s.teardownPodEgressUnsafe(podIP)
+}
+
+func (s *IpamD) teardownPodEgressUnsafe(podIP string) error {
+	nicID, ok := s.egress[podIP]
+	if !ok {
+		return nil
+	}
+	if err := s.networkClient.TeardownPodEgress(net.ParseIP(podIP)); err != nil {
+		return err
+	}
+	delete(s.egress, podIP)
+	if err := s.dataStore.ReleaseNIC(nicID); err != nil {
+		return errors.Wrapf(err, "failed to release egress nic %s of pod %s", nicID, podIP)
+	}
+	return nil
+}
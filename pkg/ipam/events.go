@@ -0,0 +1,68 @@
+package ipam
+
+import (
+	"fmt"
+
+	"github.com/yunify/hostnic-cni/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// The reasons of the events recorded by ipamd on the node and the pods
+const (
+	// EventReasonIPPoolExhausted is recorded on the node and the pod when there is no ip for a pod
+	EventReasonIPPoolExhausted = "IPPoolExhausted"
+	// EventReasonQuotaExceeded is recorded when the quota of nics or ips in the cloud keeps the pool from growing
+	EventReasonQuotaExceeded = "QuotaExceeded"
+	// EventReasonCloudThrottled is recorded when the api of the cloud rejects the calls of ipamd for a while
+	EventReasonCloudThrottled = "CloudThrottled"
+	// EventReasonAddNetworkFailed is recorded on a pod when its network fails to be set up for other reasons
+	EventReasonAddNetworkFailed = "AddNetworkFailed"
+	// EventReasonNICAttachTimeout is recorded on the node when nics are not attached in time
+	EventReasonNICAttachTimeout = "NICAttachTimeout"
+	// EventReasonNICAllocationFailed is recorded on the node when the pool fails to grow for other reasons
+	EventReasonNICAllocationFailed = "NICAllocationFailed"
+)
+
+// failureReasons is the reason of the node condition and event when ipamd fails to pass the stage
+var failureReasons = map[readinessStage]string{
+	stageCloudClientReady: "CloudClientFailed",
+	stageVxNetEnsured:     "VxNetFailed",
+	stageHostNetworkReady: "HostNetworkFailed",
+	stagePoolWarmed:       "PoolWarmFailed",
+}
+
+// poolFailureReason returns the reason of an error which keeps the pool from giving ips to pods, or an empty one if
+// the error is not about the pool
+func poolFailureReason(err error) string {
+	switch {
+	case errors.IsPoolExhausted(err):
+		return EventReasonIPPoolExhausted
+	case errors.IsQuotaExceeded(err):
+		return EventReasonQuotaExceeded
+	case errors.IsCloudThrottled(err):
+		return EventReasonCloudThrottled
+	case errors.IsNICNotReady(err):
+		return EventReasonNICAttachTimeout
+	}
+	return ""
+}
+
+// recordAllocationFailure tells why the pool fails to grow on the node
+func (s *IpamD) recordAllocationFailure(err error) {
+	reason := poolFailureReason(err)
+	if reason == "" || reason == EventReasonIPPoolExhausted {
+		reason = EventReasonNICAllocationFailed
+	}
+	s.K8sClient.RecordNodeEvent(corev1.EventTypeWarning, reason, err.Error())
+}
+
+// recordAddNetworkFailure tells why a pod gets no network, on the pod and also on the node if the pool is the cause
+func (s *IpamD) recordAddNetworkFailure(namespace, name string, err error) {
+	reason := poolFailureReason(err)
+	if reason == "" {
+		reason = EventReasonAddNetworkFailed
+	} else {
+		s.K8sClient.RecordNodeEvent(corev1.EventTypeWarning, reason, fmt.Sprintf("No ip for pod %s/%s: %v", namespace, name, err))
+	}
+	s.K8sClient.RecordPodEvent(namespace, name, corev1.EventTypeWarning, reason, err.Error())
+}
@@ -1,12 +1,16 @@
 package ipam
 
 import (
+	"fmt"
 	"net"
 
+	hostnicerrors "github.com/yunify/hostnic-cni/pkg/errors"
 	"github.com/yunify/hostnic-cni/pkg/ipam/datastore"
 	k8sapi "github.com/yunify/hostnic-cni/pkg/k8sclient"
 	"github.com/yunify/hostnic-cni/pkg/rpc"
 	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"k8s.io/klog"
 )
 
@@ -26,11 +30,37 @@ func NewGRPCServerHandler(ipamd *IpamD) *GRPCServerHandler {
 func (s *GRPCServerHandler) AddNetwork(context context.Context, in *rpc.AddNetworkRequest) (*rpc.AddNetworkReply, error) {
 	klog.V(1).Infof("Received AddNetwork for NS %s, Pod %s, NameSpace %s, Container %s, ifname %s",
 		in.Netns, in.K8S_POD_NAME, in.K8S_POD_NAMESPACE, in.K8S_POD_INFRA_CONTAINER_ID, in.IfName)
+	if err := checkAPIVersion(in.APIVersion); err != nil {
+		return &rpc.AddNetworkReply{Success: false, Message: err.Error()}, nil
+	}
 
-	addr, deviceNumber, err := s.ipamd.dataStore.AssignPodIPv4Address(&k8sapi.K8SPodInfo{
+	podInfo := &k8sapi.K8SPodInfo{
 		Name:      in.K8S_POD_NAME,
 		Namespace: in.K8S_POD_NAMESPACE,
-		Container: in.K8S_POD_INFRA_CONTAINER_ID})
+		Container: in.K8S_POD_INFRA_CONTAINER_ID}
+	var addr, securityGroup string
+	var deviceNumber int
+	pod := s.ipamd.getPod(in.K8S_POD_NAMESPACE, in.K8S_POD_NAME)
+	mtu, err := s.ipamd.podMTU(pod)
+	if err == nil {
+		securityGroup = s.ipamd.podSecurityGroup(pod, in.K8S_POD_NAMESPACE)
+		addr, deviceNumber, err = s.ipamd.dataStore.AssignPodIPv4AddressInSecurityGroup(podInfo, securityGroup)
+		if err == datastore.ErrNoAvailableIP {
+			klog.Warningf("No address in security group %q for pod %s/%s, a nic will be allocated",
+				securityGroup, in.K8S_POD_NAMESPACE, in.K8S_POD_NAME)
+			s.ipamd.requestSecurityGroup(securityGroup)
+			err = s.ipamd.poolExhaustedError()
+		}
+	}
+	if err == nil {
+		err = s.ipamd.setupPodEgress(pod, in.K8S_POD_NAMESPACE, in.K8S_POD_NAME, addr)
+		if err != nil {
+			klog.Errorf("Failed to set up egress of pod %s/%s: %v", in.K8S_POD_NAMESPACE, in.K8S_POD_NAME, err)
+			if _, _, e := s.ipamd.dataStore.UnassignPodIPv4Address(podInfo); e != nil {
+				klog.Errorf("Failed to unassign ip %s of pod %s/%s: %v", addr, in.K8S_POD_NAMESPACE, in.K8S_POD_NAME, e)
+			}
+		}
+	}
 
 	subnets := make([]string, 0)
 	for _, subnet := range s.ipamd.vpcSubnets() {
@@ -50,11 +80,16 @@ func (s *GRPCServerHandler) AddNetwork(context context.Context, in *rpc.AddNetwo
 		DeviceNumber:    int32(deviceNumber),
 		UseExternalSNAT: false,
 		VPCcidrs:        subnets,
+		PerNICSNAT:      s.ipamd.networkClient.UsePerNICSNAT(),
+		MTU:             int32(mtu),
 	}
+	klog.V(1).Infof("Send AddNetworkReply: IPv4Addr %s, DeviceNumber: %d, MTU: %d, err: %v", addr, deviceNumber, mtu, err)
 	if err != nil {
+		s.ipamd.recordAddNetworkFailure(in.K8S_POD_NAMESPACE, in.K8S_POD_NAME, err)
+		// the failures are replied instead of returned, the plugins of old versions read the reply even if grpc fails
 		resp.Message = err.Error()
+		resp.ErrorType = string(hostnicerrors.TypeOf(err))
 	}
-	klog.V(1).Infof("Send AddNetworkReply: IPv4Addr %s, DeviceNumber: %d, err: %v", addr, deviceNumber, err)
 	return &resp, nil
 }
 
@@ -62,6 +97,9 @@ func (s *GRPCServerHandler) AddNetwork(context context.Context, in *rpc.AddNetwo
 func (s *GRPCServerHandler) DelNetwork(context context.Context, in *rpc.DelNetworkRequest) (*rpc.DelNetworkReply, error) {
 	klog.V(1).Infof("Received DelNetwork for IP %s, Pod %s, Namespace %s, Container %s",
 		in.IPv4Addr, in.K8S_POD_NAME, in.K8S_POD_NAMESPACE, in.K8S_POD_INFRA_CONTAINER_ID)
+	if err := checkAPIVersion(in.APIVersion); err != nil {
+		return &rpc.DelNetworkReply{Success: false, Message: err.Error()}, nil
+	}
 
 	ip, deviceNumber, err := s.ipamd.dataStore.UnassignPodIPv4Address(&k8sapi.K8SPodInfo{
 		Name:      in.K8S_POD_NAME,
@@ -78,10 +116,35 @@ func (s *GRPCServerHandler) DelNetwork(context context.Context, in *rpc.DelNetwo
 			return &rpc.DelNetworkReply{Success: true}, nil
 		}
 	}
+	if err == nil {
+		if e := s.ipamd.teardownPodEgress(ip); e != nil {
+			klog.Errorf("Failed to tear down egress of pod %s/%s: %v", in.K8S_POD_NAMESPACE, in.K8S_POD_NAME, e)
+		}
+	}
 	klog.V(1).Infof("Send DelNetworkReply: IPv4Addr %s, DeviceNumber: %d, err: %v", ip, deviceNumber, err)
-	resp := &rpc.DelNetworkReply{Success: err == nil, IPv4Addr: ip, DeviceNumber: int32(deviceNumber)}
 	if err != nil {
-		resp.Message = err.Error()
+		return &rpc.DelNetworkReply{Success: false, Message: err.Error(), ErrorType: string(hostnicerrors.TypeOf(err))}, nil
+	}
+	return &rpc.DelNetworkReply{Success: true, IPv4Addr: ip, DeviceNumber: int32(deviceNumber)}, nil
+}
+
+// Ready tells the plugin whether ipamd is able to assign ips to pods
+func (s *GRPCServerHandler) Ready(context context.Context, in *rpc.ReadyRequest) (*rpc.ReadyReply, error) {
+	if err := checkAPIVersion(in.APIVersion); err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	ready, message := s.ipamd.ready()
+	return &rpc.ReadyReply{Ready: ready, Message: message, APIVersion: rpc.APIVersion}, nil
+}
+
+// checkAPIVersion refuses the plugins which are too old to be served. The plugin on the host is replaced after ipamd
+// during upgrades, so the ones older than ipamd are served as long as they are not older than rpc.MinAPIVersion.
+func checkAPIVersion(version int32) error {
+	if version < rpc.MinAPIVersion {
+		return fmt.Errorf("hostnic plugin of api version %d is too old, %d is required at least", version, rpc.MinAPIVersion)
+	}
+	if version < rpc.APIVersion {
+		klog.V(2).Infof("Serving hostnic plugin of api version %d", version)
 	}
-	return resp, nil
+	return nil
 }
@@ -0,0 +1,98 @@
+package ipam
+
+import (
+	"net"
+	"time"
+
+	"github.com/yunify/hostnic-cni/pkg/networkutils"
+	"k8s.io/klog"
+)
+
+const (
+	hostNetworkReconcileInterval = 60 * time.Second
+	// hostNetworkReconcileDelay coalesces a burst of netlink changes into one reconciling
+	hostNetworkReconcileDelay = 2 * time.Second
+)
+
+// StartReconcileHostNetwork keeps the host network in the desired state. It reconciles periodically and soon after
+// any link, route or rule of the host changes, so that the network of pods is fixed if someone flushes the rules or
+// the routes of a NIC are lost after a link flap.
+func (s *IpamD) StartReconcileHostNetwork(stopCh <-chan struct{}, interval ...time.Duration) {
+	klog.V(1).Infoln("Starting host network reconciling")
+	reconcileInterval := hostNetworkReconcileInterval
+	if len(interval) == 1 {
+		reconcileInterval = interval[0]
+	}
+
+	changes := make(chan struct{}, 1)
+	if err := s.networkClient.SubscribeChanges(changes, stopCh); err != nil {
+		klog.Errorf("Failed to subscribe netlink changes, only reconcile host network every %s: %v", reconcileInterval, err)
+	}
+	ticker := time.NewTicker(reconcileInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			klog.V(1).Infoln("Receive stop signal, stop host network reconciling")
+			return
+		case <-ticker.C:
+		case <-changes:
+			select {
+			case <-stopCh:
+				klog.V(1).Infoln("Receive stop signal, stop host network reconciling")
+				return
+			case <-time.After(hostNetworkReconcileDelay):
+			}
+			select {
+			case <-changes:
+			default:
+			}
+		}
+		s.reconcileHostNetwork()
+	}
+}
+
+// reconcileHostNetwork checks the rules, routes and sysctls set up by hostnic and fixes them if they drift. The pool
+// is locked, so that the network of a nic or a pod being released is not set up again.
+func (s *IpamD) reconcileHostNetwork() {
+	s.poolLock.Lock()
+	defer s.poolLock.Unlock()
+	s.hostNetworkLock.Lock()
+	defer s.hostNetworkLock.Unlock()
+	klog.V(3).Infoln("Begin to reconcile host network")
+	if err := s.networkClient.ReconcileHostNetwork(); err != nil {
+		klog.Errorf("Failed to reconcile host network: %v", err)
+	}
+
+	for _, nic := range s.getNics() {
+		if nic.IsPrimary {
+			continue
+		}
+		err := s.networkClient.ReconcileNICNetwork(nic.Address, nic.HardwareAddr, nic.DeviceNumber, s.vxnet.Network.String())
+		if err != nil {
+			klog.Errorf("Failed to reconcile network of nic %s: %v", nic.ID, err)
+		}
+	}
+
+	s.reconcilePodRules()
+}
+
+// reconcilePodRules adds the ip rules of pods in data store which are missing
+func (s *IpamD) reconcilePodRules() {
+	rules, err := s.networkClient.GetRuleList()
+	if err != nil {
+		klog.Errorf("Failed to reconcile pod rules, failed to retrieve IP rule list: %v", err)
+		return
+	}
+	var vpcCIDRs []string
+	for _, cidr := range s.vpcSubnets() {
+		vpcCIDRs = append(vpcCIDRs, *cidr)
+	}
+	for key, pod := range *s.dataStore.GetPodInfos() {
+		srcIPNet := net.IPNet{IP: net.ParseIP(pod.IP), Mask: net.IPv4Mask(255, 255, 255, 255)}
+		toCIDRs := append(vpcCIDRs[:len(vpcCIDRs):len(vpcCIDRs)], networkutils.GetVPNNet(pod.IP))
+		if err := s.networkClient.EnsurePodRules(rules, srcIPNet, toCIDRs, pod.DeviceNumber); err != nil {
+			klog.Errorf("Failed to reconcile rules of pod %s: %v", key, err)
+		}
+	}
+}
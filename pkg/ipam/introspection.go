@@ -0,0 +1,353 @@
+package ipam
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/vishvananda/netlink"
+	k8sapi "github.com/yunify/hostnic-cni/pkg/k8sclient"
+	"github.com/yunify/hostnic-cni/pkg/networkutils"
+	"github.com/yunify/hostnic-cni/pkg/rpc"
+	"golang.org/x/net/context"
+	"golang.org/x/sys/unix"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog"
+)
+
+// IntrospectionHandler serves hostnicctl, which looks into the pool and the host network of ipamd and fixes them
+type IntrospectionHandler struct {
+	ipamd *IpamD
+}
+
+// NewIntrospectionHandler creates the handler of the introspection service
+func NewIntrospectionHandler(ipamd *IpamD) *IntrospectionHandler {
+	return &IntrospectionHandler{
+		ipamd: ipamd,
+	}
+}
+
+// podIP is an ip assigned to a pod in the data store
+type podIP struct {
+	name, namespace, container string
+	ip                         string
+	deviceNumber               int
+}
+
+func (p *podIP) String() string {
+	return p.namespace + "/" + p.name
+}
+
+// podIPs returns the ips assigned to pods, ordered by namespace and name
+func (s *IpamD) podIPs() []*podIP {
+	var result []*podIP
+	for _, pod := range s.dataStore.GetPods() {
+		result = append(result, &podIP{name: pod.Name, namespace: pod.Namespace, container: pod.Container, ip: pod.IP, deviceNumber: pod.DeviceNumber})
+	}
+	return result
+}
+
+// nicOfIP returns the id of the nic owning the ip, it is empty if no nic in the data store owns it
+func (s *IpamD) nicOfIP(ip string) string {
+	for _, nic := range s.dataStore.GetNICInfos().NICIPPools {
+		if _, ok := nic.IPv4Addresses[ip]; ok {
+			return nic.ID
+		}
+	}
+	return ""
+}
+
+func (s *IpamD) egressOfIP(ip string) string {
+	s.egressLock.Lock()
+	defer s.egressLock.Unlock()
+	return s.egress[ip]
+}
+
+// expectedPodRules returns the ip rules of the pod
+func (s *IpamD) expectedPodRules(pod *podIP) []netlink.Rule {
+	var toCIDRs []string
+	for _, cidr := range s.vpcSubnets() {
+		toCIDRs = append(toCIDRs, *cidr)
+	}
+	toCIDRs = append(toCIDRs, networkutils.GetVPNNet(pod.ip))
+	src := net.IPNet{IP: net.ParseIP(pod.ip), Mask: net.IPv4Mask(255, 255, 255, 255)}
+	return s.networkClient.ExpectedPodRules(src, toCIDRs, pod.deviceNumber)
+}
+
+// ListPods lists the pods and their ips
+func (h *IntrospectionHandler) ListPods(ctx context.Context, in *rpc.ListPodsRequest) (*rpc.ListPodsReply, error) {
+	reply := &rpc.ListPodsReply{}
+	for _, pod := range h.ipamd.podIPs() {
+		reply.Pods = append(reply.Pods, &rpc.PodIP{
+			Name:         pod.name,
+			Namespace:    pod.namespace,
+			Container:    pod.container,
+			IP:           pod.ip,
+			DeviceNumber: int32(pod.deviceNumber),
+			NIC:          h.ipamd.nicOfIP(pod.ip),
+			Egress:       h.ipamd.egressOfIP(pod.ip),
+		})
+	}
+	return reply, nil
+}
+
+// ListNICs lists the nics, their ips and the routes in their route tables
+func (h *IntrospectionHandler) ListNICs(ctx context.Context, in *rpc.ListNICsRequest) (*rpc.ListNICsReply, error) {
+	reply := &rpc.ListNICsReply{}
+	for _, nic := range h.ipamd.nodeIPPoolStatus().NICs {
+		result := &rpc.NIC{
+			ID:            nic.ID,
+			DeviceNumber:  int32(nic.DeviceNumber),
+			Primary:       nic.Primary,
+			VxNet:         nic.VxNet,
+			SecurityGroup: nic.SecurityGroup,
+			Reserved:      nic.Reserved,
+		}
+		for _, addr := range nic.Addresses {
+			result.Addresses = append(result.Addresses, &rpc.NICAddress{IP: addr.IP, Secondary: addr.Secondary, Pod: addr.Pod})
+		}
+		if !nic.Primary {
+			routes, err := h.ipamd.networkClient.GetRouteList(nic.DeviceNumber)
+			if err != nil {
+				return nil, status.Errorf(codes.Internal, "failed to list routes of table %d: %v", nic.DeviceNumber, err)
+			}
+			for _, route := range routes {
+				result.Routes = append(result.Routes, formatRoute(route))
+			}
+		}
+		reply.NICs = append(reply.NICs, result)
+	}
+	return reply, nil
+}
+
+// PoolStatus shows the readiness, the size and the targets of the pool
+func (h *IntrospectionHandler) PoolStatus(ctx context.Context, in *rpc.PoolStatusRequest) (*rpc.PoolStatusReply, error) {
+	s := h.ipamd
+	ready, message := s.ready()
+	stage, _ := s.readiness.status()
+	total, assigned := s.dataStore.GetStats()
+	reply := &rpc.PoolStatusReply{
+		Ready:        ready,
+		Message:      message,
+		Stage:        stage.String(),
+		TotalIPs:     int32(total),
+		AssignedIPs:  int32(assigned),
+		PoolSize:     int32(s.poolSize),
+		MaxPoolSize:  int32(s.maxPoolSize),
+		MaxIPsPerNIC: int32(s.maxIPsPerNIC),
+		NICs:         int32(s.dataStore.GetNICs()),
+		MaxNICs:      int32(s.maxNICs),
+	}
+	if s.vxnet != nil {
+		reply.VxNet = s.vxnet.ID
+	}
+	if err := s.lastAllocationError(); err != nil {
+		reply.AllocationError = err.Error()
+	}
+	return reply, nil
+}
+
+// ReleaseIP releases an ip leaked by a pod which is gone, e.g. when kubelet never calls the plugin to delete it.
+// The ip of a pod which still exists is only released if it is forced.
+func (h *IntrospectionHandler) ReleaseIP(ctx context.Context, in *rpc.ReleaseIPRequest) (*rpc.ReleaseIPReply, error) {
+	s := h.ipamd
+	var pod *podIP
+	for _, p := range s.podIPs() {
+		if p.ip == in.IP {
+			pod = p
+			break
+		}
+	}
+	if pod == nil {
+		return nil, status.Errorf(codes.NotFound, "ip %s is not assigned to any pod", in.IP)
+	}
+	if _, err := s.K8sClient.GetPod(pod.namespace, pod.name); err == nil && !in.Force {
+		return nil, status.Errorf(codes.FailedPrecondition, "pod %s using ip %s still exists", pod, in.IP)
+	} else if err != nil && !apierrors.IsNotFound(err) && !in.Force {
+		return nil, status.Errorf(codes.Unavailable, "failed to check whether pod %s exists: %v", pod, err)
+	}
+
+	klog.Warningf("Release ip %s of pod %s by request", in.IP, pod)
+	_, _, err := s.dataStore.UnassignPodIPv4Address(&k8sapi.K8SPodInfo{Name: pod.name, Namespace: pod.namespace, Container: pod.container})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to release ip %s: %v", in.IP, err)
+	}
+	if err := s.teardownPodEgress(in.IP); err != nil {
+		klog.Errorf("Failed to tear down egress of pod %s: %v", pod, err)
+	}
+	if err := s.networkClient.DeleteRuleListBySrc(net.IPNet{IP: net.ParseIP(in.IP), Mask: net.IPv4Mask(255, 255, 255, 255)}); err != nil {
+		klog.Errorf("Failed to delete rules of pod %s: %v", pod, err)
+	}
+	return &rpc.ReleaseIPReply{IP: in.IP, Pod: pod.String()}, nil
+}
+
+// Reconcile reconciles the pool and the host network at once, instead of waiting for the next period
+func (h *IntrospectionHandler) Reconcile(ctx context.Context, in *rpc.ReconcileRequest) (*rpc.ReconcileReply, error) {
+	s := h.ipamd
+	if stage, _ := s.readiness.status(); stage < stageHostNetworkReady {
+		return nil, status.Errorf(codes.Unavailable, "ipamd is not set up: %s", s.readiness.message())
+	}
+	klog.V(1).Infoln("Reconcile the pool and the host network by request")
+	s.updateIPPoolIfRequired(ctx)
+	s.checkPoolWarmed()
+	s.reconcileHostNetwork()
+	return &rpc.ReconcileReply{}, nil
+}
+
+// DumpRules returns the ip rules of pods, both the ones expected by ipamd and the ones installed on the host
+func (h *IntrospectionHandler) DumpRules(ctx context.Context, in *rpc.DumpRulesRequest) (*rpc.DumpRulesReply, error) {
+	s := h.ipamd
+	installed, err := s.networkClient.GetRuleList()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list ip rules: %v", err)
+	}
+	reply := &rpc.DumpRulesReply{}
+	var expected []netlink.Rule
+	for _, pod := range s.podIPs() {
+		for _, rule := range s.expectedPodRules(pod) {
+			rule := rule
+			expected = append(expected, rule)
+			reply.Rules = append(reply.Rules, &rpc.Rule{
+				Rule:      formatRule(&rule),
+				Owner:     pod.String(),
+				Expected:  true,
+				Installed: networkutils.RuleExists(installed, &rule),
+			})
+		}
+	}
+	for _, rule := range installed {
+		rule := rule
+		if !networkutils.IsPodRule(&rule) || networkutils.RuleExists(expected, &rule) {
+			continue
+		}
+		reply.Rules = append(reply.Rules, &rpc.Rule{Rule: formatRule(&rule), Installed: true})
+	}
+	return reply, nil
+}
+
+// VerifyPod checks the data path of a pod: its ip, the network of its nic, its ip rules and the route to it
+func (h *IntrospectionHandler) VerifyPod(ctx context.Context, in *rpc.VerifyPodRequest) (*rpc.VerifyPodReply, error) {
+	s := h.ipamd
+	reply := &rpc.VerifyPodReply{OK: true}
+	check := func(name string, err error) {
+		c := &rpc.Check{Name: name, OK: err == nil}
+		if err != nil {
+			c.Message = err.Error()
+			reply.OK = false
+		}
+		reply.Checks = append(reply.Checks, c)
+	}
+
+	var pod *podIP
+	for _, p := range s.podIPs() {
+		if p.name == in.Name && p.namespace == in.Namespace {
+			pod = p
+			break
+		}
+	}
+	if pod == nil {
+		check("ip", fmt.Errorf("no ip is assigned to pod %s/%s", in.Namespace, in.Name))
+		return reply, nil
+	}
+	check("ip", nil)
+
+	k8sPod, err := s.K8sClient.GetPod(pod.namespace, pod.name)
+	if err == nil && k8sPod.Status.PodIP != "" && k8sPod.Status.PodIP != pod.ip {
+		err = fmt.Errorf("ip of the pod is %s, but %s is assigned to it", k8sPod.Status.PodIP, pod.ip)
+	}
+	check("pod", err)
+
+	nicID := s.nicOfIP(pod.ip)
+	if nicID == "" {
+		err = fmt.Errorf("ip %s is not in any nic", pod.ip)
+	} else if s.vxnet != nil && s.vxnet.Network != nil {
+		var drift string
+		drift, err = s.networkClient.NICNetworkDrift(nicID, pod.deviceNumber, s.vxnet.Network.String())
+		if err == nil && drift != "" {
+			err = fmt.Errorf("network of nic %s drifts: %s", nicID, drift)
+		}
+	}
+	check("nic", err)
+
+	err = nil
+	installed, e := s.networkClient.GetRuleList()
+	if e != nil {
+		err = fmt.Errorf("failed to list ip rules: %v", e)
+	} else {
+		var missing []string
+		for _, rule := range s.expectedPodRules(pod) {
+			rule := rule
+			if !networkutils.RuleExists(installed, &rule) {
+				missing = append(missing, formatRule(&rule))
+			}
+		}
+		if len(missing) > 0 {
+			err = fmt.Errorf("rules are missing: %s", strings.Join(missing, "; "))
+		}
+	}
+	check("rules", err)
+
+	err = fmt.Errorf("route to %s is missing in table main", pod.ip)
+	routes, e := s.networkClient.GetRouteList(unix.RT_TABLE_MAIN)
+	if e != nil {
+		err = fmt.Errorf("failed to list routes of table main: %v", e)
+	}
+	for _, route := range routes {
+		if route.Dst != nil && route.Dst.IP.Equal(net.ParseIP(pod.ip)) {
+			ones, _ := route.Dst.Mask.Size()
+			if ones == 32 {
+				err = nil
+				break
+			}
+		}
+	}
+	check("route", err)
+	return reply, nil
+}
+
+// formatRule formats an ip rule like `ip rule`
+func formatRule(rule *netlink.Rule) string {
+	from, to := "all", ""
+	if rule.Src != nil {
+		from = rule.Src.IP.String()
+		if ones, _ := rule.Src.Mask.Size(); ones != 32 {
+			from = rule.Src.String()
+		}
+	}
+	if rule.Dst != nil {
+		to = " to " + rule.Dst.String()
+	}
+	mark := ""
+	if rule.Mark > 0 {
+		mark = fmt.Sprintf(" fwmark %#x", rule.Mark)
+	}
+	return fmt.Sprintf("%d: from %s%s%s lookup %s", rule.Priority, from, to, mark, tableName(rule.Table))
+}
+
+// formatRoute formats a route like `ip route`, the link is shown by its index
+func formatRoute(route netlink.Route) string {
+	dst := "default"
+	if route.Dst != nil {
+		if ones, _ := route.Dst.Mask.Size(); ones != 0 || !route.Dst.IP.Equal(net.IPv4zero) {
+			dst = route.Dst.String()
+		}
+	}
+	result := dst
+	if route.Gw != nil {
+		result += " via " + route.Gw.String()
+	}
+	result += fmt.Sprintf(" dev #%d", route.LinkIndex)
+	if route.Scope == netlink.SCOPE_LINK {
+		result += " scope link"
+	}
+	return result + " table " + tableName(route.Table)
+}
+
+func tableName(table int) string {
+	if table == unix.RT_TABLE_MAIN {
+		return "main"
+	}
+	return fmt.Sprint(table)
+}
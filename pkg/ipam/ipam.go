@@ -1,22 +1,28 @@
 package ipam
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"net"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
 	"text/template"
 	"time"
 
 	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
 	"github.com/pkg/errors"
+	"github.com/yunify/hostnic-cni/pkg/apis/hostnic/v1alpha1"
+	"github.com/yunify/hostnic-cni/pkg/cloudprovider"
 	"github.com/yunify/hostnic-cni/pkg/ipam/datastore"
 	"github.com/yunify/hostnic-cni/pkg/k8sclient"
 	"github.com/yunify/hostnic-cni/pkg/networkutils"
-	"github.com/yunify/hostnic-cni/pkg/qcclient"
 	"github.com/yunify/hostnic-cni/pkg/retry"
 	"github.com/yunify/hostnic-cni/pkg/rpc"
 	"github.com/yunify/hostnic-cni/pkg/types"
+	"golang.org/x/time/rate"
 	"google.golang.org/grpc"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog"
@@ -29,13 +35,20 @@ const (
 
 	defaultPoolSize    = 3
 	defaultMaxPoolSize = 10
-	defaultClusterName = "kubernetes"
+	// defaultMaxIPsPerNIC keeps one address per nic, so that no secondary private ip is assigned by default
+	defaultMaxIPsPerNIC = 1
+	defaultClusterName  = "kubernetes"
 
-	envExtraTags      = "HOSTNIC_EXTRA_TAGS"
-	envClusterName    = "HOSTNIC_CLUSTER_NAME"
-	envVethPrefix     = "HOSTNIC_VETH_PREFIX"
+	envExtraTags    = "HOSTNIC_EXTRA_TAGS"
+	envClusterName  = "HOSTNIC_CLUSTER_NAME"
+	envVethPrefix   = "HOSTNIC_VETH_PREFIX"
+	envMaxIPsPerNIC = "HOSTNIC_MAX_IPS_PER_NIC"
+	// envCloudProvider selects the backend managing vxnets and nics, it is qingcloud by default
+	envCloudProvider  = "HOSTNIC_CLOUD_PROVIDER"
 	defaultVethPrefix = "nic"
 	configFileName    = "/host/etc/cni/net.d/10-ahostnic.conflist"
+	// checkpointFileName keeps the checkpoint of ipamd on the host between restarts
+	checkpointFileName = "/host/var/lib/hostnic/datastore.json"
 )
 
 type nodeInfo struct {
@@ -51,7 +64,7 @@ type IpamD struct {
 	dataStore *datastore.DataStore
 
 	K8sClient     k8sclient.K8sHelper
-	qcClient      qcclient.QingCloudAPI
+	qcClient      cloudprovider.Interface
 	networkClient networkutils.NetworkAPIs
 
 	nodeInfo
@@ -61,21 +74,72 @@ type IpamD struct {
 	disableLabel       bool
 	poolSize           int
 	maxPoolSize        int
+	maxIPsPerNIC       int
+	maxNICs            int
 	supportVPNTraffic  bool
 	vethPrefix         string
-	prepareCloudClient func(*qcclient.LabelResourceConfig) (qcclient.QingCloudAPI, error)
+	prepareCloudClient func(*cloudprovider.Config) (cloudprovider.Interface, error)
+
+	// nics keeps the nics which are set up on host, by nic id
+	nics    map[string]*types.HostNic
+	nicLock sync.Mutex
+
+	// egress keeps the nic id used as egress by pod ip
+	egress     map[string]string
+	egressLock sync.Mutex
+
+	// securityGroup is the security group of the nics for the pods which do not ask for one
+	securityGroup       string
+	vxnetSecurityGroups map[string]string
+	// inheritPrimarySecurityGroup binds the nics to the security group of the primary nic if none is configured
+	inheritPrimarySecurityGroup bool
+	// securityGroupRequests keeps the security groups which pods are waiting for
+	securityGroupRequests map[string]bool
+	securityGroupLock     sync.Mutex
+
+	// poolLock and hostNetworkLock keep the periodic reconciling from running with the one requested by hostnicctl
+	poolLock        sync.Mutex
+	hostNetworkLock sync.Mutex
+
+	// allocationError is the error of the last allocation which adds nothing to the pool, nil if it succeeds
+	allocationError error
+	allocationLock  sync.Mutex
+
+	// readiness tracks the stages of setting up, pods are only served after all of them are passed
+	readiness *readiness
+	// advertisedIPs is the quantity of ResourceIP in the node status, -1 before it is updated
+	advertisedIPs int
+
+	// NodeIPPools keeps the NodeIPPool of the node up to date, it is not updated if nil
+	NodeIPPools       k8sclient.NodeIPPoolInterface
+	nodeIPPoolLimiter *rate.Limiter
+	lastNodeIPPool    *v1alpha1.NodeIPPool
+
+	grpcServer *grpc.Server
+	// workers tracks the long-running goroutines, which return after stopCh is closed
+	workers sync.WaitGroup
+	// checkpointPath is where the checkpoint is flushed on shutdown and restored from on start, none is used if empty
+	checkpointPath string
 }
 
 // NewIpamD create a new IpamD object with default settings
 func NewIpamD(clientset kubernetes.Interface) *IpamD {
-	return &IpamD{
+	ipamd := &IpamD{
 		dataStore:          datastore.NewDataStore(),
 		networkClient:      networkutils.New(),
 		poolSize:           defaultPoolSize,
 		maxPoolSize:        defaultMaxPoolSize,
+		maxIPsPerNIC:       defaultMaxIPsPerNIC,
+		maxNICs:            defaultMaxNICs,
 		K8sClient:          k8sclient.NewK8sHelper(clientset),
-		prepareCloudClient: prepareQingCloudClient,
+		prepareCloudClient: prepareCloudProvider,
+		readiness:          newReadiness(),
+		advertisedIPs:      -1,
+		nodeIPPoolLimiter:  rate.NewLimiter(rate.Every(nodeIPPoolMinInterval), 1),
+		checkpointPath:     checkpointFileName,
 	}
+	ipamd.readiness.onChange = ipamd.publishReadiness
+	return ipamd
 }
 
 func (s *IpamD) vpcSubnets() []*string {
@@ -87,11 +151,16 @@ func (s *IpamD) vpcSubnets() []*string {
 	return vpcSubnets
 }
 
-func prepareQingCloudClient(config *qcclient.LabelResourceConfig) (qcclient.QingCloudAPI, error) {
-	client, err := qcclient.NewQingCloudClient(config)
+func prepareCloudProvider(config *cloudprovider.Config) (cloudprovider.Interface, error) {
+	name := os.Getenv(envCloudProvider)
+	if name == "" {
+		name = cloudprovider.QingCloud
+	}
+	client, err := cloudprovider.New(name, config)
 	if err != nil {
-		return nil, fmt.Errorf("Failed to initiate qingcloud api, err: %v", err)
+		return nil, fmt.Errorf("Failed to initiate %s cloud provider, err: %v", name, err)
 	}
+	klog.V(1).Infof("Use %s cloud provider", name)
 	return client, nil
 }
 
@@ -108,40 +177,83 @@ func (s *IpamD) parseEnv() {
 	if s.vethPrefix == "" {
 		s.vethPrefix = defaultVethPrefix
 	}
+	s.vxnetSecurityGroups = parseVxNetSecurityGroups(os.Getenv(envVxNetSecurityGroups))
+	if v := os.Getenv(envInheritPrimarySecurityGroup); v != "" {
+		inherit, err := strconv.ParseBool(v)
+		if err != nil {
+			klog.Errorf("Invalid %s %q, use false", envInheritPrimarySecurityGroup, v)
+		}
+		s.inheritPrimarySecurityGroup = inherit
+	}
+	if v := os.Getenv(envMaxIPsPerNIC); v != "" {
+		maxIPsPerNIC, err := strconv.Atoi(v)
+		if err != nil || maxIPsPerNIC < 1 {
+			klog.Errorf("Invalid %s %q, use %d", envMaxIPsPerNIC, v, defaultMaxIPsPerNIC)
+			maxIPsPerNIC = defaultMaxIPsPerNIC
+		}
+		s.maxIPsPerNIC = maxIPsPerNIC
+	}
+	if v := os.Getenv(envMaxNICs); v != "" {
+		maxNICs, err := strconv.Atoi(v)
+		if err != nil || maxNICs < 1 || maxNICs > defaultMaxNICs {
+			klog.Errorf("Invalid %s %q, use %d", envMaxNICs, v, defaultMaxNICs)
+			maxNICs = defaultMaxNICs
+		}
+		s.maxNICs = maxNICs
+	}
 }
-func (s *IpamD) setup() error {
-	s.parseEnv()
+
+// newCloudClient creates the cloud client, nothing is asked from the cloud
+func (s *IpamD) newCloudClient() error {
 	var err error
-	var labelConfig *qcclient.LabelResourceConfig
-	if s.disableLabel {
-		labelConfig = nil
-	} else {
-		labelConfig = &qcclient.LabelResourceConfig{
-			ClusterName: s.clusterName,
-			ExtraLabels: s.extraTags,
-		}
+	config := &cloudprovider.Config{MaxIPsPerNIC: s.maxIPsPerNIC}
+	if !s.disableLabel {
+		config.ClusterName = s.clusterName
+		config.ExtraLabels = s.extraTags
 	}
-	s.qcClient, err = s.prepareCloudClient(labelConfig)
+	s.qcClient, err = s.prepareCloudClient(config)
+	return err
+}
+
+// prepareCloud creates the cloud client and gets the vpc of the instance
+func (s *IpamD) prepareCloud(ctx context.Context) error {
+	err := s.newCloudClient()
 	if err != nil {
 		return err
 	}
 	s.InstanceID = s.qcClient.GetInstanceID()
 	klog.V(2).Infoln("Get current network  info of this node")
-	s.vpc, err = s.qcClient.GetNodeVPC()
+	s.vpc, err = s.qcClient.GetNodeVPC(ctx)
 	if err != nil {
 		klog.Errorf("Failed to get vpc router of %s", s.InstanceID)
 		return err
 	}
-	err = s.EnsureVxNet()
+	return nil
+}
+
+func (s *IpamD) setup(ctx context.Context) error {
+	s.parseEnv()
+	err := s.prepareCloud(ctx)
+	if err != nil {
+		return err
+	}
+	s.readiness.advance(stageCloudClientReady)
+	err = s.EnsureVxNet(ctx)
 	if err != nil {
 		klog.Errorf("Failed to ensure vxnet of instance %s", s.InstanceID)
 		return err
 	}
+	s.readiness.advance(stageVxNetEnsured)
 	s.primaryNic, err = s.qcClient.GetPrimaryNIC()
 	if err != nil {
 		klog.Errorf("Failed to get primary nic")
 		return err
 	}
+	err = s.ensureSecurityGroup()
+	if err != nil {
+		klog.Errorf("Failed to decide security group of nics")
+		return err
+	}
 	klog.V(2).Infoln("Setup host network")
 
 	primaryIP := net.ParseIP(s.primaryNic.Address)
@@ -165,9 +277,28 @@ func (s *IpamD) setup() error {
 		}
 		klog.V(2).Infof("Set up nic %s done", nic.ID)
 	}
+	if err = s.setupLocalPods(ctx); err != nil {
+		return err
+	}
+	s.readiness.advance(stageHostNetworkReady)
+	s.checkPoolWarmed()
+	klog.V(1).Infoln("IpamD: Everything is set up")
+	return nil
+}
+
+// setupLocalPods adds the pods on the node to the data store and sets up their rules. It waits a while for the pods
+// which have no ip yet.
+func (s *IpamD) setupLocalPods(ctx context.Context) error {
 	var pods []*k8sclient.K8SPodInfo
+	var err error
 	//process local pods
-	retry.Do(5, time.Second*5, func() error {
+	err = retry.DoWithBackoff(ctx, retry.Backoff{
+		Steps:    5,
+		Duration: time.Second * 2,
+		Factor:   2,
+		Jitter:   0.2,
+		Cap:      time.Second * 10,
+	}, func() error {
 		pods, err = s.K8sClient.GetCurrentNodePods()
 		if err != nil {
 			return err
@@ -185,13 +316,15 @@ func (s *IpamD) setup() error {
 		klog.V(1).Infoln("Not all pods have ips now, retry again")
 		return errors.New("Should retry")
 	})
+	if err != nil {
+		klog.Warningf("Prepare local pods anyway: %v", err)
+	}
 	klog.V(1).Infoln("Prepare local pods")
 	err = s.prepareLocalPods(pods)
 	if err != nil {
 		klog.Errorln("Failed to set up exsit pods")
 		return err
 	}
-	klog.V(1).Infoln("IpamD: Everything is ready")
 	return nil
 }
 
@@ -231,7 +364,13 @@ func (s *IpamD) prepareLocalPods(pods []*k8sclient.K8SPodInfo) error {
 		if err != nil {
 			klog.Errorf("UpdateRuleListBySrc in nodeInit() failed for IP %s: %v", ip.IP, err)
 		}
+		err = s.setupPodEgress(s.getPod(ip.Namespace, ip.Name), ip.Namespace, ip.Name, ip.IP)
+		if err != nil {
+			klog.Errorf("Failed to set up egress of pod %s/%s: %v", ip.Namespace, ip.Name, err)
+		}
 	}
+	// UpdateRuleListBySrc only rebuilds the from-pod rules to VPC CIDRs, add the others the SNAT mode needs
+	s.reconcilePodRules()
 	return nil
 }
 
@@ -245,19 +384,35 @@ func (s *IpamD) setupNic(nic *types.HostNic) error {
 		nic.DeviceNumber = link.Attrs().Index
 	}
 	err := s.dataStore.AddNIC(nic.ID, nic.DeviceNumber, nic.IsPrimary)
-	if err != nil && err.Error() != datastore.DuplicatedNICError {
+	if err != nil && errors.Cause(err) != datastore.ErrDuplicatedNIC {
 		return errors.Wrapf(err, "failed to add NIC %s to data store", nic.ID)
 	}
 	if !nic.IsPrimary {
+		if nic.SecurityGroup == "" {
+			if err := s.applySecurityGroup(s.securityGroup, nic); err != nil {
+				klog.Errorf("Failed to apply default security group to nic %s: %v", nic.ID, err)
+			}
+		}
+		// the security group must be known before the address is added, otherwise it may be given to any pod
+		if err := s.dataStore.SetNICSecurityGroup(nic.ID, nic.SecurityGroup); err != nil {
+			return errors.Wrapf(err, "failed to set security group of NIC %s in data store", nic.ID)
+		}
 		err := s.networkClient.SetupNICNetwork(nic.Address, nic.HardwareAddr, nic.DeviceNumber, s.vxnet.Network.String())
 		if err != nil {
 			klog.Errorf("Failed to set up nic %s", nic.ID)
 			return err
 		}
+		s.addNic(nic)
 		err = s.dataStore.AddIPv4AddressFromStore(nic.ID, nic.Address)
-		if err != nil && err.Error() != datastore.DuplicateIPError {
+		if err != nil && errors.Cause(err) != datastore.ErrDuplicateIP {
 			klog.Warningf("Failed to increase IP pool, failed to add IP %s to data store", nic.Address)
 		}
+		for _, addr := range nic.SecondaryAddresses {
+			err = s.dataStore.AddSecondaryIPv4AddressFromStore(nic.ID, addr)
+			if err != nil && errors.Cause(err) != datastore.ErrDuplicateIP {
+				klog.Warningf("Failed to increase IP pool, failed to add IP %s to data store", addr)
+			}
+		}
 		return nil
 	}
 	return nil
@@ -271,11 +426,29 @@ func (s *IpamD) StartIPAMD(stopCh <-chan struct{}) error {
 		return err
 	}
 	klog.V(2).Infoln("Begin to set up IPAM")
-	return s.setup()
+	s.publishReadiness(s.readiness.status())
+	if s.restore() {
+		// pods are served with the restored state at once, which is checked against the cloud and the host then
+		if err = s.StartGrpcServer(); err != nil {
+			return err
+		}
+		s.goWorker(func() { s.verifyRestoredUntilDone(stopCh) })
+		return nil
+	}
+	ctx, cancel := retry.WithStopChannel(stopCh)
+	defer cancel()
+	if err = s.setup(ctx); err != nil {
+		s.readiness.fail(err)
+		return err
+	}
+	return nil
 }
 
-// StartGrpcServer starting the GRPC server
+// StartGrpcServer starting the GRPC server, it is started only once
 func (s *IpamD) StartGrpcServer() error {
+	if s.grpcServer != nil {
+		return nil
+	}
 	listener, err := net.Listen("tcp", ipamdgRPCaddress)
 	if err != nil {
 		klog.Errorln("Failed to listen to assigned port")
@@ -288,11 +461,96 @@ func (s *IpamD) StartGrpcServer() error {
 	)
 	handlers := NewGRPCServerHandler(s)
 	rpc.RegisterCNIBackendServer(grpcServer, handlers)
+	rpc.RegisterIntrospectionServer(grpcServer, NewIntrospectionHandler(s))
 	grpc_prometheus.Register(grpcServer)
+	s.grpcServer = grpcServer
 	go grpcServer.Serve(listener)
 	return nil
 }
 
+// shutdown stops accepting calls, waits for the calls in flight and the long-running goroutines until timeout, then
+// flushes the checkpoint of the data store. The nics stay attached to the instance, so that pods keep working while
+// ipamd restarts. stopCh must be closed before, which also stops the informers.
+func (s *IpamD) shutdown(timeout time.Duration) error {
+	klog.V(1).Infoln("Shutting down IPAMD")
+	done := make(chan struct{})
+	go func() {
+		if s.grpcServer != nil {
+			s.grpcServer.GracefulStop()
+		}
+		s.workers.Wait()
+		close(done)
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		klog.Warningf("IPAMD is not stopped in %v, cancel the calls in flight", timeout)
+		if s.grpcServer != nil {
+			s.grpcServer.Stop()
+		}
+	}
+	return s.flushCheckpoint()
+}
+
+// flushCheckpoint writes the checkpoint of ipamd to checkpointPath, which is restored by the next ipamd
+func (s *IpamD) flushCheckpoint() error {
+	if s.checkpointPath == "" {
+		return nil
+	}
+	cp := s.takeCheckpoint()
+	if cp == nil {
+		klog.V(1).Infoln("IPAMD is not set up yet, there is no checkpoint to flush")
+		return nil
+	}
+	if err := writeCheckpoint(s.checkpointPath, cp); err != nil {
+		return err
+	}
+	klog.V(1).Infof("Flushed checkpoint of %d nics and %d pods to %s", len(cp.AttachedNICs), len(cp.Pods), s.checkpointPath)
+	return nil
+}
+
+// goWorker runs f in a goroutine tracked by workers
+func (s *IpamD) goWorker(f func()) {
+	s.workers.Add(1)
+	go func() {
+		defer s.workers.Done()
+		f()
+	}()
+}
+
+func (s *IpamD) addNic(nic *types.HostNic) {
+	s.nicLock.Lock()
+	defer s.nicLock.Unlock()
+	if s.nics == nil {
+		s.nics = make(map[string]*types.HostNic)
+	}
+	s.nics[nic.ID] = nic
+}
+
+func (s *IpamD) removeNic(nicID string) {
+	s.nicLock.Lock()
+	defer s.nicLock.Unlock()
+	delete(s.nics, nicID)
+}
+
+func (s *IpamD) getNic(nicID string) *types.HostNic {
+	s.nicLock.Lock()
+	defer s.nicLock.Unlock()
+	return s.nics[nicID]
+}
+
+func (s *IpamD) getNics() []*types.HostNic {
+	s.nicLock.Lock()
+	defer s.nicLock.Unlock()
+	result := make([]*types.HostNic, 0, len(s.nics))
+	for _, nic := range s.nics {
+		result = append(result, nic)
+	}
+	return result
+}
+
 func (s *IpamD) getNicIndexByIP(ip string) int {
 	nics := s.dataStore.GetNICInfos().NICIPPools
 	for _, nic := range nics {
@@ -310,11 +568,6 @@ func (s *IpamD) GetCurrentAddressInfo() (int, int) {
 }
 
 func (s *IpamD) WriteCNIConfig() error {
-	f, err := os.Create(configFileName)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
 	var conf struct {
 		CniVersion string `json:"cniVersion"`
 		VethPrefix string `json:"vethPrefix,omitempty"`
@@ -337,40 +590,60 @@ func (s *IpamD) WriteCNIConfig() error {
 	if err != nil {
 		return err
 	}
-	return t.Execute(f, &conf)
+	// the runtime may load the config at any time, so it must never see a partial one
+	var buf bytes.Buffer
+	if err = t.Execute(&buf, &conf); err != nil {
+		return err
+	}
+	return writeFileAtomically(configFileName, buf.Bytes(), 0644)
 }
 
-func Start(clientset *kubernetes.Clientset, stopCh chan struct{}) error {
+// Start starts ipamd and serves until stopCh is closed, then shuts it down gracefully. The NodeIPPool of the node is
+// kept up to date if nodeIPPools is not nil.
+func Start(clientset *kubernetes.Clientset, nodeIPPools k8sclient.NodeIPPoolInterface, stopCh chan struct{}) error {
 	klog.V(1).Infoln("Starting IPAMD")
 	ipamd := NewIpamD(clientset)
+	ipamd.NodeIPPools = nodeIPPools
+	healthAddress := os.Getenv(envHealthAddress)
+	if healthAddress == "" {
+		healthAddress = defaultHealthAddress
+	}
+	ipamd.StartHealthServer(healthAddress)
+	startWebhook()
 
 	err := ipamd.StartIPAMD(stopCh)
 	if err != nil {
 		return err
 	}
-	go ipamd.StartReconcileIPPool(stopCh)
+	ipamd.goWorker(func() { ipamd.StartReconcileIPPool(stopCh) })
+	ipamd.goWorker(func() { ipamd.StartReconcileHostNetwork(stopCh) })
 	klog.V(1).Infoln("Starting Grpc server")
 	err = ipamd.StartGrpcServer()
 	if err != nil {
 		return fmt.Errorf("Failed to start grpc server, err: %s", err.Error())
 	}
-	klog.V(1).Infoln("Writing hostnic configlist")
+	ipamd.goWorker(func() { ipamd.writeCNIConfigWhenReady(stopCh) })
 
-	//waiting for nics, just wait 20s before starting to check
-	time.Sleep(time.Second * 20)
-	err = retry.Do(10, time.Second*5, func() error {
-		if total, assigned := ipamd.dataStore.GetStats(); total > assigned {
-			err = ipamd.WriteCNIConfig()
-			if err != nil {
-				return fmt.Errorf("Failed to write CNI configlist, err: %s", err.Error())
-			}
-			return nil
-		}
-		klog.Infoln("DataStore has no enough ip address, waiting")
-		return fmt.Errorf("DataStore has no enough pods")
-	})
+	<-stopCh
+	err = ipamd.shutdown(gracefulTimeout)
+	if daemonSetDeleted(clientset) {
+		klog.V(1).Infoln("The DaemonSet of hostnic is deleted, leave no checkpoint")
+		removeCheckpoint(ipamd.checkpointPath)
+	}
+	return err
+}
+
+// writeCNIConfigWhenReady writes the configlist as soon as ipamd is ready, so that kubelet starts to schedule pods
+// to the node only after they can get ips
+func (s *IpamD) writeCNIConfigWhenReady(stopCh <-chan struct{}) {
+	ctx, cancel := retry.WithStopChannel(stopCh)
+	defer cancel()
+	if err := s.readiness.wait(ctx); err != nil {
+		return
+	}
+	klog.V(1).Infoln("Writing hostnic configlist")
+	err := retry.DoWithBackoff(ctx, retry.Backoff{Duration: time.Second, Factor: 2, Cap: time.Minute}, s.WriteCNIConfig)
 	if err != nil {
-		return err
+		klog.Errorf("Failed to write CNI configlist: %v", err)
 	}
-	return nil
 }
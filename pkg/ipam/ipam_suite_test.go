@@ -5,10 +5,11 @@ import (
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/yunify/hostnic-cni/pkg/cloudprovider"
 	"github.com/yunify/hostnic-cni/pkg/ipam/datastore"
 	"github.com/yunify/hostnic-cni/pkg/k8sclient"
 	"github.com/yunify/hostnic-cni/pkg/networkutils"
-	"github.com/yunify/hostnic-cni/pkg/qcclient"
+	"golang.org/x/time/rate"
 	"k8s.io/client-go/kubernetes"
 )
 
@@ -22,13 +23,20 @@ func TestIpam(t *testing.T) {
 	RunSpecs(t, "Ipam Suite")
 }
 
-func NewFakeIPAM(netapi networkutils.NetworkAPIs, clientset kubernetes.Interface, prepareCloud func(*qcclient.LabelResourceConfig) (qcclient.QingCloudAPI, error)) *IpamD {
-	return &IpamD{
+func NewFakeIPAM(netapi networkutils.NetworkAPIs, clientset kubernetes.Interface, prepareCloud func(*cloudprovider.Config) (cloudprovider.Interface, error)) *IpamD {
+	ipamd := &IpamD{
 		dataStore:          datastore.NewDataStore(),
 		networkClient:      netapi,
 		poolSize:           defaultPoolSize,
 		maxPoolSize:        defaultMaxPoolSize,
+		maxIPsPerNIC:       defaultMaxIPsPerNIC,
+		maxNICs:            defaultMaxNICs,
 		K8sClient:          k8sclient.NewK8sHelper(clientset),
 		prepareCloudClient: prepareCloud,
+		readiness:          newReadiness(),
+		advertisedIPs:      -1,
+		nodeIPPoolLimiter:  rate.NewLimiter(rate.Every(nodeIPPoolMinInterval), 1),
 	}
+	ipamd.readiness.onChange = ipamd.publishReadiness
+	return ipamd
 }
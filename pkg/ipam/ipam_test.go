@@ -1,21 +1,40 @@
 package ipam
 
 import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
 	"net"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	. "github.com/onsi/gomega/gstruct"
 	"github.com/vishvananda/netlink"
+	"github.com/yunify/hostnic-cni/pkg/cloudprovider"
+	hostnicerrors "github.com/yunify/hostnic-cni/pkg/errors"
+	"github.com/yunify/hostnic-cni/pkg/ipam/datastore"
 	"github.com/yunify/hostnic-cni/pkg/k8sclient"
+	fakek8s "github.com/yunify/hostnic-cni/pkg/k8sclient/fake"
 	fakenetlink "github.com/yunify/hostnic-cni/pkg/netlinkwrapper/fake"
 	"github.com/yunify/hostnic-cni/pkg/networkutils"
 	"github.com/yunify/hostnic-cni/pkg/networkutils/iptables"
 	"github.com/yunify/hostnic-cni/pkg/qcclient"
+	"github.com/yunify/hostnic-cni/pkg/rpc"
 	"github.com/yunify/hostnic-cni/pkg/types"
+	"golang.org/x/net/context"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
 )
@@ -28,6 +47,41 @@ const (
 	primaryIP     = "192.168.1.2"
 )
 
+// unreachableCloud fails to get the vpc of the node while unreachable is not zero
+type unreachableCloud struct {
+	*qcclient.FakeQingCloudAPI
+	unreachable int32
+}
+
+func (c *unreachableCloud) GetNodeVPC(ctx context.Context) (*types.VPC, error) {
+	if atomic.LoadInt32(&c.unreachable) != 0 {
+		return nil, fmt.Errorf("cloud is unreachable")
+	}
+	return c.FakeQingCloudAPI.GetNodeVPC(ctx)
+}
+
+// unreachablePods fails to get any pod
+type unreachablePods struct {
+	k8sclient.K8sHelper
+}
+
+func (p *unreachablePods) GetPod(namespace, name string) (*corev1.Pod, error) {
+	return nil, fmt.Errorf("apiserver is unreachable")
+}
+
+// brokenNICNetwork fails to set up the network of the nics in broken
+type brokenNICNetwork struct {
+	networkutils.NetworkAPIs
+	broken map[string]bool
+}
+
+func (n *brokenNICNetwork) SetupNICNetwork(nicIP string, nicMAC string, nicTable int, nicSubnetCIDR string) error {
+	if n.broken[nicMAC] {
+		return fmt.Errorf("failed to set up nic %s", nicMAC)
+	}
+	return n.NetworkAPIs.SetupNICNetwork(nicIP, nicMAC, nicTable, nicSubnetCIDR)
+}
+
 var (
 	clientset         kubernetes.Interface
 	iptablesData      *iptables.FakeIPTables
@@ -98,7 +152,7 @@ var _ = Describe("Ipam", func() {
 		node := &corev1.Node{}
 		node.Name = nodeName
 		clientset = fake.NewSimpleClientset(node)
-		prepareCloud := func(config *qcclient.LabelResourceConfig) (qcclient.QingCloudAPI, error) {
+		prepareCloud := func(config *cloudprovider.Config) (cloudprovider.Interface, error) {
 			return qcapi, nil
 		}
 		ipamd := NewFakeIPAM(fakeNetworkClient, clientset, prepareCloud)
@@ -206,7 +260,7 @@ var _ = Describe("Ipam", func() {
 		eth2.HardwareAddr, _ = net.ParseMAC(nic2Mac)
 		netlinkData.LinkAdd(eth2)
 
-		prepareCloud := func(config *qcclient.LabelResourceConfig) (qcclient.QingCloudAPI, error) {
+		prepareCloud := func(config *cloudprovider.Config) (cloudprovider.Interface, error) {
 			return qcapi, nil
 		}
 		ipamd := NewFakeIPAM(fakeNetworkClient, clientset, prepareCloud)
@@ -261,7 +315,7 @@ var _ = Describe("Ipam", func() {
 			return nil
 		}
 
-		prepareCloud := func(config *qcclient.LabelResourceConfig) (qcclient.QingCloudAPI, error) {
+		prepareCloud := func(config *cloudprovider.Config) (cloudprovider.Interface, error) {
 			return qcapi, nil
 		}
 
@@ -298,8 +352,1140 @@ var _ = Describe("Ipam", func() {
 		Expect(iptablesData.Data["nat"]["QINGCLOUD-SNAT-CHAIN-2"][0].Rule).To(Equal([]string{"-m", "comment", "--comment", "QINGCLOUD, SNAT",
 			"-m", "addrtype", "!", "--dst-type", "LOCAL",
 			"-j", "SNAT", "--to-source", primaryIP, "--random"}))
+		handler := NewGRPCServerHandler(ipamd)
+		readyReply, err := handler.Ready(context.Background(), &rpc.ReadyRequest{APIVersion: rpc.APIVersion})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(readyReply.Ready).To(BeFalse())
+		Expect(readyReply.Message).NotTo(BeEmpty())
 		go ipamd.StartReconcileIPPool(stopCh, time.Second)
 		Eventually(func() int { return ipamd.dataStore.GetNICInfos().TotalIPs }, time.Second*20, time.Second*4).Should(Equal(defaultPoolSize))
 		Eventually(func() int { return ipamd.dataStore.GetNICInfos().AssignedIPs }, time.Second*20, time.Second*4).Should(Equal(0))
+		Eventually(func() bool {
+			readyReply, err = handler.Ready(context.Background(), &rpc.ReadyRequest{APIVersion: rpc.APIVersion})
+			return err == nil && readyReply.Ready
+		}, time.Second*10, time.Second).Should(BeTrue())
+		node, err = clientset.CoreV1().Nodes().Get(nodeName, metav1.GetOptions{})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(node.Status.Conditions).To(ContainElement(MatchFields(IgnoreExtras, Fields{
+			"Type":   Equal(NodeConditionHostnicReady),
+			"Status": Equal(corev1.ConditionTrue),
+		})))
+	})
+
+	It("Should stop reconciling the pool at once when it is stopped", func() {
+		ipamd := NewFakeIPAM(fakeNetworkClient, fake.NewSimpleClientset(), func(config *cloudprovider.Config) (cloudprovider.Interface, error) {
+			return qcapi, nil
+		})
+		stopCh := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			ipamd.StartReconcileIPPool(stopCh, time.Hour)
+			close(done)
+		}()
+		close(stopCh)
+		Eventually(done, time.Second).Should(BeClosed())
+	})
+
+	It("Should fix the host network when it drifts", func() {
+		node := &corev1.Node{}
+		node.Name = nodeName
+		node.Annotations = map[string]string{NodeAnnotationVxNet: "vxnet-pod"}
+		pod1 := &corev1.Pod{}
+		pod1.Name = "pod1"
+		pod1.Namespace = "ns1"
+		pod1.Spec.NodeName = nodeName
+		pod1.Status.PodIP = "192.168.2.2"
+		pod1.Status.ContainerStatuses = []corev1.ContainerStatus{
+			corev1.ContainerStatus{
+				ContainerID: "container1",
+			},
+		}
+		clientset = fake.NewSimpleClientset(node, pod1)
+
+		podVxNet := &types.VxNet{
+			ID:       "vxnet-pod",
+			Name:     "pod",
+			RouterID: RouterID,
+		}
+		_, podVxNet.Network, _ = net.ParseCIDR("192.168.2.0/24")
+		nic1Mac := "aa:aa:aa:aa:aa:aa"
+		qcapi.Nics[nic1Mac] = &types.HostNic{
+			ID:           nic1Mac,
+			VxNet:        podVxNet,
+			HardwareAddr: nic1Mac,
+			Address:      "192.168.2.2",
+			DeviceNumber: 2,
+		}
+		qcapi.VxNets[podVxNet.ID] = podVxNet
+		eth1 := &netlink.Device{
+			LinkAttrs: netlink.NewLinkAttrs(),
+		}
+		eth1.Name = "eth1"
+		eth1.Index = 2
+		eth1.HardwareAddr, _ = net.ParseMAC(nic1Mac)
+		netlinkData.LinkAdd(eth1)
+
+		prepareCloud := func(config *cloudprovider.Config) (cloudprovider.Interface, error) {
+			return qcapi, nil
+		}
+		ipamd := NewFakeIPAM(fakeNetworkClient, clientset, prepareCloud)
+		stopCh := make(chan struct{})
+		Expect(ipamd.StartIPAMD(stopCh)).ShouldNot(HaveOccurred())
+		defer func() {
+			stopCh <- struct{}{}
+		}()
+		Expect(netlinkData.Routes).To(HaveLen(2))
+		ruleCount := len(netlinkData.Rules)
+
+		// someone flushes the rules and the routes
+		iptablesData.ClearChain("nat", "QINGCLOUD-SNAT-CHAIN-2")
+		iptablesData.ClearChain("filter", "FORWARD")
+		netlinkData.Routes = make(map[string]netlink.Route)
+		netlinkData.Rules = make(map[string]netlink.Rule)
+
+		ipamd.reconcileHostNetwork()
+		Expect(iptablesData.Data["nat"]["QINGCLOUD-SNAT-CHAIN-2"]).To(HaveLen(1))
+		Expect(iptablesData.Data["filter"]["FORWARD"]).To(HaveLen(2))
+		Expect(netlinkData.Routes).To(HaveLen(2))
+		Expect(netlinkData.Rules).To(HaveLen(ruleCount))
+		Expect(netlinkData.Rules).To(HaveKey(fakenetlink.KeyForRule(&netlink.Rule{
+			Src:      &net.IPNet{IP: net.ParseIP("192.168.2.2"), Mask: net.CIDRMask(32, 32)},
+			Dst:      podVxNet.Network,
+			Table:    2,
+			Priority: 1536,
+		})))
+	})
+
+	It("Should send traffic of a pod out of its egress", func() {
+		node := &corev1.Node{}
+		node.Name = nodeName
+		node.Annotations = map[string]string{NodeAnnotationVxNet: "vxnet-pod"}
+		ns := &corev1.Namespace{}
+		ns.Name = "ns1"
+		ns.Annotations = map[string]string{AnnotationEgress: "eip-egress"}
+		pod1 := &corev1.Pod{}
+		pod1.Name = "pod1"
+		pod1.Namespace = "ns1"
+		pod1.Spec.NodeName = nodeName
+		pod1.Status.PodIP = "192.168.2.2"
+		pod1.Status.ContainerStatuses = []corev1.ContainerStatus{
+			corev1.ContainerStatus{
+				ContainerID: "container1",
+			},
+		}
+		clientset = fake.NewSimpleClientset(node, ns, pod1)
+
+		podVxNet := &types.VxNet{
+			ID:       "vxnet-pod",
+			Name:     "pod",
+			RouterID: RouterID,
+		}
+		_, podVxNet.Network, _ = net.ParseCIDR("192.168.2.0/24")
+		nic1Mac := "aa:aa:aa:aa:aa:aa"
+		nic2Mac := "bb:bb:bb:bb:bb:bb"
+		qcapi.Nics[nic1Mac] = &types.HostNic{
+			ID:           nic1Mac,
+			VxNet:        podVxNet,
+			HardwareAddr: nic1Mac,
+			Address:      "192.168.2.2",
+			DeviceNumber: 2,
+		}
+		qcapi.Nics[nic2Mac] = &types.HostNic{
+			ID:           nic2Mac,
+			VxNet:        podVxNet,
+			HardwareAddr: nic2Mac,
+			Address:      "192.168.2.3",
+			DeviceNumber: 3,
+		}
+		qcapi.VxNets[podVxNet.ID] = podVxNet
+		qcapi.EIPs["eip-egress"] = &types.EIP{
+			ID:           "eip-egress",
+			Address:      "139.198.1.1",
+			ResourceID:   nic2Mac,
+			ResourceType: types.ResourceTypeNic,
+		}
+		for i, mac := range []string{nic1Mac, nic2Mac} {
+			eth := &netlink.Device{
+				LinkAttrs: netlink.NewLinkAttrs(),
+			}
+			eth.Name = fmt.Sprintf("eth%d", i+1)
+			eth.Index = i + 2
+			eth.HardwareAddr, _ = net.ParseMAC(mac)
+			netlinkData.LinkAdd(eth)
+		}
+
+		prepareCloud := func(config *cloudprovider.Config) (cloudprovider.Interface, error) {
+			return qcapi, nil
+		}
+		ipamd := NewFakeIPAM(fakeNetworkClient, clientset, prepareCloud)
+		stopCh := make(chan struct{})
+		Expect(ipamd.StartIPAMD(stopCh)).ShouldNot(HaveOccurred())
+		defer func() {
+			stopCh <- struct{}{}
+		}()
+
+		Expect(iptablesData.Data["nat"]["QINGCLOUD-SNAT-CHAIN-2"]).To(HaveLen(2))
+		Expect(iptablesData.Data["nat"]["QINGCLOUD-EGRESS-CHAIN"]).To(HaveLen(1))
+		Expect(iptablesData.Data["nat"]["QINGCLOUD-EGRESS-CHAIN"][0].Rule).To(Equal([]string{
+			"-s", "192.168.2.2", "-m", "comment", "--comment", "QINGCLOUD, egress",
+			"-j", "SNAT", "--to-source", "192.168.2.3",
+		}))
+		egressRule := &netlink.Rule{
+			Src:      &net.IPNet{IP: net.ParseIP("192.168.2.2"), Mask: net.CIDRMask(32, 32)},
+			Table:    3,
+			Priority: 1600,
+		}
+		Expect(netlinkData.Rules).To(HaveKey(fakenetlink.KeyForRule(egressRule)))
+		// the address of the egress nic is not given to pods
+		Expect(ipamd.dataStore.GetNICInfos().NICIPPools[nic2Mac].Reserved).To(Equal(1))
+		Expect(ipamd.dataStore.GetNICInfos().TotalIPs).To(Equal(1))
+
+		handler := NewGRPCServerHandler(ipamd)
+		reply, err := handler.DelNetwork(context.Background(), &rpc.DelNetworkRequest{
+			K8S_POD_NAME:               "pod1",
+			K8S_POD_NAMESPACE:          "ns1",
+			K8S_POD_INFRA_CONTAINER_ID: "container1",
+			APIVersion:                 rpc.APIVersion,
+		})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(reply.Success).To(BeTrue())
+		Expect(iptablesData.Data["nat"]["QINGCLOUD-SNAT-CHAIN-2"]).To(HaveLen(1))
+		Expect(iptablesData.Data["nat"]["QINGCLOUD-EGRESS-CHAIN"]).To(BeEmpty())
+		Expect(netlinkData.Rules).NotTo(HaveKey(fakenetlink.KeyForRule(egressRule)))
+		Expect(ipamd.dataStore.GetNICInfos().NICIPPools[nic2Mac].Reserved).To(Equal(0))
+		Expect(ipamd.dataStore.GetNICInfos().TotalIPs).To(Equal(2))
+	})
+
+	It("Should set the mtu of pods by annotation", func() {
+		node := &corev1.Node{}
+		node.Name = nodeName
+		node.Annotations = map[string]string{NodeAnnotationVxNet: "vxnet-pod"}
+		pod1 := &corev1.Pod{}
+		pod1.Name = "pod1"
+		pod1.Namespace = "ns1"
+		pod1.Annotations = map[string]string{AnnotationMTU: "1400"}
+		pod2 := pod1.DeepCopy()
+		pod2.Name = "pod2"
+		pod2.Annotations = map[string]string{AnnotationMTU: "9001"}
+		clientset = fake.NewSimpleClientset(node, pod1, pod2)
+
+		podVxNet := &types.VxNet{
+			ID:       "vxnet-pod",
+			Name:     "pod",
+			RouterID: RouterID,
+		}
+		_, podVxNet.Network, _ = net.ParseCIDR("192.168.2.0/24")
+		qcapi.VxNets[podVxNet.ID] = podVxNet
+		for i, mac := range []string{"aa:aa:aa:aa:aa:aa", "bb:bb:bb:bb:bb:bb", "cc:cc:cc:cc:cc:cc"} {
+			qcapi.Nics[mac] = &types.HostNic{
+				ID:           mac,
+				VxNet:        podVxNet,
+				HardwareAddr: mac,
+				Address:      fmt.Sprintf("192.168.2.%d", i+2),
+				DeviceNumber: i + 2,
+			}
+			eth := &netlink.Device{
+				LinkAttrs: netlink.NewLinkAttrs(),
+			}
+			eth.Name = fmt.Sprintf("eth%d", i+1)
+			eth.Index = i + 2
+			eth.HardwareAddr, _ = net.ParseMAC(mac)
+			netlinkData.LinkAdd(eth)
+		}
+
+		prepareCloud := func(config *cloudprovider.Config) (cloudprovider.Interface, error) {
+			return qcapi, nil
+		}
+		ipamd := NewFakeIPAM(fakeNetworkClient, clientset, prepareCloud)
+		stopCh := make(chan struct{})
+		Expect(ipamd.StartIPAMD(stopCh)).ShouldNot(HaveOccurred())
+		defer func() {
+			stopCh <- struct{}{}
+		}()
+		handler := NewGRPCServerHandler(ipamd)
+		addNetwork := func(name string) *rpc.AddNetworkReply {
+			reply, err := handler.AddNetwork(context.Background(), &rpc.AddNetworkRequest{
+				K8S_POD_NAME:               name,
+				K8S_POD_NAMESPACE:          "ns1",
+				K8S_POD_INFRA_CONTAINER_ID: "container-" + name,
+				APIVersion:                 rpc.APIVersion,
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+			return reply
+		}
+
+		// the mtu of a pod can be lowered by annotation, but not raised above the one of nics
+		reply := addNetwork("pod2")
+		Expect(reply.Success).To(BeFalse())
+		Expect(reply.Message).NotTo(BeEmpty())
+		Expect(ipamd.dataStore.GetNICInfos().AssignedIPs).To(Equal(0))
+		reply = addNetwork("pod1")
+		Expect(reply.Success).To(BeTrue())
+		Expect(reply.MTU).To(BeEquivalentTo(1400))
+
+		// the pods which are not known are set up as if they had no annotation
+		reply = addNetwork("pod3")
+		Expect(reply.Success).To(BeTrue())
+		Expect(reply.MTU).To(BeEquivalentTo(fakeNetworkClient.GetMTU()))
+		ipamd.K8sClient = &unreachablePods{K8sHelper: ipamd.K8sClient}
+		reply = addNetwork("pod4")
+		Expect(reply.Success).To(BeTrue())
+		Expect(reply.MTU).To(BeEquivalentTo(fakeNetworkClient.GetMTU()))
+	})
+
+	It("Should give pods nics in the security groups they ask for", func() {
+		node := &corev1.Node{}
+		node.Name = nodeName
+		node.Annotations = map[string]string{
+			NodeAnnotationVxNet:         "vxnet-pod",
+			NodeAnnotationSecurityGroup: "sg-node",
+		}
+		ns := &corev1.Namespace{}
+		ns.Name = "secure"
+		ns.Annotations = map[string]string{AnnotationSecurityGroup: "sg-secure"}
+		pod := &corev1.Pod{}
+		pod.Name = "pod1"
+		pod.Namespace = "secure"
+		clientset = fake.NewSimpleClientset(node, ns, pod)
+
+		podVxNet := &types.VxNet{
+			ID:       "vxnet-pod",
+			Name:     "pod",
+			RouterID: RouterID,
+		}
+		_, podVxNet.Network, _ = net.ParseCIDR("192.168.2.0/24")
+		qcapi.VxNets[podVxNet.ID] = podVxNet
+		nic1Mac := "aa:aa:aa:aa:aa:aa"
+		qcapi.Nics[nic1Mac] = &types.HostNic{
+			ID:           nic1Mac,
+			VxNet:        podVxNet,
+			HardwareAddr: nic1Mac,
+			Address:      "192.168.2.2",
+			DeviceNumber: 2,
+		}
+		eth1 := &netlink.Device{
+			LinkAttrs: netlink.NewLinkAttrs(),
+		}
+		eth1.Name = "eth1"
+		eth1.Index = 2
+		eth1.HardwareAddr, _ = net.ParseMAC(nic1Mac)
+		netlinkData.LinkAdd(eth1)
+		qcapi.AfterCreatingNIC = func(nic *types.HostNic) error {
+			eth := &netlink.Device{
+				LinkAttrs: netlink.NewLinkAttrs(),
+			}
+			eth.Name = nic.ID
+			eth.HardwareAddr, _ = net.ParseMAC(nic.ID)
+			eth.Index = nic.DeviceNumber
+			netlinkData.LinkAdd(eth)
+			return nil
+		}
+
+		prepareCloud := func(config *cloudprovider.Config) (cloudprovider.Interface, error) {
+			return qcapi, nil
+		}
+		ipamd := NewFakeIPAM(fakeNetworkClient, clientset, prepareCloud)
+		stopCh := make(chan struct{})
+		Expect(ipamd.StartIPAMD(stopCh)).ShouldNot(HaveOccurred())
+		defer func() {
+			stopCh <- struct{}{}
+		}()
+		// nics without a security group are bound to the one of the node
+		Expect(qcapi.Nics[nic1Mac].SecurityGroup).To(Equal("sg-node"))
+		Expect(ipamd.dataStore.GetStatsOfSecurityGroup("sg-node")).To(Equal(1))
+
+		handler := NewGRPCServerHandler(ipamd)
+		request := &rpc.AddNetworkRequest{
+			K8S_POD_NAME:               "pod1",
+			K8S_POD_NAMESPACE:          "secure",
+			K8S_POD_INFRA_CONTAINER_ID: "container1",
+			APIVersion:                 rpc.APIVersion,
+		}
+		failed, err := handler.AddNetwork(context.Background(), request)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(failed.Success).To(BeFalse())
+		Expect(hostnicerrors.IsPoolExhausted(hostnicerrors.FromReply(failed.ErrorType, failed.Message))).To(BeTrue())
+		Eventually(func() []string {
+			var reasons []string
+			events, _ := clientset.CoreV1().Events("").List(metav1.ListOptions{})
+			for _, event := range events.Items {
+				reasons = append(reasons, event.InvolvedObject.Kind+"/"+event.Reason)
+			}
+			return reasons
+		}, time.Second*5, time.Millisecond*100).Should(ConsistOf("Node/"+EventReasonIPPoolExhausted, "Pod/"+EventReasonIPPoolExhausted))
+
+		ipamd.updateSecurityGroupPools(context.Background())
+		reply, err := handler.AddNetwork(context.Background(), request)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(reply.Success).To(BeTrue())
+		Expect(reply.IPv4Addr).NotTo(Equal("192.168.2.2"))
+		var securityGroups []string
+		for _, nic := range qcapi.Nics {
+			if nic.Address == reply.IPv4Addr {
+				securityGroups = append(securityGroups, nic.SecurityGroup)
+			}
+		}
+		Expect(securityGroups).To(Equal([]string{"sg-secure"}))
+		Expect(ipamd.dataStore.GetStatsOfSecurityGroup("sg-node")).To(Equal(1))
+	})
+
+	It("Should only bind nics to the security group of the primary nic if asked", func() {
+		node := &corev1.Node{}
+		node.Name = nodeName
+		clientset = fake.NewSimpleClientset(node)
+		prepareCloud := func(config *cloudprovider.Config) (cloudprovider.Interface, error) {
+			return qcapi, nil
+		}
+		ipamd := NewFakeIPAM(fakeNetworkClient, clientset, prepareCloud)
+		stopCh := make(chan struct{})
+		defer close(stopCh)
+		Expect(ipamd.K8sClient.Start(stopCh)).ShouldNot(HaveOccurred())
+		ipamd.vxnet = nodeVxNet
+		ipamd.primaryNic = &types.HostNic{ID: primaryIntMac, IsPrimary: true, SecurityGroup: "sg-primary"}
+
+		ipamd.parseEnv()
+		Expect(ipamd.ensureSecurityGroup()).ShouldNot(HaveOccurred())
+		Expect(ipamd.securityGroup).To(BeEmpty())
+
+		os.Setenv(envInheritPrimarySecurityGroup, "true")
+		defer os.Unsetenv(envInheritPrimarySecurityGroup)
+		ipamd.parseEnv()
+		Expect(ipamd.ensureSecurityGroup()).ShouldNot(HaveOccurred())
+		Expect(ipamd.securityGroup).To(Equal("sg-primary"))
+	})
+
+	It("Should grow and shrink the pool with secondary ips of nics", func() {
+		os.Setenv(envMaxIPsPerNIC, "4")
+		defer os.Unsetenv(envMaxIPsPerNIC)
+		node := &corev1.Node{}
+		node.Name = nodeName
+		node.Annotations = map[string]string{NodeAnnotationVxNet: "vxnet-pod"}
+		clientset = fake.NewSimpleClientset(node)
+
+		podVxNet := &types.VxNet{
+			ID:       "vxnet-pod",
+			Name:     "pod",
+			RouterID: RouterID,
+		}
+		_, podVxNet.Network, _ = net.ParseCIDR("192.168.2.0/24")
+		qcapi.VxNets[podVxNet.ID] = podVxNet
+		nic1Mac := "aa:aa:aa:aa:aa:aa"
+		qcapi.Nics[nic1Mac] = &types.HostNic{
+			ID:                 nic1Mac,
+			VxNet:              podVxNet,
+			HardwareAddr:       nic1Mac,
+			Address:            "192.168.2.2",
+			SecondaryAddresses: []string{"192.168.2.10"},
+			DeviceNumber:       2,
+		}
+		eth1 := &netlink.Device{
+			LinkAttrs: netlink.NewLinkAttrs(),
+		}
+		eth1.Name = "eth1"
+		eth1.Index = 2
+		eth1.HardwareAddr, _ = net.ParseMAC(nic1Mac)
+		netlinkData.LinkAdd(eth1)
+		qcapi.AfterCreatingNIC = func(nic *types.HostNic) error {
+			return fmt.Errorf("no nic should be created")
+		}
+
+		prepareCloud := func(config *cloudprovider.Config) (cloudprovider.Interface, error) {
+			return qcapi, nil
+		}
+		ipamd := NewFakeIPAM(fakeNetworkClient, clientset, prepareCloud)
+		stopCh := make(chan struct{})
+		Expect(ipamd.StartIPAMD(stopCh)).ShouldNot(HaveOccurred())
+		defer func() {
+			stopCh <- struct{}{}
+		}()
+		Expect(ipamd.maxIPsPerNIC).To(Equal(4))
+		Expect(ipamd.dataStore.GetNICInfos().TotalIPs).To(Equal(2))
+
+		// the nic has room for the ip the pool lacks
+		ipamd.updateIPPoolIfRequired(context.Background())
+		Expect(ipamd.dataStore.GetNICInfos().TotalIPs).To(Equal(defaultPoolSize))
+		Expect(qcapi.Nics).To(HaveLen(2))
+		Expect(qcapi.Nics[nic1Mac].SecondaryAddresses).To(HaveLen(2))
+
+		// secondary ips are released before the nic is deleted
+		ipamd.poolSize = 1
+		ipamd.maxPoolSize = 1
+		ipamd.updateIPPoolIfRequired(context.Background())
+		ipamd.updateIPPoolIfRequired(context.Background())
+		Expect(ipamd.dataStore.GetNICInfos().TotalIPs).To(Equal(1))
+		Expect(qcapi.Nics[nic1Mac].SecondaryAddresses).To(BeEmpty())
+		Expect(ipamd.dataStore.GetNICInfos().NICIPPools).To(HaveKey(nic1Mac))
+	})
+
+	It("Should grow the pool by a batch of nics and delete the ones failing to be set up", func() {
+		node := &corev1.Node{}
+		node.Name = nodeName
+		clientset = fake.NewSimpleClientset(node)
+		network := &brokenNICNetwork{NetworkAPIs: fakeNetworkClient, broken: map[string]bool{}}
+		created := 0
+		qcapi.AfterCreatingNIC = func(nic *types.HostNic) error {
+			created++
+			if created == 2 {
+				network.broken[nic.HardwareAddr] = true
+			}
+			eth := &netlink.Device{
+				LinkAttrs: netlink.NewLinkAttrs(),
+			}
+			eth.Name = nic.ID
+			eth.HardwareAddr, _ = net.ParseMAC(nic.ID)
+			eth.Index = nic.DeviceNumber
+			netlinkData.LinkAdd(eth)
+			return nil
+		}
+
+		prepareCloud := func(config *cloudprovider.Config) (cloudprovider.Interface, error) {
+			return qcapi, nil
+		}
+		ipamd := NewFakeIPAM(network, clientset, prepareCloud)
+		stopCh := make(chan struct{})
+		Expect(ipamd.StartIPAMD(stopCh)).ShouldNot(HaveOccurred())
+		defer func() {
+			stopCh <- struct{}{}
+		}()
+		Expect(ipamd.dataStore.GetNICInfos().TotalIPs).To(Equal(0))
+
+		ipamd.updateIPPoolIfRequired(context.Background())
+		Expect(created).To(Equal(defaultPoolSize))
+		Expect(ipamd.dataStore.GetNICInfos().TotalIPs).To(Equal(defaultPoolSize - 1))
+		// the primary nic and the nics set up on host, the other one is deleted
+		Expect(qcapi.Nics).To(HaveLen(defaultPoolSize))
+		for mac := range network.broken {
+			Expect(qcapi.Nics).NotTo(HaveKey(mac))
+			Expect(ipamd.dataStore.GetNICInfos().NICIPPools).NotTo(HaveKey(mac))
+		}
+	})
+
+	It("Should advertise the ips the node is able to offer", func() {
+		node := &corev1.Node{}
+		node.Name = nodeName
+		node.Annotations = map[string]string{NodeAnnotationVxNet: "vxnet-pod"}
+		requesting := &corev1.Pod{}
+		requesting.Name, requesting.Namespace = "requesting", "default"
+		requesting.Spec.Containers = []corev1.Container{{Name: "c"}}
+		requesting.Spec.Containers[0].Resources.Requests = corev1.ResourceList{ResourceIP: resource.MustParse("1")}
+		plain := &corev1.Pod{}
+		plain.Name, plain.Namespace = "plain", "default"
+		plain.Spec.Containers = []corev1.Container{{Name: "c"}}
+		clientset = fake.NewSimpleClientset(node, requesting, plain)
+
+		podVxNet := &types.VxNet{
+			ID:       "vxnet-pod",
+			Name:     "pod",
+			RouterID: RouterID,
+		}
+		_, podVxNet.Network, _ = net.ParseCIDR("192.168.2.0/27")
+		qcapi.VxNets[podVxNet.ID] = podVxNet
+		prepareCloud := func(config *cloudprovider.Config) (cloudprovider.Interface, error) {
+			return qcapi, nil
+		}
+		ipamd := NewFakeIPAM(fakeNetworkClient, clientset, prepareCloud)
+		stopCh := make(chan struct{})
+		Expect(ipamd.StartIPAMD(stopCh)).ShouldNot(HaveOccurred())
+		defer func() {
+			stopCh <- struct{}{}
+		}()
+		capacity := func() int64 {
+			node, err := clientset.CoreV1().Nodes().Get(nodeName, metav1.GetOptions{})
+			Expect(err).ShouldNot(HaveOccurred())
+			quantity := node.Status.Capacity[ResourceIP]
+			return quantity.Value()
+		}
+
+		// the vxnet is smaller than the nics are able to hold
+		ipamd.updateIPResource()
+		Expect(capacity()).To(BeEquivalentTo(32 - reservedIPsPerVxNet))
+		ipamd.maxNICs = 2
+		ipamd.updateIPResource()
+		Expect(capacity()).To(BeEquivalentTo(2))
+
+		// the pods not requesting an ip are not counted by the scheduler
+		Expect(ipamd.dataStore.AddNIC("aa:aa:aa:aa:aa:aa", 2, false)).To(Succeed())
+		Expect(ipamd.dataStore.AddIPv4AddressFromStore("aa:aa:aa:aa:aa:aa", "192.168.2.2")).To(Succeed())
+		Expect(ipamd.dataStore.AddSecondaryIPv4AddressFromStore("aa:aa:aa:aa:aa:aa", "192.168.2.3")).To(Succeed())
+		for _, pod := range []*corev1.Pod{requesting, plain} {
+			_, _, err := ipamd.dataStore.AssignPodIPv4Address(&k8sclient.K8SPodInfo{Name: pod.Name, Namespace: pod.Namespace, Container: "c"})
+			Expect(err).ShouldNot(HaveOccurred())
+		}
+		ipamd.updateIPResource()
+		Expect(capacity()).To(BeEquivalentTo(1))
+	})
+
+	It("Should publish the pool of the node in a NodeIPPool", func() {
+		node := &corev1.Node{}
+		node.Name = nodeName
+		node.UID = "node-uid"
+		node.Annotations = map[string]string{NodeAnnotationVxNet: "vxnet-pod"}
+		clientset = fake.NewSimpleClientset(node)
+		podVxNet := &types.VxNet{
+			ID:       "vxnet-pod",
+			Name:     "pod",
+			RouterID: RouterID,
+		}
+		_, podVxNet.Network, _ = net.ParseCIDR("192.168.2.0/24")
+		qcapi.VxNets[podVxNet.ID] = podVxNet
+		prepareCloud := func(config *cloudprovider.Config) (cloudprovider.Interface, error) {
+			return qcapi, nil
+		}
+		ipamd := NewFakeIPAM(fakeNetworkClient, clientset, prepareCloud)
+		pools := fakek8s.NewFakeNodeIPPools()
+		ipamd.NodeIPPools = pools
+		stopCh := make(chan struct{})
+		Expect(ipamd.StartIPAMD(stopCh)).ShouldNot(HaveOccurred())
+		defer func() {
+			stopCh <- struct{}{}
+		}()
+
+		nicMac := "aa:aa:aa:aa:aa:aa"
+		Expect(ipamd.dataStore.AddNIC(primaryIntMac, 1, true)).To(Succeed())
+		Expect(ipamd.dataStore.AddNIC(nicMac, 2, false)).To(Succeed())
+		Expect(ipamd.dataStore.AddIPv4AddressFromStore(nicMac, "192.168.2.2")).To(Succeed())
+		Expect(ipamd.dataStore.AddSecondaryIPv4AddressFromStore(nicMac, "192.168.2.3")).To(Succeed())
+		_, _, err := ipamd.dataStore.AssignPodIPv4Address(&k8sclient.K8SPodInfo{Name: "pod1", Namespace: "default", Container: "c"})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		ipamd.updateNodeIPPool()
+		pool, err := pools.Get(nodeName)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(pool.OwnerReferences).To(HaveLen(1))
+		Expect(pool.OwnerReferences[0].UID).To(BeEquivalentTo("node-uid"))
+		Expect(pool.Spec.PoolSize).To(Equal(defaultPoolSize))
+		Expect(pool.Status.VxNet).To(Equal("vxnet-pod"))
+		Expect(pool.Status.TotalIPs).To(Equal(2))
+		Expect(pool.Status.AssignedIPs).To(Equal(1))
+		Expect(pool.Status.NICs).To(HaveLen(2))
+		Expect(pool.Status.NICs[0].Primary).To(BeTrue())
+		Expect(pool.Status.NICs[0].VxNet).To(Equal(nodeVxNet.ID))
+		Expect(pool.Status.NICs[1].ID).To(Equal(nicMac))
+		Expect(pool.Status.NICs[1].DeviceNumber).To(Equal(2))
+		Expect(pool.Status.NICs[1].VxNet).To(Equal("vxnet-pod"))
+		Expect(pool.Status.NICs[1].Addresses).To(HaveLen(2))
+		Expect(pool.Status.NICs[1].Addresses[1].Secondary).To(BeTrue())
+		Expect(pool.Status.NICs[1].Addresses).To(ContainElement(MatchFields(IgnoreExtras, Fields{
+			"Pod": Equal("default/pod1"),
+		})))
+		Expect(pool.Status.NICs[1].Addresses).To(ContainElement(MatchFields(IgnoreExtras, Fields{
+			"Pod": BeEmpty(),
+		})))
+		Expect(pools.Updates()).To(Equal(1))
+
+		// an unchanged pool is not written again, and changes are rate limited
+		ipamd.updateNodeIPPool()
+		Expect(pools.Updates()).To(Equal(1))
+		_, _, err = ipamd.dataStore.AssignPodIPv4Address(&k8sclient.K8SPodInfo{Name: "pod2", Namespace: "default", Container: "c"})
+		Expect(err).ShouldNot(HaveOccurred())
+		ipamd.updateNodeIPPool()
+		Expect(pools.Updates()).To(Equal(1))
+		ipamd.nodeIPPoolLimiter = rate.NewLimiter(rate.Inf, 1)
+		ipamd.updateNodeIPPool()
+		Expect(pools.Updates()).To(Equal(2))
+		pool, _ = pools.Get(nodeName)
+		Expect(pool.Status.AssignedIPs).To(Equal(2))
+	})
+
+	It("Should let hostnicctl look into the pool and release a leaked ip", func() {
+		node := &corev1.Node{}
+		node.Name = nodeName
+		node.Annotations = map[string]string{NodeAnnotationVxNet: "vxnet-pod"}
+		pod1 := &corev1.Pod{}
+		pod1.Name = "pod1"
+		pod1.Namespace = "ns1"
+		pod1.Spec.NodeName = nodeName
+		pod1.Status.PodIP = "192.168.2.2"
+		pod1.Status.ContainerStatuses = []corev1.ContainerStatus{
+			corev1.ContainerStatus{
+				ContainerID: "container1",
+			},
+		}
+		clientset = fake.NewSimpleClientset(node, pod1)
+
+		podVxNet := &types.VxNet{
+			ID:       "vxnet-pod",
+			Name:     "pod",
+			RouterID: RouterID,
+		}
+		_, podVxNet.Network, _ = net.ParseCIDR("192.168.2.0/24")
+		nic1Mac := "aa:aa:aa:aa:aa:aa"
+		qcapi.Nics[nic1Mac] = &types.HostNic{
+			ID:           nic1Mac,
+			VxNet:        podVxNet,
+			HardwareAddr: nic1Mac,
+			Address:      "192.168.2.2",
+			DeviceNumber: 2,
+		}
+		qcapi.VxNets[podVxNet.ID] = podVxNet
+		eth1 := &netlink.Device{
+			LinkAttrs: netlink.NewLinkAttrs(),
+		}
+		eth1.Name = "eth1"
+		eth1.Index = 2
+		eth1.HardwareAddr, _ = net.ParseMAC(nic1Mac)
+		netlinkData.LinkAdd(eth1)
+
+		prepareCloud := func(config *cloudprovider.Config) (cloudprovider.Interface, error) {
+			return qcapi, nil
+		}
+		ipamd := NewFakeIPAM(fakeNetworkClient, clientset, prepareCloud)
+		stopCh := make(chan struct{})
+		Expect(ipamd.StartIPAMD(stopCh)).ShouldNot(HaveOccurred())
+		defer func() {
+			stopCh <- struct{}{}
+		}()
+		handler := NewIntrospectionHandler(ipamd)
+		ctx := context.Background()
+
+		pods, err := handler.ListPods(ctx, &rpc.ListPodsRequest{})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(pods.Pods).To(HaveLen(1))
+		Expect(*pods.Pods[0]).To(MatchFields(IgnoreExtras, Fields{
+			"Name":         Equal("pod1"),
+			"Namespace":    Equal("ns1"),
+			"IP":           Equal("192.168.2.2"),
+			"DeviceNumber": BeEquivalentTo(2),
+			"NIC":          Equal(nic1Mac),
+		}))
+
+		pool, err := handler.PoolStatus(ctx, &rpc.PoolStatusRequest{})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(pool.VxNet).To(Equal("vxnet-pod"))
+		Expect(pool.AssignedIPs).To(BeEquivalentTo(1))
+
+		nics, err := handler.ListNICs(ctx, &rpc.ListNICsRequest{})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(nics.NICs).To(ContainElement(PointTo(MatchFields(IgnoreExtras, Fields{
+			"ID":     Equal(nic1Mac),
+			"Routes": Not(BeEmpty()),
+		}))))
+
+		rules, err := handler.DumpRules(ctx, &rpc.DumpRulesRequest{})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(rules.Rules).NotTo(BeEmpty())
+		for _, rule := range rules.Rules {
+			Expect(rule.Expected).To(BeTrue())
+			Expect(rule.Installed).To(BeTrue())
+			Expect(rule.Owner).To(Equal("ns1/pod1"))
+		}
+
+		// the plugin routes the traffic to the pod in the main table
+		netlinkData.RouteAdd(&netlink.Route{
+			Dst:   &net.IPNet{IP: net.ParseIP("192.168.2.2"), Mask: net.CIDRMask(32, 32)},
+			Table: 254,
+		})
+		verify, err := handler.VerifyPod(ctx, &rpc.VerifyPodRequest{Name: "pod1", Namespace: "ns1"})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(verify.OK).To(BeTrue(), fmt.Sprint(verify.Checks))
+
+		// someone deletes a rule of the pod
+		netlinkData.RuleDel(&netlink.Rule{
+			Src:      &net.IPNet{IP: net.ParseIP("192.168.2.2"), Mask: net.CIDRMask(32, 32)},
+			Dst:      podVxNet.Network,
+			Table:    2,
+			Priority: 1536,
+		})
+		verify, err = handler.VerifyPod(ctx, &rpc.VerifyPodRequest{Name: "pod1", Namespace: "ns1"})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(verify.OK).To(BeFalse())
+		Expect(verify.Checks).To(ContainElement(PointTo(MatchFields(IgnoreExtras, Fields{
+			"Name": Equal("rules"),
+			"OK":   BeFalse(),
+		}))))
+		rules, err = handler.DumpRules(ctx, &rpc.DumpRulesRequest{})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(rules.Rules).To(ContainElement(PointTo(MatchFields(IgnoreExtras, Fields{
+			"Expected":  BeTrue(),
+			"Installed": BeFalse(),
+		}))))
+
+		// the ip of a living pod is only released by force
+		_, err = handler.ReleaseIP(ctx, &rpc.ReleaseIPRequest{IP: "192.168.2.2"})
+		Expect(status.Code(err)).To(Equal(codes.FailedPrecondition))
+		Expect(clientset.CoreV1().Pods("ns1").Delete("pod1", &metav1.DeleteOptions{})).ShouldNot(HaveOccurred())
+		var released *rpc.ReleaseIPReply
+		Eventually(func() error {
+			released, err = handler.ReleaseIP(ctx, &rpc.ReleaseIPRequest{IP: "192.168.2.2"})
+			return err
+		}).ShouldNot(HaveOccurred())
+		Expect(released.Pod).To(Equal("ns1/pod1"))
+		_, assigned := ipamd.dataStore.GetStats()
+		Expect(assigned).To(Equal(0))
+		_, err = handler.ReleaseIP(ctx, &rpc.ReleaseIPRequest{IP: "192.168.2.2"})
+		Expect(status.Code(err)).To(Equal(codes.NotFound))
+	})
+
+	It("Should remove everything of hostnic from the node when it is uninstalled", func() {
+		node := &corev1.Node{}
+		node.Name = nodeName
+		node.Annotations = map[string]string{NodeAnnotationVxNet: "vxnet-pod"}
+		pod1 := &corev1.Pod{}
+		pod1.Name = "pod1"
+		pod1.Namespace = "ns1"
+		pod1.Spec.NodeName = nodeName
+		pod1.Status.PodIP = "192.168.2.2"
+		pod1.Status.ContainerStatuses = []corev1.ContainerStatus{
+			corev1.ContainerStatus{
+				ContainerID: "container1",
+			},
+		}
+		clientset = fake.NewSimpleClientset(node, pod1)
+
+		podVxNet := &types.VxNet{
+			ID:       "vxnet-pod",
+			Name:     NameForVxnet(nodeName),
+			RouterID: RouterID,
+		}
+		_, podVxNet.Network, _ = net.ParseCIDR("192.168.2.0/24")
+		nic1Mac := "aa:aa:aa:aa:aa:aa"
+		qcapi.Nics[nic1Mac] = &types.HostNic{
+			ID:           nic1Mac,
+			VxNet:        podVxNet,
+			HardwareAddr: nic1Mac,
+			Address:      "192.168.2.2",
+			DeviceNumber: 2,
+		}
+		qcapi.VxNets[podVxNet.ID] = podVxNet
+		eth1 := &netlink.Device{
+			LinkAttrs: netlink.NewLinkAttrs(),
+		}
+		eth1.Name = "eth1"
+		eth1.Index = 2
+		eth1.HardwareAddr, _ = net.ParseMAC(nic1Mac)
+		netlinkData.LinkAdd(eth1)
+
+		prepareCloud := func(config *cloudprovider.Config) (cloudprovider.Interface, error) {
+			return qcapi, nil
+		}
+		ipamd := NewFakeIPAM(fakeNetworkClient, clientset, prepareCloud)
+		stopCh := make(chan struct{})
+		defer close(stopCh)
+		Expect(ipamd.StartIPAMD(stopCh)).ShouldNot(HaveOccurred())
+		Expect(netlinkData.Rules).NotTo(BeEmpty())
+		Expect(netlinkData.Routes).To(HaveLen(2))
+
+		// it is uninstalled by another process after ipamd stops
+		newUninstaller := func() *IpamD {
+			networkClient := networkutils.NewFakeNetworkAPI(netlinkData, iptablesData, netlinkData.FindPrimaryInterfaceName, func(string, string) error { return nil })
+			return NewFakeIPAM(networkClient, clientset, prepareCloud)
+		}
+		err := newUninstaller().uninstall(clientset, UninstallOptions{}, stopCh)
+		Expect(err).To(MatchError(ContainSubstring("ns1/pod1")))
+		err = newUninstaller().uninstall(clientset, UninstallOptions{Force: true, ReleaseVxNet: true}, stopCh)
+		Expect(err).To(HaveOccurred())
+		Expect(netlinkData.Rules).NotTo(BeEmpty())
+
+		Expect(clientset.CoreV1().Pods("ns1").Delete("pod1", &metav1.DeleteOptions{})).ShouldNot(HaveOccurred())
+		err = newUninstaller().uninstall(clientset, UninstallOptions{DeleteNICs: true, ReleaseVxNet: true}, stopCh)
+		Expect(err).ShouldNot(HaveOccurred())
+		for table, chains := range iptablesData.Data {
+			for chain, rules := range chains {
+				Expect(chain).NotTo(HavePrefix("QINGCLOUD"), "chain %s of table %s", chain, table)
+				Expect(rules).To(BeEmpty(), "chain %s of table %s", chain, table)
+			}
+		}
+		Expect(netlinkData.Rules).To(BeEmpty())
+		Expect(netlinkData.Routes).To(BeEmpty())
+		Expect(qcapi.Nics).To(HaveKey(primaryIntMac))
+		Expect(qcapi.Nics).NotTo(HaveKey(nic1Mac))
+		Expect(podVxNet.RouterID).To(BeEmpty())
+		node, err = clientset.CoreV1().Nodes().Get(nodeName, metav1.GetOptions{})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(node.Annotations).NotTo(HaveKey(NodeAnnotationVxNet))
+	})
+
+	It("Should stop serving and flush the checkpoint when it shuts down", func() {
+		node := &corev1.Node{}
+		node.Name = nodeName
+		node.Annotations = map[string]string{NodeAnnotationVxNet: "vxnet-pod"}
+		pod1 := &corev1.Pod{}
+		pod1.Name = "pod1"
+		pod1.Namespace = "ns1"
+		pod1.Spec.NodeName = nodeName
+		pod1.Status.PodIP = "192.168.2.2"
+		pod1.Status.ContainerStatuses = []corev1.ContainerStatus{
+			corev1.ContainerStatus{
+				ContainerID: "container1",
+			},
+		}
+		clientset = fake.NewSimpleClientset(node, pod1)
+
+		podVxNet := &types.VxNet{
+			ID:       "vxnet-pod",
+			Name:     "pod",
+			RouterID: RouterID,
+		}
+		_, podVxNet.Network, _ = net.ParseCIDR("192.168.2.0/24")
+		nic1Mac := "aa:aa:aa:aa:aa:aa"
+		qcapi.Nics[nic1Mac] = &types.HostNic{
+			ID:           nic1Mac,
+			VxNet:        podVxNet,
+			HardwareAddr: nic1Mac,
+			Address:      "192.168.2.2",
+			DeviceNumber: 2,
+		}
+		qcapi.VxNets[podVxNet.ID] = podVxNet
+		eth1 := &netlink.Device{
+			LinkAttrs: netlink.NewLinkAttrs(),
+		}
+		eth1.Name = "eth1"
+		eth1.Index = 2
+		eth1.HardwareAddr, _ = net.ParseMAC(nic1Mac)
+		netlinkData.LinkAdd(eth1)
+
+		dir, err := ioutil.TempDir("", "hostnic")
+		Expect(err).ShouldNot(HaveOccurred())
+		defer os.RemoveAll(dir)
+		prepareCloud := func(config *cloudprovider.Config) (cloudprovider.Interface, error) {
+			return qcapi, nil
+		}
+		ipamd := NewFakeIPAM(fakeNetworkClient, clientset, prepareCloud)
+		ipamd.checkpointPath = filepath.Join(dir, "datastore.json")
+		stopCh := make(chan struct{})
+		Expect(ipamd.StartIPAMD(stopCh)).ShouldNot(HaveOccurred())
+		Expect(ipamd.StartGrpcServer()).ShouldNot(HaveOccurred())
+
+		conn, err := grpc.Dial(ipamdgRPCaddress, grpc.WithInsecure())
+		Expect(err).ShouldNot(HaveOccurred())
+		defer conn.Close()
+		client := rpc.NewCNIBackendClient(conn)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, err = client.Ready(ctx, &rpc.ReadyRequest{APIVersion: rpc.APIVersion})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		close(stopCh)
+		Expect(ipamd.shutdown(time.Second)).ShouldNot(HaveOccurred())
+		_, err = client.Ready(ctx, &rpc.ReadyRequest{APIVersion: rpc.APIVersion})
+		Expect(status.Code(err)).To(Equal(codes.Unavailable))
+
+		data, err := ioutil.ReadFile(ipamd.checkpointPath)
+		Expect(err).ShouldNot(HaveOccurred())
+		checkpoint := &datastore.Checkpoint{}
+		Expect(json.Unmarshal(data, checkpoint)).ShouldNot(HaveOccurred())
+		Expect(checkpoint.NICs).To(ContainElement(MatchFields(IgnoreExtras, Fields{
+			"ID":           Equal(nic1Mac),
+			"DeviceNumber": Equal(2),
+		})))
+		Expect(checkpoint.Pods).To(ConsistOf(MatchFields(IgnoreExtras, Fields{
+			"Name":      Equal("pod1"),
+			"Namespace": Equal("ns1"),
+			"IP":        Equal("192.168.2.2"),
+		})))
+		// the nics stay attached to the instance
+		Expect(qcapi.Nics).To(HaveKey(nic1Mac))
+	})
+
+	It("Should serve pods with the state handed over by the last ipamd when it is upgraded", func() {
+		node := &corev1.Node{}
+		node.Name = nodeName
+		node.Annotations = map[string]string{NodeAnnotationVxNet: "vxnet-pod"}
+		pod1 := &corev1.Pod{}
+		pod1.Name = "pod1"
+		pod1.Namespace = "ns1"
+		pod1.Spec.NodeName = nodeName
+		pod1.Status.PodIP = "192.168.2.2"
+		pod1.Status.ContainerStatuses = []corev1.ContainerStatus{
+			corev1.ContainerStatus{
+				ContainerID: "container1",
+			},
+		}
+		clientset = fake.NewSimpleClientset(node, pod1)
+
+		podVxNet := &types.VxNet{
+			ID:       "vxnet-pod",
+			Name:     "pod",
+			RouterID: RouterID,
+		}
+		_, podVxNet.Network, _ = net.ParseCIDR("192.168.2.0/24")
+		nic1Mac := "aa:aa:aa:aa:aa:aa"
+		qcapi.Nics[nic1Mac] = &types.HostNic{
+			ID:                 nic1Mac,
+			VxNet:              podVxNet,
+			HardwareAddr:       nic1Mac,
+			Address:            "192.168.2.2",
+			DeviceNumber:       2,
+			SecondaryAddresses: []string{"192.168.2.3"},
+		}
+		qcapi.VxNets[podVxNet.ID] = podVxNet
+		eth1 := &netlink.Device{
+			LinkAttrs: netlink.NewLinkAttrs(),
+		}
+		eth1.Name = "eth1"
+		eth1.Index = 2
+		eth1.HardwareAddr, _ = net.ParseMAC(nic1Mac)
+		netlinkData.LinkAdd(eth1)
+
+		dir, err := ioutil.TempDir("", "hostnic")
+		Expect(err).ShouldNot(HaveOccurred())
+		defer os.RemoveAll(dir)
+		checkpointPath := filepath.Join(dir, "datastore.json")
+		prepareCloud := func(config *cloudprovider.Config) (cloudprovider.Interface, error) {
+			return qcapi, nil
+		}
+		ipamd := NewFakeIPAM(fakeNetworkClient, clientset, prepareCloud)
+		ipamd.checkpointPath = checkpointPath
+		stopCh := make(chan struct{})
+		Expect(ipamd.StartIPAMD(stopCh)).ShouldNot(HaveOccurred())
+		close(stopCh)
+		Expect(ipamd.shutdown(time.Second)).ShouldNot(HaveOccurred())
+		routes := make(map[string]netlink.Route)
+		for k, route := range netlinkData.Routes {
+			routes[k] = route
+		}
+		// pod1 is deleted while ipamd restarts
+		Expect(clientset.CoreV1().Pods("ns1").Delete("pod1", &metav1.DeleteOptions{})).ShouldNot(HaveOccurred())
+
+		// the cloud is unreachable when the new ipamd starts, so it serves pods with the restored state only
+		cloud := &unreachableCloud{FakeQingCloudAPI: qcapi, unreachable: 1}
+		prepareCloud = func(config *cloudprovider.Config) (cloudprovider.Interface, error) {
+			return cloud, nil
+		}
+		networkClient := networkutils.NewFakeNetworkAPI(netlinkData, iptablesData, netlinkData.FindPrimaryInterfaceName, func(string, string) error { return nil })
+		upgraded := NewFakeIPAM(networkClient, clientset, prepareCloud)
+		upgraded.checkpointPath = checkpointPath
+		stopCh = make(chan struct{})
+		Expect(upgraded.StartIPAMD(stopCh)).ShouldNot(HaveOccurred())
+		defer upgraded.shutdown(time.Second)
+		// the checkpoint is kept until the restored state is verified
+		Expect(checkpointPath).To(BeAnExistingFile())
+		// nothing of the host network is set up from scratch
+		Expect(iptablesData.Cleared).To(BeEmpty())
+		Expect(netlinkData.Routes).To(Equal(routes))
+		Expect(*upgraded.dataStore.GetPodInfos()).To(HaveKey("pod1_ns1_container1"))
+		// a restored nic is detached before the state is verified
+		staleMac := "bb:bb:bb:bb:bb:bb"
+		eth2 := &netlink.Device{
+			LinkAttrs: netlink.NewLinkAttrs(),
+		}
+		eth2.Name = "eth2"
+		eth2.Index = 3
+		eth2.HardwareAddr, _ = net.ParseMAC(staleMac)
+		netlinkData.LinkAdd(eth2)
+		upgraded.poolLock.Lock()
+		err = upgraded.setupNic(&types.HostNic{
+			ID:            staleMac,
+			VxNet:         podVxNet,
+			HardwareAddr:  staleMac,
+			Address:       "192.168.2.10",
+			DeviceNumber:  3,
+			SecurityGroup: "sg-stale",
+		})
+		upgraded.poolLock.Unlock()
+		Expect(err).ShouldNot(HaveOccurred())
+
+		// the plugins which do not tell their api version are refused, with a reply they are able to read
+		conn, err := grpc.Dial(ipamdgRPCaddress, grpc.WithInsecure())
+		Expect(err).ShouldNot(HaveOccurred())
+		defer conn.Close()
+		client := rpc.NewCNIBackendClient(conn)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		request := &rpc.AddNetworkRequest{
+			K8S_POD_NAME:               "pod2",
+			K8S_POD_NAMESPACE:          "ns1",
+			K8S_POD_INFRA_CONTAINER_ID: "container2",
+		}
+		reply, err := client.AddNetwork(ctx, request)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(reply.Success).To(BeFalse())
+		Expect(reply.Message).To(ContainSubstring("too old"))
+		delReply, err := client.DelNetwork(ctx, &rpc.DelNetworkRequest{
+			K8S_POD_NAME:               "pod2",
+			K8S_POD_NAMESPACE:          "ns1",
+			K8S_POD_INFRA_CONTAINER_ID: "container2",
+		})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(delReply.Success).To(BeFalse())
+		Expect(delReply.Message).To(ContainSubstring("too old"))
+		Expect(upgraded.dataStore.GetNICInfos().AssignedIPs).To(Equal(1))
+
+		// the plugin of the oldest supported version is served
+		request.APIVersion = rpc.MinAPIVersion
+		reply, err = client.AddNetwork(ctx, request)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(reply.Success).To(BeTrue())
+		Expect(reply.IPv4Addr).To(Equal("192.168.2.3"))
+		Expect(reply.DeviceNumber).To(BeEquivalentTo(2))
+		pod2 := &corev1.Pod{}
+		pod2.Name = "pod2"
+		pod2.Namespace = "ns1"
+		pod2.Spec.NodeName = nodeName
+		pod2.Status.PodIP = reply.IPv4Addr
+		pod2.Status.ContainerStatuses = []corev1.ContainerStatus{
+			corev1.ContainerStatus{
+				ContainerID: "container2",
+			},
+		}
+		_, err = clientset.CoreV1().Pods("ns1").Create(pod2)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		// the checkpoint is rewritten with pod2 while the cloud is unreachable, and removed once it is back
+		Eventually(func() ([]datastore.CheckpointPod, error) {
+			cp, err := readCheckpoint(checkpointPath)
+			if err != nil || cp == nil {
+				return nil, err
+			}
+			return cp.Pods, nil
+		}, 5*time.Second, 200*time.Millisecond).Should(ContainElement(MatchFields(IgnoreExtras, Fields{"Name": Equal("pod2")})))
+		atomic.StoreInt32(&cloud.unreachable, 0)
+		Eventually(checkpointPath, 10*time.Second, 200*time.Millisecond).ShouldNot(BeAnExistingFile())
+		// the verification removes what is gone while ipamd restarts
+		Expect(*upgraded.dataStore.GetPodInfos()).NotTo(HaveKey("pod1_ns1_container1"))
+		Expect(*upgraded.dataStore.GetPodInfos()).To(HaveKey("pod2_ns1_container2"))
+		Expect(upgraded.getNic(staleMac)).To(BeNil())
+		Expect(upgraded.dataStore.GetNICInfos().NICIPPools).NotTo(HaveKey(staleMac))
+		close(stopCh)
+	})
+
+	It("Should leave no checkpoint only when the DaemonSet is deleted", func() {
+		os.Setenv(envPodName, "hostnic-node-abcde")
+		os.Setenv(envPodNamespace, "kube-system")
+		defer os.Unsetenv(envPodName)
+		defer os.Unsetenv(envPodNamespace)
+		ds := &appsv1.DaemonSet{}
+		ds.Name = "hostnic-node"
+		ds.Namespace = "kube-system"
+		ds.UID = "ds-uid"
+		pod := &corev1.Pod{}
+		pod.Name = "hostnic-node-abcde"
+		pod.Namespace = "kube-system"
+		pod.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(ds, appsv1.SchemeGroupVersion.WithKind("DaemonSet"))}
+		clientset = fake.NewSimpleClientset(ds, pod)
+		Expect(daemonSetDeleted(clientset)).To(BeFalse())
+
+		now := metav1.Now()
+		ds.DeletionTimestamp = &now
+		_, err := clientset.AppsV1().DaemonSets("kube-system").Update(ds)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(daemonSetDeleted(clientset)).To(BeTrue())
+
+		Expect(clientset.AppsV1().DaemonSets("kube-system").Delete(ds.Name, nil)).ShouldNot(HaveOccurred())
+		Expect(daemonSetDeleted(clientset)).To(BeTrue())
+	})
+
+	It("Should report the failures of the pool with their own reasons", func() {
+		k8s := &fakek8s.FakeK8sHelper{}
+		ipamd := &IpamD{K8sClient: k8s}
+		ipamd.recordAddNetworkFailure("ns1", "pod1", hostnicerrors.NewPoolExhaustedError("no ip"))
+		ipamd.recordAddNetworkFailure("ns1", "pod1", hostnicerrors.NewQuotaExceededError(types.ResourceTypeNic, "CreateNics", "quota"))
+		ipamd.recordAddNetworkFailure("ns1", "pod1", hostnicerrors.NewCloudThrottledError("CreateNics", "busy"))
+		ipamd.recordAddNetworkFailure("ns1", "pod1", fmt.Errorf("no netns"))
+		ipamd.recordAllocationFailure(hostnicerrors.NewNICNotReadyError("nic1", "not attached"))
+		ipamd.recordAllocationFailure(fmt.Errorf("unknown"))
+		var reasons []string
+		for _, event := range k8s.Events() {
+			fields := strings.Fields(event)
+			reasons = append(reasons, strings.SplitN(fields[0], "/", 2)[0]+"/"+fields[2])
+		}
+		Expect(reasons).To(Equal([]string{
+			"Node/" + EventReasonIPPoolExhausted, "Pod/" + EventReasonIPPoolExhausted,
+			"Node/" + EventReasonQuotaExceeded, "Pod/" + EventReasonQuotaExceeded,
+			"Node/" + EventReasonCloudThrottled, "Pod/" + EventReasonCloudThrottled,
+			"Pod/" + EventReasonAddNetworkFailed,
+			"Node/" + EventReasonNICAttachTimeout,
+			"Node/" + EventReasonNICAllocationFailed,
+		}))
 	})
 })
@@ -0,0 +1,39 @@
+package ipam
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// AnnotationMTU sets the MTU of the veth of a pod. It can only lower the MTU of the node, because packets larger
+	// than the MTU of the nics are dropped by the VPC.
+	AnnotationMTU = "hostnic.beta.kubernetes.io/mtu"
+
+	// minPodMTU is the minimum MTU of IPv4
+	minPodMTU = 68
+)
+
+// podMTU returns the MTU of the veth of a pod, which is the MTU of the node unless the pod lowers it by annotation
+func (s *IpamD) podMTU(pod *corev1.Pod) (int, error) {
+	mtu := s.networkClient.GetMTU()
+	if pod == nil {
+		return mtu, nil
+	}
+	value, ok := pod.Annotations[AnnotationMTU]
+	if !ok {
+		return mtu, nil
+	}
+	podMTU, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid mtu %q of pod %s/%s", value, pod.Namespace, pod.Name)
+	}
+	if podMTU < minPodMTU || podMTU > mtu {
+		return 0, fmt.Errorf("mtu %d of pod %s/%s is out of range [%d, %d]", podMTU, pod.Namespace, pod.Name, minPodMTU, mtu)
+	}
+	return podMTU, nil
+}
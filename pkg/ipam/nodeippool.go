@@ -0,0 +1,144 @@
+package ipam
+
+import (
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/yunify/hostnic-cni/pkg/apis/hostnic/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+)
+
+// nodeIPPoolMinInterval is the min interval between the updates of the NodeIPPool of the node, so that a node
+// busy with pods does not flood apiserver
+const nodeIPPoolMinInterval = 10 * time.Second
+
+// nodeIPPoolSpec returns the warm pool targets of ipamd
+func (s *IpamD) nodeIPPoolSpec() v1alpha1.NodeIPPoolSpec {
+	return v1alpha1.NodeIPPoolSpec{
+		PoolSize:     s.poolSize,
+		MaxPoolSize:  s.maxPoolSize,
+		MaxIPsPerNIC: s.maxIPsPerNIC,
+		MaxNICs:      s.maxNICs,
+	}
+}
+
+// nodeIPPoolStatus returns the nics in the data store and the pods their ips are assigned to
+func (s *IpamD) nodeIPPoolStatus() v1alpha1.NodeIPPoolStatus {
+	pods := make(map[string]string)
+	for _, pod := range s.dataStore.GetPods() {
+		pods[pod.IP] = pod.Namespace + "/" + pod.Name
+	}
+	infos := s.dataStore.GetNICInfos()
+	status := v1alpha1.NodeIPPoolStatus{
+		InstanceID:  s.InstanceID,
+		TotalIPs:    infos.TotalIPs,
+		AssignedIPs: infos.AssignedIPs,
+	}
+	if s.vxnet != nil {
+		status.VxNet = s.vxnet.ID
+	}
+	for _, pool := range infos.NICIPPools {
+		nic := v1alpha1.NICStatus{
+			ID:            pool.ID,
+			DeviceNumber:  pool.DeviceNumber,
+			Primary:       pool.IsPrimary,
+			VxNet:         s.vxnetOfNic(pool.ID, pool.IsPrimary),
+			SecurityGroup: pool.SecurityGroup,
+			Reserved:      pool.Reserved > 0,
+		}
+		for _, addr := range pool.IPv4Addresses {
+			nic.Addresses = append(nic.Addresses, v1alpha1.AddressStatus{
+				IP:        addr.Address,
+				Secondary: addr.Secondary,
+				Pod:       pods[addr.Address],
+			})
+		}
+		sort.Slice(nic.Addresses, func(i, j int) bool {
+			return nic.Addresses[i].IP < nic.Addresses[j].IP
+		})
+		status.NICs = append(status.NICs, nic)
+	}
+	sort.Slice(status.NICs, func(i, j int) bool {
+		return status.NICs[i].DeviceNumber < status.NICs[j].DeviceNumber
+	})
+	return status
+}
+
+func (s *IpamD) vxnetOfNic(nicID string, isPrimary bool) string {
+	if isPrimary {
+		if s.primaryNic != nil && s.primaryNic.VxNet != nil {
+			return s.primaryNic.VxNet.ID
+		}
+		return ""
+	}
+	if nic := s.getNic(nicID); nic != nil && nic.VxNet != nil {
+		return nic.VxNet.ID
+	}
+	if s.vxnet != nil {
+		return s.vxnet.ID
+	}
+	return ""
+}
+
+// updateNodeIPPool writes the pool of the node to its NodeIPPool. An unchanged pool is only written once in
+// nodeIPPoolReconcileInterval to refresh the update time, and no pool is written more often than the limiter allows.
+func (s *IpamD) updateNodeIPPool() {
+	if s.NodeIPPools == nil {
+		return
+	}
+	if stage, _ := s.readiness.status(); stage < stageHostNetworkReady {
+		return
+	}
+	spec, status := s.nodeIPPoolSpec(), s.nodeIPPoolStatus()
+	if last := s.lastNodeIPPool; last != nil && time.Since(last.Status.LastUpdateTime.Time) < nodeIPPoolReconcileInterval {
+		status.LastUpdateTime = last.Status.LastUpdateTime
+		if reflect.DeepEqual(last.Spec, spec) && reflect.DeepEqual(last.Status, status) {
+			return
+		}
+	}
+	if !s.nodeIPPoolLimiter.Allow() {
+		klog.V(4).Infoln("Skip updating the NodeIPPool of the node, it is updated recently")
+		return
+	}
+	status.LastUpdateTime = metav1.Now()
+
+	pool, err := s.NodeIPPools.Get(s.NodeName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		klog.Errorf("Failed to get NodeIPPool %s: %v", s.NodeName, err)
+		return
+	}
+	if err != nil {
+		pool = &v1alpha1.NodeIPPool{}
+		pool.Name = s.NodeName
+		s.setNodeIPPoolOwner(pool)
+		pool.Spec, pool.Status = spec, status
+		pool, err = s.NodeIPPools.Create(pool)
+	} else {
+		pool.Spec, pool.Status = spec, status
+		pool, err = s.NodeIPPools.Update(pool)
+	}
+	if err != nil {
+		klog.Errorf("Failed to update NodeIPPool %s: %v", s.NodeName, err)
+		return
+	}
+	klog.V(4).Infof("Updated NodeIPPool %s", s.NodeName)
+	s.lastNodeIPPool = pool
+}
+
+// setNodeIPPoolOwner makes the pool deleted with the node
+func (s *IpamD) setNodeIPPoolOwner(pool *v1alpha1.NodeIPPool) {
+	node, err := s.K8sClient.GetCurrentNode()
+	if err != nil || node.UID == "" {
+		return
+	}
+	pool.OwnerReferences = []metav1.OwnerReference{{
+		APIVersion: corev1.SchemeGroupVersion.String(),
+		Kind:       "Node",
+		Name:       node.Name,
+		UID:        node.UID,
+	}}
+}
@@ -1,8 +1,12 @@
 package ipam
 
 import (
+	"context"
 	"time"
 
+	"github.com/yunify/hostnic-cni/pkg/errors"
+	"github.com/yunify/hostnic-cni/pkg/ipam/datastore"
+	"github.com/yunify/hostnic-cni/pkg/retry"
 	"k8s.io/klog"
 )
 
@@ -10,44 +14,73 @@ const (
 	nodeIPPoolReconcileInterval = 60 * time.Second
 	decreaseIPPoolInterval      = 30 * time.Second
 	defaultSleepDuration        = 10 * time.Second
+	// maxNICsPerBatch is the maximum number of nics created at once
+	maxNICsPerBatch = 5
 )
 
 // StartReconcileIPPool will start reconciling ip pool
 func (s *IpamD) StartReconcileIPPool(stopCh <-chan struct{}, sleepDuration ...time.Duration) {
 	klog.V(1).Infoln("Starting ip pool reconciling")
+	ctx, cancel := retry.WithStopChannel(stopCh)
+	defer cancel()
+	sleep := defaultSleepDuration
+	if len(sleepDuration) == 1 {
+		sleep = sleepDuration[0]
+	}
+	// the pool and the NodeIPPool are reconciled in turn, sleep apart
+	timer := time.NewTimer(sleep)
+	defer timer.Stop()
+	reconcilePool := true
 	for {
 		select {
 		case <-stopCh:
 			klog.V(1).Infoln("Receive stop signal, stop pool manager")
 			return
-		default:
+		case <-timer.C:
+		}
+		if reconcilePool {
 			klog.V(3).Infoln("Begin to reconcile nic pool")
-			sleep := defaultSleepDuration
-			if len(sleepDuration) == 1 {
-				sleep = sleepDuration[0]
-			}
-			time.Sleep(sleep)
-			s.updateIPPoolIfRequired()
-			time.Sleep(sleep)
+			s.updateIPPoolIfRequired(ctx)
+			s.checkPoolWarmed()
+			s.updateIPResource()
+		} else {
 			s.nodeIPPoolReconcile()
 		}
+		reconcilePool = !reconcilePool
+		timer.Reset(sleep)
 	}
 }
 
-func (s *IpamD) updateIPPoolIfRequired() {
+func (s *IpamD) updateIPPoolIfRequired(ctx context.Context) {
+	s.poolLock.Lock()
+	defer s.poolLock.Unlock()
 	if s.nodeIPPoolTooLow() {
-		s.increaseIPPool()
+		s.increaseIPPool(ctx)
 	} else if s.nodeIPPoolTooHigh() {
 		s.decreaseIPPool()
 	}
+	s.updateSecurityGroupPools(ctx)
 }
 
-func (s *IpamD) nodeIPPoolReconcile() {
+// updateSecurityGroupPools allocates a nic for each security group which pods are waiting for, and deletes the
+// unused nics out of the default security group. Only the default security group keeps a warm pool.
+func (s *IpamD) updateSecurityGroupPools(ctx context.Context) {
+	for _, sg := range s.takeSecurityGroupRequests() {
+		if total, used := s.dataStore.GetStatsOfSecurityGroup(sg); total > used {
+			continue
+		}
+		klog.V(2).Infof("Pods are waiting for a nic in security group %q", sg)
+		s.allocateIPs(ctx, sg, 1)
+	}
+	s.releaseUnusedIP(datastore.NotInSecurityGroup(s.securityGroup))
+}
 
+func (s *IpamD) nodeIPPoolReconcile() {
+	s.updateNodeIPPool()
 }
 
 func (s *IpamD) nodeIPPoolTooLow() bool {
-	total, used := s.dataStore.GetStats()
+	total, used := s.dataStore.GetStatsOfSecurityGroup(s.securityGroup)
 	klog.V(4).Infof("IP pool stats: total = %d, used = %d", total, used)
 	if (total - used) < s.poolSize {
 		return true
@@ -56,7 +89,7 @@ func (s *IpamD) nodeIPPoolTooLow() bool {
 }
 
 func (s *IpamD) nodeIPPoolTooHigh() bool {
-	total, used := s.dataStore.GetStats()
+	total, used := s.dataStore.GetStatsOfSecurityGroup(s.securityGroup)
 	klog.V(4).Infof("IP pool stats: total = %d, used = %d", total, used)
 	if (total - used) > s.maxPoolSize {
 		return true
@@ -64,35 +97,171 @@ func (s *IpamD) nodeIPPoolTooHigh() bool {
 	return false
 }
 
-func (s *IpamD) increaseIPPool() {
+func (s *IpamD) increaseIPPool(ctx context.Context) {
 	klog.V(2).Infoln("try to increase ip pool")
-	s.tryAllocateNIC()
+	total, used := s.dataStore.GetStatsOfSecurityGroup(s.securityGroup)
+	s.allocateIPs(ctx, s.securityGroup, s.poolSize-(total-used))
+}
+
+// allocateIPs adds at most count ips in the security group to the pool. Secondary private ips are assigned to a
+// nic which has room for them, new nics are allocated only if there is no such nic.
+func (s *IpamD) allocateIPs(ctx context.Context, securityGroup string, count int) {
+	if s.maxIPsPerNIC > 1 {
+		nic := s.dataStore.GetNICNeedsIP(s.maxIPsPerNIC, true, datastore.InSecurityGroup(securityGroup))
+		if nic != nil {
+			if room := s.maxIPsPerNIC - len(nic.IPv4Addresses); count > room {
+				count = room
+			}
+			err := s.assignSecondaryIPs(nic.ID, count)
+			if err == nil {
+				return
+			}
+			klog.Errorf("Failed to assign secondary ips to nic %s, will allocate a new nic. Error: %s", nic.ID, err.Error())
+		}
+	}
+	// a new nic has one ip, the others will be assigned as secondary ips later
+	count = (count + s.maxIPsPerNIC - 1) / s.maxIPsPerNIC
+	if count > maxNICsPerBatch {
+		count = maxNICsPerBatch
+	}
+	if room := s.maxNICs - s.dataStore.GetNICs(); count > room {
+		count = room
+	}
+	if count <= 0 {
+		klog.V(2).Infof("There are already %d nics on the node, no more nic is allocated", s.maxNICs)
+		return
+	}
+	s.tryAllocateNICs(ctx, securityGroup, count)
+}
+
+func (s *IpamD) assignSecondaryIPs(nicID string, count int) error {
+	klog.V(2).Infof("Try to assign %d secondary ips to nic %s", count, nicID)
+	ips, err := s.qcClient.AssignPrivateIPs(nicID, count)
+	if err != nil {
+		return err
+	}
+	for _, ip := range ips {
+		err = s.dataStore.AddSecondaryIPv4AddressFromStore(nicID, ip)
+		if err != nil {
+			klog.Errorf("Failed to add secondary ip %s of nic %s to data store, err: %s", ip, nicID, err.Error())
+		}
+	}
+	klog.V(2).Infof("Assign secondary ips %v to nic %s successfully", ips, nicID)
+	return nil
 }
 
-func (s *IpamD) tryAllocateNIC() {
-	klog.V(2).Infoln("Try to allocate a new nic to pool")
-	nic, err := s.qcClient.CreateNic(s.vxnet.ID)
+// tryAllocateNICs creates count nics in one batch and sets them up. The nics which are created when the batch
+// partially fails are still used.
+func (s *IpamD) tryAllocateNICs(ctx context.Context, securityGroup string, count int) {
+	klog.V(2).Infof("Try to allocate %d new nics to pool", count)
+	nics, err := s.qcClient.CreateNics(ctx, s.vxnet.ID, count)
 	if err != nil {
-		klog.Errorf("Failed to create a nic in %s, err: %s", s.vxnet.ID, err.Error())
+		klog.Errorf("Failed to create %d nics in %s, %d created, err: %s", count, s.vxnet.ID, len(nics), err.Error())
+		s.recordAllocationFailure(err)
+	}
+	if len(nics) == 0 {
+		s.setAllocationError(err)
 		return
 	}
-	//tag nic
-	err = s.setupNic(nic)
+	s.setAllocationError(nil)
+	err = s.applySecurityGroup(securityGroup, nics...)
 	if err != nil {
-		klog.Errorf("Failed to setup nic in host, err: %s", err.Error())
+		klog.Errorf("Failed to bind nics to security group, err: %s", err.Error())
+		ids := make([]string, 0, len(nics))
+		for _, nic := range nics {
+			ids = append(ids, nic.ID)
+		}
+		if err = s.qcClient.DeleteNics(ids); err != nil {
+			klog.Errorf("Failed to delete nics %v in cloud, err: %s", ids, err.Error())
+		}
+		return
+	}
+	failed := make([]string, 0)
+	for _, nic := range nics {
+		err = s.setupNic(nic)
+		if err != nil {
+			klog.Errorf("Failed to setup nic %s in host, err: %s", nic.ID, err.Error())
+			if err = s.dataStore.RemoveNICFromDataStore(nic.ID); err != nil && err.Error() != datastore.UnknownNICError {
+				klog.Errorf("Failed to remove nic %s from data store, err: %s", nic.ID, err.Error())
+				continue
+			}
+			failed = append(failed, nic.ID)
+			continue
+		}
+		klog.V(2).Infof("Allocate nic %s successfully", nic.ID)
+	}
+	// the nics which are not in the pool are not deleted by anyone else
+	if len(failed) > 0 {
+		if err = s.qcClient.DeleteNics(failed); err != nil {
+			klog.Errorf("Failed to delete nics %v in cloud, err: %s", failed, err.Error())
+		}
+	}
+}
+
+func (s *IpamD) setAllocationError(err error) {
+	s.allocationLock.Lock()
+	defer s.allocationLock.Unlock()
+	s.allocationError = err
+}
+
+func (s *IpamD) lastAllocationError() error {
+	s.allocationLock.Lock()
+	defer s.allocationLock.Unlock()
+	return s.allocationError
+}
+
+// poolExhaustedError returns the error for a pod when the pool is exhausted. It is the error of the cloud if the
+// cloud keeps the pool from growing, so that users know what to fix.
+func (s *IpamD) poolExhaustedError() error {
+	s.allocationLock.Lock()
+	defer s.allocationLock.Unlock()
+	err := s.allocationError
+	if errors.IsQuotaExceeded(err) || errors.IsCloudThrottled(err) || errors.IsNICNotReady(err) {
+		return err
 	}
-	klog.V(2).Infoln("Allocate nic successfully")
+	return datastore.ErrNoAvailableIP
 }
 
 func (s *IpamD) decreaseIPPool() {
 	klog.V(2).Infoln("try to decrease ip pool")
-	nicid := s.dataStore.RemoveUnusedNICFromStore()
+	s.releaseUnusedIP(datastore.InSecurityGroup(s.securityGroup))
+	klog.V(2).Infoln("decrease pool successfully")
+}
+
+// releaseUnusedIP releases an unused secondary ip of a nic selected by filters. The nic is deleted if there is no
+// such ip, so that nics are only deleted after all their secondary ips are released or used.
+func (s *IpamD) releaseUnusedIP(filters ...datastore.NICFilter) {
+	nicID, ip := s.dataStore.RemoveUnusedSecondaryIPv4AddressFromStore(filters...)
+	if ip == "" {
+		s.deleteUnusedNIC(filters...)
+		return
+	}
+	klog.V(2).Infof("release secondary ip %s of nic %s", ip, nicID)
+	err := s.qcClient.UnassignPrivateIPs(nicID, ip)
+	if err != nil {
+		klog.Errorf("Failed to release secondary ip %s of nic %s in cloud, err: %s", ip, nicID, err.Error())
+		// keep it in the pool, it can still be given to pods
+		if err = s.dataStore.AddSecondaryIPv4AddressFromStore(nicID, ip); err != nil {
+			klog.Errorf("Failed to add secondary ip %s of nic %s back to data store, err: %s", ip, nicID, err.Error())
+		}
+	}
+}
+
+// deleteUnusedNIC deletes an unused nic selected by filters
+func (s *IpamD) deleteUnusedNIC(filters ...datastore.NICFilter) {
+	nicid := s.dataStore.RemoveUnusedNICFromStore(filters...)
 	if nicid != "" {
 		klog.V(2).Infof("delete nic %s", nicid)
+		// the network of a nic is kept by its mac address, which may differ from its id
+		if nic := s.getNic(nicid); nic != nil {
+			if err := s.networkClient.TeardownNICNetwork(nic.HardwareAddr); err != nil {
+				klog.Errorf("Failed to tear down network of nic %s, err: %s", nicid, err.Error())
+			}
+		}
+		s.removeNic(nicid)
 		err := s.qcClient.DeleteNic(nicid)
 		if err != nil {
 			klog.Errorf("Failed to delete nic %s in cloud, err: %s", nicid, err.Error())
 		}
 	}
-	klog.V(2).Infoln("decrease pool successfully")
 }
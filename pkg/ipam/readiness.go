@@ -0,0 +1,187 @@
+package ipam
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog"
+)
+
+const (
+	// NodeConditionHostnicReady tells whether hostnic is able to assign ips to the pods on the node
+	NodeConditionHostnicReady corev1.NodeConditionType = "HostnicReady"
+
+	// envHealthAddress sets the address serving /healthz and /readyz
+	envHealthAddress     = "HOSTNIC_HEALTH_ADDRESS"
+	defaultHealthAddress = ":41082"
+)
+
+// readinessStage is a step ipamd goes through before it is able to assign ips to pods, stages are passed in order
+type readinessStage int
+
+const (
+	stageStarting readinessStage = iota
+	stageCloudClientReady
+	stageVxNetEnsured
+	stageHostNetworkReady
+	stagePoolWarmed
+)
+
+var stageNames = map[readinessStage]string{
+	stageStarting:         "Starting",
+	stageCloudClientReady: "CloudClientReady",
+	stageVxNetEnsured:     "VxNetEnsured",
+	stageHostNetworkReady: "HostNetworkReady",
+	stagePoolWarmed:       "PoolWarmed",
+}
+
+func (s readinessStage) String() string {
+	return stageNames[s]
+}
+
+// readiness keeps the stage ipamd has passed, and the error which keeps it from the next one
+type readiness struct {
+	lock    sync.Mutex
+	stage   readinessStage
+	err     error
+	readyCh chan struct{}
+	// onChange is called with the new stage and error whenever they change
+	onChange func(stage readinessStage, err error)
+}
+
+func newReadiness() *readiness {
+	return &readiness{readyCh: make(chan struct{})}
+}
+
+// advance moves to the stage and clears the error, it never goes back to an earlier stage
+func (r *readiness) advance(stage readinessStage) {
+	r.lock.Lock()
+	if stage < r.stage || (stage == r.stage && r.err == nil) {
+		r.lock.Unlock()
+		return
+	}
+	if stage == stagePoolWarmed && r.stage != stagePoolWarmed {
+		close(r.readyCh)
+	}
+	r.stage, r.err = stage, nil
+	onChange := r.onChange
+	r.lock.Unlock()
+	klog.V(1).Infof("Ipamd passes stage %s", stage)
+	if onChange != nil {
+		onChange(stage, nil)
+	}
+}
+
+// fail records the error which keeps ipamd at the current stage
+func (r *readiness) fail(err error) {
+	r.lock.Lock()
+	r.err = err
+	stage, onChange := r.stage, r.onChange
+	r.lock.Unlock()
+	klog.Errorf("Ipamd is stuck at stage %s: %v", stage, err)
+	if onChange != nil {
+		onChange(stage, err)
+	}
+}
+
+func (r *readiness) status() (readinessStage, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.stage, r.err
+}
+
+func (r *readiness) isReady() bool {
+	stage, _ := r.status()
+	return stage == stagePoolWarmed
+}
+
+// message tells what ipamd is waiting for, it is empty if ipamd is ready
+func (r *readiness) message() string {
+	return readinessMessage(r.status())
+}
+
+func readinessMessage(stage readinessStage, err error) string {
+	if stage == stagePoolWarmed {
+		return ""
+	}
+	if err != nil {
+		return fmt.Sprintf("passed stage %s, failed to reach %s: %v", stage, stage+1, err)
+	}
+	return fmt.Sprintf("passed stage %s, waiting for %s", stage, stage+1)
+}
+
+// wait blocks until ipamd is ready or the context is done
+func (r *readiness) wait(ctx context.Context) error {
+	select {
+	case <-r.readyCh:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ready returns whether ipamd is able to assign ips to pods, and why if it is not
+func (s *IpamD) ready() (bool, string) {
+	return s.readiness.isReady(), s.readiness.message()
+}
+
+// checkPoolWarmed passes the last stage once the host network is set up and there is a free ip in the pool. A pool
+// at its max capacity is warmed as well, it never has a free ip while all its ips are used.
+func (s *IpamD) checkPoolWarmed() {
+	if stage, _ := s.readiness.status(); stage != stageHostNetworkReady {
+		return
+	}
+	if total, assigned := s.dataStore.GetStats(); total > assigned {
+		s.readiness.advance(stagePoolWarmed)
+	} else if s.dataStore.GetNICs() >= s.maxNICs && s.dataStore.GetNICNeedsIP(s.maxIPsPerNIC, true) == nil {
+		klog.V(2).Infof("All the %d ips of the pool are used, which is at its max capacity", total)
+		s.readiness.advance(stagePoolWarmed)
+	}
+}
+
+// publishReadiness reflects the readiness in the node condition
+func (s *IpamD) publishReadiness(stage readinessStage, err error) {
+	condition := corev1.NodeCondition{
+		Type:    NodeConditionHostnicReady,
+		Status:  corev1.ConditionFalse,
+		Reason:  stage.String(),
+		Message: readinessMessage(stage, err),
+	}
+	if stage == stagePoolWarmed {
+		condition.Status = corev1.ConditionTrue
+		condition.Message = "hostnic is ready to assign ips to pods"
+	}
+	if err != nil {
+		condition.Reason = failureReasons[stage+1]
+		s.K8sClient.RecordNodeEvent(corev1.EventTypeWarning, condition.Reason, condition.Message)
+	}
+	if err := s.K8sClient.UpdateNodeCondition(condition); err != nil {
+		klog.Errorf("Failed to update condition %s of the node: %v", NodeConditionHostnicReady, err)
+	}
+}
+
+// serveReadyz answers 200 if ipamd is ready, and 503 with what it is waiting for otherwise
+func (s *IpamD) serveReadyz(w http.ResponseWriter, r *http.Request) {
+	if ready, message := s.ready(); !ready {
+		http.Error(w, message, http.StatusServiceUnavailable)
+		return
+	}
+	w.Write([]byte("ok"))
+}
+
+// StartHealthServer serves /healthz and /readyz for the probes of kubelet
+func (s *IpamD) StartHealthServer(address string) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	mux.HandleFunc("/readyz", s.serveReadyz)
+	go func() {
+		if err := http.ListenAndServe(address, mux); err != nil {
+			klog.Errorf("Failed to serve health checks on %s: %v", address, err)
+		}
+	}()
+}
@@ -0,0 +1,76 @@
+package ipam
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/yunify/hostnic-cni/pkg/ipam/datastore"
+	"github.com/yunify/hostnic-cni/pkg/k8sclient"
+	"golang.org/x/net/context"
+)
+
+var _ = Describe("Readiness", func() {
+	It("Should pass the stages in order and record what keeps it from the next one", func() {
+		r := newReadiness()
+		var changes []readinessStage
+		r.onChange = func(stage readinessStage, err error) {
+			changes = append(changes, stage)
+		}
+		Expect(r.isReady()).To(BeFalse())
+		Expect(r.message()).To(ContainSubstring("waiting for CloudClientReady"))
+
+		r.advance(stageVxNetEnsured)
+		r.advance(stageCloudClientReady)
+		stage, err := r.status()
+		Expect(stage).To(Equal(stageVxNetEnsured))
+		Expect(err).ShouldNot(HaveOccurred())
+
+		r.fail(fmt.Errorf("no route to host"))
+		Expect(r.message()).To(ContainSubstring("failed to reach HostNetworkReady: no route to host"))
+		r.advance(stageVxNetEnsured)
+		_, err = r.status()
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(changes).To(Equal([]readinessStage{stageVxNetEnsured, stageVxNetEnsured, stageVxNetEnsured}))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		Expect(r.wait(ctx)).To(Equal(context.DeadlineExceeded))
+		r.advance(stagePoolWarmed)
+		r.advance(stagePoolWarmed)
+		Expect(r.wait(context.Background())).To(Succeed())
+		Expect(r.isReady()).To(BeTrue())
+		Expect(r.message()).To(BeEmpty())
+	})
+
+	It("Should be warmed when the pool is at its max capacity", func() {
+		ipamd := &IpamD{readiness: newReadiness(), dataStore: datastore.NewDataStore(), maxNICs: 2, maxIPsPerNIC: 1}
+		ipamd.readiness.advance(stageHostNetworkReady)
+		Expect(ipamd.dataStore.AddNIC("aa:aa:aa:aa:aa:aa", 2, false)).To(Succeed())
+		Expect(ipamd.dataStore.AddIPv4AddressFromStore("aa:aa:aa:aa:aa:aa", "192.168.2.2")).To(Succeed())
+		_, _, err := ipamd.dataStore.AssignPodIPv4Address(&k8sclient.K8SPodInfo{Name: "pod1", Namespace: "default", Container: "c"})
+		Expect(err).ShouldNot(HaveOccurred())
+		ipamd.checkPoolWarmed()
+		Expect(ipamd.readiness.isReady()).To(BeFalse())
+
+		ipamd.maxNICs = 1
+		ipamd.checkPoolWarmed()
+		Expect(ipamd.readiness.isReady()).To(BeTrue())
+	})
+
+	It("Should answer /readyz by the readiness", func() {
+		ipamd := &IpamD{readiness: newReadiness()}
+		recorder := httptest.NewRecorder()
+		ipamd.serveReadyz(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		Expect(recorder.Code).To(Equal(http.StatusServiceUnavailable))
+		Expect(recorder.Body.String()).To(ContainSubstring("waiting for"))
+
+		ipamd.readiness.advance(stagePoolWarmed)
+		recorder = httptest.NewRecorder()
+		ipamd.serveReadyz(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+	})
+})
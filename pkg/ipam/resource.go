@@ -0,0 +1,99 @@
+package ipam
+
+import (
+	"os"
+
+	"github.com/yunify/hostnic-cni/pkg/cloudprovider"
+	"github.com/yunify/hostnic-cni/pkg/webhook"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/klog"
+)
+
+const (
+	// ResourceIP is the extended resource of the pod ips on a node. Pods requesting one are only scheduled to the
+	// nodes which are able to give them an ip.
+	ResourceIP = webhook.ResourceIP
+
+	// envWebhookAddress turns on the webhook making pods request ResourceIP, it is served with the certificate
+	// and the key in envWebhookCert and envWebhookKey
+	envWebhookAddress = "HOSTNIC_WEBHOOK_ADDRESS"
+	envWebhookCert    = "HOSTNIC_WEBHOOK_CERT"
+	envWebhookKey     = "HOSTNIC_WEBHOOK_KEY"
+
+	// envMaxNICs sets the max number of nics for pods on a node
+	envMaxNICs = "HOSTNIC_MAX_NICS"
+	// defaultMaxNICs leaves a slot for the primary nic
+	defaultMaxNICs = cloudprovider.MaxNICsPerInstance - 1
+	// reservedIPsPerVxNet are the addresses of a vxnet which are never given to nics: network, gateway and broadcast
+	reservedIPsPerVxNet = 3
+)
+
+// ipCapacity returns the number of pod ips the node is able to offer to the pods requesting ResourceIP. It is
+// limited by the nics a node can attach and the size of the vxnet, and the ips taken by the pods which do not
+// request the resource are excluded, since the scheduler does not count them.
+func (s *IpamD) ipCapacity() int {
+	capacity := s.maxNICs * s.maxIPsPerNIC
+	if s.vxnet != nil && s.vxnet.Network != nil {
+		ones, bits := s.vxnet.Network.Mask.Size()
+		if size := 1<<uint(bits-ones) - reservedIPsPerVxNet; size < capacity {
+			capacity = size
+		}
+	}
+	capacity -= s.ipsNotRequested()
+	if capacity < 0 {
+		return 0
+	}
+	return capacity
+}
+
+// ipsNotRequested counts the pods which have an ip but do not request ResourceIP
+func (s *IpamD) ipsNotRequested() int {
+	count := 0
+	for _, info := range s.dataStore.GetPods() {
+		pod, err := s.K8sClient.GetPod(info.Namespace, info.Name)
+		if err != nil || !podRequestsIP(pod) {
+			count++
+		}
+	}
+	return count
+}
+
+func podRequestsIP(pod *corev1.Pod) bool {
+	for _, container := range pod.Spec.Containers {
+		if _, ok := container.Resources.Requests[ResourceIP]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// updateIPResource advertises the ip capacity of the node after the vxnet is known
+func (s *IpamD) updateIPResource() {
+	if stage, _ := s.readiness.status(); stage < stageHostNetworkReady {
+		return
+	}
+	capacity := s.ipCapacity()
+	if capacity == s.advertisedIPs {
+		return
+	}
+	klog.V(2).Infof("Advertise %d %s of the node", capacity, ResourceIP)
+	if err := s.K8sClient.UpdateNodeResource(ResourceIP, *resource.NewQuantity(int64(capacity), resource.DecimalSI)); err != nil {
+		klog.Errorf("Failed to update %s of the node: %v", ResourceIP, err)
+		return
+	}
+	s.advertisedIPs = capacity
+}
+
+// startWebhook serves the webhook if it is turned on
+func startWebhook() {
+	address := os.Getenv(envWebhookAddress)
+	if address == "" {
+		return
+	}
+	go func() {
+		if err := webhook.Serve(address, os.Getenv(envWebhookCert), os.Getenv(envWebhookKey)); err != nil {
+			klog.Errorf("Failed to serve the webhook on %s: %v", address, err)
+		}
+	}()
+}
@@ -0,0 +1,126 @@
+package ipam
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/yunify/hostnic-cni/pkg/cloudprovider"
+	"github.com/yunify/hostnic-cni/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog"
+)
+
+const (
+	// NodeAnnotationSecurityGroup sets the security group of the nics created for the pods on a node
+	NodeAnnotationSecurityGroup = "node.beta.kubernetes.io/security-group"
+	// AnnotationSecurityGroup asks for a nic in the security group for a pod. It can be set on a pod or on a
+	// namespace, the annotation of the pod takes precedence. Pods without it use the security group of the node.
+	AnnotationSecurityGroup = "hostnic.beta.kubernetes.io/security-group"
+
+	// envVxNetSecurityGroups sets the security group of the nics for the nodes using a vxnet, in the format of
+	// vxnet-a:sg-a,vxnet-b:sg-b. NodeAnnotationSecurityGroup takes precedence over it.
+	envVxNetSecurityGroups = "HOSTNIC_VXNET_SECURITY_GROUPS"
+	// envInheritPrimarySecurityGroup binds the nics to the security group of the primary nic if neither
+	// NodeAnnotationSecurityGroup nor envVxNetSecurityGroups sets one, which is false by default
+	envInheritPrimarySecurityGroup = "HOSTNIC_INHERIT_PRIMARY_SECURITY_GROUP"
+)
+
+// parseVxNetSecurityGroups parses the value of envVxNetSecurityGroups, invalid items are ignored
+func parseVxNetSecurityGroups(value string) map[string]string {
+	result := make(map[string]string)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.SplitN(item, ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+			klog.Errorf("Ignore invalid item %q of %s", item, envVxNetSecurityGroups)
+			continue
+		}
+		result[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return result
+}
+
+// ensureSecurityGroup decides the security group of the nics for the pods which do not ask for one. It is the one
+// of the node annotation, or the one of the vxnet, or the one of the primary nic if inheritPrimarySecurityGroup.
+// The nics are left as they are created if there is none.
+func (s *IpamD) ensureSecurityGroup() error {
+	node, err := s.K8sClient.GetCurrentNode()
+	if err != nil {
+		klog.Errorf("Failed to get current node")
+		return err
+	}
+	if sg := strings.TrimSpace(node.Annotations[NodeAnnotationSecurityGroup]); sg != "" {
+		s.securityGroup = sg
+	} else if sg, ok := s.vxnetSecurityGroups[s.vxnet.ID]; ok {
+		s.securityGroup = sg
+	} else if s.inheritPrimarySecurityGroup {
+		s.securityGroup = s.primaryNic.SecurityGroup
+	} else {
+		s.securityGroup = ""
+	}
+	klog.V(1).Infof("Nics of pods are in security group %q by default", s.securityGroup)
+	return nil
+}
+
+// podSecurityGroup returns the security group of the nic a pod asks for
+func (s *IpamD) podSecurityGroup(pod *corev1.Pod, namespace string) string {
+	if sg := s.podOrNamespaceAnnotation(pod, namespace, AnnotationSecurityGroup); sg != "" {
+		return sg
+	}
+	return s.securityGroup
+}
+
+// applySecurityGroup binds the nics to the security group with one request if they are not bound to it yet
+func (s *IpamD) applySecurityGroup(sg string, nics ...*types.HostNic) error {
+	if sg == "" {
+		return nil
+	}
+	ids := make([]string, 0, len(nics))
+	for _, nic := range nics {
+		if nic.SecurityGroup != sg {
+			ids = append(ids, nic.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	groups, ok := s.qcClient.(cloudprovider.SecurityGroups)
+	if !ok {
+		return errors.Errorf("failed to apply security group %s to nics %v: not supported by the cloud provider", sg, ids)
+	}
+	klog.V(2).Infof("Apply security group %s to nics %v", sg, ids)
+	if err := groups.ApplySecurityGroup(sg, ids...); err != nil {
+		return errors.Wrapf(err, "failed to apply security group %s to nics %v", sg, ids)
+	}
+	for _, nic := range nics {
+		nic.SecurityGroup = sg
+	}
+	return nil
+}
+
+// requestSecurityGroup records that a pod is waiting for a nic in the security group
+func (s *IpamD) requestSecurityGroup(sg string) {
+	s.securityGroupLock.Lock()
+	defer s.securityGroupLock.Unlock()
+	if s.securityGroupRequests == nil {
+		s.securityGroupRequests = make(map[string]bool)
+	}
+	s.securityGroupRequests[sg] = true
+}
+
+// takeSecurityGroupRequests returns and forgets the security groups which pods are waiting for
+func (s *IpamD) takeSecurityGroupRequests() []string {
+	s.securityGroupLock.Lock()
+	defer s.securityGroupLock.Unlock()
+	result := make([]string, 0, len(s.securityGroupRequests))
+	for sg := range s.securityGroupRequests {
+		result = append(result, sg)
+	}
+	sort.Strings(result)
+	s.securityGroupRequests = nil
+	return result
+}
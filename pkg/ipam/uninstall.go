@@ -0,0 +1,149 @@
+package ipam
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/yunify/hostnic-cni/pkg/k8sclient"
+	"github.com/yunify/hostnic-cni/pkg/retry"
+	"github.com/yunify/hostnic-cni/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog"
+)
+
+// UninstallOptions decides what is removed by Uninstall besides the network of the host
+type UninstallOptions struct {
+	// Force uninstalls hostnic even if pods on the node are using it
+	Force bool
+	// DeleteNICs detaches and deletes the nics of hostnic
+	DeleteNICs bool
+	// ReleaseVxNet leaves the vxnet created for the node from the vpc and deletes it, the nics in it must be deleted
+	// too
+	ReleaseVxNet bool
+}
+
+// Uninstall removes what hostnic sets up on the node: the iptables rules, the ip rules, the route tables of nics
+// and the configlist. It refuses to do it while pods on the node are using hostnic unless it is forced.
+func Uninstall(clientset kubernetes.Interface, opts UninstallOptions, stopCh <-chan struct{}) error {
+	return NewIpamD(clientset).uninstall(clientset, opts, stopCh)
+}
+
+func (s *IpamD) uninstall(clientset kubernetes.Interface, opts UninstallOptions, stopCh <-chan struct{}) error {
+	if opts.ReleaseVxNet && !opts.DeleteNICs {
+		return errors.New("the vxnet can not be released while nics are attached to it, delete the nics too")
+	}
+	pods, err := hostnicPods(clientset, os.Getenv(k8sclient.NodeNameEnvKey))
+	if err != nil {
+		return errors.Wrap(err, "failed to list pods of the node")
+	}
+	if len(pods) > 0 {
+		if !opts.Force {
+			return fmt.Errorf("%d pods on the node are using hostnic, drain the node first: %s", len(pods), strings.Join(pods, ", "))
+		}
+		klog.Warningf("Uninstall hostnic while %d pods are using it: %s", len(pods), strings.Join(pods, ", "))
+	}
+
+	if err = s.K8sClient.Start(stopCh); err != nil {
+		klog.Errorln("Failed to start k8s controller")
+		return err
+	}
+	s.parseEnv()
+	ctx, cancel := retry.WithStopChannel(stopCh)
+	defer cancel()
+	if err = s.prepareCloud(ctx); err != nil {
+		return err
+	}
+	if s.vxnet, err = s.findVxNet(); err != nil {
+		return errors.Wrap(err, "failed to find vxnet of the node")
+	}
+	if s.primaryNic, err = s.qcClient.GetPrimaryNIC(); err != nil {
+		klog.Errorf("Failed to get primary nic")
+		return err
+	}
+
+	var nics []*types.HostNic
+	if s.vxnet != nil {
+		attachedNICs, err := s.qcClient.GetAttachedNICs(s.vxnet.ID)
+		if err != nil {
+			klog.Errorf("Failed to get attached nics")
+			return err
+		}
+		for _, nic := range attachedNICs {
+			if !nic.IsPrimary {
+				nics = append(nics, nic)
+			}
+		}
+	}
+	for _, nic := range nics {
+		table := nic.DeviceNumber
+		if table <= 0 {
+			link, err := types.LinkByMacAddr(nic.HardwareAddr)
+			if err != nil {
+				klog.Warningf("Failed to find the link of nic %s, its route table is kept: %v", nic.ID, err)
+				continue
+			}
+			table = link.Attrs().Index
+		}
+		klog.V(1).Infof("Flush route table %d of nic %s", table, nic.ID)
+		if err = s.networkClient.FlushNICRouteTable(table); err != nil {
+			return errors.Wrapf(err, "failed to flush route table of nic %s", nic.ID)
+		}
+	}
+	primaryIP := net.ParseIP(s.primaryNic.Address)
+	if err = s.networkClient.TeardownHostNetwork(s.vpcSubnets(), s.primaryNic.HardwareAddr, &primaryIP); err != nil {
+		return errors.Wrap(err, "failed to tear down host network")
+	}
+	if err = os.Remove(configFileName); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "failed to remove configlist")
+	}
+
+	if opts.DeleteNICs && len(nics) > 0 {
+		ids := make([]string, 0, len(nics))
+		for _, nic := range nics {
+			ids = append(ids, nic.ID)
+		}
+		klog.V(1).Infof("Delete nics %v", ids)
+		if err = s.qcClient.DeleteNics(ids); err != nil {
+			return errors.Wrap(err, "failed to delete nics")
+		}
+	}
+	if opts.ReleaseVxNet && s.vxnet != nil {
+		if s.vxnet.Name != NameForVxnet(s.NodeName) {
+			klog.Warningf("Vxnet %s is not created by hostnic for the node, keep it", s.vxnet.ID)
+			return nil
+		}
+		klog.V(1).Infof("Release vxnet %s", s.vxnet.ID)
+		// the vxnet is deleted after it leaves the vpc
+		if err = s.qcClient.LeaveVPC(s.vxnet.ID, s.vpc.ID); err != nil {
+			return errors.Wrapf(err, "failed to release vxnet %s", s.vxnet.ID)
+		}
+		if err = s.K8sClient.RemoveNodeAnnotation(NodeAnnotationVxNet); err != nil {
+			return errors.Wrap(err, "failed to remove vxnet annotation of the node")
+		}
+	}
+	return nil
+}
+
+// hostnicPods returns the pods on the node which are running with the network of hostnic, as namespace/name
+func hostnicPods(clientset kubernetes.Interface, nodeName string) ([]string, error) {
+	list, err := clientset.CoreV1().Pods(corev1.NamespaceAll).List(metav1.ListOptions{
+		FieldSelector: "spec.nodeName=" + nodeName,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var pods []string
+	for _, pod := range list.Items {
+		if pod.Spec.NodeName != nodeName || pod.Spec.HostNetwork ||
+			pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		pods = append(pods, pod.Namespace+"/"+pod.Name)
+	}
+	return pods, nil
+}
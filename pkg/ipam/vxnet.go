@@ -1,6 +1,7 @@
 package ipam
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"time"
@@ -22,7 +23,7 @@ func NameForVxnet(node string) string {
 }
 
 // EnsureVxNet guarantee a vxnet for a node
-func (s *IpamD) EnsureVxNet() error {
+func (s *IpamD) EnsureVxNet(ctx context.Context) error {
 	node, err := s.K8sClient.GetCurrentNode()
 	if err != nil {
 		klog.Errorf("Failed to get current node")
@@ -56,7 +57,7 @@ func (s *IpamD) EnsureVxNet() error {
 		return nil
 	}
 	klog.V(1).Infof("Will creating a new vxnet for node %s, this will take up one minute", s.NodeName)
-	vxnet, err := s.createNewVxnet()
+	vxnet, err := s.createNewVxnet(ctx)
 	if err != nil {
 		return err
 	}
@@ -74,6 +75,27 @@ func (s *IpamD) EnsureVxNet() error {
 	return nil
 }
 
+// findVxNet returns the vxnet of the node without creating one, it is nil if the node has none
+func (s *IpamD) findVxNet() (*types.VxNet, error) {
+	node, err := s.K8sClient.GetCurrentNode()
+	if err != nil {
+		klog.Errorf("Failed to get current node")
+		return nil, err
+	}
+	s.NodeName = node.Name
+	if vxnet, ok := node.Annotations[NodeAnnotationVxNet]; ok {
+		return s.qcClient.GetVxNet(vxnet)
+	}
+	vxnet, err := s.qcClient.GetVxNetByName(NameForVxnet(s.NodeName))
+	if err != nil {
+		if errors.IsResourceNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return vxnet, nil
+}
+
 func (s *IpamD) joinVPC(vxnet *types.VxNet) error {
 	vxnets, err := s.qcClient.GetVPCVxNets(s.vpc.ID)
 	if err != nil {
@@ -93,13 +115,19 @@ func (s *IpamD) joinVPC(vxnet *types.VxNet) error {
 	return err
 }
 
-func (s *IpamD) createNewVxnet() (*types.VxNet, error) {
+func (s *IpamD) createNewVxnet(ctx context.Context) (*types.VxNet, error) {
 	vxnet, err := s.qcClient.CreateVxNet(NameForVxnet(s.NodeName))
 	if err != nil {
 		klog.Errorln("Failed to call create Vxnet")
 		return nil, err
 	}
-	err = retry.Do(5, time.Second*5, func() error {
+	err = retry.DoWithBackoff(ctx, retry.Backoff{
+		Steps:     5,
+		Duration:  time.Second * 2,
+		Factor:    2,
+		Jitter:    0.2,
+		Retryable: retry.UnlessResourceNotFound,
+	}, func() error {
 		return s.joinVPC(vxnet)
 	})
 	if err != nil {
@@ -1,14 +1,23 @@
 package fake
 
 import (
+	"fmt"
+
 	"github.com/yunify/hostnic-cni/pkg/k8sclient"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 type FakeK8sHelper struct {
 	currentNode    string
 	nodeAnnotation map[string]string
 	currentPods    []*k8sclient.K8SPodInfo
+	pods           map[string]*corev1.Pod
+	namespaces     map[string]*corev1.Namespace
+	conditions     []corev1.NodeCondition
+	events         []string
+	resources      corev1.ResourceList
 }
 
 func (f *FakeK8sHelper) Start(stopCh <-chan struct{}) error {
@@ -19,6 +28,9 @@ func (f *FakeK8sHelper) GetCurrentNode() (*corev1.Node, error) {
 	node := &corev1.Node{}
 	node.Name = f.currentNode
 	node.SetAnnotations(f.nodeAnnotation)
+	node.Status.Conditions = f.conditions
+	node.Status.Capacity = f.resources
+	node.Status.Allocatable = f.resources
 	return node, nil
 }
 
@@ -27,6 +39,43 @@ func (f *FakeK8sHelper) UpdateNodeAnnotation(key, value string) error {
 	return nil
 }
 
+func (f *FakeK8sHelper) RemoveNodeAnnotation(key string) error {
+	delete(f.nodeAnnotation, key)
+	return nil
+}
+
+func (f *FakeK8sHelper) UpdateNodeCondition(condition corev1.NodeCondition) error {
+	for i := range f.conditions {
+		if f.conditions[i].Type == condition.Type {
+			f.conditions[i] = condition
+			return nil
+		}
+	}
+	f.conditions = append(f.conditions, condition)
+	return nil
+}
+
+func (f *FakeK8sHelper) UpdateNodeResource(name corev1.ResourceName, quantity resource.Quantity) error {
+	if f.resources == nil {
+		f.resources = make(corev1.ResourceList)
+	}
+	f.resources[name] = quantity
+	return nil
+}
+
+func (f *FakeK8sHelper) RecordNodeEvent(eventType, reason, message string) {
+	f.events = append(f.events, fmt.Sprintf("Node/%s %s %s %s", f.currentNode, eventType, reason, message))
+}
+
+func (f *FakeK8sHelper) RecordPodEvent(namespace, name, eventType, reason, message string) {
+	f.events = append(f.events, fmt.Sprintf("Pod/%s/%s %s %s %s", namespace, name, eventType, reason, message))
+}
+
+// Events returns the events recorded, in the format of "Kind/name type reason message"
+func (f *FakeK8sHelper) Events() []string {
+	return f.events
+}
+
 func (f *FakeK8sHelper) GetCurrentNodePods() ([]*k8sclient.K8SPodInfo, error) {
 	return f.currentPods, nil
 }
@@ -34,3 +83,31 @@ func (f *FakeK8sHelper) GetCurrentNodePods() ([]*k8sclient.K8SPodInfo, error) {
 func (f *FakeK8sHelper) AddPod(pod *k8sclient.K8SPodInfo) {
 	f.currentPods = append(f.currentPods, pod)
 }
+
+func (f *FakeK8sHelper) GetPod(namespace, name string) (*corev1.Pod, error) {
+	if pod, ok := f.pods[namespace+"/"+name]; ok {
+		return pod, nil
+	}
+	return nil, apierrors.NewNotFound(corev1.Resource("pods"), name)
+}
+
+func (f *FakeK8sHelper) GetNamespace(name string) (*corev1.Namespace, error) {
+	if ns, ok := f.namespaces[name]; ok {
+		return ns, nil
+	}
+	return nil, apierrors.NewNotFound(corev1.Resource("namespaces"), name)
+}
+
+func (f *FakeK8sHelper) AddPodObject(pod *corev1.Pod) {
+	if f.pods == nil {
+		f.pods = make(map[string]*corev1.Pod)
+	}
+	f.pods[pod.Namespace+"/"+pod.Name] = pod
+}
+
+func (f *FakeK8sHelper) AddNamespace(ns *corev1.Namespace) {
+	if f.namespaces == nil {
+		f.namespaces = make(map[string]*corev1.Namespace)
+	}
+	f.namespaces[ns.Name] = ns
+}
@@ -0,0 +1,68 @@
+package fake
+
+import (
+	"sync"
+
+	"github.com/yunify/hostnic-cni/pkg/apis/hostnic/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// FakeNodeIPPools keeps NodeIPPool in memory
+type FakeNodeIPPools struct {
+	lock    sync.Mutex
+	pools   map[string]*v1alpha1.NodeIPPool
+	updates int
+}
+
+func NewFakeNodeIPPools() *FakeNodeIPPools {
+	return &FakeNodeIPPools{pools: make(map[string]*v1alpha1.NodeIPPool)}
+}
+
+func (f *FakeNodeIPPools) Get(name string) (*v1alpha1.NodeIPPool, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	pool, ok := f.pools[name]
+	if !ok {
+		return nil, apierrors.NewNotFound(v1alpha1.Resource(v1alpha1.NodeIPPoolResource), name)
+	}
+	return pool.DeepCopy(), nil
+}
+
+func (f *FakeNodeIPPools) List() (*v1alpha1.NodeIPPoolList, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	list := &v1alpha1.NodeIPPoolList{}
+	for _, pool := range f.pools {
+		list.Items = append(list.Items, *pool.DeepCopy())
+	}
+	return list, nil
+}
+
+func (f *FakeNodeIPPools) Create(pool *v1alpha1.NodeIPPool) (*v1alpha1.NodeIPPool, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if _, ok := f.pools[pool.Name]; ok {
+		return nil, apierrors.NewAlreadyExists(v1alpha1.Resource(v1alpha1.NodeIPPoolResource), pool.Name)
+	}
+	f.pools[pool.Name] = pool.DeepCopy()
+	f.updates++
+	return pool.DeepCopy(), nil
+}
+
+func (f *FakeNodeIPPools) Update(pool *v1alpha1.NodeIPPool) (*v1alpha1.NodeIPPool, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if _, ok := f.pools[pool.Name]; !ok {
+		return nil, apierrors.NewNotFound(v1alpha1.Resource(v1alpha1.NodeIPPoolResource), pool.Name)
+	}
+	f.pools[pool.Name] = pool.DeepCopy()
+	f.updates++
+	return pool.DeepCopy(), nil
+}
+
+// Updates returns the number of writes to the pools
+func (f *FakeNodeIPPools) Updates() int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.updates
+}
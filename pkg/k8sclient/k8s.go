@@ -3,9 +3,13 @@ package k8sclient
 import (
 	"fmt"
 	"os"
+	"sync"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/informers"
 	coreinformer "k8s.io/client-go/informers/core/v1"
 	corev1informer "k8s.io/client-go/informers/core/v1"
@@ -20,6 +24,13 @@ import (
 const (
 	// NodeNameEnvKey is env to get the name of current node
 	NodeNameEnvKey = "MY_NODE_NAME"
+
+	// eventSource is the component of the events recorded by hostnic
+	eventSource = "hostnic"
+	// eventInterval is the min interval between the events of the same reason on an object
+	eventInterval = time.Minute
+	// eventQueueSize is the max number of events waiting to be sent, the others are dropped
+	eventQueueSize = 100
 )
 
 // K8sHelper is used to commucate with k8s apiserver
@@ -27,33 +38,80 @@ type K8sHelper interface {
 	Start(stopCh <-chan struct{}) error
 	GetCurrentNode() (*corev1.Node, error)
 	UpdateNodeAnnotation(key, value string) error
+	RemoveNodeAnnotation(key string) error
+	UpdateNodeCondition(condition corev1.NodeCondition) error
+	UpdateNodeResource(name corev1.ResourceName, quantity resource.Quantity) error
+	RecordNodeEvent(eventType, reason, message string)
+	RecordPodEvent(namespace, name, eventType, reason, message string)
 	GetCurrentNodePods() ([]*K8SPodInfo, error)
+	GetPod(namespace, name string) (*corev1.Pod, error)
+	GetNamespace(name string) (*corev1.Namespace, error)
 }
 
 type k8sHelper struct {
 	nodeName      string
 	nodeInformer  corev1informer.NodeInformer
 	nodeInterface clientsetcorev1.NodeInterface
+	coreInterface clientsetcorev1.CoreV1Interface
 
 	podInformer coreinformer.PodInformer
 	podLister   corelisters.PodLister
 	podSynced   cache.InformerSynced
+
+	namespaceInformer coreinformer.NamespaceInformer
+	namespaceLister   corelisters.NamespaceLister
+
+	// lastEvents keeps the time of the last event by object and reason, so that repeated failures do not flood
+	// apiserver. The entries older than eventInterval are pruned once per eventInterval, since they stop nothing.
+	lastEvents     map[string]time.Time
+	lastEventPrune time.Time
+	// nodeRef is the reference of the current node once its uid is known
+	nodeRef   *corev1.ObjectReference
+	eventLock sync.Mutex
+	// events are sent to apiserver one by one after Start
+	events chan *corev1.Event
 }
 
 func (k *k8sHelper) GetCurrentNode() (*corev1.Node, error) {
 	return k.nodeInformer.Lister().Get(k.nodeName)
 }
 
+// GetPod returns the pod from the cache, or from apiserver if the cache has not seen it yet
+func (k *k8sHelper) GetPod(namespace, name string) (*corev1.Pod, error) {
+	pod, err := k.podLister.Pods(namespace).Get(name)
+	if err == nil {
+		return pod, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	return k.coreInterface.Pods(namespace).Get(name, metav1.GetOptions{})
+}
+
+// GetNamespace returns the namespace from the cache, or from apiserver if the cache has not seen it yet
+func (k *k8sHelper) GetNamespace(name string) (*corev1.Namespace, error) {
+	namespace, err := k.namespaceLister.Get(name)
+	if err == nil {
+		return namespace, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	return k.coreInterface.Namespaces().Get(name, metav1.GetOptions{})
+}
+
 func (k *k8sHelper) Start(stopCh <-chan struct{}) error {
+	go k.sendEvents(stopCh)
 	go k.nodeInformer.Informer().Run(stopCh)
-	go k.podInformer.Informer().Run(stopCh)
+	go k.namespaceInformer.Informer().Run(stopCh)
 
 	// Start the informer factories to begin populating the informer caches
 	klog.V(1).Infoln("Starting pod controller")
 	go k.podInformer.Informer().Run(stopCh)
 	// Wait for the caches to be synced before starting workers
 	klog.V(2).Info("Waiting for informer caches to sync")
-	if ok := cache.WaitForCacheSync(stopCh, k.podSynced); !ok {
+	if ok := cache.WaitForCacheSync(stopCh, k.podSynced, k.nodeInformer.Informer().HasSynced,
+		k.namespaceInformer.Informer().HasSynced); !ok {
 		return fmt.Errorf("failed to wait for caches to sync")
 	}
 	return nil
@@ -64,14 +122,22 @@ func NewK8sHelper(clientset kubernetes.Interface) K8sHelper {
 	kubeInformerFactory := informers.NewSharedInformerFactory(clientset, time.Minute*1)
 	nodeInformer := kubeInformerFactory.Core().V1().Nodes()
 	podInformer := kubeInformerFactory.Core().V1().Pods()
+	namespaceInformer := kubeInformerFactory.Core().V1().Namespaces()
 
 	cont := &k8sHelper{
 		nodeName:      nodeName,
 		nodeInformer:  nodeInformer,
 		nodeInterface: clientset.CoreV1().Nodes(),
+		coreInterface: clientset.CoreV1(),
 		podInformer:   podInformer,
 		podLister:     podInformer.Lister(),
 		podSynced:     podInformer.Informer().HasSynced,
+
+		namespaceInformer: namespaceInformer,
+		namespaceLister:   namespaceInformer.Lister(),
+
+		lastEvents: make(map[string]time.Time),
+		events:     make(chan *corev1.Event, eventQueueSize),
 	}
 	return cont
 }
@@ -91,3 +157,183 @@ func (k *k8sHelper) UpdateNodeAnnotation(key, value string) error {
 		return err
 	})
 }
+
+// RemoveNodeAnnotation removes an annotation of the current node
+func (k *k8sHelper) RemoveNodeAnnotation(key string) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		node, err := k.nodeInterface.Get(k.nodeName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if _, ok := node.Annotations[key]; !ok {
+			return nil
+		}
+		delete(node.Annotations, key)
+		_, err = k.nodeInterface.Update(node)
+		return err
+	})
+}
+
+// UpdateNodeCondition sets a condition in the status of the current node. The transition time is kept if the
+// status of the condition does not change.
+func (k *k8sHelper) UpdateNodeCondition(condition corev1.NodeCondition) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		node, err := k.nodeInterface.Get(k.nodeName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		setNodeCondition(&node.Status, condition)
+		_, err = k.nodeInterface.UpdateStatus(node)
+		return err
+	})
+}
+
+// UpdateNodeResource sets the capacity and the allocatable of an extended resource of the current node
+func (k *k8sHelper) UpdateNodeResource(name corev1.ResourceName, quantity resource.Quantity) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		node, err := k.nodeInterface.Get(k.nodeName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		capacity, allocatable := node.Status.Capacity[name], node.Status.Allocatable[name]
+		if capacity.Cmp(quantity) == 0 && allocatable.Cmp(quantity) == 0 {
+			return nil
+		}
+		if node.Status.Capacity == nil {
+			node.Status.Capacity = make(corev1.ResourceList)
+		}
+		if node.Status.Allocatable == nil {
+			node.Status.Allocatable = make(corev1.ResourceList)
+		}
+		node.Status.Capacity[name] = quantity
+		node.Status.Allocatable[name] = quantity
+		_, err = k.nodeInterface.UpdateStatus(node)
+		return err
+	})
+}
+
+// setNodeCondition adds or replaces the condition of the same type in the node status
+func setNodeCondition(status *corev1.NodeStatus, condition corev1.NodeCondition) {
+	now := metav1.Now()
+	condition.LastHeartbeatTime = now
+	for i := range status.Conditions {
+		existing := &status.Conditions[i]
+		if existing.Type != condition.Type {
+			continue
+		}
+		if existing.Status == condition.Status {
+			condition.LastTransitionTime = existing.LastTransitionTime
+		} else {
+			condition.LastTransitionTime = now
+		}
+		*existing = condition
+		return
+	}
+	condition.LastTransitionTime = now
+	status.Conditions = append(status.Conditions, condition)
+}
+
+// RecordNodeEvent records an event on the current node in background
+func (k *k8sHelper) RecordNodeEvent(eventType, reason, message string) {
+	if !k.shouldRecordEvent("Node", "", k.nodeName, reason) {
+		return
+	}
+	k.queueEvent(k.currentNodeRef(), eventType, reason, message)
+}
+
+// RecordPodEvent records an event on a pod in background
+func (k *k8sHelper) RecordPodEvent(namespace, name, eventType, reason, message string) {
+	if !k.shouldRecordEvent("Pod", namespace, name, reason) {
+		return
+	}
+	ref := &corev1.ObjectReference{Kind: "Pod", Namespace: namespace, Name: name}
+	if pod, err := k.podLister.Pods(namespace).Get(name); err == nil {
+		ref.UID = pod.UID
+	}
+	k.queueEvent(ref, eventType, reason, message)
+}
+
+// currentNodeRef returns the reference of the current node, the uid is looked up until it is known
+func (k *k8sHelper) currentNodeRef() *corev1.ObjectReference {
+	k.eventLock.Lock()
+	defer k.eventLock.Unlock()
+	if k.nodeRef != nil {
+		return k.nodeRef
+	}
+	ref := &corev1.ObjectReference{Kind: "Node", Name: k.nodeName}
+	if node, err := k.GetCurrentNode(); err == nil {
+		ref.UID = node.UID
+		k.nodeRef = ref
+	}
+	return ref
+}
+
+// shouldRecordEvent checks whether no event of the reason is recorded on the object in eventInterval, and marks
+// the event recorded if so
+func (k *k8sHelper) shouldRecordEvent(kind, namespace, name, reason string) bool {
+	key := fmt.Sprintf("%s/%s/%s/%s", kind, namespace, name, reason)
+	now := time.Now()
+	k.eventLock.Lock()
+	defer k.eventLock.Unlock()
+	if last, ok := k.lastEvents[key]; ok && now.Sub(last) < eventInterval {
+		return false
+	}
+	k.lastEvents[key] = now
+	if now.Sub(k.lastEventPrune) >= eventInterval {
+		k.pruneEvents(now)
+	}
+	return true
+}
+
+// queueEvent queues an event to be sent by sendEvents, it is dropped if the queue is full
+func (k *k8sHelper) queueEvent(ref *corev1.ObjectReference, eventType, reason, message string) {
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+	now := time.Now()
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s.%x", ref.Name, now.UnixNano()),
+			Namespace: namespace,
+		},
+		InvolvedObject: *ref,
+		Reason:         reason,
+		Message:        message,
+		Type:           eventType,
+		Source:         corev1.EventSource{Component: eventSource, Host: k.nodeName},
+		FirstTimestamp: metav1.NewTime(now),
+		LastTimestamp:  metav1.NewTime(now),
+		Count:          1,
+	}
+	select {
+	case k.events <- event:
+	default:
+		klog.Warningf("Too many events to record, drop event %s of %s %s", reason, ref.Kind, ref.Name)
+	}
+}
+
+// sendEvents sends the queued events to apiserver until stopCh is closed
+func (k *k8sHelper) sendEvents(stopCh <-chan struct{}) {
+	for {
+		select {
+		case <-stopCh:
+			return
+		case event := <-k.events:
+			ref := event.InvolvedObject
+			if _, err := k.coreInterface.Events(event.Namespace).Create(event); err != nil {
+				klog.Errorf("Failed to record event %s of %s %s: %v", event.Reason, ref.Kind, ref.Name, err)
+			}
+		}
+	}
+}
+
+// pruneEvents forgets the events which are recorded more than eventInterval ago, it is called with eventLock held
+func (k *k8sHelper) pruneEvents(now time.Time) {
+	for key, last := range k.lastEvents {
+		if now.Sub(last) >= eventInterval {
+			delete(k.lastEvents, key)
+		}
+	}
+	k.lastEventPrune = now
+}
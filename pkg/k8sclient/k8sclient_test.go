@@ -9,6 +9,7 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/client-go/kubernetes/fake"
 )
@@ -135,6 +136,7 @@ var _ = Describe("K8sclient", func() {
 	It("Should work well with k8s to get current Node", func() {
 		node := &corev1.Node{}
 		node.Name = nodeName
+		node.UID = "node-uid"
 		pod1 := &corev1.Pod{}
 		reader := strings.NewReader(podTemplate)
 		err := yaml.NewYAMLOrJSONDecoder(reader, 10).Decode(pod1)
@@ -160,5 +162,34 @@ var _ = Describe("K8sclient", func() {
 		node, err = k8sHelper.GetCurrentNode()
 		Expect(err).ShouldNot(HaveOccurred())
 		Expect(node.Annotations).To(HaveKey(testKey))
+
+		condition := corev1.NodeCondition{Type: "HostnicReady", Status: corev1.ConditionFalse, Reason: "Starting"}
+		Expect(k8sHelper.UpdateNodeCondition(condition)).ShouldNot(HaveOccurred())
+		condition.Status, condition.Reason = corev1.ConditionTrue, "Ready"
+		Expect(k8sHelper.UpdateNodeCondition(condition)).ShouldNot(HaveOccurred())
+		Expect(k8sHelper.UpdateNodeCondition(condition)).ShouldNot(HaveOccurred())
+		node, err = fakeClient.CoreV1().Nodes().Get(nodeName, metav1.GetOptions{})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(node.Status.Conditions).To(HaveLen(1))
+		Expect(node.Status.Conditions[0].Status).To(Equal(corev1.ConditionTrue))
+		Expect(node.Status.Conditions[0].Reason).To(Equal("Ready"))
+
+		k8sHelper.RecordNodeEvent(corev1.EventTypeWarning, "NICAttachTimeout", "nic is not attached")
+		k8sHelper.RecordNodeEvent(corev1.EventTypeWarning, "NICAttachTimeout", "nic is not attached again")
+		k8sHelper.RecordPodEvent(pod1.Namespace, pod1.Name, corev1.EventTypeWarning, "AddNetworkFailed", "no ip")
+		Eventually(func() int {
+			events, _ := fakeClient.CoreV1().Events("").List(metav1.ListOptions{})
+			return len(events.Items)
+		}, time.Second*5, time.Millisecond*100).Should(Equal(2))
+		events, err := fakeClient.CoreV1().Events(pod1.Namespace).List(metav1.ListOptions{})
+		Expect(err).ShouldNot(HaveOccurred())
+		for _, event := range events.Items {
+			Expect(event.Source.Component).To(Equal("hostnic"))
+			if event.InvolvedObject.Kind == "Pod" {
+				Expect(event.InvolvedObject.UID).To(Equal(pod1.UID))
+			} else {
+				Expect(event.InvolvedObject.UID).To(Equal(node.UID))
+			}
+		}
 	})
 })
@@ -0,0 +1,81 @@
+package k8sclient
+
+import (
+	"github.com/yunify/hostnic-cni/pkg/apis/hostnic/v1alpha1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/client-go/rest"
+)
+
+// NodeIPPoolInterface reads and writes the NodeIPPool resources
+type NodeIPPoolInterface interface {
+	Get(name string) (*v1alpha1.NodeIPPool, error)
+	List() (*v1alpha1.NodeIPPoolList, error)
+	Create(pool *v1alpha1.NodeIPPool) (*v1alpha1.NodeIPPool, error)
+	Update(pool *v1alpha1.NodeIPPool) (*v1alpha1.NodeIPPool, error)
+}
+
+type nodeIPPools struct {
+	client rest.Interface
+}
+
+// NewNodeIPPoolClient returns a client of NodeIPPool with the config of the k8s clientset
+func NewNodeIPPoolClient(config *rest.Config) (NodeIPPoolInterface, error) {
+	s := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(s); err != nil {
+		return nil, err
+	}
+	c := *config
+	c.GroupVersion = &v1alpha1.SchemeGroupVersion
+	c.APIPath = "/apis"
+	c.ContentType = runtime.ContentTypeJSON
+	c.NegotiatedSerializer = serializer.DirectCodecFactory{CodecFactory: serializer.NewCodecFactory(s)}
+	if c.UserAgent == "" {
+		c.UserAgent = rest.DefaultKubernetesUserAgent()
+	}
+	client, err := rest.RESTClientFor(&c)
+	if err != nil {
+		return nil, err
+	}
+	return &nodeIPPools{client: client}, nil
+}
+
+func (c *nodeIPPools) Get(name string) (*v1alpha1.NodeIPPool, error) {
+	result := &v1alpha1.NodeIPPool{}
+	err := c.client.Get().
+		Resource(v1alpha1.NodeIPPoolResource).
+		Name(name).
+		Do().
+		Into(result)
+	return result, err
+}
+
+func (c *nodeIPPools) List() (*v1alpha1.NodeIPPoolList, error) {
+	result := &v1alpha1.NodeIPPoolList{}
+	err := c.client.Get().
+		Resource(v1alpha1.NodeIPPoolResource).
+		Do().
+		Into(result)
+	return result, err
+}
+
+func (c *nodeIPPools) Create(pool *v1alpha1.NodeIPPool) (*v1alpha1.NodeIPPool, error) {
+	result := &v1alpha1.NodeIPPool{}
+	err := c.client.Post().
+		Resource(v1alpha1.NodeIPPoolResource).
+		Body(pool).
+		Do().
+		Into(result)
+	return result, err
+}
+
+func (c *nodeIPPools) Update(pool *v1alpha1.NodeIPPool) (*v1alpha1.NodeIPPool, error) {
+	result := &v1alpha1.NodeIPPool{}
+	err := c.client.Put().
+		Resource(v1alpha1.NodeIPPoolResource).
+		Name(pool.Name).
+		Body(pool).
+		Do().
+		Into(result)
+	return result, err
+}
@@ -0,0 +1,80 @@
+package k8sclient
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/yunify/hostnic-cni/pkg/apis/hostnic/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+var _ = Describe("NodeIPPool", func() {
+	It("Should read and write NodeIPPool with the rest api of the crd", func() {
+		pools := make(map[string][]byte)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			const prefix = "/apis/hostnic.io/v1alpha1/nodeippools"
+			w.Header().Set("Content-Type", "application/json")
+			switch {
+			case r.Method == http.MethodPost && r.URL.Path == prefix:
+				body, _ := ioutil.ReadAll(r.Body)
+				pool := &v1alpha1.NodeIPPool{}
+				Expect(json.Unmarshal(body, pool)).To(Succeed())
+				pool.ResourceVersion = "1"
+				pools[pool.Name], _ = json.Marshal(pool)
+				w.WriteHeader(http.StatusCreated)
+				w.Write(pools[pool.Name])
+			case r.Method == http.MethodPut && r.URL.Path == prefix+"/node1":
+				body, _ := ioutil.ReadAll(r.Body)
+				pools["node1"] = body
+				w.Write(body)
+			case r.Method == http.MethodGet && r.URL.Path == prefix:
+				list := &v1alpha1.NodeIPPoolList{}
+				for _, data := range pools {
+					pool := v1alpha1.NodeIPPool{}
+					json.Unmarshal(data, &pool)
+					list.Items = append(list.Items, pool)
+				}
+				data, _ := json.Marshal(list)
+				w.Write(data)
+			case r.Method == http.MethodGet && r.URL.Path == prefix+"/node1" && pools["node1"] != nil:
+				w.Write(pools["node1"])
+			default:
+				status := apierrors.NewNotFound(v1alpha1.Resource(v1alpha1.NodeIPPoolResource), "node1").ErrStatus
+				status.APIVersion, status.Kind = "v1", "Status"
+				data, _ := json.Marshal(status)
+				w.WriteHeader(http.StatusNotFound)
+				w.Write(data)
+			}
+		}))
+		defer server.Close()
+
+		client, err := NewNodeIPPoolClient(&rest.Config{Host: server.URL})
+		Expect(err).ShouldNot(HaveOccurred())
+		_, err = client.Get("node1")
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+		pool := &v1alpha1.NodeIPPool{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}
+		pool.Status.NICs = []v1alpha1.NICStatus{{ID: "aa:aa:aa:aa:aa:aa", DeviceNumber: 2, VxNet: "vxnet-pod"}}
+		pool, err = client.Create(pool)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(pool.ResourceVersion).To(Equal("1"))
+
+		pool.Status.AssignedIPs = 1
+		_, err = client.Update(pool)
+		Expect(err).ShouldNot(HaveOccurred())
+		pool, err = client.Get("node1")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(pool.Status.AssignedIPs).To(Equal(1))
+		Expect(pool.Status.NICs[0].DeviceNumber).To(Equal(2))
+
+		list, err := client.List()
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(list.Items).To(HaveLen(1))
+	})
+})
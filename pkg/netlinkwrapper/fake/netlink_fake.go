@@ -12,6 +12,10 @@ type FakeNetlink struct {
 	LinkAddr map[string]map[string]*netlink.Addr
 	Routes   map[string]netlink.Route
 	Rules    map[string]netlink.Rule
+	// Conntrack is the conntrack table
+	Conntrack []*netlink.ConntrackFlow
+
+	subscribers []chan<- struct{}
 }
 
 // LinkByName gets a link object given the device name
@@ -105,6 +109,18 @@ func (f *FakeNetlink) RouteList(link netlink.Link, family int) ([]netlink.Route,
 	return result, nil
 }
 
+// RouteListFiltered gets a list of routes filtered by table only
+func (f *FakeNetlink) RouteListFiltered(family int, filter *netlink.Route, filterMask uint64) ([]netlink.Route, error) {
+	result := make([]netlink.Route, 0)
+	for _, v := range f.Routes {
+		if filterMask&netlink.RT_FILTER_TABLE != 0 && filter.Table != v.Table {
+			continue
+		}
+		result = append(result, v)
+	}
+	return result, nil
+}
+
 // RouteAdd will add a route to the route table
 func (f *FakeNetlink) RouteAdd(route *netlink.Route) error {
 	f.Routes[keyForRoute(route)] = *route
@@ -184,6 +200,37 @@ func (f *FakeNetlink) LinkSetMTU(link netlink.Link, mtu int) error {
 	return nil
 }
 
+// ConntrackDeleteFilter deletes the conntrack flows matching the filter
+func (f *FakeNetlink) ConntrackDeleteFilter(table netlink.ConntrackTableType, family netlink.InetFamily, filter netlink.CustomConntrackFilter) (uint, error) {
+	var matched uint
+	flows := make([]*netlink.ConntrackFlow, 0)
+	for _, flow := range f.Conntrack {
+		if filter.MatchConntrackFlow(flow) {
+			matched++
+			continue
+		}
+		flows = append(flows, flow)
+	}
+	f.Conntrack = flows
+	return matched, nil
+}
+
+// Subscribe registers ch to be notified by Notify
+func (f *FakeNetlink) Subscribe(ch chan<- struct{}, done <-chan struct{}) error {
+	f.subscribers = append(f.subscribers, ch)
+	return nil
+}
+
+// Notify simulates a change of links, routes or rules
+func (f *FakeNetlink) Notify() {
+	for _, ch := range f.subscribers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
 func (f *FakeNetlink) FindPrimaryInterfaceName(mac string) (string, error) {
 	for _, link := range f.Links {
 		if string(link.Attrs().HardwareAddr) == mac {
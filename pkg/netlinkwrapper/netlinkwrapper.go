@@ -1,9 +1,13 @@
 package netlinkwrapper
 
 import (
+	"net"
 	"syscall"
 
 	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+	"k8s.io/klog"
 )
 
 // NetLink wraps methods used from the vishvananda/netlink package
@@ -30,6 +34,8 @@ type NetLink interface {
 	LinkSetDown(link netlink.Link) error
 	// RouteList gets a list of routes in the system.
 	RouteList(link netlink.Link, family int) ([]netlink.Route, error)
+	// RouteListFiltered gets a list of routes in the system filtered by the fields set in filterMask
+	RouteListFiltered(family int, filter *netlink.Route, filterMask uint64) ([]netlink.Route, error)
 	// RouteAdd will add a route to the route table
 	RouteAdd(route *netlink.Route) error
 	// RouteReplace will replace the route in the route table
@@ -50,6 +56,11 @@ type NetLink interface {
 	RuleList(family int) ([]netlink.Rule, error)
 	// LinkSetMTU is equivalent to `ip link set dev $link mtu $mtu`
 	LinkSetMTU(link netlink.Link, mtu int) error
+	// ConntrackDeleteFilter deletes the conntrack flows matching the filter, equivalent to: `conntrack -D [filter]`
+	ConntrackDeleteFilter(table netlink.ConntrackTableType, family netlink.InetFamily, filter netlink.CustomConntrackFilter) (uint, error)
+	// Subscribe sends to ch when any link, IPv4 route or IPv4 rule changes, until done is closed.
+	// Notifications are dropped when ch is full.
+	Subscribe(ch chan<- struct{}, done <-chan struct{}) error
 }
 
 type netLink struct {
@@ -100,6 +111,10 @@ func (*netLink) RouteList(link netlink.Link, family int) ([]netlink.Route, error
 	return netlink.RouteList(link, family)
 }
 
+func (*netLink) RouteListFiltered(family int, filter *netlink.Route, filterMask uint64) ([]netlink.Route, error) {
+	return netlink.RouteListFiltered(family, filter, filterMask)
+}
+
 func (*netLink) RouteAdd(route *netlink.Route) error {
 	return netlink.RouteAdd(route)
 }
@@ -144,6 +159,53 @@ func (*netLink) LinkSetMTU(link netlink.Link, mtu int) error {
 	return netlink.LinkSetMTU(link, mtu)
 }
 
+func (*netLink) ConntrackDeleteFilter(table netlink.ConntrackTableType, family netlink.InetFamily, filter netlink.CustomConntrackFilter) (uint, error) {
+	return netlink.ConntrackDeleteFilter(table, family, filter)
+}
+
+func (*netLink) Subscribe(ch chan<- struct{}, done <-chan struct{}) error {
+	s, err := nl.Subscribe(unix.NETLINK_ROUTE, unix.RTNLGRP_LINK, unix.RTNLGRP_IPV4_ROUTE, unix.RTNLGRP_IPV4_RULE)
+	if err != nil {
+		return err
+	}
+	go func() {
+		<-done
+		s.Close()
+	}()
+	go func() {
+		for {
+			msgs, err := s.Receive()
+			if err != nil {
+				select {
+				case <-done:
+				default:
+					klog.Errorf("Failed to receive netlink messages, stop watching changes: %v", err)
+				}
+				return
+			}
+			if len(msgs) == 0 {
+				continue
+			}
+			select {
+			case ch <- struct{}{}:
+			default:
+			}
+		}
+	}()
+	return nil
+}
+
+// IPConntrackFilter matches the conntrack flows which use the IP as source or destination in either direction
+type IPConntrackFilter struct {
+	IP net.IP
+}
+
+// MatchConntrackFlow returns true if the flow uses the IP
+func (f *IPConntrackFilter) MatchConntrackFlow(flow *netlink.ConntrackFlow) bool {
+	return f.IP.Equal(flow.Forward.SrcIP) || f.IP.Equal(flow.Forward.DstIP) ||
+		f.IP.Equal(flow.Reverse.SrcIP) || f.IP.Equal(flow.Reverse.DstIP)
+}
+
 // IsNotExistsError returns true if the error type is syscall.ESRCH
 // This helps us determine if we should ignore this error as the route
 // that we want to cleanup has been deleted already routing table
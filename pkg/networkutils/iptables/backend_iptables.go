@@ -0,0 +1,161 @@
+package iptables
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog"
+)
+
+type iptablesBackend struct {
+	ipt IptablesIface
+}
+
+// NewIptablesBackend returns a Backend which checks and programs rules one by one
+func NewIptablesBackend(ipt IptablesIface) Backend {
+	return &iptablesBackend{ipt: ipt}
+}
+
+func (b *iptablesBackend) HasRandomFully() bool {
+	return b.ipt.HasRandomFully()
+}
+
+func (b *iptablesBackend) Apply(chains []Chain, rules []IptablesRule) error {
+	for _, chain := range chains {
+		klog.V(2).Infof("iptables -N %s -t %s", chain.Name, chain.Table)
+		if err := b.ipt.NewChain(chain.Table, chain.Name); err != nil {
+			if !containChainExistErr(err) {
+				klog.Errorf("ipt.NewChain error for chain [%s]: %v", chain, err)
+				return errors.Wrapf(err, "failed to add chain %s", chain)
+			}
+			// flushing a chain breaks the traffic through it until the rules are appended again, so a chain which
+			// has the rules already, which is the case when ipamd restarts, is kept
+			inSync, err := b.inSync(chain, rules)
+			if err != nil {
+				return err
+			}
+			if inSync {
+				klog.V(2).Infof("Keep chain %s which is in sync", chain)
+				continue
+			}
+			klog.V(1).Infof("Clear chain %s before insert rule", chain)
+			if err = b.ipt.ClearChain(chain.Table, chain.Name); err != nil {
+				klog.Errorf("Failed to clear chain %s", chain)
+				return errors.Wrapf(err, "failed to clear chain %s", chain)
+			}
+		}
+	}
+
+	for _, rule := range rules {
+		klog.V(2).Infof("execute iptable rule : %s", rule.Name)
+
+		exists, err := b.ipt.Exists(rule.Table, rule.Chain, rule.Rule...)
+		if err != nil {
+			klog.Errorf("failed to check existence of %v, %v", rule, err)
+			return errors.Wrapf(err, "failed to check existence of %v", rule)
+		}
+
+		if !exists && rule.ShouldExist {
+			err = b.ipt.Append(rule.Table, rule.Chain, rule.Rule...)
+			if err != nil {
+				klog.Errorf("failed to add %v, %v", rule, err)
+				return errors.Wrapf(err, "failed to add %v", rule)
+			}
+		} else if exists && !rule.ShouldExist {
+			err = b.ipt.Delete(rule.Table, rule.Chain, rule.Rule...)
+			if err != nil {
+				klog.Errorf("failed to delete %v, %v", rule, err)
+				return errors.Wrapf(err, "failed to delete %v", rule)
+			}
+		}
+	}
+	return nil
+}
+
+// inSync tells whether an owned chain has exactly the rules which should exist in it
+func (b *iptablesBackend) inSync(chain Chain, rules []IptablesRule) (bool, error) {
+	listed, err := b.ipt.List(chain.Table, chain.Name)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to list rules of chain %s", chain)
+	}
+	count := 0
+	for _, line := range listed {
+		if strings.HasPrefix(line, "-A ") {
+			count++
+		}
+	}
+	for _, rule := range rules {
+		if rule.Table != chain.Table || rule.Chain != chain.Name || !rule.ShouldExist {
+			continue
+		}
+		count--
+		exists, err := b.ipt.Exists(rule.Table, rule.Chain, rule.Rule...)
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to check existence of %v", rule)
+		}
+		if !exists {
+			return false, nil
+		}
+	}
+	return count == 0, nil
+}
+
+func (b *iptablesBackend) Remove(chains []Chain, rules []IptablesRule) error {
+	owned := make(map[Chain]bool)
+	for _, chain := range chains {
+		owned[chain] = true
+	}
+	for _, rule := range rules {
+		if owned[Chain{Table: rule.Table, Name: rule.Chain}] {
+			continue
+		}
+		exists, err := b.ipt.Exists(rule.Table, rule.Chain, rule.Rule...)
+		if err != nil {
+			return errors.Wrapf(err, "failed to check existence of %v", rule)
+		}
+		if !exists {
+			continue
+		}
+		klog.V(2).Infof("Delete iptables rule: %s", rule.Name)
+		if err = b.ipt.Delete(rule.Table, rule.Chain, rule.Rule...); err != nil {
+			return errors.Wrapf(err, "failed to delete %v", rule)
+		}
+	}
+
+	// the owned chains jump to each other, so all of them are flushed before any is deleted
+	var existing []Chain
+	listed := make(map[string]map[string]bool)
+	for _, chain := range chains {
+		if _, ok := listed[chain.Table]; !ok {
+			names, err := b.ipt.ListChains(chain.Table)
+			if err != nil {
+				return errors.Wrapf(err, "failed to list chains of table %s", chain.Table)
+			}
+			listed[chain.Table] = make(map[string]bool)
+			for _, name := range names {
+				listed[chain.Table][name] = true
+			}
+		}
+		if listed[chain.Table][chain.Name] {
+			existing = append(existing, chain)
+			listed[chain.Table][chain.Name] = false
+		}
+	}
+	for _, chain := range existing {
+		klog.V(2).Infof("iptables -F %s -t %s", chain.Name, chain.Table)
+		if err := b.ipt.ClearChain(chain.Table, chain.Name); err != nil {
+			return errors.Wrapf(err, "failed to flush chain %s", chain)
+		}
+	}
+	for _, chain := range existing {
+		klog.V(2).Infof("iptables -X %s -t %s", chain.Name, chain.Table)
+		if err := b.ipt.DeleteChain(chain.Table, chain.Name); err != nil {
+			return errors.Wrapf(err, "failed to delete chain %s", chain)
+		}
+	}
+	return nil
+}
+
+func containChainExistErr(err error) bool {
+	return strings.Contains(err.Error(), "Chain already exists")
+}
@@ -0,0 +1,174 @@
+package iptables
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog"
+)
+
+// RestoreIface applies a payload in the format of iptables-save
+type RestoreIface interface {
+	// Restore applies the payload without flushing the chains it does not declare
+	Restore(data []byte) error
+}
+
+type restoreCommand struct{}
+
+// NewIptablesRestore returns a RestoreIface which runs iptables-restore --noflush
+func NewIptablesRestore() RestoreIface {
+	return &restoreCommand{}
+}
+
+func (r *restoreCommand) Restore(data []byte) error {
+	cmd := exec.Command("iptables-restore", "--noflush", "--wait")
+	cmd.Stdin = bytes.NewReader(data)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return errors.Wrapf(err, "failed to run iptables-restore: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+type iptablesRestoreBackend struct {
+	ipt     IptablesIface
+	restore RestoreIface
+}
+
+// NewIptablesRestoreBackend returns a Backend which renders the chains owned by
+// hostnic which are out of sync and applies all rules with one iptables-restore
+// call. Each table is committed atomically, so there is no window in which the
+// SNAT rules are missing.
+// Rules in chains which hostnic does not own, e.g. FORWARD, are checked with
+// Exists before the payload is rendered, so they are not atomic: a rule changed
+// by someone else in between may be added twice or fail to be deleted, and is
+// fixed on the next Apply.
+func NewIptablesRestoreBackend(ipt IptablesIface, restore RestoreIface) Backend {
+	return &iptablesRestoreBackend{ipt: ipt, restore: restore}
+}
+
+func (b *iptablesRestoreBackend) HasRandomFully() bool {
+	return b.ipt.HasRandomFully()
+}
+
+func (b *iptablesRestoreBackend) Apply(chains []Chain, rules []IptablesRule) error {
+	payload, err := b.render(chains, rules)
+	if err != nil {
+		return err
+	}
+	if len(payload) == 0 {
+		klog.V(4).Infoln("iptables rules are in sync, skip iptables-restore")
+		return nil
+	}
+	klog.V(4).Infof("iptables-restore --noflush\n%s", payload)
+	return b.restore.Restore(payload)
+}
+
+// Remove runs the iptables commands one by one like the iptables backend, it is
+// only used to uninstall hostnic, so there is no need to do it atomically.
+func (b *iptablesRestoreBackend) Remove(chains []Chain, rules []IptablesRule) error {
+	return NewIptablesBackend(b.ipt).Remove(chains, rules)
+}
+
+// render returns the payload of iptables-restore, which is empty if nothing
+// needs to change. Declaring an owned chain flushes it, so an owned chain is
+// only declared and rendered from scratch if its rules differ from the host,
+// the traffic through the others is never touched. Rules in other chains are
+// checked against the host and only added or deleted when needed.
+func (b *iptablesRestoreBackend) render(chains []Chain, rules []IptablesRule) ([]byte, error) {
+	var tables []string
+	declares := make(map[string][]string)
+	lines := make(map[string][]string)
+	addTable := func(table string) {
+		if _, ok := declares[table]; !ok {
+			tables = append(tables, table)
+			declares[table] = []string{}
+		}
+	}
+
+	existing := make(map[string]map[string]bool)
+	owned := make(map[Chain]bool)
+	inSync := make(map[Chain]bool)
+	for _, chain := range chains {
+		addTable(chain.Table)
+		if owned[chain] {
+			continue
+		}
+		owned[chain] = true
+		if _, ok := existing[chain.Table]; !ok {
+			names, err := b.ipt.ListChains(chain.Table)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to list chains of table %s", chain.Table)
+			}
+			existing[chain.Table] = make(map[string]bool)
+			for _, name := range names {
+				existing[chain.Table][name] = true
+			}
+		}
+		if existing[chain.Table][chain.Name] {
+			ok, err := (&iptablesBackend{ipt: b.ipt}).inSync(chain, rules)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				inSync[chain] = true
+				continue
+			}
+		}
+		declares[chain.Table] = append(declares[chain.Table], fmt.Sprintf(":%s - [0:0]", chain.Name))
+	}
+
+	for _, rule := range rules {
+		addTable(rule.Table)
+		if chain := (Chain{Table: rule.Table, Name: rule.Chain}); owned[chain] {
+			if rule.ShouldExist && !inSync[chain] {
+				lines[rule.Table] = append(lines[rule.Table], restoreLine("-A", rule))
+			}
+			continue
+		}
+
+		exists, err := b.ipt.Exists(rule.Table, rule.Chain, rule.Rule...)
+		if err != nil {
+			klog.Errorf("failed to check existence of %v, %v", rule, err)
+			return nil, errors.Wrapf(err, "failed to check existence of %v", rule)
+		}
+		if !exists && rule.ShouldExist {
+			lines[rule.Table] = append(lines[rule.Table], restoreLine("-A", rule))
+		} else if exists && !rule.ShouldExist {
+			lines[rule.Table] = append(lines[rule.Table], restoreLine("-D", rule))
+		}
+	}
+
+	var buf bytes.Buffer
+	for _, table := range tables {
+		if len(declares[table]) == 0 && len(lines[table]) == 0 {
+			continue
+		}
+		fmt.Fprintf(&buf, "*%s\n", table)
+		for _, line := range declares[table] {
+			fmt.Fprintln(&buf, line)
+		}
+		for _, line := range lines[table] {
+			fmt.Fprintln(&buf, line)
+		}
+		fmt.Fprintln(&buf, "COMMIT")
+	}
+	return buf.Bytes(), nil
+}
+
+func restoreLine(op string, rule IptablesRule) string {
+	args := []string{op, rule.Chain}
+	for _, arg := range rule.Rule {
+		args = append(args, quoteRestoreArg(arg))
+	}
+	return strings.Join(args, " ")
+}
+
+func quoteRestoreArg(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, " \t\"'\\") {
+		return arg
+	}
+	return "\"" + strings.Replace(strings.Replace(arg, "\\", "\\\\", -1), "\"", "\\\"", -1) + "\""
+}
@@ -1,6 +1,22 @@
 package iptables
 
-import "fmt"
+import (
+	"fmt"
+	"os/exec"
+
+	coreosiptables "github.com/coreos/go-iptables/iptables"
+)
+
+const (
+	// BackendIptables programs rules one by one with the iptables command
+	BackendIptables = "iptables"
+	// BackendIptablesRestore programs all rules with one iptables-restore call
+	BackendIptablesRestore = "iptables-restore"
+	// BackendNftables programs all rules in one nftables transaction
+	BackendNftables = "nftables"
+	// BackendAuto selects a backend according to the tools installed on the host
+	BackendAuto = "auto"
+)
 
 // IptablesIface wrapper package coreos/iptables
 type IptablesIface interface {
@@ -26,3 +42,66 @@ type IptablesRule struct {
 func (r IptablesRule) String() string {
 	return fmt.Sprintf("%s/%s rule %s", r.Table, r.Chain, r.Name)
 }
+
+// Chain is a user defined chain owned by hostnic. Rules in it which are not
+// declared by hostnic will be removed when rules are applied.
+type Chain struct {
+	Table, Name string
+}
+
+func (c Chain) String() string {
+	return fmt.Sprintf("%s/%s", c.Table, c.Name)
+}
+
+// Backend programs the packet filtering rules of hostnic on the host
+type Backend interface {
+	// Apply creates the chains owned by hostnic, then makes every rule exist
+	// or not according to IptablesRule.ShouldExist
+	Apply(chains []Chain, rules []IptablesRule) error
+	// Remove deletes the rules which are not in the chains owned by hostnic, then
+	// flushes and deletes the owned chains. Missing rules and chains are ignored.
+	Remove(chains []Chain, rules []IptablesRule) error
+	// HasRandomFully reports whether SNAT supports fully randomized port mapping
+	HasRandomFully() bool
+}
+
+// DetectBackend returns the backend which can work on this host. iptables is
+// preferred as long as it is installed, because it also works on top of
+// nftables with the iptables-nft variant.
+func DetectBackend() string {
+	if _, err := exec.LookPath("iptables"); err == nil {
+		if _, err := exec.LookPath("iptables-restore"); err == nil {
+			return BackendIptablesRestore
+		}
+		return BackendIptables
+	}
+	if _, err := exec.LookPath("nft"); err == nil {
+		return BackendNftables
+	}
+	return BackendIptables
+}
+
+// NewBackend creates the backend by name, BackendAuto detects it on the host
+func NewBackend(name string) (Backend, error) {
+	if name == "" || name == BackendAuto {
+		name = DetectBackend()
+	}
+	switch name {
+	case BackendIptables:
+		ipt, err := coreosiptables.New()
+		if err != nil {
+			return nil, err
+		}
+		return NewIptablesBackend(ipt), nil
+	case BackendIptablesRestore:
+		ipt, err := coreosiptables.New()
+		if err != nil {
+			return nil, err
+		}
+		return NewIptablesRestoreBackend(ipt, NewIptablesRestore()), nil
+	case BackendNftables:
+		return NewNftablesBackend(NewNftables()), nil
+	default:
+		return nil, fmt.Errorf("unknown rule backend %q", name)
+	}
+}
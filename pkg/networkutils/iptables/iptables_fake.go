@@ -1,8 +1,17 @@
 package iptables
 
+import (
+	"fmt"
+	"strings"
+)
+
 type FakeIPTables struct {
 	EnableRandomFully bool
 	Data              map[string]map[string][]IptablesRule
+	// Restored keeps the payloads passed to Restore
+	Restored []string
+	// Cleared keeps the chains passed to ClearChain
+	Cleared []Chain
 }
 
 func ruleEqual(rule IptablesRule, rulespec ...string) bool {
@@ -81,23 +90,26 @@ func (f *FakeIPTables) Delete(table, chain string, rulespec ...string) error {
 	return nil
 }
 
+// List returns the rules of the chain like `iptables -S`
 func (f *FakeIPTables) List(table, chain string) ([]string, error) {
 	rules := f.Data[table][chain]
-	result := make([]string, len(rules))
-	for index := 0; index < len(result); index++ {
-		result[index] = rules[index].String()
+	result := []string{"-N " + chain}
+	for _, rule := range rules {
+		result = append(result, "-A "+chain+" "+strings.Join(rule.Rule, " "))
 	}
 	return result, nil
 }
 
 func (f *FakeIPTables) NewChain(table, chain string) error {
-	if _, ok := f.Data[table][chain]; !ok {
-		f.Data[table][chain] = make([]IptablesRule, 0)
+	if _, ok := f.Data[table][chain]; ok {
+		return fmt.Errorf("iptables: Chain already exists")
 	}
+	f.Data[table][chain] = make([]IptablesRule, 0)
 	return nil
 }
 
 func (f *FakeIPTables) ClearChain(table, chain string) error {
+	f.Cleared = append(f.Cleared, Chain{Table: table, Name: chain})
 	f.Data[table][chain] = make([]IptablesRule, 0)
 	return nil
 }
@@ -118,3 +130,148 @@ func (f *FakeIPTables) ListChains(table string) ([]string, error) {
 func (f *FakeIPTables) HasRandomFully() bool {
 	return f.EnableRandomFully
 }
+
+// Restore applies the payload like iptables-restore --noflush, the changes of a
+// table are only visible after its COMMIT
+func (f *FakeIPTables) Restore(data []byte) error {
+	f.Restored = append(f.Restored, string(data))
+	var table string
+	var chains map[string][]IptablesRule
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case strings.HasPrefix(line, "*"):
+			table = line[1:]
+			chains = make(map[string][]IptablesRule)
+			for chain, rules := range f.Data[table] {
+				chains[chain] = append([]IptablesRule{}, rules...)
+			}
+		case line == "COMMIT":
+			if chains == nil {
+				return fmt.Errorf("COMMIT without table")
+			}
+			f.Data[table] = chains
+			chains = nil
+		case chains == nil:
+			return fmt.Errorf("line %q without table", line)
+		case strings.HasPrefix(line, ":"):
+			fields := strings.Fields(line[1:])
+			chains[fields[0]] = make([]IptablesRule, 0)
+		default:
+			args, err := splitRestoreLine(line)
+			if err != nil {
+				return err
+			}
+			if len(args) < 2 {
+				return fmt.Errorf("invalid line %q", line)
+			}
+			chain, rulespec := args[1], args[2:]
+			switch args[0] {
+			case "-A":
+				chains[chain] = append(chains[chain], IptablesRule{Table: table, Chain: chain, Rule: rulespec})
+			case "-D":
+				for index, rule := range chains[chain] {
+					if ruleEqual(rule, rulespec...) {
+						chains[chain] = append(chains[chain][:index], chains[chain][index+1:]...)
+						break
+					}
+				}
+			default:
+				return fmt.Errorf("unsupported command %q", line)
+			}
+		}
+	}
+	if chains != nil {
+		return fmt.Errorf("table %s is not committed", table)
+	}
+	return nil
+}
+
+// splitRestoreLine splits a line of iptables-restore payload into arguments
+func splitRestoreLine(line string) ([]string, error) {
+	var args []string
+	var cur strings.Builder
+	inArg, quoted := false, false
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '\\' && quoted:
+			if i+1 >= len(line) {
+				return nil, fmt.Errorf("unterminated escape in %q", line)
+			}
+			i++
+			cur.WriteByte(line[i])
+		case c == '"':
+			quoted = !quoted
+			inArg = true
+		case (c == ' ' || c == '\t') && !quoted:
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		default:
+			cur.WriteByte(c)
+			inArg = true
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("unterminated quote in %q", line)
+	}
+	if inArg {
+		args = append(args, cur.String())
+	}
+	return args, nil
+}
+
+// FakeNftables keeps the last loaded ruleset in the same layout as FakeIPTables
+type FakeNftables struct {
+	Script string
+	Data   map[string]map[string][]IptablesRule
+}
+
+func NewFakeNftables() *FakeNftables {
+	result := &FakeNftables{}
+	result.reset()
+	return result
+}
+
+func (f *FakeNftables) reset() {
+	f.Data = make(map[string]map[string][]IptablesRule)
+	f.Data["nat"] = make(map[string][]IptablesRule)
+	f.Data["filter"] = make(map[string][]IptablesRule)
+	f.Data["mangle"] = make(map[string][]IptablesRule)
+}
+
+func (f *FakeNftables) Load(ruleset *Ruleset) error {
+	script, err := ruleset.Render()
+	if err != nil {
+		return err
+	}
+	f.Script = script
+	f.reset()
+	for _, chain := range ruleset.Chains {
+		if _, ok := f.Data[chain.Table]; !ok {
+			f.Data[chain.Table] = make(map[string][]IptablesRule)
+		}
+		f.Data[chain.Table][chain.Name] = make([]IptablesRule, 0)
+	}
+	for _, rule := range ruleset.Rules {
+		if _, ok := f.Data[rule.Table]; !ok {
+			f.Data[rule.Table] = make(map[string][]IptablesRule)
+		}
+		f.Data[rule.Table][rule.Chain] = append(f.Data[rule.Table][rule.Chain], IptablesRule{
+			Table: rule.Table,
+			Chain: rule.Chain,
+			Rule:  rule.Rule,
+		})
+	}
+	return nil
+}
+
+func (f *FakeNftables) Delete() error {
+	f.Script = ""
+	f.reset()
+	return nil
+}
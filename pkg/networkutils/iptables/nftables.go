@@ -0,0 +1,415 @@
+package iptables
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog"
+)
+
+// NftablesTable is the nftables table which holds all rules of hostnic
+const NftablesTable = "hostnic"
+
+// NftablesIface loads a ruleset into nftables
+type NftablesIface interface {
+	// Load replaces the content of the hostnic table with the ruleset in one transaction
+	Load(ruleset *Ruleset) error
+	// Delete deletes the hostnic table, it does nothing if the table does not exist
+	Delete() error
+}
+
+// Ruleset is the complete set of chains and rules hostnic wants in nftables
+type Ruleset struct {
+	Chains []Chain
+	Rules  []IptablesRule
+}
+
+type baseChain struct {
+	typ, hook string
+	priority  int
+}
+
+// baseChains maps the builtin iptables chains to nftables base chains, the
+// priorities are the same as the ones of iptables
+var baseChains = map[string]map[string]baseChain{
+	"nat": {
+		"PREROUTING":  {"nat", "prerouting", -100},
+		"INPUT":       {"nat", "input", 100},
+		"OUTPUT":      {"nat", "output", -100},
+		"POSTROUTING": {"nat", "postrouting", 100},
+	},
+	"filter": {
+		"INPUT":   {"filter", "input", 0},
+		"FORWARD": {"filter", "forward", 0},
+		"OUTPUT":  {"filter", "output", 0},
+	},
+	"mangle": {
+		"PREROUTING":  {"filter", "prerouting", -150},
+		"INPUT":       {"filter", "input", -150},
+		"FORWARD":     {"filter", "forward", -150},
+		"OUTPUT":      {"route", "output", -150},
+		"POSTROUTING": {"filter", "postrouting", -150},
+	},
+}
+
+type nftChain struct {
+	name  string
+	base  *baseChain
+	rules []string
+}
+
+// Render returns the nft script of the ruleset. The script deletes and
+// recreates the hostnic table, nft runs it as a single transaction.
+func (r *Ruleset) Render() (string, error) {
+	var chains []*nftChain
+	index := make(map[Chain]*nftChain)
+	owner := make(map[string]string)
+	for _, c := range r.Chains {
+		if _, ok := baseChains[c.Table][c.Name]; ok {
+			return "", fmt.Errorf("builtin chain %s can not be owned", c)
+		}
+		if table, ok := owner[c.Name]; ok && table != c.Table {
+			return "", fmt.Errorf("chain %s is defined in both table %s and %s", c.Name, table, c.Table)
+		}
+		owner[c.Name] = c.Table
+		if _, ok := index[c]; !ok {
+			chain := &nftChain{name: c.Name}
+			index[c] = chain
+			chains = append(chains, chain)
+		}
+	}
+
+	chainName := func(table, name string) (string, error) {
+		chain, ok := index[Chain{Table: table, Name: name}]
+		if !ok {
+			return "", fmt.Errorf("chain %s/%s is not owned by hostnic", table, name)
+		}
+		return chain.name, nil
+	}
+
+	for _, rule := range r.Rules {
+		c := Chain{Table: rule.Table, Name: rule.Chain}
+		chain, ok := index[c]
+		if !ok {
+			base, ok := baseChains[rule.Table][rule.Chain]
+			if !ok {
+				return "", fmt.Errorf("chain %s of %v is not owned by hostnic", c, rule)
+			}
+			chain = &nftChain{name: strings.ToLower(rule.Table + "-" + rule.Chain), base: &base}
+			index[c] = chain
+			chains = append(chains, chain)
+		}
+		stmt, err := translateRule(rule.Table, rule.Rule, chainName)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to translate %v", rule)
+		}
+		chain.rules = append(chain.rules, stmt)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "add table ip %s\n", NftablesTable)
+	fmt.Fprintf(&b, "delete table ip %s\n", NftablesTable)
+	fmt.Fprintf(&b, "table ip %s {\n", NftablesTable)
+	for _, chain := range chains {
+		fmt.Fprintf(&b, "\tchain %s {\n", chain.name)
+		if chain.base != nil {
+			fmt.Fprintf(&b, "\t\ttype %s hook %s priority %d; policy accept;\n",
+				chain.base.typ, chain.base.hook, chain.base.priority)
+		}
+		for _, stmt := range chain.rules {
+			fmt.Fprintf(&b, "\t\t%s\n", stmt)
+		}
+		fmt.Fprintf(&b, "\t}\n")
+	}
+	fmt.Fprintf(&b, "}\n")
+	return b.String(), nil
+}
+
+// translateRule converts the iptables rule spec used by hostnic into a nftables statement
+func translateRule(table string, spec []string, chainName func(table, name string) (string, error)) (string, error) {
+	var exprs []string
+	var comment string
+	negate := false
+	op := func() string {
+		if negate {
+			negate = false
+			return "!= "
+		}
+		return ""
+	}
+
+	for i := 0; i < len(spec); i++ {
+		arg := spec[i]
+		value := func() (string, error) {
+			if i+1 >= len(spec) {
+				return "", fmt.Errorf("missing value of %s", arg)
+			}
+			i++
+			return spec[i], nil
+		}
+
+		switch arg {
+		case "!":
+			negate = true
+			continue
+		case "-s", "--source", "-d", "--destination":
+			v, err := value()
+			if err != nil {
+				return "", err
+			}
+			field := "saddr"
+			if arg == "-d" || arg == "--destination" {
+				field = "daddr"
+			}
+			exprs = append(exprs, fmt.Sprintf("ip %s %s%s", field, op(), v))
+		case "-i", "--in-interface", "-o", "--out-interface":
+			v, err := value()
+			if err != nil {
+				return "", err
+			}
+			field := "iifname"
+			if arg == "-o" || arg == "--out-interface" {
+				field = "oifname"
+			}
+			if strings.HasSuffix(v, "+") {
+				v = strings.TrimSuffix(v, "+") + "*"
+			}
+			exprs = append(exprs, fmt.Sprintf("%s %s\"%s\"", field, op(), v))
+		case "-p", "--protocol":
+			v, err := value()
+			if err != nil {
+				return "", err
+			}
+			exprs = append(exprs, fmt.Sprintf("meta l4proto %s%s", op(), v))
+		case "-m", "--match":
+			v, err := value()
+			if err != nil {
+				return "", err
+			}
+			switch v {
+			case "comment", "addrtype", "conntrack", "mark":
+			default:
+				return "", fmt.Errorf("unsupported match %s", v)
+			}
+		case "--comment":
+			v, err := value()
+			if err != nil {
+				return "", err
+			}
+			comment = strings.Replace(v, "\"", "'", -1)
+		case "--dst-type", "--src-type":
+			v, err := value()
+			if err != nil {
+				return "", err
+			}
+			field := "daddr"
+			if arg == "--src-type" {
+				field = "saddr"
+			}
+			if i+1 < len(spec) && spec[i+1] == "--limit-iface-in" {
+				i++
+				field += " . iif"
+			}
+			exprs = append(exprs, fmt.Sprintf("fib %s type %s%s", field, op(), strings.ToLower(v)))
+		case "--ctstate":
+			v, err := value()
+			if err != nil {
+				return "", err
+			}
+			exprs = append(exprs, fmt.Sprintf("ct state %s%s", op(), strings.ToLower(v)))
+		case "--mark":
+			v, err := value()
+			if err != nil {
+				return "", err
+			}
+			mark, mask, err := parseMark(v)
+			if err != nil {
+				return "", err
+			}
+			exprs = append(exprs, fmt.Sprintf("meta mark and %#x %s%#x", mask, op(), mark))
+		case "-j", "--jump":
+			v, err := value()
+			if err != nil {
+				return "", err
+			}
+			verdict, err := translateTarget(table, v, spec[i+1:], chainName)
+			if err != nil {
+				return "", err
+			}
+			exprs = append(exprs, verdict)
+			i = len(spec)
+		default:
+			return "", fmt.Errorf("unsupported option %s", arg)
+		}
+		if negate {
+			return "", fmt.Errorf("%s can not be negated", arg)
+		}
+	}
+
+	if comment != "" {
+		exprs = append(exprs, fmt.Sprintf("comment \"%s\"", comment))
+	}
+	return strings.Join(exprs, " "), nil
+}
+
+func translateTarget(table, target string, opts []string, chainName func(table, name string) (string, error)) (string, error) {
+	option := func(name string) (string, bool) {
+		for i, opt := range opts {
+			if opt == name {
+				if i+1 < len(opts) {
+					return opts[i+1], true
+				}
+				return "", true
+			}
+		}
+		return "", false
+	}
+
+	switch target {
+	case "ACCEPT", "DROP", "RETURN":
+		return strings.ToLower(target), nil
+	case "SNAT":
+		source, ok := option("--to-source")
+		if !ok || source == "" {
+			return "", fmt.Errorf("SNAT without --to-source")
+		}
+		stmt := "snat to " + source
+		if _, ok := option("--random-fully"); ok {
+			stmt += " fully-random"
+		} else if _, ok := option("--random"); ok {
+			stmt += " random"
+		}
+		return stmt, nil
+	case "MASQUERADE":
+		if _, ok := option("--random-fully"); ok {
+			return "masquerade fully-random", nil
+		}
+		if _, ok := option("--random"); ok {
+			return "masquerade random", nil
+		}
+		return "masquerade", nil
+	case "CONNMARK":
+		if v, ok := option("--set-mark"); ok {
+			mark, mask, err := parseMark(v)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("ct mark set ct mark and %#x or %#x", ^mask, mark), nil
+		}
+		if _, ok := option("--restore-mark"); ok {
+			// nftables can not merge two registers, so the bits outside the
+			// mask are cleared. The packet mark is not set yet in PREROUTING.
+			mask := uint32(0xffffffff)
+			if v, ok := option("--mask"); ok {
+				m, err := strconv.ParseUint(v, 0, 32)
+				if err != nil {
+					return "", errors.Wrapf(err, "invalid mask %s", v)
+				}
+				mask = uint32(m)
+			}
+			return fmt.Sprintf("meta mark set ct mark and %#x", mask), nil
+		}
+		return "", fmt.Errorf("unsupported CONNMARK options %v", opts)
+	case "MARK":
+		if v, ok := option("--set-mark"); ok {
+			mark, mask, err := parseMark(v)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("meta mark set meta mark and %#x or %#x", ^mask, mark), nil
+		}
+		return "", fmt.Errorf("unsupported MARK options %v", opts)
+	default:
+		if len(opts) != 0 {
+			return "", fmt.Errorf("unsupported target %s with options %v", target, opts)
+		}
+		name, err := chainName(table, target)
+		if err != nil {
+			return "", err
+		}
+		return "jump " + name, nil
+	}
+}
+
+// parseMark parses mark in the format of value[/mask]
+func parseMark(s string) (uint32, uint32, error) {
+	parts := strings.SplitN(s, "/", 2)
+	mark, err := strconv.ParseUint(parts[0], 0, 32)
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "invalid mark %s", s)
+	}
+	mask := uint64(0xffffffff)
+	if len(parts) == 2 {
+		mask, err = strconv.ParseUint(parts[1], 0, 32)
+		if err != nil {
+			return 0, 0, errors.Wrapf(err, "invalid mark %s", s)
+		}
+	}
+	return uint32(mark), uint32(mask), nil
+}
+
+type nftCommand struct{}
+
+// NewNftables returns a NftablesIface which loads rulesets with the nft command
+func NewNftables() NftablesIface {
+	return &nftCommand{}
+}
+
+func (n *nftCommand) Load(ruleset *Ruleset) error {
+	script, err := ruleset.Render()
+	if err != nil {
+		return err
+	}
+	klog.V(4).Infof("nft -f -\n%s", script)
+	cmd := exec.Command("nft", "-f", "-")
+	cmd.Stdin = strings.NewReader(script)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return errors.Wrapf(err, "failed to load nftables ruleset: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func (n *nftCommand) Delete() error {
+	script := fmt.Sprintf("add table ip %s\ndelete table ip %s\n", NftablesTable, NftablesTable)
+	klog.V(4).Infof("nft -f -\n%s", script)
+	cmd := exec.Command("nft", "-f", "-")
+	cmd.Stdin = strings.NewReader(script)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return errors.Wrapf(err, "failed to delete nftables table %s: %s", NftablesTable, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+type nftablesBackend struct {
+	nft NftablesIface
+}
+
+// NewNftablesBackend returns a Backend which replaces all rules of hostnic in
+// one nftables transaction
+func NewNftablesBackend(nft NftablesIface) Backend {
+	return &nftablesBackend{nft: nft}
+}
+
+func (b *nftablesBackend) HasRandomFully() bool {
+	return true
+}
+
+func (b *nftablesBackend) Apply(chains []Chain, rules []IptablesRule) error {
+	ruleset := &Ruleset{Chains: chains}
+	for _, rule := range rules {
+		if rule.ShouldExist {
+			ruleset.Rules = append(ruleset.Rules, rule)
+		}
+	}
+	klog.V(2).Infof("Loading %d rules into nftables table %s", len(ruleset.Rules), NftablesTable)
+	return b.nft.Load(ruleset)
+}
+
+// Remove deletes the hostnic table, which holds every chain and rule of hostnic
+func (b *nftablesBackend) Remove(chains []Chain, rules []IptablesRule) error {
+	klog.V(2).Infof("Deleting nftables table %s", NftablesTable)
+	return b.nft.Delete()
+}
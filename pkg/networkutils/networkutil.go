@@ -7,12 +7,13 @@ import (
 	"math"
 	"net"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
-	coreosiptables "github.com/coreos/go-iptables/iptables"
 	"github.com/pkg/errors"
 	"github.com/vishvananda/netlink"
 	"github.com/yunify/hostnic-cni/pkg/netlinkwrapper"
@@ -34,6 +35,12 @@ const (
 	// 1025 - 1535 can be used priority lower than fromPodRulePriority but higher than default nonVPC CIDR rule
 	fromPodRulePriority = 1536
 
+	// 1537 - 32765 are lower than the from-pod rules, so only traffic to non-VPC CIDRs reaches them
+	egressRulePriority = 1600
+
+	// in per-NIC SNAT mode, the rest of traffic from a pod is routed through the table of its NIC
+	fromPodNICRulePriority = 1700
+
 	mainRoutingTable = unix.RT_TABLE_MAIN
 
 	// This environment is used to specify whether an external NAT gateway will be used to provide SNAT of
@@ -61,13 +68,34 @@ const (
 	// sent over the main NIC.
 	envConnmark = "QINGCLOUD_VPC_K8S_CNI_CONNMARK"
 
+	// egressChain holds the SNAT rules of pods whose egress identity is not the primary NIC
+	egressChain = "QINGCLOUD-EGRESS-CHAIN"
+
+	// envSNATMode is the name of the environment variable that selects which address the non-VPC traffic of pods is
+	// SNATed to. "primary" SNATs it to the address of the primary NIC and sends it out of the primary NIC, "nic" sends
+	// it out of the NIC which owns the pod IP and SNATs it to the address of that NIC, so that the bandwidth of all NICs
+	// is used. It is ignored when external SNAT is used. Defaults to primary.
+	envSNATMode = "QINGCLOUD_VPC_K8S_CNI_SNAT_MODE"
+
+	// envRuleBackend is the name of the environment variable that selects how the SNAT, FORWARD and connmark rules
+	// are programmed, "iptables", "iptables-restore", "nftables" or "auto". Defaults to auto, which detects the tools
+	// installed on the host and prefers iptables-restore.
+	envRuleBackend = "QINGCLOUD_VPC_K8S_CNI_RULE_BACKEND"
+
 	// defaultConnmark is the default value for the connmark described above.  Note: the mark space is a little crowded,
 	// - kube-proxy uses 0x0000c000
 	// - Calico uses 0xffff0000.
 	defaultConnmark = 0x80
 
-	// MTU of NIC - veth MTU defined in plugins/routed-nic/driver/driver.go
-	ethernetMTU = 9001
+	// envMTU is the name of the environment variable that overrides the MTU of the secondary NICs and the veths
+	// of pods. By default the MTU of the primary NIC is used, because all NICs of a node are attached to the same VPC.
+	envMTU = "QINGCLOUD_VPC_K8S_CNI_MTU"
+
+	// defaultMTU is used when the MTU of the primary NIC can not be detected
+	defaultMTU = 1500
+	// minMTU is the minimum MTU of IPv4
+	minMTU = 68
+	maxMTU = 65535
 
 	// number of retries to add a route
 	maxRetryRouteAdd = 5
@@ -84,28 +112,89 @@ const (
 type NetworkAPIs interface {
 	// SetupNodeNetwork performs node level network configuration
 	SetupHostNetwork(vpcCIDR *net.IPNet, vpcCIDRs []*string, primaryMAC string, primaryAddr *net.IP) error
+	// GetMTU returns the MTU of the secondary NICs and the veths of pods, which is detected from the primary NIC by
+	// SetupHostNetwork unless it is overridden
+	GetMTU() int
 	// SetupNICNetwork performs nic level network configuration
 	SetupNICNetwork(nicIP string, mac string, table int, subnetCIDR string) error
+	// TeardownNICNetwork removes the host level configuration of a nic, which is going to be detached
+	TeardownNICNetwork(mac string) error
 	UseExternalSNAT() bool
+	// UsePerNICSNAT reports whether the non-VPC traffic of pods leaves through their own NICs
+	UsePerNICSNAT() bool
 	GetRuleList() ([]netlink.Rule, error)
 	GetRuleListBySrc(ruleList []netlink.Rule, src net.IPNet) ([]netlink.Rule, error)
 	UpdateRuleListBySrc(ruleList []netlink.Rule, src net.IPNet, toCIDRs []string, toFlag bool, table int) error
 	DeleteRuleListBySrc(src net.IPNet) error
+	// ReconcileHostNetwork applies the node level network configuration of the last SetupHostNetwork again
+	ReconcileHostNetwork() error
+	// ReconcileNICNetwork sets up the nic level network again if it drifts
+	ReconcileNICNetwork(nicIP string, mac string, table int, subnetCIDR string) error
+	// EnsurePodRules adds the ip rules of a pod which are missing in ruleList
+	EnsurePodRules(ruleList []netlink.Rule, src net.IPNet, toCIDRs []string, table int) error
+	// ExpectedPodRules returns the ip rules a pod should have, including the rule of its egress
+	ExpectedPodRules(src net.IPNet, toCIDRs []string, table int) []netlink.Rule
+	// GetRouteList returns the routes in a route table
+	GetRouteList(table int) ([]netlink.Route, error)
+	// NICNetworkDrift returns how the network of a nic differs from what SetupNICNetwork configures, it is empty if
+	// there is no difference
+	NICNetworkDrift(mac string, table int, subnetCIDR string) (string, error)
+	// SubscribeChanges sends to ch when links, routes or rules of the host change, until done is closed
+	SubscribeChanges(ch chan<- struct{}, done <-chan struct{}) error
+	// TeardownHostNetwork removes the node level network configuration of SetupHostNetwork, the ip rules of pods
+	// and their egress. The rp_filter of the primary NIC is left loose.
+	TeardownHostNetwork(vpcCIDRs []*string, primaryMAC string, primaryAddr *net.IP) error
+	// FlushNICRouteTable deletes the routes SetupNICNetwork adds to the route table of a nic
+	FlushNICRouteTable(table int) error
+	// SetupPodEgress sends the non-VPC traffic of a pod out of the nic of table and SNATs it to egressIP
+	SetupPodEgress(podIP net.IP, egressIP net.IP, table int) error
+	// TeardownPodEgress removes the egress rules of a pod set up by SetupPodEgress
+	TeardownPodEgress(podIP net.IP) error
 }
 
 type linuxNetwork struct {
 	useExternalSNAT        bool
 	typeOfSNAT             snatType
+	snatMode               snatMode
 	nodePortSupportEnabled bool
 	connmark               uint32
 	vpnSupportEnabled      bool
 
 	netLink                  netlinkwrapper.NetLink
 	ns                       nswrapper.NS
-	newIptables              func() (iptables.IptablesIface, error)
+	newBackend               func() (iptables.Backend, error)
 	mainNICMark              uint32
 	findPrimaryInterfaceName func(primaryMAC string) (string, error)
 	setProcSys               func(string, string) error
+
+	// lock protects backend, mtu, egress and hostNetwork, which is the configuration of the last successful
+	// SetupHostNetwork
+	lock        sync.Mutex
+	backend     iptables.Backend
+	hostNetwork *hostNetworkConfig
+	mtu         int
+	// egress keeps the egress of pods by pod ip
+	egress map[string]podEgress
+	// egressChainCreated is set once the egress chain is created, so that it is flushed after the last egress is gone
+	egressChainCreated bool
+	// nics keeps the secondary nics by MAC, whose addresses are used by SNAT in per-NIC SNAT mode
+	nics map[string]nicSNAT
+}
+
+type nicSNAT struct {
+	address string
+	link    string
+}
+
+type podEgress struct {
+	egressIP net.IP
+	table    int
+}
+
+type hostNetworkConfig struct {
+	vpcCIDRs    []*string
+	primaryMAC  string
+	primaryAddr net.IP
 }
 
 type snatType uint32
@@ -116,19 +205,26 @@ const (
 	randomPRNGSNAT
 )
 
+type snatMode string
+
+const (
+	primarySNATMode snatMode = "primary"
+	nicSNATMode     snatMode = "nic"
+)
+
 // New creates a linuxNetwork object
 func New() NetworkAPIs {
 	return &linuxNetwork{
 		useExternalSNAT:        useExternalSNAT(),
 		typeOfSNAT:             typeOfSNAT(),
+		snatMode:               getSNATMode(),
 		nodePortSupportEnabled: nodePortSupportEnabled(),
 		mainNICMark:            getConnmark(),
 
 		netLink: netlinkwrapper.NewNetLink(),
 		ns:      nswrapper.NewNS(),
-		newIptables: func() (iptables.IptablesIface, error) {
-			ipt, err := coreosiptables.New()
-			return ipt, err
+		newBackend: func() (iptables.Backend, error) {
+			return iptables.NewBackend(ruleBackend())
 		},
 		findPrimaryInterfaceName: findPrimaryInterfaceName,
 		setProcSys:               setProcSysByWritingFile,
@@ -167,10 +263,184 @@ func findPrimaryInterfaceName(primaryMAC string) (string, error) {
 func (n *linuxNetwork) SetupHostNetwork(vpcCIDR *net.IPNet, vpcCIDRs []*string, primaryMAC string, primaryAddr *net.IP) error {
 	klog.V(1).Info("Setting up host network... ")
 
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	// The main NIC rule is only needed when connmark is enabled. If this is a restart, it is kept instead of being
+	// deleted and added back, which breaks NodePort traffic in between
+	if !n.connmarkEnabled() {
+		err := n.netLink.RuleDel(n.mainNICRule())
+		if err != nil && !containsNoSuchRule(err) {
+			klog.Errorf("Failed to cleanup old main NIC Rule: %v", err)
+			return errors.Wrapf(err, "host network setup: failed to delete old main NIC rule")
+		}
+	}
+
+	config := &hostNetworkConfig{
+		vpcCIDRs:    vpcCIDRs,
+		primaryMAC:  primaryMAC,
+		primaryAddr: *primaryAddr,
+	}
+	if err := n.applyHostNetwork(config); err != nil {
+		return err
+	}
+	n.hostNetwork = config
+	n.mtu = n.discoverMTU(primaryMAC)
+	klog.V(1).Infof("Using MTU %d for NICs and veths", n.mtu)
+	return nil
+}
+
+// TeardownHostNetwork removes the iptables rules and chains of SetupHostNetwork, the main NIC rule and the ip rules
+// of pods and their egress, so that nothing of hostnic is left on the host
+func (n *linuxNetwork) TeardownHostNetwork(vpcCIDRs []*string, primaryMAC string, primaryAddr *net.IP) error {
+	klog.V(1).Info("Tearing down host network... ")
+
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
 	primaryIntf := "eth0"
-	var err error
-	if n.nodePortSupportEnabled {
+	if n.connmarkEnabled() {
+		var err error
 		primaryIntf, err = n.findPrimaryInterfaceName(primaryMAC)
+		if err != nil {
+			return errors.Wrap(err, "failed to TeardownHostNetwork")
+		}
+	}
+	if n.backend == nil {
+		backend, err := n.newBackend()
+		if err != nil {
+			return errors.Wrap(err, "host network teardown: failed to create rule backend")
+		}
+		n.backend = backend
+	}
+	chains, iptableRules := n.hostRules(vpcCIDRs, primaryIntf, primaryAddr, n.backend.HasRandomFully())
+	// the egress chain is only declared by hostRules once a pod has an egress, which is unknown after restarting
+	if len(n.egress) == 0 && !n.egressChainCreated {
+		chains = append(chains, iptables.Chain{Table: "nat", Name: egressChain})
+	}
+	if err := n.backend.Remove(chains, iptableRules); err != nil {
+		return errors.Wrap(err, "host network teardown: failed to remove rules")
+	}
+
+	ruleList, err := n.GetRuleList()
+	if err != nil {
+		return errors.Wrap(err, "host network teardown: failed to list rules")
+	}
+	mainNICRule := n.mainNICRule()
+	for _, rule := range ruleList {
+		rule := rule
+		if !IsPodRule(&rule) && !(rule.Priority == mainNICRule.Priority && rule.Mark == mainNICRule.Mark) {
+			continue
+		}
+		klog.V(2).Infof("Delete rule %v", rule)
+		if err := n.netLink.RuleDel(&rule); err != nil && !containsNoSuchRule(err) {
+			return errors.Wrapf(err, "host network teardown: failed to delete rule %v", rule)
+		}
+	}
+
+	n.hostNetwork = nil
+	n.egress = nil
+	n.egressChainCreated = false
+	n.nics = nil
+	return nil
+}
+
+// FlushNICRouteTable deletes the routes in the route table (nic-<nic_table>) of a nic
+func (n *linuxNetwork) FlushNICRouteTable(table int) error {
+	if table <= 0 || table == mainRoutingTable {
+		return nil
+	}
+	routes, err := n.GetRouteList(table)
+	if err != nil {
+		return errors.Wrapf(err, "failed to list routes of table %d", table)
+	}
+	for _, route := range routes {
+		route := route
+		if err := n.netLink.RouteDel(&route); err != nil && !netlinkwrapper.IsNotExistsError(err) {
+			return errors.Wrapf(err, "failed to delete route %v of table %d", route, table)
+		}
+	}
+	return nil
+}
+
+// discoverMTU returns the MTU overridden by envMTU, or the MTU of the primary NIC. The MTU of a vxnet is not
+// exposed by the API of QingCloud, the primary NIC is configured with it by DHCP when the instance boots.
+func (n *linuxNetwork) discoverMTU(primaryMAC string) int {
+	if mtu := getMTUOverride(); mtu != 0 {
+		return mtu
+	}
+	link, err := LinkByMac(primaryMAC, n.netLink, 0)
+	if err != nil {
+		klog.Warningf("Failed to find the primary NIC to detect MTU, will use %d: %v", defaultMTU, err)
+		return defaultMTU
+	}
+	if mtu := link.Attrs().MTU; mtu >= minMTU {
+		return mtu
+	}
+	klog.Warningf("Primary NIC %s has invalid MTU %d, will use %d", link.Attrs().Name, link.Attrs().MTU, defaultMTU)
+	return defaultMTU
+}
+
+// GetMTU returns the MTU of the secondary NICs and the veths of pods
+func (n *linuxNetwork) GetMTU() int {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	return n.getMTU()
+}
+
+// getMTU is GetMTU without locking, it falls back to the override or the default before SetupHostNetwork
+func (n *linuxNetwork) getMTU() int {
+	if n.mtu != 0 {
+		return n.mtu
+	}
+	if mtu := getMTUOverride(); mtu != 0 {
+		return mtu
+	}
+	return defaultMTU
+}
+
+// ReconcileHostNetwork applies the node level network configuration of the last SetupHostNetwork again
+func (n *linuxNetwork) ReconcileHostNetwork() error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if n.hostNetwork == nil {
+		klog.V(3).Info("Host network is not set up yet, skip reconciling")
+		return nil
+	}
+	if err := n.applyHostNetwork(n.hostNetwork); err != nil {
+		return err
+	}
+	if len(n.egress) == 0 {
+		return nil
+	}
+	ruleList, err := n.GetRuleList()
+	if err != nil {
+		return errors.Wrap(err, "failed to list rules to reconcile egress")
+	}
+	return n.ensureEgressRules(ruleList)
+}
+
+// If node port support is enabled, add a rule that will force marked traffic out of the main NIC.  We then
+// add iptables rules below that will mark traffic that needs this special treatment.  In particular NodePort
+// traffic always comes in via the main NIC but response traffic would go out of the pod's assigned NIC if we
+// didn't handle it specially. This is because the routing decision is done before the NodePort's DNAT is
+// reversed so, to the routing table, it looks like the traffic is pod traffic instead of NodePort traffic.
+func (n *linuxNetwork) mainNICRule() *netlink.Rule {
+	mainNICRule := n.netLink.NewRule()
+	mainNICRule.Mark = int(n.mainNICMark)
+	mainNICRule.Mask = int(n.mainNICMark)
+	mainNICRule.Table = mainRoutingTable
+	mainNICRule.Priority = hostRulePriority
+	return mainNICRule
+}
+
+// applyHostNetwork makes the rp_filter, the main NIC rule and the iptables rules match the config. It is idempotent.
+func (n *linuxNetwork) applyHostNetwork(config *hostNetworkConfig) error {
+	primaryIntf := "eth0"
+	var err error
+	if n.connmarkEnabled() {
+		primaryIntf, err = n.findPrimaryInterfaceName(config.primaryMAC)
 		if err != nil {
 			return errors.Wrapf(err, "failed to SetupHostNetwork")
 		}
@@ -191,62 +461,49 @@ func (n *linuxNetwork) SetupHostNetwork(vpcCIDR *net.IPNet, vpcCIDRs []*string,
 		if err != nil {
 			return errors.Wrapf(err, "failed to configure %s RPF check", primaryIntf)
 		}
-	}
 
-	// If node port support is enabled, add a rule that will force marked traffic out of the main NIC.  We then
-	// add iptables rules below that will mark traffic that needs this special treatment.  In particular NodePort
-	// traffic always comes in via the main NIC but response traffic would go out of the pod's assigned NIC if we
-	// didn't handle it specially. This is because the routing decision is done before the NodePort's DNAT is
-	// reversed so, to the routing table, it looks like the traffic is pod traffic instead of NodePort traffic.
-	mainNICRule := n.netLink.NewRule()
-	mainNICRule.Mark = int(n.mainNICMark)
-	mainNICRule.Mask = int(n.mainNICMark)
-	mainNICRule.Table = mainRoutingTable
-	mainNICRule.Priority = hostRulePriority
-	// If this is a restart, cleanup previous rule first
-	err = n.netLink.RuleDel(mainNICRule)
-	if err != nil && !containsNoSuchRule(err) {
-		klog.Errorf("Failed to cleanup old main NIC Rule: %v", err)
-		return errors.Wrapf(err, "host network setup: failed to delete old main NIC rule")
+		ruleList, err := n.GetRuleList()
+		if err != nil {
+			return errors.Wrap(err, "host network setup: failed to list rules")
+		}
+		mainNICRule := n.mainNICRule()
+		if !ruleExists(ruleList, mainNICRule) {
+			err = n.netLink.RuleAdd(mainNICRule)
+			if err != nil {
+				klog.Errorf("Failed to add host main NIC Rule: %v", err)
+				return errors.Wrapf(err, "host network setup: failed to add main NIC rule")
+			}
+		}
 	}
 
-	if n.nodePortSupportEnabled {
-		err = n.netLink.RuleAdd(mainNICRule)
+	if n.backend == nil {
+		n.backend, err = n.newBackend()
 		if err != nil {
-			klog.Errorf("Failed to add host main NIC Rule: %v", err)
-			return errors.Wrapf(err, "host network setup: failed to add main NIC rule")
+			return errors.Wrap(err, "host network setup: failed to create rule backend")
 		}
 	}
 
-	ipt, err := n.newIptables()
-	if err != nil {
-		return errors.Wrap(err, "host network setup: failed to create iptables")
+	chains, iptableRules := n.hostRules(config.vpcCIDRs, primaryIntf, &config.primaryAddr, n.backend.HasRandomFully())
+	for _, iptablerule := range iptableRules {
+		klog.V(2).Infof("Preparing iptables rule: %s", iptablerule.String())
+	}
+	if err = n.backend.Apply(chains, iptableRules); err != nil {
+		klog.Errorf("host network setup: failed to apply rules, %v", err)
+		return errors.Wrap(err, "host network setup: failed to apply rules")
 	}
+	return nil
+}
 
+// hostRules returns the chains owned by hostnic and the rules for SNAT of non-VPC outbound traffic, forwarding and
+// connmark of NodePort traffic
+func (n *linuxNetwork) hostRules(vpcCIDRs []*string, primaryIntf string, primaryAddr *net.IP, hasRandomFully bool) ([]iptables.Chain, []iptables.IptablesRule) {
 	// build IPTABLES chain for SNAT of non-VPC outbound traffic
-	var chains []string
+	var chains []iptables.Chain
 	for i := 0; i <= len(vpcCIDRs); i++ {
-		chain := fmt.Sprintf("QINGCLOUD-SNAT-CHAIN-%d", i)
-		klog.V(2).Infof("Setup Host Network: iptables -N %s -t nat", chain)
-		if err = ipt.NewChain("nat", chain); err != nil {
-			if containChainExistErr(err) {
-				klog.V(1).Infof("Clear chain %s before insert rule", chain)
-				err = ipt.ClearChain("nat", chain)
-				if err != nil {
-					klog.Errorf("Failed to clear chain %s", chain)
-					return err
-				}
-			} else {
-				klog.Errorf("ipt.NewChain error for chain [%s]: %v", chain, err)
-				return errors.Wrapf(err, "host network setup: failed to add chain")
-			}
-		}
-		chains = append(chains, chain)
+		chains = append(chains, iptables.Chain{Table: "nat", Name: fmt.Sprintf("QINGCLOUD-SNAT-CHAIN-%d", i)})
 	}
 
 	// build SNAT rules for outbound non-VPC traffic
-	klog.V(2).Infof("Setup Host Network: iptables -A POSTROUTING -m comment --comment \"QINGCLOUD SNAT CHAIN\" -j QINGCLOUD-SNAT-CHAIN-0")
-
 	var iptableRules []iptables.IptablesRule
 	iptableRules = append(iptableRules, iptables.IptablesRule{
 		Name:        "first SNAT rules for non-VPC outbound traffic",
@@ -258,12 +515,10 @@ func (n *linuxNetwork) SetupHostNetwork(vpcCIDR *net.IPNet, vpcCIDRs []*string,
 		}})
 
 	for i, cidr := range vpcCIDRs {
-		curChain := chains[i]
-		nextChain := chains[i+1]
+		curChain := chains[i].Name
+		nextChain := chains[i+1].Name
 		curName := fmt.Sprintf("[%d] QINGCLOUD-SNAT-CHAIN", i)
 
-		klog.V(2).Infof("Setup Host Network: iptables -A %s ! -d %s -t nat -j %s", curChain, *cidr, nextChain)
-
 		iptableRules = append(iptableRules, iptables.IptablesRule{
 			Name:        curName,
 			ShouldExist: !n.useExternalSNAT,
@@ -274,7 +529,7 @@ func (n *linuxNetwork) SetupHostNetwork(vpcCIDR *net.IPNet, vpcCIDRs []*string,
 			}})
 	}
 
-	lastChain := chains[len(chains)-1]
+	lastChain := chains[len(chains)-1].Name
 	// Prepare the Desired Rule for SNAT Rule
 	snatRule := []string{"-m", "comment", "--comment", "QINGCLOUD, SNAT",
 
@@ -284,7 +539,7 @@ func (n *linuxNetwork) SetupHostNetwork(vpcCIDR *net.IPNet, vpcCIDRs []*string,
 		snatRule = append(snatRule, "--random")
 	}
 	if n.typeOfSNAT == randomPRNGSNAT {
-		if ipt.HasRandomFully() {
+		if hasRandomFully {
 			snatRule = append(snatRule, "--random-fully")
 		} else {
 			klog.Warningf("prng (--random-fully) requested, but iptables version does not support it. " +
@@ -292,22 +547,6 @@ func (n *linuxNetwork) SetupHostNetwork(vpcCIDR *net.IPNet, vpcCIDRs []*string,
 			snatRule = append(snatRule, "--random")
 		}
 	}
-	//turn on the forward chain on the nics
-	// iptableRules = append(iptableRules, iptables.IptablesRule{
-	// 	Name:        "accept traffic to/from nics in chain Forward in RELATED,ESTABLISHED",
-	// 	ShouldExist: true,
-	// 	Table:       "filter",
-	// 	Chain:       "FORWARD",
-	// 	Rule:        []string{"-i", "nic+", "-m", "comment", "--comment", "hostnic forwarding conntrack rule", "-m", "conntrack", "--ctstate", "RELATED,ESTABLISHED", "-j", "ACCEPT"},
-	// })
-
-	// iptableRules = append(iptableRules, iptables.IptablesRule{
-	// 	Name:        "accept traffic to/from nics in chain Forward in RELATED,ESTABLISHED",
-	// 	ShouldExist: true,
-	// 	Table:       "filter",
-	// 	Chain:       "FORWARD",
-	// 	Rule:        []string{"-o", "nic+", "-m", "comment", "--comment", "hostnic forwarding conntrack rule", "-m", "conntrack", "--ctstate", "RELATED,ESTABLISHED", "-j", "ACCEPT"},
-	// })
 
 	iptableRules = append(iptableRules, iptables.IptablesRule{
 		Name:        "accept traffic to/from nics in chain Forward",
@@ -325,6 +564,13 @@ func (n *linuxNetwork) SetupHostNetwork(vpcCIDR *net.IPNet, vpcCIDRs []*string,
 		Rule:        []string{"-o", "nic+", "-j", "ACCEPT"},
 	})
 
+	chains, iptableRules = n.egressRules(chains, iptableRules, lastChain)
+	if n.UsePerNICSNAT() {
+		// traffic of pods leaves through their NICs, only the rest is SNATed to the address of the primary NIC
+		snatRule = append([]string{"-o", primaryIntf}, snatRule...)
+		iptableRules = append(iptableRules, n.nicSNATRules(lastChain)...)
+	}
+
 	iptableRules = append(iptableRules, iptables.IptablesRule{
 		Name:        "last SNAT rule for non-VPC outbound traffic",
 		ShouldExist: !n.useExternalSNAT,
@@ -333,13 +579,9 @@ func (n *linuxNetwork) SetupHostNetwork(vpcCIDR *net.IPNet, vpcCIDRs []*string,
 		Rule:        snatRule,
 	})
 
-	for _, iptablerule := range iptableRules {
-		klog.V(2).Infof("Preparing iptables rule: %s", iptablerule.String())
-	}
-
 	iptableRules = append(iptableRules, iptables.IptablesRule{
 		Name:        "connmark for primary NIC",
-		ShouldExist: n.nodePortSupportEnabled,
+		ShouldExist: n.connmarkEnabled(),
 		Table:       "mangle",
 		Chain:       "PREROUTING",
 		Rule: []string{
@@ -352,7 +594,7 @@ func (n *linuxNetwork) SetupHostNetwork(vpcCIDR *net.IPNet, vpcCIDRs []*string,
 
 	iptableRules = append(iptableRules, iptables.IptablesRule{
 		Name:        "connmark restore for primary NIC",
-		ShouldExist: n.nodePortSupportEnabled,
+		ShouldExist: n.connmarkEnabled(),
 		Table:       "mangle",
 		Chain:       "PREROUTING",
 		Rule: []string{
@@ -360,35 +602,79 @@ func (n *linuxNetwork) SetupHostNetwork(vpcCIDR *net.IPNet, vpcCIDRs []*string,
 			"-i", "nic+", "-j", "CONNMARK", "--restore-mark", "--mask", fmt.Sprintf("%#x", n.mainNICMark),
 		},
 	})
+	return chains, iptableRules
+}
 
-	for _, rule := range iptableRules {
-		klog.V(2).Infof("execute iptable rule : %s", rule.Name)
+// nicSNATRules returns the SNAT rules of secondary nics in per-NIC SNAT mode, traffic leaving a nic is SNATed to the
+// address of it. The pod owning the address keeps its source unchanged.
+func (n *linuxNetwork) nicSNATRules(lastChain string) []iptables.IptablesRule {
+	macs := make([]string, 0, len(n.nics))
+	for mac := range n.nics {
+		macs = append(macs, mac)
+	}
+	sort.Strings(macs)
+	var iptableRules []iptables.IptablesRule
+	for _, mac := range macs {
+		nic := n.nics[mac]
+		iptableRules = append(iptableRules, iptables.IptablesRule{
+			Name:        fmt.Sprintf("SNAT rule of nic %s", mac),
+			ShouldExist: true,
+			Table:       "nat",
+			Chain:       lastChain,
+			Rule: []string{
+				"-o", nic.link, "-m", "comment", "--comment", "QINGCLOUD, NIC SNAT",
+				"-j", "SNAT", "--to-source", nic.address,
+			},
+		})
+	}
+	return iptableRules
+}
 
-		exists, err := ipt.Exists(rule.Table, rule.Chain, rule.Rule...)
-		if err != nil {
-			klog.Errorf("host network setup: failed to check existence of %v, %v", rule, err)
-			return errors.Wrapf(err, "host network setup: failed to check existence of %v", rule)
-		}
+// connmarkEnabled reports whether traffic coming from the primary NIC must be marked to return through it. It is
+// required by NodePort and by per-NIC SNAT mode, in which replies would otherwise leave through the NIC of the pod.
+func (n *linuxNetwork) connmarkEnabled() bool {
+	return n.nodePortSupportEnabled || n.UsePerNICSNAT()
+}
 
-		if !exists && rule.ShouldExist {
-			err = ipt.Append(rule.Table, rule.Chain, rule.Rule...)
-			if err != nil {
-				klog.Errorf("host network setup: failed to add %v, %v", rule, err)
-				return errors.Wrapf(err, "host network setup: failed to add %v", rule)
-			}
-		} else if exists && !rule.ShouldExist {
-			err = ipt.Delete(rule.Table, rule.Chain, rule.Rule...)
-			if err != nil {
-				klog.Errorf("host network setup: failed to delete %v, %v", rule, err)
-				return errors.Wrapf(err, "host network setup: failed to delete %v", rule)
-			}
-		}
+// egressRules appends the egress chain and a jump to it from the last SNAT chain, the jump must be placed before the
+// SNAT rule of the primary NIC. Nothing is appended until a pod has an egress.
+func (n *linuxNetwork) egressRules(chains []iptables.Chain, iptableRules []iptables.IptablesRule, lastChain string) ([]iptables.Chain, []iptables.IptablesRule) {
+	if len(n.egress) == 0 && !n.egressChainCreated {
+		return chains, iptableRules
 	}
-	return nil
-}
+	n.egressChainCreated = true
+	chains = append(chains, iptables.Chain{Table: "nat", Name: egressChain})
+	if len(n.egress) == 0 {
+		return chains, iptableRules
+	}
+	iptableRules = append(iptableRules, iptables.IptablesRule{
+		Name:        "jump to egress chain for non-VPC outbound traffic",
+		ShouldExist: !n.useExternalSNAT,
+		Table:       "nat",
+		Chain:       lastChain,
+		Rule: []string{
+			"-m", "comment", "--comment", "QINGCLOUD, egress", "-j", egressChain,
+		},
+	})
 
-func containChainExistErr(err error) bool {
-	return strings.Contains(err.Error(), "Chain already exists")
+	podIPs := make([]string, 0, len(n.egress))
+	for podIP := range n.egress {
+		podIPs = append(podIPs, podIP)
+	}
+	sort.Strings(podIPs)
+	for _, podIP := range podIPs {
+		iptableRules = append(iptableRules, iptables.IptablesRule{
+			Name:        fmt.Sprintf("egress SNAT rule of pod %s", podIP),
+			ShouldExist: !n.useExternalSNAT,
+			Table:       "nat",
+			Chain:       egressChain,
+			Rule: []string{
+				"-s", podIP, "-m", "comment", "--comment", "QINGCLOUD, egress",
+				"-j", "SNAT", "--to-source", n.egress[podIP].egressIP.String(),
+			},
+		})
+	}
+	return chains, iptableRules
 }
 
 func setProcSysByWritingFile(key, value string) error {
@@ -405,6 +691,24 @@ func setProcSysByWritingFile(key, value string) error {
 	return f.Close()
 }
 
+// ruleExists reports whether an equivalent rule is in ruleList
+func ruleExists(ruleList []netlink.Rule, rule *netlink.Rule) bool {
+	for _, r := range ruleList {
+		if r.Priority == rule.Priority && r.Table == rule.Table && r.Mark == rule.Mark &&
+			ipNetEqual(r.Src, rule.Src) && ipNetEqual(r.Dst, rule.Dst) {
+			return true
+		}
+	}
+	return false
+}
+
+func ipNetEqual(a, b *net.IPNet) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.String() == b.String()
+}
+
 func containsNoSuchRule(err error) bool {
 	if errno, ok := err.(syscall.Errno); ok {
 		return errno == syscall.ENOENT
@@ -419,6 +723,9 @@ func GetConfigForDebug() map[string]interface{} {
 		envNodePortSupport: nodePortSupportEnabled(),
 		envConnmark:        getConnmark(),
 		envRandomizeSNAT:   typeOfSNAT(),
+		envRuleBackend:     ruleBackend(),
+		envSNATMode:        getSNATMode(),
+		envMTU:             getMTUOverride(),
 	}
 }
 
@@ -429,6 +736,12 @@ func (n *linuxNetwork) UseExternalSNAT() bool {
 	return useExternalSNAT()
 }
 
+// UsePerNICSNAT reports whether the non-VPC traffic of pods leaves through their own NICs and is SNATed to the
+// addresses of the NICs. External SNAT takes precedence over it.
+func (n *linuxNetwork) UsePerNICSNAT() bool {
+	return !n.useExternalSNAT && n.snatMode == nicSNATMode
+}
+
 func useExternalSNAT() bool {
 	return getBoolEnvVar(envExternalSNAT, false)
 }
@@ -460,6 +773,31 @@ func typeOfSNAT() snatType {
 	}
 }
 
+func getSNATMode() snatMode {
+	switch mode := snatMode(os.Getenv(envSNATMode)); mode {
+	case "":
+		return primarySNATMode
+	case primarySNATMode, nicSNATMode:
+		return mode
+	default:
+		klog.Errorf("Failed to parse %s; using default: %s. Provided string was %q", envSNATMode, primarySNATMode, mode)
+		return primarySNATMode
+	}
+}
+
+func ruleBackend() string {
+	switch backend := os.Getenv(envRuleBackend); backend {
+	case "":
+		return iptables.BackendAuto
+	case iptables.BackendIptables, iptables.BackendIptablesRestore, iptables.BackendNftables, iptables.BackendAuto:
+		return backend
+	default:
+		klog.Errorf("Failed to parse %s; using default: %s. Provided string was %q", envRuleBackend,
+			iptables.BackendAuto, backend)
+		return iptables.BackendAuto
+	}
+}
+
 func nodePortSupportEnabled() bool {
 	return getBoolEnvVar(envNodePortSupport, true)
 }
@@ -496,6 +834,24 @@ func getConnmark() uint32 {
 	return defaultConnmark
 }
 
+// getMTUOverride returns the MTU set by envMTU, or 0 if it is not set or invalid
+func getMTUOverride() int {
+	value := os.Getenv(envMTU)
+	if value == "" {
+		return 0
+	}
+	mtu, err := strconv.Atoi(value)
+	if err != nil {
+		klog.Errorf("Failed to parse %s; will detect MTU. Provided string was %q", envMTU, value)
+		return 0
+	}
+	if mtu < minMTU || mtu > maxMTU {
+		klog.Errorf("%s out of range [%d, %d]; will detect MTU", envMTU, minMTU, maxMTU)
+		return 0
+	}
+	return mtu
+}
+
 // LinkByMac returns linux netlink based on interface MAC
 func LinkByMac(mac string, netLink netlinkwrapper.NetLink, retryInterval time.Duration) (netlink.Link, error) {
 	// The adapter might not be immediately available, so we perform retries
@@ -533,10 +889,116 @@ func LinkByMac(mac string, netLink netlinkwrapper.NetLink, retryInterval time.Du
 
 // SetupNICNetwork adds default route to route table (nic-<nic_table>)
 func (n *linuxNetwork) SetupNICNetwork(nicIP string, nicMAC string, nicTable int, nicSubnetCIDR string) error {
-	return setupNICNetwork(nicIP, nicMAC, nicTable, nicSubnetCIDR, n.netLink, retryLinkByMacInterval)
+	err := setupNICNetwork(nicIP, nicMAC, nicTable, nicSubnetCIDR, n.GetMTU(), n.netLink, retryLinkByMacInterval)
+	if err != nil || nicTable == 0 || !n.UsePerNICSNAT() {
+		return err
+	}
+
+	link, err := LinkByMac(nicMAC, n.netLink, retryLinkByMacInterval)
+	if err != nil {
+		return errors.Wrapf(err, "setupNICNetwork: failed to find the link which uses MAC address %s", nicMAC)
+	}
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	if n.nics == nil {
+		n.nics = make(map[string]nicSNAT)
+	}
+	nic := nicSNAT{address: nicIP, link: link.Attrs().Name}
+	if old, ok := n.nics[nicMAC]; ok && old == nic {
+		return nil
+	}
+	n.nics[nicMAC] = nic
+	if n.hostNetwork == nil {
+		return nil
+	}
+	if err = n.applyHostNetwork(n.hostNetwork); err != nil {
+		return errors.Wrapf(err, "setupNICNetwork: failed to add SNAT rule of NIC %s", nicIP)
+	}
+	return nil
 }
 
-func setupNICNetwork(nicIP string, nicMAC string, nicTable int, nicSubnetCIDR string, netLink netlinkwrapper.NetLink, retryLinkByMacInterval time.Duration) error {
+// TeardownNICNetwork removes the SNAT rule of a nic in per-NIC SNAT mode
+func (n *linuxNetwork) TeardownNICNetwork(nicMAC string) error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	if _, ok := n.nics[nicMAC]; !ok {
+		return nil
+	}
+	delete(n.nics, nicMAC)
+	if n.hostNetwork == nil {
+		return nil
+	}
+	if err := n.applyHostNetwork(n.hostNetwork); err != nil {
+		return errors.Wrapf(err, "teardownNICNetwork: failed to delete SNAT rule of NIC %s", nicMAC)
+	}
+	return nil
+}
+
+// ReconcileNICNetwork sets up the nic level network again if the link is down, the MTU changed or the routes in
+// route table (nic-<nic_table>) are lost
+func (n *linuxNetwork) ReconcileNICNetwork(nicIP string, nicMAC string, nicTable int, nicSubnetCIDR string) error {
+	if nicTable == 0 {
+		return nil
+	}
+	link, err := LinkByMac(nicMAC, n.netLink, 0)
+	if err != nil {
+		return errors.Wrapf(err, "reconcileNICNetwork: failed to find the link which uses MAC address %s", nicMAC)
+	}
+	mtu := n.GetMTU()
+	drift, err := nicNetworkDrift(link, nicTable, nicSubnetCIDR, mtu, n.netLink)
+	if err != nil {
+		return err
+	}
+	if drift == "" {
+		return nil
+	}
+	klog.Warningf("Network of NIC %s drifts: %s, set it up again", nicIP, drift)
+	return setupNICNetwork(nicIP, nicMAC, nicTable, nicSubnetCIDR, mtu, n.netLink, retryLinkByMacInterval)
+}
+
+// nicNetworkDrift returns the difference between the network of NIC and what setupNICNetwork configures,
+// or empty string if there is no difference
+func nicNetworkDrift(link netlink.Link, nicTable int, nicSubnetCIDR string, mtu int, netLink netlinkwrapper.NetLink) (string, error) {
+	if link.Attrs().Flags&net.FlagUp == 0 {
+		return "link is down", nil
+	}
+	if link.Attrs().MTU != mtu {
+		return fmt.Sprintf("MTU is %d", link.Attrs().MTU), nil
+	}
+
+	_, ipnet, err := net.ParseCIDR(nicSubnetCIDR)
+	if err != nil {
+		return "", errors.Wrapf(err, "reconcileNICNetwork: invalid IPv4 CIDR block %s", nicSubnetCIDR)
+	}
+	gw, err := incrementIPv4Addr(ipnet.IP)
+	if err != nil {
+		return "", errors.Wrapf(err, "reconcileNICNetwork: failed to define gateway address from %v", ipnet.IP)
+	}
+	routes, err := netLink.RouteListFiltered(unix.AF_INET, &netlink.Route{Table: nicTable}, netlink.RT_FILTER_TABLE)
+	if err != nil {
+		return "", errors.Wrapf(err, "reconcileNICNetwork: failed to list routes of table %d", nicTable)
+	}
+	hasGwRoute, hasDefaultRoute := false, false
+	for _, r := range routes {
+		if r.LinkIndex != link.Attrs().Index {
+			continue
+		}
+		if r.Dst == nil || (r.Dst.IP.Equal(net.IPv4zero) && r.Dst.Mask.String() == net.CIDRMask(0, 32).String()) {
+			hasDefaultRoute = hasDefaultRoute || r.Gw.Equal(gw)
+		} else if r.Dst.IP.Equal(gw) {
+			hasGwRoute = true
+		}
+	}
+	if !hasGwRoute {
+		return fmt.Sprintf("route to %s is missing in table %d", gw, nicTable), nil
+	}
+	if !hasDefaultRoute {
+		return fmt.Sprintf("default route via %s is missing in table %d", gw, nicTable), nil
+	}
+	return "", nil
+}
+
+func setupNICNetwork(nicIP string, nicMAC string, nicTable int, nicSubnetCIDR string, mtu int, netLink netlinkwrapper.NetLink, retryLinkByMacInterval time.Duration) error {
 	if nicTable == 0 {
 		klog.V(2).Infof("Skipping set up NIC network for primary interface %s", nicIP)
 		return nil
@@ -549,7 +1011,7 @@ func setupNICNetwork(nicIP string, nicMAC string, nicTable int, nicSubnetCIDR st
 		return errors.Wrapf(err, "setupNICNetwork: failed to find the link which uses MAC address %s", nicMAC)
 	}
 
-	if err = netLink.LinkSetMTU(link, ethernetMTU); err != nil {
+	if err = netLink.LinkSetMTU(link, mtu); err != nil {
 		return errors.Wrapf(err, "setupNICNetwork: failed to set MTU for %s", nicIP)
 	}
 	// TODO: due to the bug of iaas, we must set it down if it is up.
@@ -569,6 +1031,57 @@ func setupNICNetwork(nicIP string, nicMAC string, nicTable int, nicSubnetCIDR st
 		return errors.Wrapf(err, "setupNICNetwork: failed to define gateway address from %v", ipnet.IP)
 	}
 
+	routes := []netlink.Route{
+		// Add a direct link route for the host's NIC IP only
+		{
+			LinkIndex: deviceNumber,
+			Dst:       &net.IPNet{IP: gw, Mask: net.CIDRMask(32, 32)},
+			Scope:     netlink.SCOPE_LINK,
+			Table:     nicTable,
+		},
+		// Route all other traffic via the host's NIC IP
+		{
+			LinkIndex: deviceNumber,
+			Dst:       &net.IPNet{IP: net.IPv4zero, Mask: net.CIDRMask(0, 32)},
+			Scope:     netlink.SCOPE_UNIVERSE,
+			Gw:        gw,
+			Table:     nicTable,
+		},
+	}
+	// ipamd restarts on upgrades, the addresses and routes of a nic set up before are kept, otherwise its pods lose
+	// their network until the routes are added back
+	existing, err := netLink.RouteListFiltered(unix.AF_INET, &netlink.Route{Table: nicTable}, netlink.RT_FILTER_TABLE)
+	if err != nil {
+		return errors.Wrapf(err, "setupNICNetwork: failed to list routes of table %d", nicTable)
+	}
+	if routesExist(existing, routes) {
+		klog.V(2).Infof("NIC with MAC address %s is set up already in route table %d", nicMAC, nicTable)
+	} else if err = resetNICNetwork(link, nicIP, gw, nicTable, routes, netLink); err != nil {
+		return err
+	}
+
+	// Remove the route that default out to NIC-x out of main route table
+	_, cidr, err := net.ParseCIDR(nicSubnetCIDR)
+	if err != nil {
+		return errors.Wrapf(err, "setupNICNetwork: invalid IPv4 CIDR block %s", nicSubnetCIDR)
+	}
+	defaultRoute := netlink.Route{
+		Dst:   cidr,
+		Src:   net.ParseIP(nicIP),
+		Table: mainRoutingTable,
+		Scope: netlink.SCOPE_LINK,
+	}
+
+	if err := netLink.RouteDel(&defaultRoute); err != nil {
+		if !netlinkwrapper.IsNotExistsError(err) {
+			return errors.Wrapf(err, "setupNICNetwork: unable to delete default route %s for source IP %s", cidr.String(), nicIP)
+		}
+	}
+	return nil
+}
+
+// resetNICNetwork deletes the addresses of a nic and replaces the routes in its route table
+func resetNICNetwork(link netlink.Link, nicIP string, gw net.IP, nicTable int, routes []netlink.Route, netLink netlinkwrapper.NetLink) error {
 	// Explicitly set the IP on the device if not already set.
 	// Required for older kernels.
 	// ip addr show
@@ -588,23 +1101,6 @@ func setupNICNetwork(nicIP string, nicMAC string, nicTable int, nicSubnetCIDR st
 	}
 
 	klog.V(2).Infof("Setting up NIC's default gateway %v", gw)
-	routes := []netlink.Route{
-		// Add a direct link route for the host's NIC IP only
-		{
-			LinkIndex: deviceNumber,
-			Dst:       &net.IPNet{IP: gw, Mask: net.CIDRMask(32, 32)},
-			Scope:     netlink.SCOPE_LINK,
-			Table:     nicTable,
-		},
-		// Route all other traffic via the host's NIC IP
-		{
-			LinkIndex: deviceNumber,
-			Dst:       &net.IPNet{IP: net.IPv4zero, Mask: net.CIDRMask(0, 32)},
-			Scope:     netlink.SCOPE_UNIVERSE,
-			Gw:        gw,
-			Table:     nicTable,
-		},
-	}
 	for _, r := range routes {
 		err := netLink.RouteDel(&r)
 		if err != nil && !netlinkwrapper.IsNotExistsError(err) {
@@ -642,25 +1138,31 @@ func setupNICNetwork(nicIP string, nicMAC string, nicTable int, nicSubnetCIDR st
 			}
 		}
 	}
+	return nil
+}
 
-	// Remove the route that default out to NIC-x out of main route table
-	_, cidr, err := net.ParseCIDR(nicSubnetCIDR)
-	if err != nil {
-		return errors.Wrapf(err, "setupNICNetwork: invalid IPv4 CIDR block %s", nicSubnetCIDR)
-	}
-	defaultRoute := netlink.Route{
-		Dst:   cidr,
-		Src:   net.ParseIP(nicIP),
-		Table: mainRoutingTable,
-		Scope: netlink.SCOPE_LINK,
+// routesExist tells whether all the routes are in the list, a default route is listed without Dst
+func routesExist(list []netlink.Route, routes []netlink.Route) bool {
+	for _, route := range routes {
+		found := false
+		for _, r := range list {
+			if r.LinkIndex == route.LinkIndex && r.Gw.Equal(route.Gw) && routeDst(&r) == routeDst(&route) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
 	}
+	return true
+}
 
-	if err := netLink.RouteDel(&defaultRoute); err != nil {
-		if !netlinkwrapper.IsNotExistsError(err) {
-			return errors.Wrapf(err, "setupNICNetwork: unable to delete default route %s for source IP %s", cidr.String(), nicIP)
-		}
+func routeDst(route *netlink.Route) string {
+	if route.Dst == nil {
+		return "0.0.0.0/0"
 	}
-	return nil
+	return route.Dst.String()
 }
 
 // incrementIPv4Addr returns incremented IPv4 address
@@ -811,6 +1313,176 @@ func (n *linuxNetwork) UpdateRuleListBySrc(ruleList []netlink.Rule, src net.IPNe
 	return nil
 }
 
+// EnsurePodRules adds the to-pod rule and the from-pod rules of a pod which are missing in ruleList
+func (n *linuxNetwork) EnsurePodRules(ruleList []netlink.Rule, src net.IPNet, toCIDRs []string, table int) error {
+	for _, podRule := range n.podRules(src, toCIDRs, table) {
+		if ruleExists(ruleList, podRule) {
+			continue
+		}
+		klog.Warningf("Rule [%v] of pod %s is missing, add it again", podRule, src.IP)
+		if err := n.netLink.RuleAdd(podRule); err != nil && !IsRuleExistsError(err) {
+			klog.Errorf("Failed to add pod IP rule [%v]: %v", podRule, err)
+			return errors.Wrapf(err, "EnsurePodRules: failed to add pod rule [%v]", podRule)
+		}
+	}
+	return nil
+}
+
+// ExpectedPodRules returns the to-pod rule, the from-pod rules and the egress rule a pod should have
+func (n *linuxNetwork) ExpectedPodRules(src net.IPNet, toCIDRs []string, table int) []netlink.Rule {
+	var rules []netlink.Rule
+	for _, rule := range n.podRules(src, toCIDRs, table) {
+		rules = append(rules, *rule)
+	}
+	n.lock.Lock()
+	egress, ok := n.egress[src.IP.String()]
+	n.lock.Unlock()
+	if ok {
+		rules = append(rules, *n.egressRule(src.IP, egress.table))
+	}
+	return rules
+}
+
+// podRules returns the to-pod rule and the from-pod rules of a pod
+func (n *linuxNetwork) podRules(src net.IPNet, toCIDRs []string, table int) []*netlink.Rule {
+	toPodRule := n.netLink.NewRule()
+	toPodRule.Dst = &src
+	toPodRule.Table = mainRoutingTable
+	toPodRule.Priority = toPodRulePriority
+	podRules := []*netlink.Rule{toPodRule}
+
+	// from-pod rules are only needed when it is not primary NIC. ipamd never tells the plugin to use external SNAT,
+	// so the pods only have the rules to the VPC CIDRs even if it is enabled.
+	if table > 0 {
+		for _, cidr := range toCIDRs {
+			podRule := n.netLink.NewRule()
+			_, podRule.Dst, _ = net.ParseCIDR(cidr)
+			podRule.Src = &src
+			podRule.Table = table
+			podRule.Priority = fromPodRulePriority
+			podRules = append(podRules, podRule)
+		}
+		if n.UsePerNICSNAT() {
+			podRule := n.netLink.NewRule()
+			podRule.Src = &src
+			podRule.Table = table
+			podRule.Priority = fromPodNICRulePriority
+			podRules = append(podRules, podRule)
+		}
+	}
+	return podRules
+}
+
+// GetRouteList returns the routes in a route table
+func (n *linuxNetwork) GetRouteList(table int) ([]netlink.Route, error) {
+	return n.netLink.RouteListFiltered(unix.AF_INET, &netlink.Route{Table: table}, netlink.RT_FILTER_TABLE)
+}
+
+// NICNetworkDrift returns how the network of a nic differs from what SetupNICNetwork configures
+func (n *linuxNetwork) NICNetworkDrift(nicMAC string, nicTable int, nicSubnetCIDR string) (string, error) {
+	if nicTable == 0 {
+		return "", nil
+	}
+	link, err := LinkByMac(nicMAC, n.netLink, 0)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to find the link which uses MAC address %s", nicMAC)
+	}
+	return nicNetworkDrift(link, nicTable, nicSubnetCIDR, n.GetMTU(), n.netLink)
+}
+
+// SubscribeChanges sends to ch when links, routes or rules of the host change, until done is closed
+func (n *linuxNetwork) SubscribeChanges(ch chan<- struct{}, done <-chan struct{}) error {
+	return n.netLink.Subscribe(ch, done)
+}
+
+// SetupPodEgress sends the non-VPC traffic of a pod out of the nic of table and SNATs it to egressIP, instead of
+// the primary NIC and its address. The rules are kept in sync by ReconcileHostNetwork.
+func (n *linuxNetwork) SetupPodEgress(podIP net.IP, egressIP net.IP, table int) error {
+	if n.useExternalSNAT {
+		return fmt.Errorf("egress of pod %s is not supported when external SNAT is used", podIP)
+	}
+	if table <= 0 {
+		return fmt.Errorf("egress of pod %s must be a secondary nic, got table %d", podIP, table)
+	}
+	klog.V(1).Infof("Setting up egress of pod %s, SNAT to %s via table %d", podIP, egressIP, table)
+
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if n.hostNetwork == nil {
+		return fmt.Errorf("egress of pod %s: host network is not set up yet", podIP)
+	}
+	if n.egress == nil {
+		n.egress = make(map[string]podEgress)
+	}
+	old, existed := n.egress[podIP.String()]
+	n.egress[podIP.String()] = podEgress{egressIP: egressIP, table: table}
+	if err := n.applyHostNetwork(n.hostNetwork); err != nil {
+		if existed {
+			n.egress[podIP.String()] = old
+		} else {
+			delete(n.egress, podIP.String())
+		}
+		return errors.Wrapf(err, "failed to set up egress of pod %s", podIP)
+	}
+	if existed && old.table != table {
+		if err := n.netLink.RuleDel(n.egressRule(podIP, old.table)); err != nil && !containsNoSuchRule(err) {
+			return errors.Wrapf(err, "failed to delete old egress rule of pod %s", podIP)
+		}
+	}
+	ruleList, err := n.GetRuleList()
+	if err != nil {
+		return errors.Wrapf(err, "failed to set up egress of pod %s", podIP)
+	}
+	return n.ensureEgressRules(ruleList)
+}
+
+// TeardownPodEgress removes the egress rules of a pod set up by SetupPodEgress
+func (n *linuxNetwork) TeardownPodEgress(podIP net.IP) error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	egress, ok := n.egress[podIP.String()]
+	if !ok {
+		return nil
+	}
+	klog.V(1).Infof("Tearing down egress of pod %s", podIP)
+	if err := n.netLink.RuleDel(n.egressRule(podIP, egress.table)); err != nil && !containsNoSuchRule(err) {
+		return errors.Wrapf(err, "failed to delete egress rule of pod %s", podIP)
+	}
+	delete(n.egress, podIP.String())
+	if n.hostNetwork == nil {
+		return nil
+	}
+	if err := n.applyHostNetwork(n.hostNetwork); err != nil {
+		return errors.Wrapf(err, "failed to tear down egress of pod %s", podIP)
+	}
+	return nil
+}
+
+func (n *linuxNetwork) egressRule(podIP net.IP, table int) *netlink.Rule {
+	rule := n.netLink.NewRule()
+	rule.Src = &net.IPNet{IP: podIP, Mask: net.CIDRMask(32, 32)}
+	rule.Table = table
+	rule.Priority = egressRulePriority
+	return rule
+}
+
+// ensureEgressRules adds the ip rules of pod egress which are missing in ruleList
+func (n *linuxNetwork) ensureEgressRules(ruleList []netlink.Rule) error {
+	for podIP, egress := range n.egress {
+		rule := n.egressRule(net.ParseIP(podIP), egress.table)
+		if ruleExists(ruleList, rule) {
+			continue
+		}
+		if err := n.netLink.RuleAdd(rule); err != nil && !IsRuleExistsError(err) {
+			klog.Errorf("Failed to add egress rule [%v]: %v", rule, err)
+			return errors.Wrapf(err, "failed to add egress rule of pod %s", podIP)
+		}
+	}
+	return nil
+}
+
 // GetVPNNet return the ip from the vpn tunnel, which in most time is the x.x.255.254
 func GetVPNNet(ip string) string {
 	i := net.ParseIP(ip).To4()
@@ -825,15 +1497,21 @@ func GetVPNNet(ip string) string {
 
 // NewFakeNetworkAPI is used by unit test
 func NewFakeNetworkAPI(netlink netlinkwrapper.NetLink, iptableIface iptables.IptablesIface, findPrimaryName func(string) (string, error), setProcSys func(string, string) error) NetworkAPIs {
+	return NewFakeNetworkAPIWithBackend(netlink, iptables.NewIptablesBackend(iptableIface), findPrimaryName, setProcSys)
+}
+
+// NewFakeNetworkAPIWithBackend is used by unit test which programs rules with the given backend
+func NewFakeNetworkAPIWithBackend(netlink netlinkwrapper.NetLink, backend iptables.Backend, findPrimaryName func(string) (string, error), setProcSys func(string, string) error) NetworkAPIs {
 	return &linuxNetwork{
 		useExternalSNAT:        useExternalSNAT(),
 		typeOfSNAT:             typeOfSNAT(),
+		snatMode:               getSNATMode(),
 		nodePortSupportEnabled: nodePortSupportEnabled(),
 		mainNICMark:            getConnmark(),
 		netLink:                netlink,
 		ns:                     &nswrapper.FakeNsWrapper{},
-		newIptables: func() (iptables.IptablesIface, error) {
-			return iptableIface, nil
+		newBackend: func() (iptables.Backend, error) {
+			return backend, nil
 		},
 		findPrimaryInterfaceName: findPrimaryName,
 		setProcSys:               setProcSys,
@@ -848,6 +1526,21 @@ func ContainsNoSuchRule(err error) bool {
 	return false
 }
 
+// RuleExists reports whether the rule is in the rule list, rules are compared by priority, table, mark, source
+// and destination
+func RuleExists(ruleList []netlink.Rule, rule *netlink.Rule) bool {
+	return ruleExists(ruleList, rule)
+}
+
+// IsPodRule reports whether the rule is one of the rules hostnic sets up for pods
+func IsPodRule(rule *netlink.Rule) bool {
+	switch rule.Priority {
+	case toPodRulePriority, fromPodRulePriority, egressRulePriority, fromPodNICRulePriority:
+		return true
+	}
+	return false
+}
+
 // IsRuleExistsError report whether the rule is exist
 func IsRuleExistsError(err error) bool {
 	if errno, ok := err.(syscall.Errno); ok {
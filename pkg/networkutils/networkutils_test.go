@@ -35,6 +35,35 @@ var (
 	testNICIP     = net.ParseIP(testnicIP)
 )
 
+type fakeRuleBackend struct {
+	name string
+	new  func() (iptables.Backend, func() map[string]map[string][]iptables.IptablesRule)
+}
+
+var fakeRuleBackends = []fakeRuleBackend{
+	{
+		name: iptables.BackendIptables,
+		new: func() (iptables.Backend, func() map[string]map[string][]iptables.IptablesRule) {
+			ipt := iptables.NewFakeIPTables()
+			return iptables.NewIptablesBackend(ipt), func() map[string]map[string][]iptables.IptablesRule { return ipt.Data }
+		},
+	},
+	{
+		name: iptables.BackendIptablesRestore,
+		new: func() (iptables.Backend, func() map[string]map[string][]iptables.IptablesRule) {
+			ipt := iptables.NewFakeIPTables()
+			return iptables.NewIptablesRestoreBackend(ipt, ipt), func() map[string]map[string][]iptables.IptablesRule { return ipt.Data }
+		},
+	},
+	{
+		name: iptables.BackendNftables,
+		new: func() (iptables.Backend, func() map[string]map[string][]iptables.IptablesRule) {
+			nft := iptables.NewFakeNftables()
+			return iptables.NewNftablesBackend(nft), func() map[string]map[string][]iptables.IptablesRule { return nft.Data }
+		},
+	},
+}
+
 var _ = Describe("Networkutils", func() {
 	It("Should get proper vpn net", func() {
 		Expect(GetVPNNet("192.168.0.2")).To(Equal("192.168.255.254/32"))
@@ -43,8 +72,228 @@ var _ = Describe("Networkutils", func() {
 	var setProcSys = func(string, string) error {
 		return nil
 	}
-	It("Should set up the hostnetwork properly when supporting nodePort", func() {
-		iptablesData := iptables.NewFakeIPTables()
+	for _, fakeBackend := range fakeRuleBackends {
+		fakeBackend := fakeBackend
+		Context("with "+fakeBackend.name+" backend", func() {
+			It("Should set up the hostnetwork properly when supporting nodePort", func() {
+				backend, ruleData := fakeBackend.new()
+				netlinkData := fakenetlink.NewFakeNetlink()
+
+				eth0 := &netlink.Device{
+					LinkAttrs: netlink.NewLinkAttrs(),
+				}
+				eth0.Name = "eth0"
+				eth0.HardwareAddr = net.HardwareAddr(testMAC)
+				netlinkData.LinkAdd(eth0)
+				os.Setenv(envNodePortSupport, "true")
+				api := NewFakeNetworkAPIWithBackend(netlinkData, backend, netlinkData.FindPrimaryInterfaceName, setProcSys)
+				//prepare setup network parameter
+				testSubnet1 := "10.10.1.0/24"
+				testSubnet2 := "10.10.2.0/24"
+				err := api.SetupHostNetwork(testVPC, []*string{&testSubnet1, &testSubnet2}, testMAC, &testNICIP)
+				Expect(err).ShouldNot(HaveOccurred())
+				iptablesData := ruleData()
+
+				//rule check
+				mainNICRule := netlink.NewRule()
+				mainNICRule.Mark = defaultConnmark
+				mainNICRule.Mask = defaultConnmark
+				mainNICRule.Table = mainRoutingTable
+				mainNICRule.Priority = hostRulePriority
+				rules, _ := netlinkData.RuleList(0)
+				Expect(rules[0]).To(Equal(*mainNICRule))
+				//nat chains check
+				Expect(iptablesData["nat"]).To(MatchAllKeys(
+					Keys{
+						"POSTROUTING":            HaveLen(1),
+						"QINGCLOUD-SNAT-CHAIN-0": HaveLen(1),
+						"QINGCLOUD-SNAT-CHAIN-1": HaveLen(1),
+						"QINGCLOUD-SNAT-CHAIN-2": HaveLen(1),
+					},
+				))
+				Expect(iptablesData["nat"]["POSTROUTING"][0].Rule).To(Equal([]string{"-m", "comment", "--comment", "QINGCLOUD SNAT CHAIN", "-j", "QINGCLOUD-SNAT-CHAIN-0"}))
+				Expect(iptablesData["nat"]["QINGCLOUD-SNAT-CHAIN-0"][0].Rule).To(Equal([]string{
+					"!", "-d", "10.10.1.0/24", "-m", "comment", "--comment", "QINGCLOUD SNAT CHAN", "-j", "QINGCLOUD-SNAT-CHAIN-1",
+				}))
+				Expect(iptablesData["nat"]["QINGCLOUD-SNAT-CHAIN-1"][0].Rule).To(Equal([]string{
+					"!", "-d", "10.10.2.0/24", "-m", "comment", "--comment", "QINGCLOUD SNAT CHAN", "-j", "QINGCLOUD-SNAT-CHAIN-2",
+				}))
+				Expect(iptablesData["nat"]["QINGCLOUD-SNAT-CHAIN-2"][0].Rule).To(Equal([]string{"-m", "comment", "--comment", "QINGCLOUD, SNAT",
+					"-m", "addrtype", "!", "--dst-type", "LOCAL",
+					"-j", "SNAT", "--to-source", testnicIP, "--random"}))
+
+				// filter chain check
+				Expect(iptablesData["filter"]).To(MatchAllKeys(
+					Keys{
+						"FORWARD": HaveLen(2),
+					},
+				))
+				Expect(iptablesData["filter"]["FORWARD"][0].Rule).To(Equal([]string{"-i", "nic+", "-j", "ACCEPT"}))
+				Expect(iptablesData["filter"]["FORWARD"][1].Rule).To(Equal([]string{"-o", "nic+", "-j", "ACCEPT"}))
+
+				// mangle chain check
+				Expect(iptablesData["mangle"]).To(MatchAllKeys(
+					Keys{
+						"PREROUTING": HaveLen(2),
+					},
+				))
+				Expect(iptablesData["mangle"]["PREROUTING"][0].Rule).To(Equal([]string{
+					"-m", "comment", "--comment", "QINGCLOUD, primary NIC",
+					"-i", "eth0",
+					"-m", "addrtype", "--dst-type", "LOCAL", "--limit-iface-in",
+					"-j", "CONNMARK", "--set-mark", fmt.Sprintf("%#x/%#x", defaultConnmark, defaultConnmark),
+				}))
+
+				Expect(iptablesData["mangle"]["PREROUTING"][1].Rule).To(Equal([]string{
+					"-m", "comment", "--comment", "QINGCLOUD, primary NIC",
+					"-i", "nic+", "-j", "CONNMARK", "--restore-mark", "--mask", fmt.Sprintf("%#x", defaultConnmark),
+				}))
+			})
+
+			It("Should set up the hostnetwork properly without supporting nodePort", func() {
+				backend, ruleData := fakeBackend.new()
+				netlinkData := fakenetlink.NewFakeNetlink()
+
+				eth0 := &netlink.Device{
+					LinkAttrs: netlink.NewLinkAttrs(),
+				}
+				eth0.Name = "eth0"
+				eth0.HardwareAddr, _ = net.ParseMAC(testMAC)
+				netlinkData.LinkAdd(eth0)
+				os.Setenv(envNodePortSupport, "false")
+				api := NewFakeNetworkAPIWithBackend(netlinkData, backend, netlinkData.FindPrimaryInterfaceName, setProcSys)
+				//prepare setup network parameter
+				testSubnet1 := "10.10.1.0/24"
+				testSubnet2 := "10.10.2.0/24"
+				err := api.SetupHostNetwork(testVPC, []*string{&testSubnet1, &testSubnet2}, testMAC, &testNICIP)
+				Expect(err).ShouldNot(HaveOccurred())
+				iptablesData := ruleData()
+
+				//nat chains check
+				Expect(iptablesData["nat"]).To(MatchAllKeys(
+					Keys{
+						"POSTROUTING":            HaveLen(1),
+						"QINGCLOUD-SNAT-CHAIN-0": HaveLen(1),
+						"QINGCLOUD-SNAT-CHAIN-1": HaveLen(1),
+						"QINGCLOUD-SNAT-CHAIN-2": HaveLen(1),
+					},
+				))
+				Expect(iptablesData["nat"]["POSTROUTING"][0].Rule).To(Equal([]string{"-m", "comment", "--comment", "QINGCLOUD SNAT CHAIN", "-j", "QINGCLOUD-SNAT-CHAIN-0"}))
+				Expect(iptablesData["nat"]["QINGCLOUD-SNAT-CHAIN-0"][0].Rule).To(Equal([]string{
+					"!", "-d", "10.10.1.0/24", "-m", "comment", "--comment", "QINGCLOUD SNAT CHAN", "-j", "QINGCLOUD-SNAT-CHAIN-1",
+				}))
+				Expect(iptablesData["nat"]["QINGCLOUD-SNAT-CHAIN-1"][0].Rule).To(Equal([]string{
+					"!", "-d", "10.10.2.0/24", "-m", "comment", "--comment", "QINGCLOUD SNAT CHAN", "-j", "QINGCLOUD-SNAT-CHAIN-2",
+				}))
+				Expect(iptablesData["nat"]["QINGCLOUD-SNAT-CHAIN-2"][0].Rule).To(Equal([]string{"-m", "comment", "--comment", "QINGCLOUD, SNAT",
+					"-m", "addrtype", "!", "--dst-type", "LOCAL",
+					"-j", "SNAT", "--to-source", testnicIP, "--random"}))
+
+				// filter chain check
+				Expect(iptablesData["filter"]).To(MatchAllKeys(
+					Keys{
+						"FORWARD": HaveLen(2),
+					},
+				))
+				Expect(iptablesData["filter"]["FORWARD"][0].Rule).To(Equal([]string{"-i", "nic+", "-j", "ACCEPT"}))
+				Expect(iptablesData["filter"]["FORWARD"][1].Rule).To(Equal([]string{"-o", "nic+", "-j", "ACCEPT"}))
+
+				// mangle chain check
+				Expect(iptablesData["mangle"]).To(HaveLen(0))
+			})
+
+			It("Should SNAT a pod to its egress nic", func() {
+				backend, ruleData := fakeBackend.new()
+				netlinkData := fakenetlink.NewFakeNetlink()
+				os.Setenv(envNodePortSupport, "false")
+				api := NewFakeNetworkAPIWithBackend(netlinkData, backend, netlinkData.FindPrimaryInterfaceName, setProcSys)
+				testSubnet1 := "10.10.1.0/24"
+				podIP := net.ParseIP("10.10.1.5")
+				egressIP := net.ParseIP("10.10.1.6")
+				Expect(api.SetupPodEgress(podIP, egressIP, testTable)).Should(HaveOccurred())
+				Expect(api.SetupHostNetwork(testVPC, []*string{&testSubnet1}, testMAC, &testNICIP)).ShouldNot(HaveOccurred())
+
+				Expect(api.SetupPodEgress(podIP, egressIP, testTable)).ShouldNot(HaveOccurred())
+				iptablesData := ruleData()
+				Expect(iptablesData["nat"]).To(MatchAllKeys(
+					Keys{
+						"POSTROUTING":            HaveLen(1),
+						"QINGCLOUD-SNAT-CHAIN-0": HaveLen(1),
+						"QINGCLOUD-SNAT-CHAIN-1": HaveLen(2),
+						egressChain:              HaveLen(1),
+					},
+				))
+				Expect(iptablesData["nat"]["QINGCLOUD-SNAT-CHAIN-1"][0].Rule).To(Equal([]string{
+					"-m", "comment", "--comment", "QINGCLOUD, egress", "-j", egressChain,
+				}))
+				Expect(iptablesData["nat"][egressChain][0].Rule).To(Equal([]string{
+					"-s", podIP.String(), "-m", "comment", "--comment", "QINGCLOUD, egress",
+					"-j", "SNAT", "--to-source", egressIP.String(),
+				}))
+				egressRule := &netlink.Rule{
+					Src:      &net.IPNet{IP: podIP, Mask: net.CIDRMask(32, 32)},
+					Table:    testTable,
+					Priority: egressRulePriority,
+				}
+				Expect(netlinkData.Rules).To(HaveKey(fakenetlink.KeyForRule(egressRule)))
+
+				// the egress is kept by reconciling
+				netlinkData.Rules = make(map[string]netlink.Rule)
+				Expect(api.ReconcileHostNetwork()).ShouldNot(HaveOccurred())
+				Expect(netlinkData.Rules).To(HaveKey(fakenetlink.KeyForRule(egressRule)))
+
+				Expect(api.TeardownPodEgress(podIP)).ShouldNot(HaveOccurred())
+				iptablesData = ruleData()
+				Expect(iptablesData["nat"]["QINGCLOUD-SNAT-CHAIN-1"]).To(HaveLen(1))
+				Expect(iptablesData["nat"][egressChain]).To(BeEmpty())
+				Expect(netlinkData.Rules).NotTo(HaveKey(fakenetlink.KeyForRule(egressRule)))
+			})
+
+			It("Should remove everything of the host network when it is torn down", func() {
+				backend, ruleData := fakeBackend.new()
+				netlinkData := fakenetlink.NewFakeNetlink()
+				eth0 := &netlink.Device{
+					LinkAttrs: netlink.NewLinkAttrs(),
+				}
+				eth0.Name = "eth0"
+				eth0.HardwareAddr = net.HardwareAddr(testMAC)
+				netlinkData.LinkAdd(eth0)
+				os.Setenv(envNodePortSupport, "true")
+				api := NewFakeNetworkAPIWithBackend(netlinkData, backend, netlinkData.FindPrimaryInterfaceName, setProcSys)
+				testSubnet1 := "10.10.1.0/24"
+				podIP := net.ParseIP("10.10.1.5")
+				Expect(api.SetupHostNetwork(testVPC, []*string{&testSubnet1}, testMAC, &testNICIP)).ShouldNot(HaveOccurred())
+				Expect(api.SetupPodEgress(podIP, net.ParseIP("10.10.1.6"), testTable)).ShouldNot(HaveOccurred())
+				src := net.IPNet{IP: podIP, Mask: net.CIDRMask(32, 32)}
+				Expect(api.EnsurePodRules(nil, src, []string{testSubnet1}, testTable)).ShouldNot(HaveOccurred())
+				netlinkData.RouteAdd(&netlink.Route{Dst: &net.IPNet{IP: net.IPv4zero, Mask: net.CIDRMask(0, 32)}, Table: testTable})
+				mainRoute := &netlink.Route{Dst: &src, Table: mainRoutingTable}
+				netlinkData.RouteAdd(mainRoute)
+				Expect(netlinkData.Rules).To(HaveLen(4))
+
+				// the host network is torn down by another process, which knows nothing about the egress
+				api = NewFakeNetworkAPIWithBackend(netlinkData, backend, netlinkData.FindPrimaryInterfaceName, setProcSys)
+				Expect(api.TeardownHostNetwork([]*string{&testSubnet1}, testMAC, &testNICIP)).ShouldNot(HaveOccurred())
+				Expect(api.FlushNICRouteTable(testTable)).ShouldNot(HaveOccurred())
+				for table, chains := range ruleData() {
+					for chain, rules := range chains {
+						Expect(chain).NotTo(HavePrefix("QINGCLOUD"), "chain %s of table %s", chain, table)
+						Expect(rules).To(BeEmpty(), "chain %s of table %s", chain, table)
+					}
+				}
+				Expect(netlinkData.Rules).To(BeEmpty())
+				Expect(netlinkData.Routes).To(HaveLen(1))
+				Expect(api.GetRouteList(mainRoutingTable)).To(ConsistOf(*mainRoute))
+
+				// it is fine to tear it down again
+				Expect(api.TeardownHostNetwork([]*string{&testSubnet1}, testMAC, &testNICIP)).ShouldNot(HaveOccurred())
+				os.Setenv(envNodePortSupport, "false")
+			})
+		})
+	}
+
+	It("Should replace rules of owned chains with one iptables-restore call", func() {
+		ipt := iptables.NewFakeIPTables()
 		netlinkData := fakenetlink.NewFakeNetlink()
 
 		eth0 := &netlink.Device{
@@ -53,119 +302,128 @@ var _ = Describe("Networkutils", func() {
 		eth0.Name = "eth0"
 		eth0.HardwareAddr = net.HardwareAddr(testMAC)
 		netlinkData.LinkAdd(eth0)
-		os.Setenv(envNodePortSupport, "true")
-		api := NewFakeNetworkAPI(netlinkData, iptablesData, netlinkData.FindPrimaryInterfaceName, setProcSys)
-		//prepare setup network parameter
+		os.Setenv(envNodePortSupport, "false")
+		api := NewFakeNetworkAPIWithBackend(netlinkData, iptables.NewIptablesRestoreBackend(ipt, ipt), netlinkData.FindPrimaryInterfaceName, setProcSys)
 		testSubnet1 := "10.10.1.0/24"
-		testSubnet2 := "10.10.2.0/24"
-		err := api.SetupHostNetwork(testVPC, []*string{&testSubnet1, &testSubnet2}, testMAC, &testNICIP)
-		Expect(err).ShouldNot(HaveOccurred())
+		Expect(api.SetupHostNetwork(testVPC, []*string{&testSubnet1}, testMAC, &testNICIP)).ShouldNot(HaveOccurred())
 
-		//rule check
-		mainNICRule := netlink.NewRule()
-		mainNICRule.Mark = defaultConnmark
-		mainNICRule.Mask = defaultConnmark
-		mainNICRule.Table = mainRoutingTable
-		mainNICRule.Priority = hostRulePriority
-		rules, _ := netlinkData.RuleList(0)
-		Expect(rules[0]).To(Equal(*mainNICRule))
-		//nat chains check
-		Expect(iptablesData.Data["nat"]).To(MatchAllKeys(
-			Keys{
-				"POSTROUTING":            HaveLen(1),
-				"QINGCLOUD-SNAT-CHAIN-0": HaveLen(1),
-				"QINGCLOUD-SNAT-CHAIN-1": HaveLen(1),
-				"QINGCLOUD-SNAT-CHAIN-2": HaveLen(1),
-			},
-		))
-		Expect(iptablesData.Data["nat"]["POSTROUTING"][0].Rule).To(Equal([]string{"-m", "comment", "--comment", "QINGCLOUD SNAT CHAIN", "-j", "QINGCLOUD-SNAT-CHAIN-0"}))
-		Expect(iptablesData.Data["nat"]["QINGCLOUD-SNAT-CHAIN-0"][0].Rule).To(Equal([]string{
-			"!", "-d", "10.10.1.0/24", "-m", "comment", "--comment", "QINGCLOUD SNAT CHAN", "-j", "QINGCLOUD-SNAT-CHAIN-1",
-		}))
-		Expect(iptablesData.Data["nat"]["QINGCLOUD-SNAT-CHAIN-1"][0].Rule).To(Equal([]string{
-			"!", "-d", "10.10.2.0/24", "-m", "comment", "--comment", "QINGCLOUD SNAT CHAN", "-j", "QINGCLOUD-SNAT-CHAIN-2",
-		}))
-		Expect(iptablesData.Data["nat"]["QINGCLOUD-SNAT-CHAIN-2"][0].Rule).To(Equal([]string{"-m", "comment", "--comment", "QINGCLOUD, SNAT",
+		// stale rule left by an old version
+		ipt.Append("nat", "QINGCLOUD-SNAT-CHAIN-1", "-j", "SNAT", "--to-source", "10.10.10.21")
+		ipt.Append("mangle", "PREROUTING", "-m", "comment", "--comment", "QINGCLOUD, primary NIC",
+			"-i", "nic+", "-j", "CONNMARK", "--restore-mark", "--mask", fmt.Sprintf("%#x", defaultConnmark))
+		Expect(api.SetupHostNetwork(testVPC, []*string{&testSubnet1}, testMAC, &testNICIP)).ShouldNot(HaveOccurred())
+
+		// only the chain which differs is flushed
+		Expect(ipt.Restored).To(HaveLen(2))
+		Expect(ipt.Restored[1]).To(Equal(`*nat
+:QINGCLOUD-SNAT-CHAIN-1 - [0:0]
+-A QINGCLOUD-SNAT-CHAIN-1 -m comment --comment "QINGCLOUD, SNAT" -m addrtype ! --dst-type LOCAL -j SNAT --to-source 10.10.10.20 --random
+COMMIT
+*mangle
+-D PREROUTING -m comment --comment "QINGCLOUD, primary NIC" -i nic+ -j CONNMARK --restore-mark --mask 0x80
+COMMIT
+`))
+		Expect(ipt.Data["nat"]["POSTROUTING"]).To(HaveLen(1))
+		Expect(ipt.Data["nat"]["QINGCLOUD-SNAT-CHAIN-1"]).To(HaveLen(1))
+		Expect(ipt.Data["nat"]["QINGCLOUD-SNAT-CHAIN-1"][0].Rule).To(Equal([]string{"-m", "comment", "--comment", "QINGCLOUD, SNAT",
 			"-m", "addrtype", "!", "--dst-type", "LOCAL",
 			"-j", "SNAT", "--to-source", testnicIP, "--random"}))
+		Expect(ipt.Data["filter"]["FORWARD"]).To(HaveLen(2))
+		Expect(ipt.Data["mangle"]["PREROUTING"]).To(HaveLen(0))
 
-		// filter chain check
-		Expect(iptablesData.Data["filter"]).To(MatchAllKeys(
-			Keys{
-				"FORWARD": HaveLen(2),
-			},
-		))
-		Expect(iptablesData.Data["filter"]["FORWARD"][0].Rule).To(Equal([]string{"-i", "nic+", "-j", "ACCEPT"}))
-		Expect(iptablesData.Data["filter"]["FORWARD"][1].Rule).To(Equal([]string{"-o", "nic+", "-j", "ACCEPT"}))
-
-		// mangle chain check
-		Expect(iptablesData.Data["mangle"]).To(MatchAllKeys(
-			Keys{
-				"PREROUTING": HaveLen(2),
-			},
-		))
-		Expect(iptablesData.Data["mangle"]["PREROUTING"][0].Rule).To(Equal([]string{
-			"-m", "comment", "--comment", "QINGCLOUD, primary NIC",
-			"-i", "eth0",
-			"-m", "addrtype", "--dst-type", "LOCAL", "--limit-iface-in",
-			"-j", "CONNMARK", "--set-mark", fmt.Sprintf("%#x/%#x", defaultConnmark, defaultConnmark),
-		}))
-
-		Expect(iptablesData.Data["mangle"]["PREROUTING"][1].Rule).To(Equal([]string{
-			"-m", "comment", "--comment", "QINGCLOUD, primary NIC",
-			"-i", "nic+", "-j", "CONNMARK", "--restore-mark", "--mask", fmt.Sprintf("%#x", defaultConnmark),
-		}))
+		// nothing is restored if the rules are in sync
+		Expect(api.SetupHostNetwork(testVPC, []*string{&testSubnet1}, testMAC, &testNICIP)).ShouldNot(HaveOccurred())
+		Expect(ipt.Restored).To(HaveLen(2))
 	})
 
-	It("Should set up the hostnetwork properly without supporting nodePort", func() {
-		iptablesData := iptables.NewFakeIPTables()
+	It("Should keep the host network and nics as they are when ipamd restarts", func() {
+		ipt := iptables.NewFakeIPTables()
 		netlinkData := fakenetlink.NewFakeNetlink()
 
 		eth0 := &netlink.Device{
 			LinkAttrs: netlink.NewLinkAttrs(),
 		}
 		eth0.Name = "eth0"
-		eth0.HardwareAddr, _ = net.ParseMAC(testMAC)
+		eth0.HardwareAddr = net.HardwareAddr(testMAC)
 		netlinkData.LinkAdd(eth0)
-		os.Setenv(envNodePortSupport, "false")
-		api := NewFakeNetworkAPI(netlinkData, iptablesData, netlinkData.FindPrimaryInterfaceName, setProcSys)
-		//prepare setup network parameter
+		eth1 := &netlink.Device{
+			LinkAttrs: netlink.NewLinkAttrs(),
+		}
+		eth1.Name = "eth1"
+		eth1.HardwareAddr, _ = net.ParseMAC(testMAC1)
+		netlinkData.LinkAdd(eth1)
+		os.Setenv(envNodePortSupport, "true")
+		defer os.Setenv(envNodePortSupport, "false")
 		testSubnet1 := "10.10.1.0/24"
+		api := NewFakeNetworkAPI(netlinkData, ipt, netlinkData.FindPrimaryInterfaceName, setProcSys)
+		Expect(api.SetupHostNetwork(testVPC, []*string{&testSubnet1}, testMAC, &testNICIP)).ShouldNot(HaveOccurred())
+		Expect(api.SetupNICNetwork(testIP, testMAC1, 2, "10.0.10.0/24")).ShouldNot(HaveOccurred())
+		addr, _ := netlink.ParseAddr("10.0.10.3/24")
+		netlinkData.AddrAdd(eth1, addr)
+		rules, _ := netlinkData.RuleList(0)
+		nat := make(map[string][]iptables.IptablesRule)
+		for chain, rules := range ipt.Data["nat"] {
+			nat[chain] = append([]iptables.IptablesRule{}, rules...)
+		}
+		ipt.Cleared = nil
+
+		api = NewFakeNetworkAPI(netlinkData, ipt, netlinkData.FindPrimaryInterfaceName, setProcSys)
+		Expect(api.SetupHostNetwork(testVPC, []*string{&testSubnet1}, testMAC, &testNICIP)).ShouldNot(HaveOccurred())
+		Expect(api.SetupNICNetwork(testIP, testMAC1, 2, "10.0.10.0/24")).ShouldNot(HaveOccurred())
+		Expect(ipt.Cleared).To(BeEmpty())
+		Expect(ipt.Data["nat"]).To(Equal(nat))
+		Expect(netlinkData.RuleList(0)).To(ConsistOf(rules))
+		Expect(netlinkData.AddrList(eth1, 0)).To(HaveLen(1))
+		Expect(netlinkData.Routes).To(HaveLen(2))
+
+		// the chains are rebuilt if the vpc changes
 		testSubnet2 := "10.10.2.0/24"
-		err := api.SetupHostNetwork(testVPC, []*string{&testSubnet1, &testSubnet2}, testMAC, &testNICIP)
-		Expect(err).ShouldNot(HaveOccurred())
+		Expect(api.SetupHostNetwork(testVPC, []*string{&testSubnet1, &testSubnet2}, testMAC, &testNICIP)).ShouldNot(HaveOccurred())
+		Expect(ipt.Cleared).To(ContainElement(iptables.Chain{Table: "nat", Name: "QINGCLOUD-SNAT-CHAIN-1"}))
+		Expect(ipt.Data["nat"]).To(HaveKey("QINGCLOUD-SNAT-CHAIN-2"))
+		Expect(ipt.Data["nat"]["QINGCLOUD-SNAT-CHAIN-1"]).To(HaveLen(1))
+		Expect(ipt.Data["nat"]["QINGCLOUD-SNAT-CHAIN-1"][0].Rule).To(ContainElement("QINGCLOUD-SNAT-CHAIN-2"))
+	})
 
-		//nat chains check
-		Expect(iptablesData.Data["nat"]).To(MatchAllKeys(
-			Keys{
-				"POSTROUTING":            HaveLen(1),
-				"QINGCLOUD-SNAT-CHAIN-0": HaveLen(1),
-				"QINGCLOUD-SNAT-CHAIN-1": HaveLen(1),
-				"QINGCLOUD-SNAT-CHAIN-2": HaveLen(1),
-			},
-		))
-		Expect(iptablesData.Data["nat"]["POSTROUTING"][0].Rule).To(Equal([]string{"-m", "comment", "--comment", "QINGCLOUD SNAT CHAIN", "-j", "QINGCLOUD-SNAT-CHAIN-0"}))
-		Expect(iptablesData.Data["nat"]["QINGCLOUD-SNAT-CHAIN-0"][0].Rule).To(Equal([]string{
-			"!", "-d", "10.10.1.0/24", "-m", "comment", "--comment", "QINGCLOUD SNAT CHAN", "-j", "QINGCLOUD-SNAT-CHAIN-1",
-		}))
-		Expect(iptablesData.Data["nat"]["QINGCLOUD-SNAT-CHAIN-1"][0].Rule).To(Equal([]string{
-			"!", "-d", "10.10.2.0/24", "-m", "comment", "--comment", "QINGCLOUD SNAT CHAN", "-j", "QINGCLOUD-SNAT-CHAIN-2",
-		}))
-		Expect(iptablesData.Data["nat"]["QINGCLOUD-SNAT-CHAIN-2"][0].Rule).To(Equal([]string{"-m", "comment", "--comment", "QINGCLOUD, SNAT",
-			"-m", "addrtype", "!", "--dst-type", "LOCAL",
-			"-j", "SNAT", "--to-source", testnicIP, "--random"}))
+	It("Should load host rules into nftables in one table", func() {
+		nft := iptables.NewFakeNftables()
+		netlinkData := fakenetlink.NewFakeNetlink()
 
-		// filter chain check
-		Expect(iptablesData.Data["filter"]).To(MatchAllKeys(
-			Keys{
-				"FORWARD": HaveLen(2),
-			},
-		))
-		Expect(iptablesData.Data["filter"]["FORWARD"][0].Rule).To(Equal([]string{"-i", "nic+", "-j", "ACCEPT"}))
-		Expect(iptablesData.Data["filter"]["FORWARD"][1].Rule).To(Equal([]string{"-o", "nic+", "-j", "ACCEPT"}))
-
-		// mangle chain check
-		Expect(iptablesData.Data["mangle"]).To(HaveLen(0))
+		eth0 := &netlink.Device{
+			LinkAttrs: netlink.NewLinkAttrs(),
+		}
+		eth0.Name = "eth0"
+		eth0.HardwareAddr = net.HardwareAddr(testMAC)
+		netlinkData.LinkAdd(eth0)
+		os.Setenv(envNodePortSupport, "true")
+		api := NewFakeNetworkAPIWithBackend(netlinkData, iptables.NewNftablesBackend(nft), netlinkData.FindPrimaryInterfaceName, setProcSys)
+		testSubnet1 := "10.10.1.0/24"
+		Expect(api.SetupHostNetwork(testVPC, []*string{&testSubnet1}, testMAC, &testNICIP)).ShouldNot(HaveOccurred())
+		Expect(nft.Script).To(Equal(`add table ip hostnic
+delete table ip hostnic
+table ip hostnic {
+	chain QINGCLOUD-SNAT-CHAIN-0 {
+		ip daddr != 10.10.1.0/24 jump QINGCLOUD-SNAT-CHAIN-1 comment "QINGCLOUD SNAT CHAN"
+	}
+	chain QINGCLOUD-SNAT-CHAIN-1 {
+		fib daddr type != local snat to 10.10.10.20 random comment "QINGCLOUD, SNAT"
+	}
+	chain nat-postrouting {
+		type nat hook postrouting priority 100; policy accept;
+		jump QINGCLOUD-SNAT-CHAIN-0 comment "QINGCLOUD SNAT CHAIN"
+	}
+	chain filter-forward {
+		type filter hook forward priority 0; policy accept;
+		iifname "nic*" accept
+		oifname "nic*" accept
+	}
+	chain mangle-prerouting {
+		type filter hook prerouting priority -150; policy accept;
+		iifname "eth0" fib daddr . iif type local ct mark set ct mark and 0xffffff7f or 0x80 comment "QINGCLOUD, primary NIC"
+		iifname "nic*" meta mark set ct mark and 0x80 comment "QINGCLOUD, primary NIC"
+	}
+}
+`))
 	})
 
 	It("Should setup nic network properly", func() {
@@ -177,6 +435,7 @@ var _ = Describe("Networkutils", func() {
 		}
 		eth0.Name = "eth0"
 		eth0.HardwareAddr, _ = net.ParseMAC(testMAC)
+		eth0.MTU = 1450
 		netlinkData.LinkAdd(eth0)
 
 		eth1 := &netlink.Device{
@@ -185,9 +444,13 @@ var _ = Describe("Networkutils", func() {
 		eth1.Name = "eth1"
 		eth1.HardwareAddr, _ = net.ParseMAC(testMAC1)
 		netlinkData.LinkAdd(eth1)
+		os.Setenv(envNodePortSupport, "false")
 		api := NewFakeNetworkAPI(netlinkData, iptablesData, netlinkData.FindPrimaryInterfaceName, setProcSys)
+		Expect(api.GetMTU()).To(Equal(defaultMTU))
+		Expect(api.SetupHostNetwork(testVPC, []*string{}, testMAC, &testNICIP)).ShouldNot(HaveOccurred())
+		Expect(api.GetMTU()).To(Equal(1450))
 		Expect(api.SetupNICNetwork(testIP, testMAC1, 2, "10.0.10.0/24")).ShouldNot(HaveOccurred())
-		Expect(eth1.MTU).To(Equal(ethernetMTU))
+		Expect(eth1.MTU).To(Equal(1450))
 		Expect(eth1.Flags | net.FlagUp).To(Equal(eth1.Flags))
 
 		Expect(netlinkData.Routes).To(HaveLen(2))
@@ -195,6 +458,126 @@ var _ = Describe("Networkutils", func() {
 		Expect(netlinkData.Routes["<nil>+10.0.10.1/32"].String()).To(Equal("{Ifindex: 0 Dst: 10.0.10.1/32 Src: <nil> Gw: <nil> Flags: [] Table: 2}"))
 	})
 
+	It("Should SNAT traffic leaving a nic to its address in per-NIC SNAT mode", func() {
+		iptablesData := iptables.NewFakeIPTables()
+		netlinkData := fakenetlink.NewFakeNetlink()
+
+		eth0 := &netlink.Device{
+			LinkAttrs: netlink.NewLinkAttrs(),
+		}
+		eth0.Name = "eth0"
+		eth0.HardwareAddr = net.HardwareAddr(testMAC)
+		netlinkData.LinkAdd(eth0)
+		eth1 := &netlink.Device{
+			LinkAttrs: netlink.NewLinkAttrs(),
+		}
+		eth1.Name = "eth1"
+		eth1.HardwareAddr, _ = net.ParseMAC(testMAC1)
+		netlinkData.LinkAdd(eth1)
+
+		os.Setenv(envNodePortSupport, "false")
+		os.Setenv(envSNATMode, string(nicSNATMode))
+		defer os.Unsetenv(envSNATMode)
+		api := NewFakeNetworkAPI(netlinkData, iptablesData, netlinkData.FindPrimaryInterfaceName, setProcSys)
+		Expect(api.UsePerNICSNAT()).To(BeTrue())
+		testSubnet1 := "10.10.1.0/24"
+		Expect(api.SetupHostNetwork(testVPC, []*string{&testSubnet1}, testMAC, &testNICIP)).ShouldNot(HaveOccurred())
+		Expect(api.SetupNICNetwork(testIP, testMAC1, 2, "10.0.10.0/24")).ShouldNot(HaveOccurred())
+
+		Expect(iptablesData.Data["nat"]["QINGCLOUD-SNAT-CHAIN-1"]).To(HaveLen(2))
+		Expect(iptablesData.Data["nat"]["QINGCLOUD-SNAT-CHAIN-1"][0].Rule).To(Equal([]string{
+			"-o", "eth1", "-m", "comment", "--comment", "QINGCLOUD, NIC SNAT", "-j", "SNAT", "--to-source", testIP,
+		}))
+		Expect(iptablesData.Data["nat"]["QINGCLOUD-SNAT-CHAIN-1"][1].Rule).To(Equal([]string{"-o", "eth0",
+			"-m", "comment", "--comment", "QINGCLOUD, SNAT",
+			"-m", "addrtype", "!", "--dst-type", "LOCAL",
+			"-j", "SNAT", "--to-source", testnicIP, "--random"}))
+		// NodePort replies must not follow the pod to its nic even if NodePort support is disabled
+		Expect(iptablesData.Data["mangle"]["PREROUTING"]).To(HaveLen(2))
+		rules, _ := netlinkData.RuleList(0)
+		Expect(rules).To(HaveLen(1))
+		Expect(rules[0].Priority).To(Equal(hostRulePriority))
+
+		podIP := net.IPNet{IP: net.ParseIP("10.0.10.5"), Mask: net.CIDRMask(32, 32)}
+		Expect(api.EnsurePodRules(nil, podIP, []string{testSubnet1}, 2)).ShouldNot(HaveOccurred())
+		Expect(netlinkData.Rules).To(HaveKey(fakenetlink.KeyForRule(&netlink.Rule{
+			Src:      &podIP,
+			Table:    2,
+			Priority: fromPodNICRulePriority,
+		})))
+
+		Expect(api.TeardownNICNetwork(testMAC1)).ShouldNot(HaveOccurred())
+		Expect(iptablesData.Data["nat"]["QINGCLOUD-SNAT-CHAIN-1"]).To(HaveLen(1))
+	})
+
+	It("Should only ensure the pod rules the plugin adds when external SNAT is used", func() {
+		os.Setenv(envExternalSNAT, "true")
+		defer os.Unsetenv(envExternalSNAT)
+		netlinkData := fakenetlink.NewFakeNetlink()
+		api := NewFakeNetworkAPI(netlinkData, iptables.NewFakeIPTables(), netlinkData.FindPrimaryInterfaceName, setProcSys)
+		testSubnet1 := "10.10.1.0/24"
+		podIP := net.IPNet{IP: net.ParseIP("10.10.1.5"), Mask: net.CIDRMask(32, 32)}
+		Expect(api.EnsurePodRules(nil, podIP, []string{testSubnet1}, 2)).ShouldNot(HaveOccurred())
+		_, vpcNet, _ := net.ParseCIDR(testSubnet1)
+		Expect(netlinkData.Rules).To(HaveLen(2))
+		Expect(netlinkData.Rules).To(HaveKey(fakenetlink.KeyForRule(&netlink.Rule{
+			Src:      &podIP,
+			Dst:      vpcNet,
+			Table:    2,
+			Priority: fromPodRulePriority,
+		})))
+	})
+
+	It("Should set up nic network again when it drifts", func() {
+		iptablesData := iptables.NewFakeIPTables()
+		netlinkData := fakenetlink.NewFakeNetlink()
+
+		eth1 := &netlink.Device{
+			LinkAttrs: netlink.NewLinkAttrs(),
+		}
+		eth1.Name = "eth1"
+		eth1.HardwareAddr, _ = net.ParseMAC(testMAC1)
+		netlinkData.LinkAdd(eth1)
+		api := NewFakeNetworkAPI(netlinkData, iptablesData, netlinkData.FindPrimaryInterfaceName, setProcSys)
+		Expect(api.SetupNICNetwork(testIP, testMAC1, 2, "10.0.10.0/24")).ShouldNot(HaveOccurred())
+		Expect(api.ReconcileNICNetwork(testIP, testMAC1, 2, "10.0.10.0/24")).ShouldNot(HaveOccurred())
+		Expect(netlinkData.Routes).To(HaveLen(2))
+
+		delete(netlinkData.Routes, "<nil>+0.0.0.0/0")
+		Expect(api.ReconcileNICNetwork(testIP, testMAC1, 2, "10.0.10.0/24")).ShouldNot(HaveOccurred())
+		Expect(netlinkData.Routes).To(HaveLen(2))
+		Expect(netlinkData.Routes["<nil>+0.0.0.0/0"].String()).To(Equal("{Ifindex: 0 Dst: 0.0.0.0/0 Src: <nil> Gw: 10.0.10.1 Flags: [] Table: 2}"))
+
+		netlinkData.LinkSetMTU(eth1, 1400)
+		Expect(api.ReconcileNICNetwork(testIP, testMAC1, 2, "10.0.10.0/24")).ShouldNot(HaveOccurred())
+		Expect(eth1.MTU).To(Equal(defaultMTU))
+	})
+
+	It("Should use the MTU in config instead of the one of primary nic", func() {
+		iptablesData := iptables.NewFakeIPTables()
+		netlinkData := fakenetlink.NewFakeNetlink()
+
+		eth0 := &netlink.Device{
+			LinkAttrs: netlink.NewLinkAttrs(),
+		}
+		eth0.Name = "eth0"
+		eth0.HardwareAddr, _ = net.ParseMAC(testMAC)
+		eth0.MTU = testMTU
+		netlinkData.LinkAdd(eth0)
+
+		os.Setenv(envNodePortSupport, "false")
+		os.Setenv(envMTU, "1400")
+		defer os.Unsetenv(envMTU)
+		api := NewFakeNetworkAPI(netlinkData, iptablesData, netlinkData.FindPrimaryInterfaceName, setProcSys)
+		Expect(api.SetupHostNetwork(testVPC, []*string{}, testMAC, &testNICIP)).ShouldNot(HaveOccurred())
+		Expect(api.GetMTU()).To(Equal(1400))
+
+		os.Setenv(envMTU, "invalid")
+		api = NewFakeNetworkAPI(netlinkData, iptablesData, netlinkData.FindPrimaryInterfaceName, setProcSys)
+		Expect(api.SetupHostNetwork(testVPC, []*string{}, testMAC, &testNICIP)).ShouldNot(HaveOccurred())
+		Expect(api.GetMTU()).To(Equal(testMTU))
+	})
+
 	It("Can get rule list by source", func() {
 		iptablesData := iptables.NewFakeIPTables()
 		netlinkData := fakenetlink.NewFakeNetlink()
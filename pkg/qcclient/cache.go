@@ -0,0 +1,359 @@
+package qcclient
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/yunify/hostnic-cni/pkg/types"
+	"k8s.io/klog"
+)
+
+const (
+	// envCacheTTLs enables caching cloud metadata and sets the ttl of each kind of resource, in the format of
+	// vpc=10m,vxnet=5m,tag=1m. The resources not in it are not cached.
+	envCacheTTLs = "HOSTNIC_CACHE_TTLS"
+
+	cacheKeyVPC       = "vpc/"
+	cacheKeyNodeVPC   = "node-vpc"
+	cacheKeyVPCVxNets = "vpc-vxnets/"
+	cacheKeyVxNet     = "vxnet/"
+	cacheKeyVxNetName = "vxnet-name/"
+	cacheKeyTag       = "tag/"
+	cacheKeyTagLabel  = "tag-label/"
+)
+
+var (
+	cacheRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "yunify_nic_cloud_cache_requests_total",
+			Help: "The number of lookups of cloud metadata in cache",
+		},
+		[]string{"resource", "result"},
+	)
+	cachePrometheusRegistered = false
+	cachePrometheusLock       sync.Mutex
+)
+
+func cachePrometheusRegister() {
+	cachePrometheusLock.Lock()
+	defer cachePrometheusLock.Unlock()
+	if !cachePrometheusRegistered {
+		prometheus.MustRegister(cacheRequests)
+		cachePrometheusRegistered = true
+	}
+}
+
+// CacheConfig is the ttl of each kind of cached resource, zero disables caching the resource
+type CacheConfig struct {
+	VPC   time.Duration
+	VxNet time.Duration
+	Tag   time.Duration
+}
+
+// Enabled checks whether any resource is cached
+func (c CacheConfig) Enabled() bool {
+	return c.VPC > 0 || c.VxNet > 0 || c.Tag > 0
+}
+
+// ParseCacheConfig parses the config in the format of vpc=10m,vxnet=5m,tag=1m, invalid items are ignored
+func ParseCacheConfig(value string) CacheConfig {
+	var config CacheConfig
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 {
+			klog.Errorf("Ignore invalid cache ttl %q", item)
+			continue
+		}
+		ttl, err := time.ParseDuration(strings.TrimSpace(parts[1]))
+		if err != nil || ttl < 0 {
+			klog.Errorf("Ignore invalid cache ttl %q", item)
+			continue
+		}
+		switch strings.TrimSpace(parts[0]) {
+		case string(types.ResourceTypeVPC):
+			config.VPC = ttl
+		case string(types.ResourceTypeVxnet):
+			config.VxNet = ttl
+		case string(types.ResourceTypeTag):
+			config.Tag = ttl
+		default:
+			klog.Errorf("Ignore cache ttl of unknown resource %q", item)
+		}
+	}
+	return config
+}
+
+type cacheEntry struct {
+	value  interface{}
+	expire time.Time
+}
+
+// cachedQingCloudAPI caches the vpc, vxnets and tags read from the QingCloudAPI it wraps. Mutating calls
+// invalidate the entries they may change, the other calls go to the wrapped api directly.
+type cachedQingCloudAPI struct {
+	QingCloudAPI
+	config CacheConfig
+
+	lock    sync.Mutex
+	entries map[string]cacheEntry
+}
+
+var _ QingCloudAPI = &cachedQingCloudAPI{}
+
+// NewCachedQingCloudAPI wraps a QingCloudAPI with a cache of cloud metadata
+func NewCachedQingCloudAPI(api QingCloudAPI, config CacheConfig) QingCloudAPI {
+	cachePrometheusRegister()
+	return &cachedQingCloudAPI{
+		QingCloudAPI: api,
+		config:       config,
+		entries:      make(map[string]cacheEntry),
+	}
+}
+
+func (c *cachedQingCloudAPI) get(resource types.ResourceType, key string) (interface{}, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	entry, ok := c.entries[key]
+	if ok && time.Now().After(entry.expire) {
+		delete(c.entries, key)
+		ok = false
+	}
+	if ok {
+		cacheRequests.WithLabelValues(string(resource), "hit").Inc()
+		return entry.value, true
+	}
+	cacheRequests.WithLabelValues(string(resource), "miss").Inc()
+	return nil, false
+}
+
+func (c *cachedQingCloudAPI) set(key string, value interface{}, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.entries[key] = cacheEntry{value: value, expire: time.Now().Add(ttl)}
+}
+
+// invalidate deletes the entries of the keys. A key ending with "/" deletes all the entries of the kind.
+func (c *cachedQingCloudAPI) invalidate(keys ...string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, key := range keys {
+		if !strings.HasSuffix(key, "/") {
+			delete(c.entries, key)
+			continue
+		}
+		for k := range c.entries {
+			if strings.HasPrefix(k, key) {
+				delete(c.entries, k)
+			}
+		}
+	}
+}
+
+// The cached values are copied before they are returned, because callers may modify them
+
+func copyVxNet(vxnet *types.VxNet) *types.VxNet {
+	if vxnet == nil {
+		return nil
+	}
+	result := *vxnet
+	result.Network = copyIPNet(vxnet.Network)
+	return &result
+}
+
+func copyVxNets(vxnets []*types.VxNet) []*types.VxNet {
+	if vxnets == nil {
+		return nil
+	}
+	result := make([]*types.VxNet, 0, len(vxnets))
+	for _, vxnet := range vxnets {
+		result = append(result, copyVxNet(vxnet))
+	}
+	return result
+}
+
+func copyVPC(vpc *types.VPC) *types.VPC {
+	if vpc == nil {
+		return nil
+	}
+	result := *vpc
+	result.Network = copyIPNet(vpc.Network)
+	result.VxNets = copyVxNets(vpc.VxNets)
+	return &result
+}
+
+func copyIPNet(ipnet *net.IPNet) *net.IPNet {
+	if ipnet == nil {
+		return nil
+	}
+	return &net.IPNet{
+		IP:   append(net.IP(nil), ipnet.IP...),
+		Mask: append(net.IPMask(nil), ipnet.Mask...),
+	}
+}
+
+func copyTag(tag *types.Tag) *types.Tag {
+	if tag == nil {
+		return nil
+	}
+	result := *tag
+	result.TaggedResources = make([]*types.TaggedResource, 0, len(tag.TaggedResources))
+	for _, resource := range tag.TaggedResources {
+		r := *resource
+		result.TaggedResources = append(result.TaggedResources, &r)
+	}
+	return &result
+}
+
+func (c *cachedQingCloudAPI) GetVxNet(vxNet string) (*types.VxNet, error) {
+	if value, ok := c.get(types.ResourceTypeVxnet, cacheKeyVxNet+vxNet); ok {
+		return copyVxNet(value.(*types.VxNet)), nil
+	}
+	result, err := c.QingCloudAPI.GetVxNet(vxNet)
+	if err != nil {
+		return nil, err
+	}
+	c.set(cacheKeyVxNet+vxNet, copyVxNet(result), c.config.VxNet)
+	return result, nil
+}
+
+func (c *cachedQingCloudAPI) GetVxNets(vxNets []string) ([]*types.VxNet, error) {
+	result := make([]*types.VxNet, 0, len(vxNets))
+	for _, id := range vxNets {
+		value, ok := c.get(types.ResourceTypeVxnet, cacheKeyVxNet+id)
+		if !ok {
+			result = nil
+			break
+		}
+		result = append(result, copyVxNet(value.(*types.VxNet)))
+	}
+	if result != nil {
+		return result, nil
+	}
+	result, err := c.QingCloudAPI.GetVxNets(vxNets)
+	if err != nil {
+		return nil, err
+	}
+	for _, vxnet := range result {
+		c.set(cacheKeyVxNet+vxnet.ID, copyVxNet(vxnet), c.config.VxNet)
+	}
+	return result, nil
+}
+
+func (c *cachedQingCloudAPI) GetVxNetByName(name string) (*types.VxNet, error) {
+	if value, ok := c.get(types.ResourceTypeVxnet, cacheKeyVxNetName+name); ok {
+		return copyVxNet(value.(*types.VxNet)), nil
+	}
+	result, err := c.QingCloudAPI.GetVxNetByName(name)
+	if err != nil {
+		return nil, err
+	}
+	c.set(cacheKeyVxNetName+name, copyVxNet(result), c.config.VxNet)
+	return result, nil
+}
+
+func (c *cachedQingCloudAPI) CreateVxNet(name string) (*types.VxNet, error) {
+	defer c.invalidate(cacheKeyVxNetName + name)
+	return c.QingCloudAPI.CreateVxNet(name)
+}
+
+func (c *cachedQingCloudAPI) DeleteVxNet(id string) error {
+	defer c.invalidate(cacheKeyVxNet+id, cacheKeyVxNetName, cacheKeyVPCVxNets, cacheKeyVPC, cacheKeyNodeVPC)
+	return c.QingCloudAPI.DeleteVxNet(id)
+}
+
+func (c *cachedQingCloudAPI) GetVPC(ctx context.Context, id string) (*types.VPC, error) {
+	if value, ok := c.get(types.ResourceTypeVPC, cacheKeyVPC+id); ok {
+		return copyVPC(value.(*types.VPC)), nil
+	}
+	result, err := c.QingCloudAPI.GetVPC(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	c.set(cacheKeyVPC+id, copyVPC(result), c.config.VPC)
+	return result, nil
+}
+
+func (c *cachedQingCloudAPI) GetNodeVPC(ctx context.Context) (*types.VPC, error) {
+	if value, ok := c.get(types.ResourceTypeVPC, cacheKeyNodeVPC); ok {
+		return copyVPC(value.(*types.VPC)), nil
+	}
+	result, err := c.QingCloudAPI.GetNodeVPC(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.set(cacheKeyNodeVPC, copyVPC(result), c.config.VPC)
+	return result, nil
+}
+
+func (c *cachedQingCloudAPI) GetVPCVxNets(id string) ([]*types.VxNet, error) {
+	if value, ok := c.get(types.ResourceTypeVPC, cacheKeyVPCVxNets+id); ok {
+		return copyVxNets(value.([]*types.VxNet)), nil
+	}
+	result, err := c.QingCloudAPI.GetVPCVxNets(id)
+	if err != nil {
+		return nil, err
+	}
+	c.set(cacheKeyVPCVxNets+id, copyVxNets(result), c.config.VPC)
+	return result, nil
+}
+
+// invalidateVPC invalidates the entries changed when a vxnet joins or leaves a vpc
+func (c *cachedQingCloudAPI) invalidateVPC(vxnetID, vpcID string) {
+	c.invalidate(cacheKeyVxNet+vxnetID, cacheKeyVxNetName, cacheKeyVPC+vpcID, cacheKeyVPCVxNets+vpcID, cacheKeyNodeVPC)
+}
+
+func (c *cachedQingCloudAPI) JoinVPC(network, vxnetID, vpcID string) error {
+	defer c.invalidateVPC(vxnetID, vpcID)
+	return c.QingCloudAPI.JoinVPC(network, vxnetID, vpcID)
+}
+
+func (c *cachedQingCloudAPI) LeaveVPC(vxnetID, vpcID string) error {
+	defer c.invalidateVPC(vxnetID, vpcID)
+	return c.QingCloudAPI.LeaveVPC(vxnetID, vpcID)
+}
+
+func (c *cachedQingCloudAPI) GetTagByLabel(label string) (*types.Tag, error) {
+	if value, ok := c.get(types.ResourceTypeTag, cacheKeyTagLabel+label); ok {
+		return copyTag(value.(*types.Tag)), nil
+	}
+	result, err := c.QingCloudAPI.GetTagByLabel(label)
+	if err != nil {
+		return nil, err
+	}
+	c.set(cacheKeyTagLabel+label, copyTag(result), c.config.Tag)
+	return result, nil
+}
+
+func (c *cachedQingCloudAPI) GetTagByID(id string) (*types.Tag, error) {
+	if value, ok := c.get(types.ResourceTypeTag, cacheKeyTag+id); ok {
+		return copyTag(value.(*types.Tag)), nil
+	}
+	result, err := c.QingCloudAPI.GetTagByID(id)
+	if err != nil {
+		return nil, err
+	}
+	c.set(cacheKeyTag+id, copyTag(result), c.config.Tag)
+	return result, nil
+}
+
+func (c *cachedQingCloudAPI) CreateTag(label, color string) (string, error) {
+	defer c.invalidate(cacheKeyTagLabel + label)
+	return c.QingCloudAPI.CreateTag(label, color)
+}
+
+func (c *cachedQingCloudAPI) TagResources(tagid string, resourceType types.ResourceType, ids ...string) error {
+	// the tagged resources of the tag change, and the label of it is unknown here
+	defer c.invalidate(cacheKeyTag+tagid, cacheKeyTagLabel)
+	return c.QingCloudAPI.TagResources(tagid, resourceType, ids...)
+}
@@ -0,0 +1,99 @@
+package qcclient
+
+import (
+	"context"
+	"net"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/yunify/hostnic-cni/pkg/types"
+)
+
+func cacheRequestCount(resource types.ResourceType, result string) float64 {
+	metric := &dto.Metric{}
+	Expect(cacheRequests.WithLabelValues(string(resource), result).Write(metric)).ShouldNot(HaveOccurred())
+	return metric.GetCounter().GetValue()
+}
+
+var _ = Describe("Cache", func() {
+	var (
+		fake   *FakeQingCloudAPI
+		cached QingCloudAPI
+	)
+
+	BeforeEach(func() {
+		_, n, _ := net.ParseCIDR("192.168.0.0/16")
+		fake = NewFakeQingCloudAPI("i-fake", &types.VPC{ID: "rtr-fake", Network: n})
+		fake.VxNets["vxnet-a"] = &types.VxNet{ID: "vxnet-a", Name: "a"}
+		cached = NewCachedQingCloudAPI(fake, ParseCacheConfig("vpc=1m, vxnet=1m, tag=bad, eip=1m"))
+	})
+
+	It("Should parse ttls of resources", func() {
+		Expect(ParseCacheConfig("vpc=10m,vxnet=5m,tag=1m")).To(Equal(CacheConfig{
+			VPC:   10 * time.Minute,
+			VxNet: 5 * time.Minute,
+			Tag:   time.Minute,
+		}))
+		Expect(ParseCacheConfig("vpc=-1m,nic=1m,tag").Enabled()).To(BeFalse())
+	})
+
+	It("Should cache vxnets until they are changed", func() {
+		hits := cacheRequestCount(types.ResourceTypeVxnet, "hit")
+		misses := cacheRequestCount(types.ResourceTypeVxnet, "miss")
+		vxnet, err := cached.GetVxNet("vxnet-a")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(vxnet.Name).To(Equal("a"))
+		// callers can not change the cache
+		vxnet.Name = "changed"
+		fake.VxNets["vxnet-a"] = &types.VxNet{ID: "vxnet-a", Name: "b"}
+		vxnet, err = cached.GetVxNet("vxnet-a")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(vxnet.Name).To(Equal("a"))
+		Expect(cacheRequestCount(types.ResourceTypeVxnet, "hit") - hits).To(BeEquivalentTo(1))
+		Expect(cacheRequestCount(types.ResourceTypeVxnet, "miss") - misses).To(BeEquivalentTo(1))
+
+		Expect(cached.JoinVPC("192.168.1.0/24", "vxnet-a", "rtr-fake")).ShouldNot(HaveOccurred())
+		vxnet, err = cached.GetVxNet("vxnet-a")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(vxnet.Name).To(Equal("b"))
+		Expect(vxnet.RouterID).To(Equal("rtr-fake"))
+	})
+
+	It("Should invalidate vxnets of the vpc when a vxnet joins it", func() {
+		vxnets, err := cached.GetVPCVxNets("rtr-fake")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(vxnets).To(BeEmpty())
+		vpc, err := cached.GetNodeVPC(context.Background())
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(vpc.VxNets).To(BeEmpty())
+		vpc.VxNets = append(vpc.VxNets, &types.VxNet{ID: "vxnet-local"})
+
+		vpc, err = cached.GetNodeVPC(context.Background())
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(vpc.VxNets).To(BeEmpty())
+		vpc.Network.IP[0] = 10
+		vpc, _ = cached.GetNodeVPC(context.Background())
+		Expect(vpc.Network.String()).To(Equal("192.168.0.0/16"))
+
+		Expect(cached.JoinVPC("192.168.1.0/24", "vxnet-a", "rtr-fake")).ShouldNot(HaveOccurred())
+		vxnets, err = cached.GetVPCVxNets("rtr-fake")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(vxnets).To(HaveLen(1))
+		vpc, err = cached.GetNodeVPC(context.Background())
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(vpc.VxNets).To(HaveLen(1))
+	})
+
+	It("Should not cache the resources without ttl", func() {
+		fake.Tags = map[string]*types.Tag{"tag-1": {ID: "tag-1", Label: "label"}}
+		tag, err := cached.GetTagByID("tag-1")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(tag.Label).To(Equal("label"))
+		fake.Tags["tag-1"] = &types.Tag{ID: "tag-1", Label: "changed"}
+		tag, err = cached.GetTagByID("tag-1")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(tag.Label).To(Equal("changed"))
+	})
+})
@@ -1,6 +1,7 @@
 package qcclient
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
 	"net"
@@ -19,9 +20,12 @@ type FakeQingCloudAPI struct {
 	Nics       map[string]*types.HostNic
 	VxNets     map[string]*types.VxNet
 	VPC        *types.VPC
+	EIPs       map[string]*types.EIP
 
 	Tags             map[string]*types.Tag
 	AfterCreatingNIC func(*types.HostNic) error
+	// PrivateIPsLimit is the maximum number of secondary private ips of a nic, zero means no limit
+	PrivateIPsLimit int
 }
 
 func NewFakeQingCloudAPI(instanceID string, vpc *types.VPC) *FakeQingCloudAPI {
@@ -29,6 +33,7 @@ func NewFakeQingCloudAPI(instanceID string, vpc *types.VPC) *FakeQingCloudAPI {
 		InstanceID: instanceID,
 		Nics:       make(map[string]*types.HostNic),
 		VxNets:     make(map[string]*types.VxNet),
+		EIPs:       make(map[string]*types.EIP),
 		VPC:        vpc,
 	}
 }
@@ -44,9 +49,7 @@ func generateMAC() string {
 	return fmt.Sprintf("%02x:%02x:%02x:%02x:%02x:%02x", buf[0], buf[1], buf[2], buf[3], buf[4], buf[5])
 }
 
-func (f *FakeQingCloudAPI) CreateNic(vxnet string) (*types.HostNic, error) {
-	v := f.VxNets[vxnet]
-	var ip net.IP
+func (f *FakeQingCloudAPI) freeAddress(v *types.VxNet) string {
 	n := v.Network.IP.To4()
 	for {
 		i := rand.Int31n(253) + 2
@@ -59,17 +62,26 @@ func (f *FakeQingCloudAPI) CreateNic(vxnet string) (*types.HostNic, error) {
 				notgood = true
 				break
 			}
+			for _, addr := range nic.SecondaryAddresses {
+				if addr == dup.String() {
+					notgood = true
+					break
+				}
+			}
 		}
 		if !notgood {
-			ip = dup
-			break
+			return dup.String()
 		}
 	}
+}
+
+func (f *FakeQingCloudAPI) CreateNic(_ context.Context, vxnet string) (*types.HostNic, error) {
+	v := f.VxNets[vxnet]
 	mac := generateMAC()
 	nic := &types.HostNic{
 		ID:           mac,
 		VxNet:        v,
-		Address:      ip.String(),
+		Address:      f.freeAddress(v),
 		HardwareAddr: mac,
 		DeviceNumber: len(f.Nics),
 		IsPrimary:    false,
@@ -82,6 +94,23 @@ func (f *FakeQingCloudAPI) CreateNic(vxnet string) (*types.HostNic, error) {
 	return nic, nil
 }
 
+func (f *FakeQingCloudAPI) CreateNics(ctx context.Context, vxnet string, count int) ([]*types.HostNic, error) {
+	result := make([]*types.HostNic, 0, count)
+	var err error
+	for i := 0; i < count; i++ {
+		var nic *types.HostNic
+		nic, err = f.CreateNic(ctx, vxnet)
+		if err != nil {
+			continue
+		}
+		result = append(result, nic)
+	}
+	if len(result) == 0 {
+		return nil, err
+	}
+	return result, err
+}
+
 func (f *FakeQingCloudAPI) DeleteNic(nicID string) error {
 	delete(f.Nics, nicID)
 	return nil
@@ -153,13 +182,13 @@ func (f *FakeQingCloudAPI) GetAttachedNICs(vxnet string) ([]*types.HostNic, erro
 	return result, nil
 }
 
-func (f *FakeQingCloudAPI) GetVPC(string) (*types.VPC, error) {
+func (f *FakeQingCloudAPI) GetVPC(_ context.Context, _ string) (*types.VPC, error) {
 	f.VPC.VxNets, _ = f.GetVPCVxNets(f.VPC.ID)
 	return f.VPC, nil
 }
 
-func (f *FakeQingCloudAPI) GetNodeVPC() (*types.VPC, error) {
-	return f.GetVPC(f.InstanceID)
+func (f *FakeQingCloudAPI) GetNodeVPC(ctx context.Context) (*types.VPC, error) {
+	return f.GetVPC(ctx, f.InstanceID)
 }
 
 func (f *FakeQingCloudAPI) GetVPCVxNets(routeid string) ([]*types.VxNet, error) {
@@ -187,6 +216,63 @@ func (f *FakeQingCloudAPI) GetInstanceID() string {
 	return f.InstanceID
 }
 
+func (f *FakeQingCloudAPI) GetEIP(eipID string) (*types.EIP, error) {
+	if eip, ok := f.EIPs[eipID]; ok {
+		return eip, nil
+	}
+	return nil, errors.NewResourceNotFoundError(types.ResourceTypeEIP, eipID)
+}
+
+func (f *FakeQingCloudAPI) ApplySecurityGroup(securityGroupID string, nicIDs ...string) error {
+	for _, id := range nicIDs {
+		if _, ok := f.Nics[id]; !ok {
+			return errors.NewResourceNotFoundError(types.ResourceTypeNic, id)
+		}
+	}
+	for _, id := range nicIDs {
+		f.Nics[id].SecurityGroup = securityGroupID
+	}
+	return nil
+}
+
+func (f *FakeQingCloudAPI) AssignPrivateIPs(nicID string, count int) ([]string, error) {
+	nic, ok := f.Nics[nicID]
+	if !ok {
+		return nil, errors.NewResourceNotFoundError(types.ResourceTypeNic, nicID)
+	}
+	if f.PrivateIPsLimit > 0 && len(nic.SecondaryAddresses)+count > f.PrivateIPsLimit {
+		return nil, fmt.Errorf("nic %s can not have more than %d secondary private ips", nicID, f.PrivateIPsLimit)
+	}
+	result := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		addr := f.freeAddress(nic.VxNet)
+		nic.SecondaryAddresses = append(nic.SecondaryAddresses, addr)
+		result = append(result, addr)
+	}
+	return result, nil
+}
+
+func (f *FakeQingCloudAPI) UnassignPrivateIPs(nicID string, ips ...string) error {
+	nic, ok := f.Nics[nicID]
+	if !ok {
+		return errors.NewResourceNotFoundError(types.ResourceTypeNic, nicID)
+	}
+	for _, ip := range ips {
+		found := false
+		for i, addr := range nic.SecondaryAddresses {
+			if addr == ip {
+				nic.SecondaryAddresses = append(nic.SecondaryAddresses[:i], nic.SecondaryAddresses[i+1:]...)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("ip %s is not a secondary private ip of nic %s", ip, nicID)
+		}
+	}
+	return nil
+}
+
 func (f *FakeQingCloudAPI) GetTagByLabel(label string) (*types.Tag, error) {
 	for _, v := range f.Tags {
 		if v.Label == label {
@@ -0,0 +1,871 @@
+package qcclient
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/yunify/qingcloud-sdk-go/service"
+)
+
+// The ret codes of qingcloud returned by the fake server
+const (
+	RetCodeParameterError   = 1100
+	RetCodeAuthFailure      = 1200
+	RetCodePermissionDenied = 1400
+	RetCodeResourceNotFound = 2100
+	RetCodeServerBusy       = 5100
+)
+
+const (
+	fakeServerUserID          = "usr-fake"
+	fakeServerAccessKeyID     = "FAKEACCESSKEYID"
+	fakeServerSecretAccessKey = "fake-secret-access-key"
+	fakeServerZone            = "fake1a"
+)
+
+type fakeJob struct {
+	action string
+	failed bool
+	polls  int
+}
+
+type fakeVxNet struct {
+	vxnet    *service.VxNet
+	network  *net.IPNet
+	gateway  string
+	routerID string
+}
+
+// FakeQingCloudServer is an in-process http server of the qingcloud api. It checks the signatures of requests the
+// same way as qingcloud, and keeps the nics, vxnets, routers, instances, eips, tags and jobs the api of hostnic
+// touches, so that the real client can be tested against it. Errors can be injected into any action.
+type FakeQingCloudServer struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	Zone            string
+	UserID          string
+
+	server *httptest.Server
+
+	lock      sync.Mutex
+	nextID    int
+	routers   map[string]*service.Router
+	vxnets    map[string]*fakeVxNet
+	instances map[string]bool
+	nics      map[string]*service.NIC
+	// privateIPs keeps the secondary private ips of nics
+	privateIPs map[string][]string
+	eips       map[string]*service.EIP
+	tags       map[string]*service.Tag
+	jobs       map[string]*fakeJob
+	// errors keeps the ret codes the next requests of an action fail with
+	errors map[string][]int
+	// jobFailures keeps the number of the next jobs of an action which fail
+	jobFailures map[string]int
+	requests    map[string]int
+}
+
+// NewFakeQingCloudServer starts a fake server, it should be closed after use
+func NewFakeQingCloudServer() *FakeQingCloudServer {
+	s := &FakeQingCloudServer{
+		AccessKeyID:     fakeServerAccessKeyID,
+		SecretAccessKey: fakeServerSecretAccessKey,
+		Zone:            fakeServerZone,
+		UserID:          fakeServerUserID,
+		routers:         make(map[string]*service.Router),
+		vxnets:          make(map[string]*fakeVxNet),
+		instances:       make(map[string]bool),
+		nics:            make(map[string]*service.NIC),
+		privateIPs:      make(map[string][]string),
+		eips:            make(map[string]*service.EIP),
+		tags:            make(map[string]*service.Tag),
+		jobs:            make(map[string]*fakeJob),
+		errors:          make(map[string][]int),
+		jobFailures:     make(map[string]int),
+		requests:        make(map[string]int),
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// Endpoint returns the endpoint of the api
+func (s *FakeQingCloudServer) Endpoint() string {
+	return s.server.URL + "/iaas/"
+}
+
+// ClientOptions returns the options for NewQingCloudClientWithOptions to reach the server as the instance
+func (s *FakeQingCloudServer) ClientOptions(instanceID string) ClientOptions {
+	return ClientOptions{
+		Endpoint:        s.Endpoint(),
+		AccessKeyID:     s.AccessKeyID,
+		SecretAccessKey: s.SecretAccessKey,
+		Zone:            s.Zone,
+		InstanceID:      instanceID,
+	}
+}
+
+// Close shuts down the server
+func (s *FakeQingCloudServer) Close() {
+	s.server.Close()
+}
+
+// AddRouter adds a vpc
+func (s *FakeQingCloudServer) AddRouter(id, network string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.routers[id] = &service.Router{RouterID: service.String(id), VpcNetwork: service.String(network)}
+}
+
+// AddVxNet adds a vxnet, it joins the router if routerID is not empty
+func (s *FakeQingCloudServer) AddVxNet(id, name, routerID, network string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.vxnets[id] = &fakeVxNet{vxnet: &service.VxNet{
+		VxNetID:   service.String(id),
+		VxNetName: service.String(name),
+		VxNetType: service.Int(1),
+		Owner:     service.String(s.UserID),
+	}}
+	if routerID != "" {
+		return s.joinRouter(id, routerID, network)
+	}
+	return nil
+}
+
+// AddInstance adds an instance with its primary nic in the vxnet, and returns the id of the nic
+func (s *FakeQingCloudServer) AddInstance(id, vxnet string) (string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.instances[id] = true
+	nic, err := s.createNic(vxnet, "primary")
+	if err != nil {
+		return "", err
+	}
+	nic.InstanceID = service.String(id)
+	nic.Status = service.String("in-use")
+	nic.Role = service.Int(1)
+	return *nic.NICID, nil
+}
+
+// AddEIP adds an eip
+func (s *FakeQingCloudServer) AddEIP(id, address string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.eips[id] = &service.EIP{EIPID: service.String(id), EIPAddr: service.String(address)}
+}
+
+// NicIDs returns the ids of all nics, attached or not
+func (s *FakeQingCloudServer) NicIDs() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	result := make([]string, 0, len(s.nics))
+	for id := range s.nics {
+		result = append(result, id)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// InjectError makes the next count requests of the action fail with the ret code
+func (s *FakeQingCloudServer) InjectError(action string, retCode, count int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for i := 0; i < count; i++ {
+		s.errors[action] = append(s.errors[action], retCode)
+	}
+}
+
+// InjectJobFailure makes the jobs of the next count requests of the action fail, the requests change nothing
+func (s *FakeQingCloudServer) InjectJobFailure(action string, count int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.jobFailures[action] += count
+}
+
+// Requests returns the number of requests of the action the server received
+func (s *FakeQingCloudServer) Requests(action string) int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.requests[action]
+}
+
+type fakeRequestError struct {
+	retCode int
+	message string
+}
+
+func (e *fakeRequestError) Error() string {
+	return e.message
+}
+
+func newFakeRequestError(retCode int, format string, args ...interface{}) error {
+	return &fakeRequestError{retCode: retCode, message: fmt.Sprintf(format, args...)}
+}
+
+func (s *FakeQingCloudServer) handle(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	action := params.Get("action")
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.requests[action]++
+
+	result, err := s.serve(r.URL.Path, action, params)
+	response := map[string]interface{}{
+		"action":   action + "Response",
+		"ret_code": 0,
+	}
+	if err != nil {
+		e, ok := err.(*fakeRequestError)
+		if !ok {
+			e = &fakeRequestError{retCode: RetCodeParameterError, message: err.Error()}
+		}
+		response["ret_code"] = e.retCode
+		response["message"] = e.message
+	} else {
+		for k, v := range result {
+			response[k] = v
+		}
+	}
+	content, _ := json.Marshal(response)
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(content)
+}
+
+func (s *FakeQingCloudServer) serve(path, action string, params url.Values) (map[string]interface{}, error) {
+	if err := s.checkSignature(path, params); err != nil {
+		return nil, err
+	}
+	if codes := s.errors[action]; len(codes) > 0 {
+		s.errors[action] = codes[1:]
+		return nil, newFakeRequestError(codes[0], "injected error of %s", action)
+	}
+	handler, ok := fakeServerHandlers[action]
+	if !ok {
+		return nil, newFakeRequestError(RetCodeParameterError, "unknown action %s", action)
+	}
+	return handler(s, params)
+}
+
+// checkSignature checks the request is signed by the secret access key like the sdk does
+func (s *FakeQingCloudServer) checkSignature(path string, params url.Values) error {
+	if params.Get("access_key_id") != s.AccessKeyID {
+		return newFakeRequestError(RetCodeAuthFailure, "unknown access key %s", params.Get("access_key_id"))
+	}
+	if params.Get("signature_method") != "HmacSHA256" || params.Get("signature_version") != "1" {
+		return newFakeRequestError(RetCodeAuthFailure, "unsupported signature method")
+	}
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		if key != "signature" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		value := url.QueryEscape(strings.TrimSpace(strings.Join(params[key], "")))
+		parts = append(parts, key+"="+strings.Replace(value, "+", "%20", -1))
+	}
+	h := hmac.New(sha256.New, []byte(s.SecretAccessKey))
+	h.Write([]byte("GET\n" + path + "\n" + strings.Join(parts, "&")))
+	if base64.StdEncoding.EncodeToString(h.Sum(nil)) != params.Get("signature") {
+		return newFakeRequestError(RetCodeAuthFailure, "signature not match")
+	}
+	return nil
+}
+
+var fakeServerHandlers = map[string]func(*FakeQingCloudServer, url.Values) (map[string]interface{}, error){
+	"DescribeAccessKeys":    (*FakeQingCloudServer).describeAccessKeys,
+	"DescribeJobs":          (*FakeQingCloudServer).describeJobs,
+	"DescribeInstances":     (*FakeQingCloudServer).describeInstances,
+	"DescribeRouters":       (*FakeQingCloudServer).describeRouters,
+	"DescribeRouterVxnets":  (*FakeQingCloudServer).describeRouterVxNets,
+	"JoinRouter":            (*FakeQingCloudServer).joinRouterAction,
+	"LeaveRouter":           (*FakeQingCloudServer).leaveRouter,
+	"DescribeVxnets":        (*FakeQingCloudServer).describeVxNets,
+	"CreateVxnets":          (*FakeQingCloudServer).createVxNets,
+	"DeleteVxnets":          (*FakeQingCloudServer).deleteVxNets,
+	"DescribeNics":          (*FakeQingCloudServer).describeNics,
+	"CreateNics":            (*FakeQingCloudServer).createNics,
+	"AttachNics":            (*FakeQingCloudServer).attachNics,
+	"DetachNics":            (*FakeQingCloudServer).detachNics,
+	"DeleteNics":            (*FakeQingCloudServer).deleteNics,
+	"AssignNicPrivateIPs":   (*FakeQingCloudServer).assignNicPrivateIPs,
+	"UnassignNicPrivateIPs": (*FakeQingCloudServer).unassignNicPrivateIPs,
+	"DescribeNicPrivateIPs": (*FakeQingCloudServer).describeNicPrivateIPs,
+	"ApplySecurityGroup":    (*FakeQingCloudServer).applySecurityGroup,
+	"DescribeEips":          (*FakeQingCloudServer).describeEIPs,
+	"DescribeTags":          (*FakeQingCloudServer).describeTags,
+	"CreateTag":             (*FakeQingCloudServer).createTag,
+	"AttachTags":            (*FakeQingCloudServer).attachTags,
+}
+
+// listParam returns the values of a list parameter like nics.1, nics.2
+func listParam(params url.Values, name string) []string {
+	var result []string
+	for i := 1; ; i++ {
+		value, ok := params[name+"."+strconv.Itoa(i)]
+		if !ok {
+			return result
+		}
+		result = append(result, value[0])
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *FakeQingCloudServer) newID(prefix string) string {
+	s.nextID++
+	return fmt.Sprintf("%s-%08d", prefix, s.nextID)
+}
+
+// newJob creates a job of the action, which works until it is described once. It returns whether the job fails,
+// the request should change nothing if so.
+func (s *FakeQingCloudServer) newJob(action string) (string, bool) {
+	id := s.newID("j")
+	failed := s.jobFailures[action] > 0
+	if failed {
+		s.jobFailures[action]--
+	}
+	s.jobs[id] = &fakeJob{action: action, failed: failed}
+	return id, failed
+}
+
+func (s *FakeQingCloudServer) describeAccessKeys(params url.Values) (map[string]interface{}, error) {
+	result := make([]*service.AccessKey, 0)
+	if containsString(listParam(params, "access_keys"), s.AccessKeyID) {
+		result = append(result, &service.AccessKey{
+			AccessKeyID: service.String(s.AccessKeyID),
+			Owner:       service.String(s.UserID),
+		})
+	}
+	return map[string]interface{}{"access_key_set": result, "total_count": len(result)}, nil
+}
+
+func (s *FakeQingCloudServer) describeJobs(params url.Values) (map[string]interface{}, error) {
+	result := make([]*service.Job, 0)
+	for _, id := range listParam(params, "jobs") {
+		job, ok := s.jobs[id]
+		if !ok {
+			continue
+		}
+		status := "working"
+		if job.polls > 0 {
+			status = "successful"
+			if job.failed {
+				status = "failed"
+			}
+		}
+		job.polls++
+		result = append(result, &service.Job{
+			JobID:     service.String(id),
+			JobAction: service.String(job.action),
+			Status:    service.String(status),
+		})
+	}
+	return map[string]interface{}{"job_set": result, "total_count": len(result)}, nil
+}
+
+func (s *FakeQingCloudServer) describeInstances(params url.Values) (map[string]interface{}, error) {
+	result := make([]*service.Instance, 0)
+	for _, id := range listParam(params, "instances") {
+		if !s.instances[id] {
+			continue
+		}
+		instance := &service.Instance{InstanceID: service.String(id), Status: service.String("running")}
+		for _, nic := range s.nics {
+			if service.StringValue(nic.InstanceID) == id {
+				instance.VxNets = append(instance.VxNets, &service.NICVxNet{
+					NICID:     nic.NICID,
+					PrivateIP: nic.PrivateIP,
+					Role:      nic.Role,
+					VxNetID:   nic.VxNetID,
+				})
+			}
+		}
+		result = append(result, instance)
+	}
+	return map[string]interface{}{"instance_set": result, "total_count": len(result)}, nil
+}
+
+func (s *FakeQingCloudServer) describeRouters(params url.Values) (map[string]interface{}, error) {
+	result := make([]*service.Router, 0)
+	for _, id := range listParam(params, "routers") {
+		if router, ok := s.routers[id]; ok {
+			result = append(result, router)
+		}
+	}
+	return map[string]interface{}{"router_set": result, "total_count": len(result)}, nil
+}
+
+func (s *FakeQingCloudServer) describeRouterVxNets(params url.Values) (map[string]interface{}, error) {
+	routerID := params.Get("router")
+	if _, ok := s.routers[routerID]; !ok {
+		return nil, newFakeRequestError(RetCodeResourceNotFound, "router %s not found", routerID)
+	}
+	result := make([]*service.RouterVxNet, 0)
+	for id, v := range s.vxnets {
+		if v.routerID == routerID {
+			result = append(result, &service.RouterVxNet{
+				RouterID:  service.String(routerID),
+				VxNetID:   service.String(id),
+				IPNetwork: service.String(v.network.String()),
+				ManagerIP: service.String(v.gateway),
+			})
+		}
+	}
+	return map[string]interface{}{"router_vxnet_set": result, "total_count": len(result)}, nil
+}
+
+func (s *FakeQingCloudServer) joinRouter(vxnetID, routerID, network string) error {
+	v, ok := s.vxnets[vxnetID]
+	if !ok {
+		return newFakeRequestError(RetCodeResourceNotFound, "vxnet %s not found", vxnetID)
+	}
+	if _, ok := s.routers[routerID]; !ok {
+		return newFakeRequestError(RetCodeResourceNotFound, "router %s not found", routerID)
+	}
+	_, ipnet, err := net.ParseCIDR(network)
+	if err != nil {
+		return newFakeRequestError(RetCodeParameterError, "invalid ip network %s", network)
+	}
+	gateway := make(net.IP, len(ipnet.IP))
+	copy(gateway, ipnet.IP)
+	gateway[len(gateway)-1]++
+	v.network = ipnet
+	v.gateway = gateway.String()
+	v.routerID = routerID
+	return nil
+}
+
+func (s *FakeQingCloudServer) joinRouterAction(params url.Values) (map[string]interface{}, error) {
+	jobID, failed := s.newJob("JoinRouter")
+	if !failed {
+		if err := s.joinRouter(params.Get("vxnet"), params.Get("router"), params.Get("ip_network")); err != nil {
+			return nil, err
+		}
+	}
+	return map[string]interface{}{"job_id": jobID}, nil
+}
+
+func (s *FakeQingCloudServer) leaveRouter(params url.Values) (map[string]interface{}, error) {
+	jobID, failed := s.newJob("LeaveRouter")
+	for _, id := range listParam(params, "vxnets") {
+		v, ok := s.vxnets[id]
+		if !ok || v.routerID != params.Get("router") {
+			return nil, newFakeRequestError(RetCodeResourceNotFound, "vxnet %s not found in router", id)
+		}
+		if !failed {
+			v.routerID = ""
+			v.network = nil
+		}
+	}
+	return map[string]interface{}{"job_id": jobID}, nil
+}
+
+func (s *FakeQingCloudServer) describeVxNets(params url.Values) (map[string]interface{}, error) {
+	ids := listParam(params, "vxnets")
+	searchWord := params.Get("search_word")
+	owner := params.Get("owner")
+	result := make([]*service.VxNet, 0)
+	for id, v := range s.vxnets {
+		if len(ids) > 0 && !containsString(ids, id) {
+			continue
+		}
+		if !strings.Contains(*v.vxnet.VxNetName, searchWord) || (owner != "" && owner != *v.vxnet.Owner) {
+			continue
+		}
+		vxnet := *v.vxnet
+		vxnet.VpcRouterID = service.String(v.routerID)
+		if v.routerID != "" {
+			vxnet.Router = &service.Router{
+				RouterID:  service.String(v.routerID),
+				IPNetwork: service.String(v.network.String()),
+				ManagerIP: service.String(v.gateway),
+			}
+		}
+		result = append(result, &vxnet)
+	}
+	return map[string]interface{}{"vxnet_set": result, "total_count": len(result)}, nil
+}
+
+func (s *FakeQingCloudServer) createVxNets(params url.Values) (map[string]interface{}, error) {
+	id := s.newID("vxnet")
+	s.vxnets[id] = &fakeVxNet{vxnet: &service.VxNet{
+		VxNetID:   service.String(id),
+		VxNetName: service.String(params.Get("vxnet_name")),
+		VxNetType: service.Int(1),
+		Owner:     service.String(s.UserID),
+	}}
+	return map[string]interface{}{"vxnets": []string{id}}, nil
+}
+
+func (s *FakeQingCloudServer) deleteVxNets(params url.Values) (map[string]interface{}, error) {
+	ids := listParam(params, "vxnets")
+	for _, id := range ids {
+		v, ok := s.vxnets[id]
+		if !ok {
+			return nil, newFakeRequestError(RetCodeResourceNotFound, "vxnet %s not found", id)
+		}
+		if v.routerID != "" {
+			return nil, newFakeRequestError(RetCodePermissionDenied, "vxnet %s is in router %s", id, v.routerID)
+		}
+	}
+	for _, id := range ids {
+		delete(s.vxnets, id)
+	}
+	return map[string]interface{}{"vxnets": ids}, nil
+}
+
+// allocateIP returns a free ip in the vxnet, the first ip is the gateway
+func (s *FakeQingCloudServer) allocateIP(v *fakeVxNet) (string, error) {
+	used := map[string]bool{v.gateway: true}
+	for id, nic := range s.nics {
+		used[*nic.PrivateIP] = true
+		for _, ip := range s.privateIPs[id] {
+			used[ip] = true
+		}
+	}
+	ip := make(net.IP, len(v.network.IP))
+	copy(ip, v.network.IP)
+	for {
+		for i := len(ip) - 1; i >= 0; i-- {
+			ip[i]++
+			if ip[i] != 0 {
+				break
+			}
+		}
+		if !v.network.Contains(ip) {
+			return "", newFakeRequestError(RetCodePermissionDenied, "no available ip in vxnet %s", *v.vxnet.VxNetID)
+		}
+		if !used[ip.String()] {
+			return ip.String(), nil
+		}
+	}
+}
+
+func (s *FakeQingCloudServer) createNic(vxnetID, name string) (*service.NIC, error) {
+	v, ok := s.vxnets[vxnetID]
+	if !ok {
+		return nil, newFakeRequestError(RetCodeResourceNotFound, "vxnet %s not found", vxnetID)
+	}
+	if v.network == nil {
+		return nil, newFakeRequestError(RetCodePermissionDenied, "vxnet %s is not in a router", vxnetID)
+	}
+	ip, err := s.allocateIP(v)
+	if err != nil {
+		return nil, err
+	}
+	s.nextID++
+	id := fmt.Sprintf("52:54:%02x:%02x:%02x:%02x", byte(s.nextID>>24), byte(s.nextID>>16), byte(s.nextID>>8), byte(s.nextID))
+	nic := &service.NIC{
+		NICID:      service.String(id),
+		NICName:    service.String(name),
+		VxNetID:    service.String(vxnetID),
+		PrivateIP:  service.String(ip),
+		Owner:      service.String(s.UserID),
+		Role:       service.Int(0),
+		Sequence:   service.Int(0),
+		Status:     service.String("available"),
+		InstanceID: service.String(""),
+	}
+	s.nics[id] = nic
+	return nic, nil
+}
+
+func (s *FakeQingCloudServer) describeNics(params url.Values) (map[string]interface{}, error) {
+	ids := listParam(params, "nics")
+	instances := listParam(params, "instances")
+	vxnets := listParam(params, "vxnets")
+	status := params.Get("status")
+	limit, _ := strconv.Atoi(params.Get("limit"))
+	result := make([]*service.NIC, 0)
+	for _, id := range sortedKeys(s.nics) {
+		nic := s.nics[id]
+		if (len(ids) > 0 && !containsString(ids, id)) ||
+			(len(instances) > 0 && !containsString(instances, *nic.InstanceID)) ||
+			(len(vxnets) > 0 && !containsString(vxnets, *nic.VxNetID)) ||
+			(status != "" && status != *nic.Status) {
+			continue
+		}
+		if limit > 0 && len(result) == limit {
+			break
+		}
+		result = append(result, nic)
+	}
+	return map[string]interface{}{"nic_set": result, "total_count": len(result)}, nil
+}
+
+func sortedKeys(nics map[string]*service.NIC) []string {
+	result := make([]string, 0, len(nics))
+	for id := range nics {
+		result = append(result, id)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func (s *FakeQingCloudServer) createNics(params url.Values) (map[string]interface{}, error) {
+	count := 1
+	if params.Get("count") != "" {
+		count, _ = strconv.Atoi(params.Get("count"))
+	}
+	result := make([]*service.NICIP, 0, count)
+	for i := 0; i < count; i++ {
+		nic, err := s.createNic(params.Get("vxnet"), params.Get("nic_name"))
+		if err != nil {
+			for _, created := range result {
+				delete(s.nics, *created.NICID)
+			}
+			return nil, err
+		}
+		result = append(result, &service.NICIP{NICID: nic.NICID, PrivateIP: nic.PrivateIP})
+	}
+	return map[string]interface{}{"nics": result}, nil
+}
+
+func (s *FakeQingCloudServer) getNics(ids []string) ([]*service.NIC, error) {
+	if len(ids) == 0 {
+		return nil, newFakeRequestError(RetCodeParameterError, "nics are required")
+	}
+	result := make([]*service.NIC, 0, len(ids))
+	for _, id := range ids {
+		nic, ok := s.nics[id]
+		if !ok {
+			return nil, newFakeRequestError(RetCodeResourceNotFound, "nic %s not found", id)
+		}
+		result = append(result, nic)
+	}
+	return result, nil
+}
+
+func (s *FakeQingCloudServer) attachNics(params url.Values) (map[string]interface{}, error) {
+	instance := params.Get("instance")
+	if !s.instances[instance] {
+		return nil, newFakeRequestError(RetCodeResourceNotFound, "instance %s not found", instance)
+	}
+	nics, err := s.getNics(listParam(params, "nics"))
+	if err != nil {
+		return nil, err
+	}
+	for _, nic := range nics {
+		if *nic.Status != "available" {
+			return nil, newFakeRequestError(RetCodePermissionDenied, "nic %s is in use", *nic.NICID)
+		}
+	}
+	jobID, failed := s.newJob("AttachNics")
+	if failed {
+		return map[string]interface{}{"job_id": jobID}, nil
+	}
+	for _, nic := range nics {
+		sequences := make(map[int]bool)
+		for _, n := range s.nics {
+			if *n.InstanceID == instance {
+				sequences[*n.Sequence] = true
+			}
+		}
+		sequence := 1
+		for sequences[sequence] {
+			sequence++
+		}
+		nic.InstanceID = service.String(instance)
+		nic.Status = service.String("in-use")
+		nic.Sequence = service.Int(sequence)
+	}
+	return map[string]interface{}{"job_id": jobID}, nil
+}
+
+func (s *FakeQingCloudServer) detachNics(params url.Values) (map[string]interface{}, error) {
+	nics, err := s.getNics(listParam(params, "nics"))
+	if err != nil {
+		return nil, err
+	}
+	for _, nic := range nics {
+		if *nic.Role == 1 {
+			return nil, newFakeRequestError(RetCodePermissionDenied, "nic %s is primary", *nic.NICID)
+		}
+	}
+	jobID, failed := s.newJob("DetachNics")
+	if !failed {
+		for _, nic := range nics {
+			nic.InstanceID = service.String("")
+			nic.Status = service.String("available")
+			nic.Sequence = service.Int(0)
+		}
+	}
+	return map[string]interface{}{"job_id": jobID}, nil
+}
+
+func (s *FakeQingCloudServer) deleteNics(params url.Values) (map[string]interface{}, error) {
+	nics, err := s.getNics(listParam(params, "nics"))
+	if err != nil {
+		return nil, err
+	}
+	for _, nic := range nics {
+		if *nic.Status != "available" {
+			return nil, newFakeRequestError(RetCodePermissionDenied, "nic %s is in use", *nic.NICID)
+		}
+	}
+	for _, nic := range nics {
+		delete(s.nics, *nic.NICID)
+		delete(s.privateIPs, *nic.NICID)
+	}
+	return map[string]interface{}{}, nil
+}
+
+func (s *FakeQingCloudServer) assignNicPrivateIPs(params url.Values) (map[string]interface{}, error) {
+	nics, err := s.getNics([]string{params.Get("nic")})
+	if err != nil {
+		return nil, err
+	}
+	count, _ := strconv.Atoi(params.Get("count"))
+	v := s.vxnets[*nics[0].VxNetID]
+	id := *nics[0].NICID
+	result := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		ip, err := s.allocateIP(v)
+		if err != nil {
+			s.privateIPs[id] = s.privateIPs[id][:len(s.privateIPs[id])-len(result)]
+			return nil, err
+		}
+		s.privateIPs[id] = append(s.privateIPs[id], ip)
+		result = append(result, ip)
+	}
+	jobID, _ := s.newJob("AssignNicPrivateIPs")
+	return map[string]interface{}{"private_ips": result, "job_id": jobID}, nil
+}
+
+func (s *FakeQingCloudServer) unassignNicPrivateIPs(params url.Values) (map[string]interface{}, error) {
+	nics, err := s.getNics([]string{params.Get("nic")})
+	if err != nil {
+		return nil, err
+	}
+	id := *nics[0].NICID
+	ips := listParam(params, "private_ips")
+	for _, ip := range ips {
+		if !containsString(s.privateIPs[id], ip) {
+			return nil, newFakeRequestError(RetCodeResourceNotFound, "private ip %s of nic %s not found", ip, id)
+		}
+	}
+	remain := make([]string, 0, len(s.privateIPs[id]))
+	for _, ip := range s.privateIPs[id] {
+		if !containsString(ips, ip) {
+			remain = append(remain, ip)
+		}
+	}
+	s.privateIPs[id] = remain
+	jobID, _ := s.newJob("UnassignNicPrivateIPs")
+	return map[string]interface{}{"job_id": jobID}, nil
+}
+
+// describeNicPrivateIPs returns the primary ips of nics as well as the secondary ones
+func (s *FakeQingCloudServer) describeNicPrivateIPs(params url.Values) (map[string]interface{}, error) {
+	result := make([]*nicPrivateIP, 0)
+	for _, id := range listParam(params, "nics") {
+		nic, ok := s.nics[id]
+		if !ok {
+			continue
+		}
+		result = append(result, &nicPrivateIP{NICID: nic.NICID, PrivateIP: nic.PrivateIP})
+		for _, ip := range s.privateIPs[id] {
+			result = append(result, &nicPrivateIP{NICID: nic.NICID, PrivateIP: service.String(ip)})
+		}
+	}
+	return map[string]interface{}{"private_ip_set": result}, nil
+}
+
+func (s *FakeQingCloudServer) applySecurityGroup(params url.Values) (map[string]interface{}, error) {
+	sg := params.Get("security_group")
+	var nics []*service.NIC
+	for _, id := range listParam(params, "instances") {
+		if nic, ok := s.nics[id]; ok {
+			nics = append(nics, nic)
+		} else if !s.instances[id] {
+			return nil, newFakeRequestError(RetCodeResourceNotFound, "resource %s not found", id)
+		}
+	}
+	jobID, failed := s.newJob("ApplySecurityGroup")
+	if !failed {
+		for _, nic := range nics {
+			nic.SecurityGroup = service.String(sg)
+		}
+	}
+	return map[string]interface{}{"job_id": jobID}, nil
+}
+
+func (s *FakeQingCloudServer) describeEIPs(params url.Values) (map[string]interface{}, error) {
+	result := make([]*service.EIP, 0)
+	for _, id := range listParam(params, "eips") {
+		if eip, ok := s.eips[id]; ok {
+			result = append(result, eip)
+		}
+	}
+	return map[string]interface{}{"eip_set": result, "total_count": len(result)}, nil
+}
+
+func (s *FakeQingCloudServer) describeTags(params url.Values) (map[string]interface{}, error) {
+	ids := listParam(params, "tags")
+	searchWord := params.Get("search_word")
+	result := make([]*service.Tag, 0)
+	for id, tag := range s.tags {
+		if (len(ids) > 0 && !containsString(ids, id)) || !strings.Contains(*tag.TagName, searchWord) {
+			continue
+		}
+		t := *tag
+		t.ResourceCount = service.Int(len(tag.ResourceTagPairs))
+		result = append(result, &t)
+	}
+	return map[string]interface{}{"tag_set": result, "total_count": len(result)}, nil
+}
+
+func (s *FakeQingCloudServer) createTag(params url.Values) (map[string]interface{}, error) {
+	id := s.newID("tag")
+	s.tags[id] = &service.Tag{
+		TagID:   service.String(id),
+		TagName: service.String(params.Get("tag_name")),
+		Color:   service.String(params.Get("color")),
+		Owner:   service.String(s.UserID),
+	}
+	return map[string]interface{}{"tag_id": id}, nil
+}
+
+func (s *FakeQingCloudServer) attachTags(params url.Values) (map[string]interface{}, error) {
+	var pairs []*service.ResourceTagPair
+	for i := 1; params.Get(fmt.Sprintf("resource_tag_pairs.%d.tag_id", i)) != ""; i++ {
+		prefix := fmt.Sprintf("resource_tag_pairs.%d.", i)
+		pairs = append(pairs, &service.ResourceTagPair{
+			TagID:        service.String(params.Get(prefix + "tag_id")),
+			ResourceID:   service.String(params.Get(prefix + "resource_id")),
+			ResourceType: service.String(params.Get(prefix + "resource_type")),
+		})
+	}
+	for _, pair := range pairs {
+		if _, ok := s.tags[*pair.TagID]; !ok {
+			return nil, newFakeRequestError(RetCodeResourceNotFound, "tag %s not found", *pair.TagID)
+		}
+	}
+	for _, pair := range pairs {
+		tag := s.tags[*pair.TagID]
+		tag.ResourceTagPairs = append(tag.ResourceTagPairs, pair)
+	}
+	return map[string]interface{}{}, nil
+}
@@ -0,0 +1,201 @@
+package qcclient
+
+import (
+	"context"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+	sdkerrors "github.com/yunify/qingcloud-sdk-go/request/errors"
+)
+
+var _ = Describe("FakeServer", func() {
+	var (
+		server  *FakeQingCloudServer
+		primary string
+		client  QingCloudAPI
+		timeout [4]time.Duration
+	)
+
+	newClient := func(options ClientOptions) (QingCloudAPI, error) {
+		api, err := NewQingCloudClientWithOptions(&LabelResourceConfig{ClusterName: "test"}, options)
+		if err != nil {
+			return nil, err
+		}
+		limits := make(map[apiFamily]apiRateLimit)
+		for _, family := range []apiFamily{apiFamilyNic, apiFamilyVxNet, apiFamilyRouter, apiFamilyInstance,
+			apiFamilyEIP, apiFamilySecurityGroup, apiFamilyTag} {
+			limits[family] = apiRateLimit{qps: 1000, burst: 100}
+		}
+		wrapper, ok := api.(*qingcloudAPIWrapper)
+		if !ok {
+			wrapper = api.(*cachedQingCloudAPI).QingCloudAPI.(*qingcloudAPIWrapper)
+		}
+		wrapper.limiter = newAPILimiter(limits)
+		wrapper.limiter.backoff = time.Millisecond
+		return api, nil
+	}
+
+	BeforeEach(func() {
+		timeout = [4]time.Duration{defaultOpTimeout, defaultWaitInterval, waitNicLocalTimeout, waitNicLocalInterval}
+		defaultOpTimeout, defaultWaitInterval = 5*time.Second, 10*time.Millisecond
+		waitNicLocalTimeout, waitNicLocalInterval = 50*time.Millisecond, 10*time.Millisecond
+
+		server = NewFakeQingCloudServer()
+		server.AddRouter("rtr-1", "172.16.0.0/16")
+		Expect(server.AddVxNet("vxnet-1", "pods", "rtr-1", "172.16.1.0/24")).To(Succeed())
+		var err error
+		primary, err = server.AddInstance("i-1", "vxnet-1")
+		Expect(err).ShouldNot(HaveOccurred())
+		client, err = newClient(server.ClientOptions("i-1"))
+		Expect(err).ShouldNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		server.Close()
+		defaultOpTimeout, defaultWaitInterval, waitNicLocalTimeout, waitNicLocalInterval = timeout[0], timeout[1], timeout[2], timeout[3]
+	})
+
+	It("Should describe the vpc and the primary nic of the node", func() {
+		Expect(client.GetInstanceID()).To(Equal("i-1"))
+		vpc, err := client.GetNodeVPC(context.Background())
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(vpc.ID).To(Equal("rtr-1"))
+		Expect(vpc.Network.String()).To(Equal("172.16.0.0/16"))
+		Expect(vpc.VxNets).To(HaveLen(1))
+
+		vxnet, err := client.GetVxNetByName("pods")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(vxnet.ID).To(Equal("vxnet-1"))
+		Expect(vxnet.GateWay).To(Equal("172.16.1.1"))
+
+		nic, err := client.GetPrimaryNIC()
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(nic.ID).To(Equal(primary))
+		Expect(nic.IsPrimary).To(BeTrue())
+
+		tag, err := client.GetTagByLabel("hostnic-nic-test")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(tag.TaggedResources).To(BeEmpty())
+	})
+
+	It("Should create, attach, label and delete nics", func() {
+		options := server.ClientOptions("i-1")
+		options.MaxIPsPerNIC = 3
+		client, err := newClient(options)
+		Expect(err).ShouldNot(HaveOccurred())
+		nics, err := client.CreateNics(context.Background(), "vxnet-1", 2)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(nics).To(HaveLen(2))
+		Expect(server.Requests("DescribeJobs")).To(BeNumerically(">=", 2))
+
+		attached, err := client.GetAttachedNICs("vxnet-1")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(attached).To(HaveLen(2))
+		Expect(attached[0].DeviceNumber).To(BeNumerically(">", 0))
+		tag, err := client.GetTagByLabel("hostnic-nic-test")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(tag.TaggedResources).To(HaveLen(2))
+
+		Expect(client.ApplySecurityGroup("sg-1", nics[0].ID)).To(Succeed())
+		ips, err := client.AssignPrivateIPs(nics[0].ID, 2)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(ips).To(HaveLen(2))
+		Expect(client.UnassignPrivateIPs(nics[0].ID, ips[0])).To(Succeed())
+		got, err := client.GetNics([]string{nics[0].ID})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(got[0].SecurityGroup).To(Equal("sg-1"))
+		attached, _ = client.GetAttachedNICs("vxnet-1")
+		for _, nic := range attached {
+			if nic.ID == nics[0].ID {
+				Expect(nic.SecondaryAddresses).To(Equal([]string{ips[1]}))
+			}
+		}
+
+		Expect(client.DeleteNics([]string{nics[0].ID, nics[1].ID})).To(Succeed())
+		Expect(server.NicIDs()).To(Equal([]string{primary}))
+	})
+
+	It("Should invalidate the cached tags when nics are labeled", func() {
+		os.Setenv(envCacheTTLs, "tag=1m")
+		defer os.Unsetenv(envCacheTTLs)
+		client, err := newClient(server.ClientOptions("i-1"))
+		Expect(err).ShouldNot(HaveOccurred())
+		tag, err := client.GetTagByLabel("hostnic-nic-test")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(tag.TaggedResources).To(BeEmpty())
+
+		_, err = client.CreateNics(context.Background(), "vxnet-1", 1)
+		Expect(err).ShouldNot(HaveOccurred())
+		tag, err = client.GetTagByLabel("hostnic-nic-test")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(tag.TaggedResources).To(HaveLen(1))
+	})
+
+	It("Should not require secondary private ips of nics", func() {
+		nics, err := client.CreateNics(context.Background(), "vxnet-1", 1)
+		Expect(err).ShouldNot(HaveOccurred())
+		attached, err := client.GetAttachedNICs("vxnet-1")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(attached).To(HaveLen(1))
+		Expect(server.Requests("DescribeNicPrivateIPs")).To(BeZero())
+
+		// the cloud may not know the action at all
+		options := server.ClientOptions("i-1")
+		options.MaxIPsPerNIC = 3
+		client, err = newClient(options)
+		Expect(err).ShouldNot(HaveOccurred())
+		server.InjectError("DescribeNicPrivateIPs", RetCodePermissionDenied, 1)
+		attached, err = client.GetAttachedNICs("vxnet-1")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(server.Requests("DescribeNicPrivateIPs")).To(Equal(1))
+		Expect(attached).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+			"ID":                 Equal(nics[0].ID),
+			"SecondaryAddresses": BeEmpty(),
+		}))))
+
+		// the other errors are not mistaken for no secondary private ips
+		server.InjectError("DescribeNicPrivateIPs", RetCodeAuthFailure, 1)
+		_, err = client.GetAttachedNICs("vxnet-1")
+		Expect(err).Should(HaveOccurred())
+	})
+
+	It("Should delete the nics which fail to be attached", func() {
+		server.InjectJobFailure("AttachNics", 1)
+		_, err := client.CreateNics(context.Background(), "vxnet-1", 2)
+		Expect(err).Should(HaveOccurred())
+		Expect(server.NicIDs()).To(Equal([]string{primary}))
+	})
+
+	It("Should not delete the nics until they are detached", func() {
+		nics, err := client.CreateNics(context.Background(), "vxnet-1", 1)
+		Expect(err).ShouldNot(HaveOccurred())
+		server.InjectJobFailure("DetachNics", 1)
+		Expect(client.DeleteNics([]string{nics[0].ID})).ShouldNot(Succeed())
+		Expect(server.Requests("DeleteNics")).To(BeZero())
+		Expect(server.NicIDs()).To(ConsistOf(primary, nics[0].ID))
+
+		Expect(client.DeleteNics([]string{nics[0].ID})).To(Succeed())
+		Expect(server.NicIDs()).To(Equal([]string{primary}))
+	})
+
+	It("Should surface and retry the errors of qingcloud", func() {
+		server.InjectError("DescribeVxnets", RetCodeServerBusy, 2)
+		_, err := client.GetVxNet("vxnet-1")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(server.Requests("DescribeVxnets")).To(Equal(3))
+
+		server.InjectError("CreateNics", RetCodePermissionDenied, 1)
+		_, err = client.CreateNic(context.Background(), "vxnet-1")
+		Expect(err).Should(HaveOccurred())
+		Expect(err.(*sdkerrors.QingCloudError).RetCode).To(Equal(RetCodePermissionDenied))
+
+		options := server.ClientOptions("i-1")
+		options.SecretAccessKey = "wrong"
+		_, err = newClient(options)
+		Expect(err).Should(HaveOccurred())
+		Expect(err.(*sdkerrors.QingCloudError).RetCode).To(Equal(RetCodeAuthFailure))
+	})
+})
@@ -1,6 +1,10 @@
 package qcclient
 
-import "github.com/yunify/hostnic-cni/pkg/types"
+import (
+	"context"
+
+	"github.com/yunify/hostnic-cni/pkg/types"
+)
 
 // QingCloudAPI is a wrapper interface of qingcloud api
 type QingCloudAPI interface {
@@ -11,7 +15,10 @@ type QingCloudAPI interface {
 // QingCloudNetAPI  do dirty works on  net interface on qingcloud
 type QingCloudNetAPI interface {
 	//CreateNicInVxnet create network interface card in vxnet and attach to host
-	CreateNic(vxnet string) (*types.HostNic, error)
+	CreateNic(ctx context.Context, vxnet string) (*types.HostNic, error)
+	// CreateNics creates count nics in vxnet and attaches them to host in one batch. If some nics fail, the
+	// others are returned along with the error, and the failed ones are deleted.
+	CreateNics(ctx context.Context, vxnet string, count int) ([]*types.HostNic, error)
 	DeleteNic(nicID string) error
 
 	GetPrimaryNIC() (*types.HostNic, error)
@@ -24,12 +31,19 @@ type QingCloudNetAPI interface {
 	GetNics([]string) ([]*types.HostNic, error)
 	CreateVxNet(name string) (*types.VxNet, error)
 	GetAttachedNICs(string) ([]*types.HostNic, error)
-	GetVPC(string) (*types.VPC, error)
-	GetNodeVPC() (*types.VPC, error)
+	GetVPC(ctx context.Context, id string) (*types.VPC, error)
+	GetNodeVPC(ctx context.Context) (*types.VPC, error)
 	GetVPCVxNets(string) ([]*types.VxNet, error)
 	JoinVPC(network, vxnetID, vpcID string) error
 	LeaveVPC(vxnetID, vpcID string) error
 	GetInstanceID() string
+	GetEIP(eipID string) (*types.EIP, error)
+	// ApplySecurityGroup binds nics to a security group, replacing the one they are bound to
+	ApplySecurityGroup(securityGroupID string, nicIDs ...string) error
+	// AssignPrivateIPs assigns count secondary private ips to a nic, and returns the new ips
+	AssignPrivateIPs(nicID string, count int) ([]string, error)
+	// UnassignPrivateIPs releases secondary private ips of a nic
+	UnassignPrivateIPs(nicID string, ips ...string) error
 }
 
 // QingCloudTagAPI do dirty works of tags on qingcloud
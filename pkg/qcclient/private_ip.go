@@ -0,0 +1,173 @@
+package qcclient
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yunify/qingcloud-sdk-go/client"
+	"github.com/yunify/qingcloud-sdk-go/request"
+	"github.com/yunify/qingcloud-sdk-go/request/data"
+	sdkerrors "github.com/yunify/qingcloud-sdk-go/request/errors"
+	"github.com/yunify/qingcloud-sdk-go/service"
+)
+
+// The vendored sdk does not support secondary private ips of nics yet, the requests are built here the same way
+// as the generated services of the sdk.
+
+type assignNicPrivateIPsInput struct {
+	Nic   *string `json:"nic" name:"nic" location:"params"`     // Required
+	Count *int    `json:"count" name:"count" location:"params"` // Required
+}
+
+func (v *assignNicPrivateIPsInput) Validate() error {
+	if v.Nic == nil {
+		return sdkerrors.ParameterRequiredError{
+			ParameterName: "Nic",
+			ParentName:    "AssignNicPrivateIPsInput",
+		}
+	}
+	if v.Count == nil || *v.Count <= 0 {
+		return sdkerrors.ParameterRequiredError{
+			ParameterName: "Count",
+			ParentName:    "AssignNicPrivateIPsInput",
+		}
+	}
+	return nil
+}
+
+type assignNicPrivateIPsOutput struct {
+	Message    *string   `json:"message" name:"message"`
+	Action     *string   `json:"action" name:"action" location:"elements"`
+	JobID      *string   `json:"job_id" name:"job_id" location:"elements"`
+	PrivateIPs []*string `json:"private_ips" name:"private_ips" location:"elements"`
+	RetCode    *int      `json:"ret_code" name:"ret_code" location:"elements"`
+}
+
+type unassignNicPrivateIPsInput struct {
+	Nic        *string   `json:"nic" name:"nic" location:"params"`                 // Required
+	PrivateIPs []*string `json:"private_ips" name:"private_ips" location:"params"` // Required
+}
+
+func (v *unassignNicPrivateIPsInput) Validate() error {
+	if v.Nic == nil {
+		return sdkerrors.ParameterRequiredError{
+			ParameterName: "Nic",
+			ParentName:    "UnassignNicPrivateIPsInput",
+		}
+	}
+	if len(v.PrivateIPs) == 0 {
+		return sdkerrors.ParameterRequiredError{
+			ParameterName: "PrivateIPs",
+			ParentName:    "UnassignNicPrivateIPsInput",
+		}
+	}
+	return nil
+}
+
+type unassignNicPrivateIPsOutput struct {
+	Message *string `json:"message" name:"message"`
+	Action  *string `json:"action" name:"action" location:"elements"`
+	JobID   *string `json:"job_id" name:"job_id" location:"elements"`
+	RetCode *int    `json:"ret_code" name:"ret_code" location:"elements"`
+}
+
+type describeNicPrivateIPsInput struct {
+	Nics []*string `json:"nics" name:"nics" location:"params"` // Required
+}
+
+func (v *describeNicPrivateIPsInput) Validate() error {
+	if len(v.Nics) == 0 {
+		return sdkerrors.ParameterRequiredError{
+			ParameterName: "Nics",
+			ParentName:    "DescribeNicPrivateIPsInput",
+		}
+	}
+	return nil
+}
+
+type nicPrivateIP struct {
+	NICID     *string `json:"nic_id" name:"nic_id"`
+	PrivateIP *string `json:"private_ip" name:"private_ip"`
+}
+
+type describeNicPrivateIPsOutput struct {
+	Message      *string         `json:"message" name:"message"`
+	Action       *string         `json:"action" name:"action" location:"elements"`
+	PrivateIPSet []*nicPrivateIP `json:"private_ip_set" name:"private_ip_set" location:"elements"`
+	RetCode      *int            `json:"ret_code" name:"ret_code" location:"elements"`
+}
+
+func (q *qingcloudAPIWrapper) sendNicRequest(apiName string, input data.Input, output interface{}) error {
+	o := &data.Operation{
+		Config:        q.nicService.Config,
+		Properties:    q.nicService.Properties,
+		APIName:       apiName,
+		RequestMethod: "GET",
+	}
+	r, err := request.New(o, input, output)
+	if err != nil {
+		return err
+	}
+	return q.limiter.call(context.Background(), apiFamilyNic, r.Send)
+}
+
+// AssignPrivateIPs assigns secondary private ips in the vxnet of a nic to it
+func (q *qingcloudAPIWrapper) AssignPrivateIPs(nicID string, count int) ([]string, error) {
+	input := &assignNicPrivateIPsInput{Nic: &nicID, Count: &count}
+	output := &assignNicPrivateIPsOutput{}
+	err := q.sendNicRequest("AssignNicPrivateIPs", input, output)
+	if err != nil {
+		return nil, err
+	}
+	if *output.RetCode != 0 {
+		return nil, fmt.Errorf("Failed to assign %d private ips to nic %s, err: %s", count, nicID, *output.Message)
+	}
+	if output.JobID != nil {
+		err = client.WaitJob(q.jobService, *output.JobID, defaultOpTimeout, defaultWaitInterval)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return service.StringValueSlice(output.PrivateIPs), nil
+}
+
+// UnassignPrivateIPs releases secondary private ips of a nic
+func (q *qingcloudAPIWrapper) UnassignPrivateIPs(nicID string, ips ...string) error {
+	input := &unassignNicPrivateIPsInput{Nic: &nicID, PrivateIPs: service.StringSlice(ips)}
+	output := &unassignNicPrivateIPsOutput{}
+	err := q.sendNicRequest("UnassignNicPrivateIPs", input, output)
+	if err != nil {
+		return err
+	}
+	if *output.RetCode != 0 {
+		return fmt.Errorf("Failed to unassign private ips %v of nic %s, err: %s", ips, nicID, *output.Message)
+	}
+	if output.JobID != nil {
+		return client.WaitJob(q.jobService, *output.JobID, defaultOpTimeout, defaultWaitInterval)
+	}
+	return nil
+}
+
+// getSecondaryAddresses returns the secondary private ips of nics by nic id
+func (q *qingcloudAPIWrapper) getSecondaryAddresses(nicIDs []string) (map[string][]string, error) {
+	result := make(map[string][]string)
+	if len(nicIDs) == 0 {
+		return result, nil
+	}
+	input := &describeNicPrivateIPsInput{Nics: service.StringSlice(nicIDs)}
+	output := &describeNicPrivateIPsOutput{}
+	err := q.sendNicRequest("DescribeNicPrivateIPs", input, output)
+	if err != nil {
+		return nil, err
+	}
+	if *output.RetCode != 0 {
+		return nil, fmt.Errorf("DescribeNicPrivateIPs invalid output [%+v]", *output)
+	}
+	for _, item := range output.PrivateIPSet {
+		if item.NICID == nil || item.PrivateIP == nil {
+			continue
+		}
+		result[*item.NICID] = append(result[*item.NICID], *item.PrivateIP)
+	}
+	return result, nil
+}
@@ -0,0 +1,13 @@
+package qcclient
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestQcclient(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Qcclient Suite")
+}
@@ -0,0 +1,225 @@
+package qcclient
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yunify/hostnic-cni/pkg/errors"
+	"github.com/yunify/hostnic-cni/pkg/retry"
+	"github.com/yunify/hostnic-cni/pkg/types"
+	sdkerrors "github.com/yunify/qingcloud-sdk-go/request/errors"
+	"golang.org/x/time/rate"
+	"k8s.io/klog"
+)
+
+// apiFamily groups the apis of qingcloud sharing a rate limit
+type apiFamily string
+
+const (
+	apiFamilyNic           apiFamily = "nic"
+	apiFamilyVxNet         apiFamily = "vxnet"
+	apiFamilyRouter        apiFamily = "router"
+	apiFamilyInstance      apiFamily = "instance"
+	apiFamilyEIP           apiFamily = "eip"
+	apiFamilySecurityGroup apiFamily = "security_group"
+	apiFamilyTag           apiFamily = "tag"
+
+	// envAPIRateLimits sets the qps and burst of api families, in the format of nic=2:5,vxnet=1:3.
+	// The families not in it use defaultAPIQPS and defaultAPIBurst.
+	envAPIRateLimits = "HOSTNIC_API_RATE_LIMITS"
+	defaultAPIQPS    = 2
+	defaultAPIBurst  = 5
+
+	rateLimitMaxRetries = 5
+	rateLimitBackoff    = time.Second
+	rateLimitMaxBackoff = 30 * time.Second
+)
+
+// retCodesOfRateLimit are the return codes of qingcloud when it throttles requests
+var retCodesOfRateLimit = map[int]bool{
+	// the server is busy
+	5100: true,
+}
+
+// retCodesOfQuotaExceeded are the return codes of qingcloud when the quota of the account is used up
+var retCodesOfQuotaExceeded = map[int]bool{
+	2500: true,
+}
+
+// retCodesOfUnsupported are the return codes of qingcloud when it does not support or permit an action, e.g. the
+// private clouds of old versions
+var retCodesOfUnsupported = map[int]bool{
+	// the request is invalid, which is the code of unknown actions
+	1100: true,
+	// the account is not permitted to call the action
+	1400: true,
+}
+
+type apiRateLimit struct {
+	qps   float64
+	burst int
+}
+
+// parseAPIRateLimits parses the value of envAPIRateLimits, invalid items are ignored
+func parseAPIRateLimits(value string) map[apiFamily]apiRateLimit {
+	result := make(map[apiFamily]apiRateLimit)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 {
+			klog.Errorf("Ignore invalid item %q of %s", item, envAPIRateLimits)
+			continue
+		}
+		limits := strings.SplitN(parts[1], ":", 2)
+		qps, err := strconv.ParseFloat(strings.TrimSpace(limits[0]), 64)
+		if err != nil || qps <= 0 {
+			klog.Errorf("Ignore invalid item %q of %s", item, envAPIRateLimits)
+			continue
+		}
+		burst := defaultAPIBurst
+		if len(limits) == 2 {
+			burst, err = strconv.Atoi(strings.TrimSpace(limits[1]))
+			if err != nil || burst <= 0 {
+				klog.Errorf("Ignore invalid item %q of %s", item, envAPIRateLimits)
+				continue
+			}
+		}
+		result[apiFamily(strings.TrimSpace(parts[0]))] = apiRateLimit{qps: qps, burst: burst}
+	}
+	return result
+}
+
+// isRateLimited checks whether an error is returned because qingcloud throttles requests
+func isRateLimited(err error) bool {
+	e, ok := err.(*sdkerrors.QingCloudError)
+	return ok && retCodesOfRateLimit[e.RetCode]
+}
+
+// isUnsupported checks whether an error is returned because qingcloud does not support or permit an action
+func isUnsupported(err error) bool {
+	e, ok := err.(*sdkerrors.QingCloudError)
+	return ok && retCodesOfUnsupported[e.RetCode]
+}
+
+// classifyError converts the errors of qingcloud which hostnic reports to users to typed errors
+func classifyError(family apiFamily, err error) error {
+	e, ok := err.(*sdkerrors.QingCloudError)
+	if ok && retCodesOfQuotaExceeded[e.RetCode] {
+		return errors.NewQuotaExceededError(types.ResourceType(family), "", e.Message)
+	}
+	return err
+}
+
+type coalescedCall struct {
+	done   chan struct{}
+	output interface{}
+	err    error
+}
+
+// apiLimiter throttles the requests to qingcloud with a token bucket per api family, so that the nodes of a large
+// cluster do not exceed the rate limit of the account when they start at the same time.
+type apiLimiter struct {
+	limits map[apiFamily]apiRateLimit
+	// backoff is the initial wait time before retrying a throttled request
+	backoff time.Duration
+
+	lock     sync.Mutex
+	limiters map[apiFamily]*rate.Limiter
+	calls    map[string]*coalescedCall
+}
+
+func newAPILimiter(limits map[apiFamily]apiRateLimit) *apiLimiter {
+	return &apiLimiter{
+		limits:   limits,
+		backoff:  rateLimitBackoff,
+		limiters: make(map[apiFamily]*rate.Limiter),
+		calls:    make(map[string]*coalescedCall),
+	}
+}
+
+func (l *apiLimiter) limiter(family apiFamily) *rate.Limiter {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	limiter, ok := l.limiters[family]
+	if !ok {
+		limit, ok := l.limits[family]
+		if !ok {
+			limit = apiRateLimit{qps: defaultAPIQPS, burst: defaultAPIBurst}
+		}
+		limiter = rate.NewLimiter(rate.Limit(limit.qps), limit.burst)
+		l.limiters[family] = limiter
+	}
+	return limiter
+}
+
+// call sends a request of the api family when the rate limit allows, and retries it with exponential backoff if
+// it is throttled by qingcloud. A CloudThrottled error is returned if it is still throttled after retries. Waiting
+// for the rate limit or the next retry stops when ctx is done.
+func (l *apiLimiter) call(ctx context.Context, family apiFamily, fn func() error) error {
+	err := retry.DoWithBackoff(ctx, retry.Backoff{
+		Steps:     rateLimitMaxRetries + 1,
+		Duration:  l.backoff,
+		Factor:    2,
+		Jitter:    0.2,
+		Cap:       rateLimitMaxBackoff,
+		Retryable: isRateLimited,
+	}, func() error {
+		if err := l.limiter(family).Wait(ctx); err != nil {
+			return err
+		}
+		err := fn()
+		if isRateLimited(err) {
+			klog.Warningf("Requests of %s api are throttled, will retry", family)
+		}
+		return err
+	})
+	if retry.IsMaxRetries(err) {
+		return errors.NewCloudThrottledError(string(family), err.(*retry.Error).Err.Error())
+	}
+	return classifyError(family, err)
+}
+
+// describe is call for the apis which do not change anything. The concurrent calls of an api with identical input
+// share one request and its output, so callers must not modify the output. The request is sent with the ctx of the
+// first caller, the others stop waiting for it when their own ctx is done.
+func (l *apiLimiter) describe(ctx context.Context, family apiFamily, apiName string, input interface{}, fn func() (interface{}, error)) (interface{}, error) {
+	content, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+	key := apiName + string(content)
+
+	l.lock.Lock()
+	if c, ok := l.calls[key]; ok {
+		l.lock.Unlock()
+		klog.V(4).Infof("Wait for the same %s request in flight", apiName)
+		select {
+		case <-c.done:
+			return c.output, c.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	c := &coalescedCall{done: make(chan struct{})}
+	l.calls[key] = c
+	l.lock.Unlock()
+
+	c.err = l.call(ctx, family, func() error {
+		var err error
+		c.output, err = fn()
+		return err
+	})
+
+	l.lock.Lock()
+	delete(l.calls, key)
+	l.lock.Unlock()
+	close(c.done)
+	return c.output, c.err
+}
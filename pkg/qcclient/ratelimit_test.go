@@ -0,0 +1,121 @@
+package qcclient
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/yunify/hostnic-cni/pkg/errors"
+	"github.com/yunify/hostnic-cni/pkg/retry"
+	sdkerrors "github.com/yunify/qingcloud-sdk-go/request/errors"
+)
+
+var _ = Describe("Ratelimit", func() {
+	It("Should parse rate limits of api families", func() {
+		limits := parseAPIRateLimits("nic=2:5, vxnet=0.5,tag=abc,router=1:-1,eip")
+		Expect(limits).To(Equal(map[apiFamily]apiRateLimit{
+			apiFamilyNic:   {qps: 2, burst: 5},
+			apiFamilyVxNet: {qps: 0.5, burst: defaultAPIBurst},
+		}))
+		Expect(parseAPIRateLimits("")).To(BeEmpty())
+	})
+
+	It("Should retry throttled requests with backoff", func() {
+		limiter := newAPILimiter(map[apiFamily]apiRateLimit{apiFamilyNic: {qps: 1000, burst: 10}})
+		limiter.backoff = time.Millisecond
+		calls := 0
+		err := limiter.call(context.Background(), apiFamilyNic, func() error {
+			calls++
+			if calls < 3 {
+				return &sdkerrors.QingCloudError{RetCode: 5100}
+			}
+			return nil
+		})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(calls).To(Equal(3))
+
+		calls = 0
+		err = limiter.call(context.Background(), apiFamilyNic, func() error {
+			calls++
+			return &sdkerrors.QingCloudError{RetCode: 2100}
+		})
+		Expect(err).Should(HaveOccurred())
+		Expect(calls).To(Equal(1))
+
+		calls = 0
+		err = limiter.call(context.Background(), apiFamilyNic, func() error {
+			calls++
+			return &sdkerrors.QingCloudError{RetCode: 5100}
+		})
+		Expect(errors.IsCloudThrottled(err)).To(BeTrue())
+		Expect(calls).To(Equal(rateLimitMaxRetries + 1))
+
+		err = limiter.call(context.Background(), apiFamilyNic, func() error {
+			return &sdkerrors.QingCloudError{RetCode: 2500}
+		})
+		Expect(errors.IsQuotaExceeded(err)).To(BeTrue())
+	})
+
+	It("Should stop retrying throttled requests when the context is done", func() {
+		limiter := newAPILimiter(map[apiFamily]apiRateLimit{apiFamilyNic: {qps: 1000, burst: 10}})
+		limiter.backoff = time.Hour
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		calls := 0
+		err := limiter.call(ctx, apiFamilyNic, func() error {
+			calls++
+			return &sdkerrors.QingCloudError{RetCode: 5100}
+		})
+		Expect(retry.IsCanceled(err)).To(BeTrue())
+		Expect(calls).To(Equal(1))
+
+		// the rate limit is not waited for either
+		limiter = newAPILimiter(map[apiFamily]apiRateLimit{apiFamilyNic: {qps: 0.001, burst: 1}})
+		Expect(limiter.call(context.Background(), apiFamilyNic, func() error { return nil })).To(Succeed())
+		ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		Expect(limiter.call(ctx, apiFamilyNic, func() error { return nil })).ShouldNot(Succeed())
+	})
+
+	It("Should coalesce identical concurrent describe calls", func() {
+		limiter := newAPILimiter(nil)
+		var calls int32
+		release := make(chan struct{})
+		describe := func(id string) (interface{}, error) {
+			return limiter.describe(context.Background(), apiFamilyNic, "DescribeNics", []string{id}, func() (interface{}, error) {
+				atomic.AddInt32(&calls, 1)
+				<-release
+				return id, nil
+			})
+		}
+
+		var wg sync.WaitGroup
+		results := make([]interface{}, 4)
+		for i := range results {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				id := "nic-1"
+				if i == 3 {
+					id = "nic-2"
+				}
+				results[i], _ = describe(id)
+			}(i)
+		}
+		Eventually(func() int {
+			limiter.lock.Lock()
+			defer limiter.lock.Unlock()
+			return len(limiter.calls)
+		}).Should(Equal(2))
+		// let the callers of the same request join it before it returns
+		time.Sleep(100 * time.Millisecond)
+		close(release)
+		wg.Wait()
+		Expect(atomic.LoadInt32(&calls)).To(BeEquivalentTo(2))
+		Expect(results).To(Equal([]interface{}{"nic-1", "nic-1", "nic-1", "nic-2"}))
+		Expect(limiter.calls).To(BeEmpty())
+	})
+})
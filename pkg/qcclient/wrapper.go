@@ -1,10 +1,12 @@
 package qcclient
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"math/rand"
 	"net"
+	"os"
 	"strings"
 	"time"
 
@@ -30,16 +32,26 @@ type LabelResourceConfig struct {
 }
 
 const (
-	nicPrefix            = "hostnic_"
-	instanceIDFile       = "/host/etc/qingcloud/instance-id"
+	nicPrefix      = "hostnic_"
+	instanceIDFile = "/host/etc/qingcloud/instance-id"
+	nicNumLimit    = 60
+	// MaxNICsPerInstance is the max number of nics attached to an instance, the primary one included
+	MaxNICsPerInstance = nicNumLimit
+
+	retryTimes    = 3
+	retryInterval = time.Second * 5
+
+	// envQingCloudEndpoint overrides the endpoint of the api in the config of the sdk,
+	// like https://api.qingcloud.com:443/iaas
+	envQingCloudEndpoint = "HOSTNIC_QINGCLOUD_ENDPOINT"
+)
+
+// The timeouts of waiting for jobs and nics are variables, so that tests against a fake api server do not wait long
+var (
 	defaultOpTimeout     = 180 * time.Second
 	defaultWaitInterval  = 10 * time.Second
 	waitNicLocalTimeout  = 20 * time.Second
 	waitNicLocalInterval = 2 * time.Second
-	nicNumLimit          = 60
-
-	retryTimes    = 3
-	retryInterval = time.Second * 5
 )
 
 var _ QingCloudAPI = &qingcloudAPIWrapper{}
@@ -51,6 +63,12 @@ type qingcloudAPIWrapper struct {
 	instanceService *service.InstanceService
 	vpcService      *service.RouterService
 	tagSerivce      *service.TagService
+	eipService      *service.EIPService
+	sgService       *service.SecurityGroupService
+	limiter         *apiLimiter
+	// api is the wrapper itself, or the cache wrapping it if there is one. The wrapper looks up vxnets and tags
+	// through it, so that the cache is used and invalidated when they change.
+	api QingCloudAPI
 
 	userID        string
 	instanceID    string
@@ -59,20 +77,66 @@ type qingcloudAPIWrapper struct {
 	vxnetLabelID  string
 	nicLabelID    string
 	labelResource bool
+	// maxIPsPerNIC is the max number of private ips of a nic, the primary one included
+	maxIPsPerNIC int
+}
+
+// ClientOptions overrides the settings which NewQingCloudClient loads from the host, the empty ones are loaded as
+// usual
+type ClientOptions struct {
+	// Endpoint is the url of the api, like https://api.qingcloud.com:443/iaas
+	Endpoint        string
+	AccessKeyID     string
+	SecretAccessKey string
+	Zone            string
+	InstanceID      string
+	// MaxIPsPerNIC is the max number of private ips of a nic, the primary one included. The secondary ones are not
+	// described if it is not more than 1, because not all clouds support them.
+	MaxIPsPerNIC int
 }
 
 // NewQingCloudClient create a qingcloud client to manipulate cloud resources
 func NewQingCloudClient(labelConfig *LabelResourceConfig) (QingCloudAPI, error) {
-	content, err := ioutil.ReadFile(instanceIDFile)
-	if err != nil {
-		return nil, fmt.Errorf("Load instance-id from %s error: %v", instanceIDFile, err)
+	return NewQingCloudClientWithOptions(labelConfig, ClientOptions{})
+}
+
+// NewQingCloudClientWithOptions create a qingcloud client with the settings in options
+func NewQingCloudClientWithOptions(labelConfig *LabelResourceConfig, options ClientOptions) (QingCloudAPI, error) {
+	if options.Endpoint == "" {
+		options.Endpoint = os.Getenv(envQingCloudEndpoint)
+	}
+	instanceID := options.InstanceID
+	if instanceID == "" {
+		content, err := ioutil.ReadFile(instanceIDFile)
+		if err != nil {
+			return nil, fmt.Errorf("Load instance-id from %s error: %v", instanceIDFile, err)
+		}
+		instanceID = string(content)
 	}
 	qsdkconfig, err := config.NewDefault()
 	if err != nil {
 		return nil, err
 	}
-	if err = qsdkconfig.LoadUserConfig(); err != nil {
-		return nil, err
+	if options.AccessKeyID == "" {
+		if err = qsdkconfig.LoadUserConfig(); err != nil {
+			return nil, err
+		}
+	} else {
+		qsdkconfig.AccessKeyID = options.AccessKeyID
+		qsdkconfig.SecretAccessKey = options.SecretAccessKey
+	}
+	if options.Zone != "" {
+		qsdkconfig.Zone = options.Zone
+	}
+	if options.Endpoint != "" {
+		endpoint, err := config.NewWithEndpoint(qsdkconfig.AccessKeyID, qsdkconfig.SecretAccessKey, options.Endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid endpoint %s, err: %v", options.Endpoint, err)
+		}
+		qsdkconfig.Protocol = endpoint.Protocol
+		qsdkconfig.Host = endpoint.Host
+		qsdkconfig.Port = endpoint.Port
+		qsdkconfig.URI = endpoint.URI
 	}
 	qcService, err := service.Init(qsdkconfig)
 	if err != nil {
@@ -97,6 +161,14 @@ func NewQingCloudClient(labelConfig *LabelResourceConfig) (QingCloudAPI, error)
 	}
 	vpcService, _ := qcService.Router(qsdkconfig.Zone)
 	tagService, _ := qcService.Tag(qsdkconfig.Zone)
+	eipService, err := qcService.EIP(qsdkconfig.Zone)
+	if err != nil {
+		return nil, err
+	}
+	sgService, err := qcService.SecurityGroup(qsdkconfig.Zone)
+	if err != nil {
+		return nil, err
+	}
 
 	//useid
 	api, _ := qcService.Accesskey(qsdkconfig.Zone)
@@ -118,9 +190,19 @@ func NewQingCloudClient(labelConfig *LabelResourceConfig) (QingCloudAPI, error)
 		instanceService: instanceService,
 		vpcService:      vpcService,
 		tagSerivce:      tagService,
+		eipService:      eipService,
+		sgService:       sgService,
+		limiter:         newAPILimiter(parseAPIRateLimits(os.Getenv(envAPIRateLimits))),
 		userID:          *output.AccessKeySet[0].Owner,
-		instanceID:      string(content),
+		instanceID:      instanceID,
+		maxIPsPerNIC:    options.MaxIPsPerNIC,
+	}
+	p.api = p
+	if cacheConfig := ParseCacheConfig(os.Getenv(envCacheTTLs)); cacheConfig.Enabled() {
+		klog.V(2).Infof("Cache cloud metadata with ttls %+v", cacheConfig)
+		p.api = NewCachedQingCloudAPI(p, cacheConfig)
 	}
+
 	if labelConfig != nil {
 		klog.V(2).Infoln("Ensuring labels")
 		p.labelResource = true
@@ -132,8 +214,7 @@ func NewQingCloudClient(labelConfig *LabelResourceConfig) (QingCloudAPI, error)
 			p.labelResource = false
 		}
 	}
-
-	return p, nil
+	return p.api, nil
 }
 
 func (q *qingcloudAPIWrapper) ensureLabels() error {
@@ -149,15 +230,16 @@ func (q *qingcloudAPIWrapper) ensureLabels() error {
 }
 
 func (q *qingcloudAPIWrapper) ensureLabel(label string, des *string) error {
-	l, err := q.GetTagByLabel(label)
+	l, err := q.api.GetTagByLabel(label)
 	if err != nil {
 		if errors.IsResourceNotFound(err) {
-			id, err := q.CreateTag(label, colors.RandomColor())
+			id, err := q.api.CreateTag(label, colors.RandomColor())
 			if err != nil {
 				klog.Errorf("Failed to create tag %s", label)
 				return err
 			}
 			*des = id
+			return nil
 		}
 		klog.Errorln("Failed to get tag by label")
 		return err
@@ -170,60 +252,120 @@ func (q *qingcloudAPIWrapper) GetInstanceID() string {
 	return q.instanceID
 }
 
-func (q *qingcloudAPIWrapper) CreateNic(vxnet string) (*types.HostNic, error) {
+func (q *qingcloudAPIWrapper) CreateNic(ctx context.Context, vxnet string) (*types.HostNic, error) {
+	nics, err := q.CreateNics(ctx, vxnet, 1)
+	if err != nil {
+		return nil, err
+	}
+	return nics[0], nil
+}
+
+// CreateNics creates count nics in the vxnet and attaches them to the instance with one request. The nics which
+// fail to be attached are deleted, the others are returned with the error. The nics are deleted as well if ctx is
+// done before they are described.
+func (q *qingcloudAPIWrapper) CreateNics(ctx context.Context, vxnet string, count int) ([]*types.HostNic, error) {
 	input := &service.CreateNicsInput{
 		VxNet:   &vxnet,
 		NICName: service.String(nicPrefix + q.instanceID),
+		Count:   &count,
 	}
-	output, err := q.nicService.CreateNics(input)
+	var output *service.CreateNicsOutput
+	err := q.limiter.call(ctx, apiFamilyNic, func() (err error) {
+		output, err = q.nicService.CreateNics(input)
+		return
+	})
 	//TODO check too many nic in vDxnet err, and retry with another vxnet.
 	if err != nil {
 		return nil, err
 	}
+	if *output.RetCode != 0 || len(output.Nics) == 0 {
+		return nil, fmt.Errorf("Failed to creat nic, error: %s", service.StringValue(output.Message))
+	}
 
-	if *output.RetCode == 0 && len(output.Nics) > 0 {
-		qcnic := output.Nics[0]
-		var hostnic *types.HostNic
+	ids := make([]string, 0, len(output.Nics))
+	for _, qcnic := range output.Nics {
+		ids = append(ids, *qcnic.NICID)
 		err = q.tagResource(q.nicLabelID, *qcnic.NICID, types.ResourceTypeNic)
 		if err != nil {
 			klog.Errorf("Failed to attach labels to nic %s, will continue. Error: %s", *qcnic.NICID, err.Error())
 		}
-		retry.Do(5, time.Second*3, func() error {
-			hostNics, err := q.GetNics([]string{*qcnic.NICID})
-			if err != nil {
-				return err
-			}
-			if len(hostNics) == 0 {
-				return fmt.Errorf("get empty nic")
-			}
-			hostnic = hostNics[0]
-			return nil
-		})
-		vn, err := q.GetVxNet(vxnet)
+	}
+	var hostnics []*types.HostNic
+	retry.DoWithBackoff(ctx, retry.Backoff{
+		Steps:    5,
+		Duration: time.Second,
+		Factor:   2,
+		Jitter:   0.2,
+		Cap:      time.Second * 5,
+	}, func() error {
+		hostNics, err := q.GetNics(ids)
 		if err != nil {
-			klog.Errorf("Failed to get vxnet of this nic")
-			return nil, err
+			return err
+		}
+		if len(hostNics) != len(ids) {
+			return fmt.Errorf("get %d nics of %d", len(hostNics), len(ids))
+		}
+		hostnics = hostNics
+		return nil
+	})
+	vn, err := q.api.GetVxNet(vxnet)
+	if err != nil || len(hostnics) != len(ids) {
+		klog.Errorf("Failed to get vxnet or info of nics %v", ids)
+		if deleteErr := q.deleteNics(ids); deleteErr != nil {
+			klog.Errorf("Failed to delete nics %v, err: %s", ids, deleteErr.Error())
 		}
+		if err == nil {
+			err = fmt.Errorf("failed to get info of nics %v", ids)
+		}
+		return nil, err
+	}
+	for _, hostnic := range hostnics {
 		hostnic.VxNet = vn
-		err = q.attachNic(hostnic)
-		if err != nil {
-			klog.Errorf("Failed to attach nic %s", *qcnic.NICID)
-			q.DeleteNic(*qcnic.NICID)
-			return nil, err
+	}
+
+	attached, err := q.attachNics(hostnics)
+	if len(attached) != len(hostnics) {
+		failed := make([]string, 0, len(hostnics)-len(attached))
+		for _, hostnic := range hostnics {
+			if !containsNic(attached, hostnic.ID) {
+				failed = append(failed, hostnic.ID)
+			}
+		}
+		klog.Errorf("Failed to attach nics %v", failed)
+		// the nics may be attached after waiting times out, so they are detached before deleted
+		if detachErr := q.detachNics(failed); detachErr != nil {
+			klog.Errorf("Failed to detach nics %v, err: %s", failed, detachErr.Error())
+		}
+		if deleteErr := q.deleteNics(failed); deleteErr != nil {
+			klog.Errorf("Failed to delete nics %v, err: %s", failed, deleteErr.Error())
+		}
+		if err == nil {
+			err = fmt.Errorf("failed to attach nics %v", failed)
+		}
+	}
+	if len(attached) == 0 {
+		return nil, err
+	}
+	return attached, err
+}
+
+func containsNic(nics []*types.HostNic, id string) bool {
+	for _, nic := range nics {
+		if nic.ID == id {
+			return true
 		}
-		return hostnic, nil
 	}
-	return nil, fmt.Errorf("Failed to creat nic, error: %s", *output.Message)
+	return false
 }
 
 func (q *qingcloudAPIWrapper) tagResource(tagid, resourceid string, resourceType types.ResourceType) error {
 	if q.labelResource {
-		err := q.TagResources(tagid, resourceType, resourceid)
+		err := q.api.TagResources(tagid, resourceType, resourceid)
 		if err != nil {
 			return err
 		}
 		for _, extraLabel := range q.extraLabels {
-			err = q.TagResources(extraLabel, resourceType, resourceid)
+			err = q.api.TagResources(extraLabel, resourceType, resourceid)
 			if err != nil {
 				return err
 			}
@@ -233,12 +375,16 @@ func (q *qingcloudAPIWrapper) tagResource(tagid, resourceid string, resourceType
 }
 
 func (q *qingcloudAPIWrapper) GetAttachedNICs(vxnet string) ([]*types.HostNic, error) {
-	output, err := q.nicService.DescribeNics(&service.DescribeNicsInput{
+	input := &service.DescribeNicsInput{
 		Instances: []*string{&q.instanceID},
 		Limit:     service.Int(nicNumLimit),
 		VxNets:    []*string{&vxnet},
 		VxNetType: []*int{service.Int(1)},
+	}
+	o, err := q.limiter.describe(context.Background(), apiFamilyNic, "DescribeNics", input, func() (interface{}, error) {
+		return q.nicService.DescribeNics(input)
 	})
+	output, _ := o.(*service.DescribeNicsOutput)
 	if err != nil {
 		return nil, err
 	}
@@ -250,50 +396,102 @@ func (q *qingcloudAPIWrapper) GetAttachedNICs(vxnet string) ([]*types.HostNic, e
 				VxNet: &types.VxNet{
 					ID: *nic.VxNetID,
 				},
-				HardwareAddr: *nic.NICID,
-				Address:      *nic.PrivateIP,
-				DeviceNumber: *nic.Sequence,
-				IsPrimary:    false,
+				HardwareAddr:  *nic.NICID,
+				Address:       *nic.PrivateIP,
+				DeviceNumber:  *nic.Sequence,
+				IsPrimary:     false,
+				SecurityGroup: service.StringValue(nic.SecurityGroup),
 			}
 			result = append(result, h)
 		}
 	}
+	if q.maxIPsPerNIC <= 1 {
+		return result, nil
+	}
+	ids := make([]string, 0, len(result))
+	for _, h := range result {
+		ids = append(ids, h.ID)
+	}
+	// the nics are still usable with their primary ips if the cloud does not describe the secondary ones
+	secondaryAddresses, err := q.getSecondaryAddresses(ids)
+	if isUnsupported(err) {
+		klog.Warningf("Failed to get secondary private ips of nics %v, assume there is none: %v", ids, err)
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range result {
+		for _, addr := range secondaryAddresses[h.ID] {
+			if addr != h.Address {
+				h.SecondaryAddresses = append(h.SecondaryAddresses, addr)
+			}
+		}
+	}
 	return result, nil
 }
 
-func (q *qingcloudAPIWrapper) attachNic(nic *types.HostNic) error {
-	input := &service.AttachNicsInput{Nics: []*string{&nic.HardwareAddr}, Instance: &q.instanceID}
-	output, err := q.nicService.AttachNics(input)
+// attachNics attaches nics with one request, and returns the nics which are attached
+func (q *qingcloudAPIWrapper) attachNics(nics []*types.HostNic) ([]*types.HostNic, error) {
+	ids := make([]*string, 0, len(nics))
+	for _, nic := range nics {
+		ids = append(ids, service.String(nic.HardwareAddr))
+	}
+	input := &service.AttachNicsInput{Nics: ids, Instance: &q.instanceID}
+	var output *service.AttachNicsOutput
+	err := q.limiter.call(context.Background(), apiFamilyNic, func() (err error) {
+		output, err = q.nicService.AttachNics(input)
+		return
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if *output.RetCode == 0 {
-		jobID := *output.JobID
-		err := q.waitNic(nic.ID, jobID)
-		if err != nil {
-			return err
+	if *output.RetCode != 0 {
+		return nil, fmt.Errorf("AttachNics output [%+v] error", *output)
+	}
+	err = q.waitNics(nics, *output.JobID)
+	if err == nil {
+		return nics, nil
+	}
+	// the job fails or times out as a whole, find the nics which are up on host
+	attached := make([]*types.HostNic, 0, len(nics))
+	for _, nic := range nics {
+		if nicIsUp(nic.ID) {
+			attached = append(attached, nic)
 		}
-		return nil
 	}
-	return fmt.Errorf("AttachNics output [%+v] error", *output)
+	return attached, err
+}
+
+func nicIsUp(nicid string) bool {
+	link, err := types.LinkByMacAddr(nicid)
+	if err != nil {
+		return false
+	}
+	return link.Attrs().Flags&net.FlagUp != 0 && link.Attrs().OperState&netlink.OperUp != 0
 }
 
-func (q *qingcloudAPIWrapper) waitNic(nicid, jobid string) error {
-	klog.V(2).Infoln("Waiting for nic attached")
+func (q *qingcloudAPIWrapper) waitNics(nics []*types.HostNic, jobid string) error {
+	klog.V(2).Infof("Waiting for %d nics attached", len(nics))
 	err := qcutil.WaitForSpecific(func() bool {
-		link, err := types.LinkByMacAddr(nicid)
-		if err != nil {
-			return false
-		}
-		if link.Attrs().Flags&net.FlagUp != 0 && link.Attrs().OperState&netlink.OperUp != 0 {
-			return true
+		for _, nic := range nics {
+			if !nicIsUp(nic.ID) {
+				return false
+			}
 		}
-		return false
+		return true
 	}, waitNicLocalTimeout, waitNicLocalInterval)
 	if _, ok := err.(*qcutil.TimeoutError); ok {
-		klog.V(2).Infof("Wait nic %s by local timeout", nicid)
+		klog.V(2).Infof("Wait nics by local timeout, wait job %s", jobid)
 		err = client.WaitJob(q.jobService, jobid, defaultOpTimeout, defaultWaitInterval)
 	}
+	if _, ok := err.(*qcutil.TimeoutError); ok {
+		ids := make([]string, 0, len(nics))
+		for _, nic := range nics {
+			ids = append(ids, nic.ID)
+		}
+		return errors.NewNICNotReadyError(strings.Join(ids, ","), fmt.Sprintf("job %s is not done in %v", jobid, defaultOpTimeout))
+	}
 	return err
 }
 
@@ -303,30 +501,39 @@ func (q *qingcloudAPIWrapper) DeleteNic(nicID string) error {
 
 func (q *qingcloudAPIWrapper) detachNics(nicIDs []string) error {
 	input := &service.DetachNicsInput{Nics: service.StringSlice(nicIDs)}
-	output, err := q.nicService.DetachNics(input)
+	var output *service.DetachNicsOutput
+	err := q.limiter.call(context.Background(), apiFamilyNic, func() (err error) {
+		output, err = q.nicService.DetachNics(input)
+		return
+	})
 	if err != nil {
 		return err
 	}
 	if *output.RetCode == 0 {
-		jobID := *output.JobID
-		//TODO optimize detachNic wait
-		err := client.WaitJob(q.jobService, jobID, defaultOpTimeout, defaultWaitInterval)
-		if err != nil {
-			return err
-		}
-		return nil
+		// the nics may disappear from host before the job is done, but they are only deletable after it
+		return client.WaitJob(q.jobService, *output.JobID, defaultOpTimeout, defaultWaitInterval)
 	}
 	return fmt.Errorf("DetachNics output error %s", *output.Message)
 }
 
+// DeleteNics detaches the nics with one request and deletes them
 func (q *qingcloudAPIWrapper) DeleteNics(nicIDs []string) error {
 	err := q.detachNics(nicIDs)
 	if err != nil {
 		klog.Errorf("Failed to detach nics")
 		return err
 	}
+	return q.deleteNics(nicIDs)
+}
+
+// deleteNics deletes detached nics
+func (q *qingcloudAPIWrapper) deleteNics(nicIDs []string) error {
 	input := &service.DeleteNicsInput{Nics: service.StringSlice(nicIDs)}
-	output, err := q.nicService.DeleteNics(input)
+	var output *service.DeleteNicsOutput
+	err := q.limiter.call(context.Background(), apiFamilyNic, func() (err error) {
+		output, err = q.nicService.DeleteNics(input)
+		return
+	})
 	if err != nil {
 		klog.Errorf("Failed to delete nics from %s", q.instanceID)
 		return err
@@ -348,9 +555,59 @@ func (q *qingcloudAPIWrapper) GetVxNet(vxNet string) (*types.VxNet, error) {
 	return output[0], nil
 }
 
+func (q *qingcloudAPIWrapper) GetEIP(eipID string) (*types.EIP, error) {
+	input := &service.DescribeEIPsInput{EIPs: []*string{&eipID}}
+	o, err := q.limiter.describe(context.Background(), apiFamilyEIP, "DescribeEIPs", input, func() (interface{}, error) {
+		return q.eipService.DescribeEIPs(input)
+	})
+	output, _ := o.(*service.DescribeEIPsOutput)
+	if err != nil {
+		return nil, err
+	}
+	if *output.RetCode != 0 {
+		return nil, fmt.Errorf("DescribeEIPs invalid output [%+v]", *output)
+	}
+	if len(output.EIPSet) == 0 {
+		return nil, errors.NewResourceNotFoundError(types.ResourceTypeEIP, eipID)
+	}
+	eip := output.EIPSet[0]
+	result := &types.EIP{
+		ID:      *eip.EIPID,
+		Address: *eip.EIPAddr,
+	}
+	if eip.Resource != nil && eip.Resource.ResourceID != nil && eip.Resource.ResourceType != nil {
+		result.ResourceID = *eip.Resource.ResourceID
+		result.ResourceType = types.ResourceType(*eip.Resource.ResourceType)
+	}
+	return result, nil
+}
+
+// ApplySecurityGroup binds nics to a security group. The instances of the API accept the ids of nics as well.
+func (q *qingcloudAPIWrapper) ApplySecurityGroup(securityGroupID string, nicIDs ...string) error {
+	input := &service.ApplySecurityGroupInput{
+		SecurityGroup: &securityGroupID,
+		Instances:     service.StringSlice(nicIDs),
+	}
+	var output *service.ApplySecurityGroupOutput
+	err := q.limiter.call(context.Background(), apiFamilySecurityGroup, func() (err error) {
+		output, err = q.sgService.ApplySecurityGroup(input)
+		return
+	})
+	if err != nil {
+		return err
+	}
+	if *output.RetCode != 0 {
+		return fmt.Errorf("Failed to apply security group %s to nics %v, err: %s", securityGroupID, nicIDs, *output.Message)
+	}
+	return client.WaitJob(q.jobService, *output.JobID, defaultOpTimeout, defaultWaitInterval)
+}
+
 func (q *qingcloudAPIWrapper) GetVxNets(ids []string) ([]*types.VxNet, error) {
 	input := &service.DescribeVxNetsInput{VxNets: service.StringSlice(ids)}
-	output, err := q.vxNetService.DescribeVxNets(input)
+	o, err := q.limiter.describe(context.Background(), apiFamilyVxNet, "DescribeVxNets", input, func() (interface{}, error) {
+		return q.vxNetService.DescribeVxNets(input)
+	})
+	output, _ := o.(*service.DescribeVxNetsOutput)
 	if err != nil {
 		return nil, err
 	}
@@ -373,7 +630,10 @@ func (q *qingcloudAPIWrapper) GetNics(ids []string) ([]*types.HostNic, error) {
 	input := &service.DescribeNicsInput{
 		Nics: service.StringSlice(ids),
 	}
-	output, err := q.nicService.DescribeNics(input)
+	o, err := q.limiter.describe(context.Background(), apiFamilyNic, "DescribeNics", input, func() (interface{}, error) {
+		return q.nicService.DescribeNics(input)
+	})
+	output, _ := o.(*service.DescribeNicsOutput)
 	if err != nil {
 		return nil, err
 	}
@@ -385,8 +645,9 @@ func (q *qingcloudAPIWrapper) GetNics(ids []string) ([]*types.HostNic, error) {
 				VxNet: &types.VxNet{
 					ID: *nic.VxNetID,
 				},
-				HardwareAddr: *nic.NICID,
-				Address:      *nic.PrivateIP,
+				HardwareAddr:  *nic.NICID,
+				Address:       *nic.PrivateIP,
+				SecurityGroup: service.StringValue(nic.SecurityGroup),
 			})
 		}
 		return niclist, nil
@@ -399,7 +660,11 @@ func (q *qingcloudAPIWrapper) CreateVxNet(name string) (*types.VxNet, error) {
 		VxNetType: service.Int(1),
 		VxNetName: &name,
 	}
-	output, err := q.vxNetService.CreateVxNets(input)
+	var output *service.CreateVxNetsOutput
+	err := q.limiter.call(context.Background(), apiFamilyVxNet, func() (err error) {
+		output, err = q.vxNetService.CreateVxNets(input)
+		return
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -418,7 +683,10 @@ func (q *qingcloudAPIWrapper) CreateVxNet(name string) (*types.VxNet, error) {
 
 func (q *qingcloudAPIWrapper) GetVxNetByName(name string) (*types.VxNet, error) {
 	input := &service.DescribeVxNetsInput{SearchWord: &name, Owner: &q.userID}
-	output, err := q.vxNetService.DescribeVxNets(input)
+	o, err := q.limiter.describe(context.Background(), apiFamilyVxNet, "DescribeVxNets", input, func() (interface{}, error) {
+		return q.vxNetService.DescribeVxNets(input)
+	})
+	output, _ := o.(*service.DescribeVxNetsOutput)
 	if err != nil {
 		return nil, err
 	}
@@ -437,12 +705,15 @@ func (q *qingcloudAPIWrapper) GetVxNetByName(name string) (*types.VxNet, error)
 	return nil, errors.NewResourceNotFoundError(types.ResourceTypeVxnet, name)
 }
 
-func (q *qingcloudAPIWrapper) GetNodeVPC() (*types.VPC, error) {
+func (q *qingcloudAPIWrapper) GetNodeVPC(ctx context.Context) (*types.VPC, error) {
 	input := &service.DescribeInstancesInput{
 		Instances: []*string{&q.instanceID},
 		Verbose:   service.Int(1),
 	}
-	output, err := q.instanceService.DescribeInstances(input)
+	o, err := q.limiter.describe(ctx, apiFamilyInstance, "DescribeInstances", input, func() (interface{}, error) {
+		return q.instanceService.DescribeInstances(input)
+	})
+	output, _ := o.(*service.DescribeInstancesOutput)
 	if err != nil {
 		return nil, err
 	}
@@ -466,14 +737,17 @@ func (q *qingcloudAPIWrapper) GetNodeVPC() (*types.VPC, error) {
 			return nil, fmt.Errorf("Vxnet is not under the same VPC's management")
 		}
 	}
-	return q.GetVPC(routerID)
+	return q.GetVPC(ctx, routerID)
 }
 
-func (q *qingcloudAPIWrapper) GetVPC(id string) (*types.VPC, error) {
+func (q *qingcloudAPIWrapper) GetVPC(ctx context.Context, id string) (*types.VPC, error) {
 	input := &service.DescribeRoutersInput{
 		Routers: []*string{&id},
 	}
-	output, err := q.vpcService.DescribeRouters(input)
+	o, err := q.limiter.describe(ctx, apiFamilyRouter, "DescribeRouters", input, func() (interface{}, error) {
+		return q.vpcService.DescribeRouters(input)
+	})
+	output, _ := o.(*service.DescribeRoutersOutput)
 	if err != nil {
 		return nil, err
 	}
@@ -488,7 +762,13 @@ func (q *qingcloudAPIWrapper) GetVPC(id string) (*types.VPC, error) {
 		return nil, err
 	}
 	vpc.Network = net
-	err = retry.Do(3, time.Second*5, func() error {
+	err = retry.DoWithBackoff(ctx, retry.Backoff{
+		Steps:     3,
+		Duration:  time.Second * 2,
+		Factor:    2,
+		Jitter:    0.2,
+		Retryable: retry.UnlessResourceNotFound,
+	}, func() error {
 		vpc.VxNets, err = q.GetVPCVxNets(vpc.ID)
 		if err != nil {
 			klog.V(3).Infof("[Will retry] Error in get vxnets of vpc %s", vpc.ID)
@@ -506,7 +786,10 @@ func (q *qingcloudAPIWrapper) GetVPCVxNets(vpcid string) ([]*types.VxNet, error)
 	input := &service.DescribeRouterVxNetsInput{
 		Router: &vpcid,
 	}
-	output, err := q.vpcService.DescribeRouterVxNets(input)
+	o, err := q.limiter.describe(context.Background(), apiFamilyRouter, "DescribeRouterVxNets", input, func() (interface{}, error) {
+		return q.vpcService.DescribeRouterVxNets(input)
+	})
+	output, _ := o.(*service.DescribeRouterVxNetsOutput)
 	if err != nil {
 		return nil, err
 	}
@@ -530,7 +813,11 @@ func (q *qingcloudAPIWrapper) JoinVPC(network, vxnetID, vpcID string) error {
 		Router:    &vpcID,
 		IPNetwork: &network,
 	}
-	output, err := q.vpcService.JoinRouter(input)
+	var output *service.JoinRouterOutput
+	err := q.limiter.call(context.Background(), apiFamilyRouter, func() (err error) {
+		output, err = q.vpcService.JoinRouter(input)
+		return
+	})
 	if err != nil {
 		return err
 	}
@@ -542,7 +829,11 @@ func (q *qingcloudAPIWrapper) LeaveVPC(vxnetID, vpcID string) error {
 		Router: &vpcID,
 		VxNets: []*string{&vxnetID},
 	}
-	output, err := q.vpcService.LeaveRouter(input)
+	var output *service.LeaveRouterOutput
+	err := q.limiter.call(context.Background(), apiFamilyRouter, func() (err error) {
+		output, err = q.vpcService.LeaveRouter(input)
+		return
+	})
 	if err != nil {
 		return err
 	}
@@ -557,7 +848,11 @@ func (q *qingcloudAPIWrapper) DeleteVxNet(id string) error {
 	input := &service.DeleteVxNetsInput{
 		VxNets: []*string{&id},
 	}
-	output, err := q.vxNetService.DeleteVxNets(input)
+	var output *service.DeleteVxNetsOutput
+	err := q.limiter.call(context.Background(), apiFamilyVxNet, func() (err error) {
+		output, err = q.vxNetService.DeleteVxNets(input)
+		return
+	})
 	if err != nil {
 		return err
 	}
@@ -574,7 +869,10 @@ func (q *qingcloudAPIWrapper) GetPrimaryNIC() (*types.HostNic, error) {
 		Limit:     service.Int(nicNumLimit),
 		VxNetType: []*int{service.Int(1)},
 	}
-	output, err := q.nicService.DescribeNics(input)
+	o, err := q.limiter.describe(context.Background(), apiFamilyNic, "DescribeNics", input, func() (interface{}, error) {
+		return q.nicService.DescribeNics(input)
+	})
+	output, _ := o.(*service.DescribeNicsOutput)
 	if err != nil {
 		return nil, err
 	}
@@ -585,10 +883,11 @@ func (q *qingcloudAPIWrapper) GetPrimaryNIC() (*types.HostNic, error) {
 				VxNet: &types.VxNet{
 					ID: *nic.VxNetID,
 				},
-				HardwareAddr: *nic.NICID,
-				Address:      *nic.PrivateIP,
-				IsPrimary:    true,
-				DeviceNumber: *nic.Sequence,
+				HardwareAddr:  *nic.NICID,
+				Address:       *nic.PrivateIP,
+				IsPrimary:     true,
+				DeviceNumber:  *nic.Sequence,
+				SecurityGroup: service.StringValue(nic.SecurityGroup),
 			}, nil
 		}
 	}
@@ -600,7 +899,10 @@ func (q *qingcloudAPIWrapper) GetTagByLabel(label string) (*types.Tag, error) {
 		SearchWord: &label,
 		Verbose:    service.Int(1),
 	}
-	output, err := q.tagSerivce.DescribeTags(input)
+	o, err := q.limiter.describe(context.Background(), apiFamilyTag, "DescribeTags", input, func() (interface{}, error) {
+		return q.tagSerivce.DescribeTags(input)
+	})
+	output, _ := o.(*service.DescribeTagsOutput)
 	if err != nil {
 		return nil, err
 	}
@@ -628,7 +930,10 @@ func (q *qingcloudAPIWrapper) GetTagByID(id string) (*types.Tag, error) {
 		Tags:    []*string{&id},
 		Verbose: service.Int(1),
 	}
-	output, err := q.tagSerivce.DescribeTags(input)
+	o, err := q.limiter.describe(context.Background(), apiFamilyTag, "DescribeTags", input, func() (interface{}, error) {
+		return q.tagSerivce.DescribeTags(input)
+	})
+	output, _ := o.(*service.DescribeTagsOutput)
 	if err != nil {
 		return nil, err
 	}
@@ -654,7 +959,11 @@ func (q *qingcloudAPIWrapper) CreateTag(label, color string) (string, error) {
 		Color:   &color,
 		TagName: &label,
 	}
-	output, err := q.tagSerivce.CreateTag(input)
+	var output *service.CreateTagOutput
+	err := q.limiter.call(context.Background(), apiFamilyTag, func() (err error) {
+		output, err = q.tagSerivce.CreateTag(input)
+		return
+	})
 	if err != nil {
 		return "", err
 	}
@@ -676,7 +985,11 @@ func (q *qingcloudAPIWrapper) TagResources(tagid string, resourceType types.Reso
 	input := &service.AttachTagsInput{
 		ResourceTagPairs: tags,
 	}
-	output, err := q.tagSerivce.AttachTags(input)
+	var output *service.AttachTagsOutput
+	err := q.limiter.call(context.Background(), apiFamilyTag, func() (err error) {
+		output, err = q.tagSerivce.AttachTags(input)
+		return
+	})
 	if err != nil {
 		return err
 	}
@@ -1,35 +1,139 @@
 package retry
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"math/rand"
 	"time"
+
+	hostnicerrors "github.com/yunify/hostnic-cni/pkg/errors"
 )
 
-var errMaxRetriesReached = errors.New("exceeded retry limit")
+var (
+	errMaxRetriesReached   = errors.New("exceeded retry limit")
+	errMaxElapsedTimeSpent = errors.New("exceeded max elapsed time")
+)
 
 // Func represents functions that can be retried.
 type Func func() error
 
+// Backoff describes how a function is retried. The zero value tries the function once.
+type Backoff struct {
+	// Steps is the max number of attempts, zero or negative means no limit
+	Steps int
+	// Duration is the interval before the first retry
+	Duration time.Duration
+	// Factor multiplies the interval after each retry, the interval is fixed if it is not greater than 1
+	Factor float64
+	// Jitter adds a random duration up to Jitter*interval to each interval
+	Jitter float64
+	// Cap is the max interval before jitter, zero means no limit
+	Cap time.Duration
+	// MaxElapsedTime stops retrying when it is spent since the first attempt, zero means no limit
+	MaxElapsedTime time.Duration
+	// Retryable tells whether an error is worth another attempt, all errors are if it is nil
+	Retryable func(error) bool
+}
+
+// Error is returned when the function is given up before it succeeds. It keeps the last error of the function,
+// which is returned by Cause.
+type Error struct {
+	Attempts int
+	Reason   error
+	Err      error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s after %d attempts, last error: %v", e.Reason, e.Attempts, e.Err)
+}
+
+// Cause returns the last error of the function
+func (e *Error) Cause() error {
+	return e.Err
+}
+
 // Do keeps trying the function until the second argument
 // returns false, or no error is returned.
 func Do(maxRetries int, interval time.Duration, fn Func) error {
-	var err error
-	attempt := 1
+	return DoWithBackoff(context.Background(), Backoff{Steps: maxRetries, Duration: interval}, fn)
+}
+
+// DoWithBackoff keeps trying the function until it succeeds, it returns an error which is not retryable, the
+// backoff runs out or the context is done. The error which is not retryable is returned as it is, otherwise an
+// *Error with the last error of the function is returned.
+func DoWithBackoff(ctx context.Context, backoff Backoff, fn Func) error {
+	start := time.Now()
+	interval := backoff.Duration
+	attempt := 0
 	for {
-		err = fn()
+		attempt++
+		err := fn()
 		if err == nil {
 			return nil
 		}
-		attempt++
-		if attempt > maxRetries {
-			return errMaxRetriesReached
+		if backoff.Retryable != nil && !backoff.Retryable(err) {
+			return err
+		}
+		if backoff.Steps > 0 && attempt >= backoff.Steps {
+			return &Error{Attempts: attempt, Reason: errMaxRetriesReached, Err: err}
+		}
+
+		sleep := interval
+		if backoff.Jitter > 0 {
+			sleep += time.Duration(rand.Float64() * backoff.Jitter * float64(interval))
+		}
+		if backoff.MaxElapsedTime > 0 && time.Since(start)+sleep > backoff.MaxElapsedTime {
+			return &Error{Attempts: attempt, Reason: errMaxElapsedTimeSpent, Err: err}
+		}
+		timer := time.NewTimer(sleep)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return &Error{Attempts: attempt, Reason: ctx.Err(), Err: err}
+		case <-timer.C:
+		}
+
+		if backoff.Factor > 1 {
+			interval = time.Duration(float64(interval) * backoff.Factor)
+		}
+		if backoff.Cap > 0 && interval > backoff.Cap {
+			interval = backoff.Cap
 		}
-		time.Sleep(interval)
 	}
 }
 
 // IsMaxRetries checks whether the error is due to hitting the
 // maximum number of retries or not.
 func IsMaxRetries(err error) bool {
-	return err == errMaxRetriesReached
+	if err == errMaxRetriesReached {
+		return true
+	}
+	e, ok := err.(*Error)
+	return ok && (e.Reason == errMaxRetriesReached || e.Reason == errMaxElapsedTimeSpent)
+}
+
+// IsCanceled checks whether the error is due to the context being done
+func IsCanceled(err error) bool {
+	e, ok := err.(*Error)
+	return ok && (e.Reason == context.Canceled || e.Reason == context.DeadlineExceeded)
+}
+
+// UnlessResourceNotFound is a Retryable which stops retrying when a resource is not found, because it will not
+// show up by itself.
+func UnlessResourceNotFound(err error) bool {
+	return !hostnicerrors.IsResourceNotFound(err)
+}
+
+// WithStopChannel returns a context which is canceled when the stop channel is closed or the cancel func is called
+func WithStopChannel(stopCh <-chan struct{}) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		select {
+		case <-stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
 }
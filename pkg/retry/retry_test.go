@@ -1,11 +1,14 @@
 package retry_test
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	hostnicerrors "github.com/yunify/hostnic-cni/pkg/errors"
 	"github.com/yunify/hostnic-cni/pkg/retry"
 )
 
@@ -29,4 +32,78 @@ var _ = Describe("Retry", func() {
 		}
 		Expect(retry.Do(5, time.Second, willOK)).ShouldNot(HaveOccurred())
 	})
+
+	It("Should keep the last error", func() {
+		i := 0
+		err := retry.Do(3, time.Millisecond, func() error {
+			i++
+			return fmt.Errorf("error %d", i)
+		})
+		Expect(retry.IsMaxRetries(err)).To(BeTrue())
+		Expect(errors.Cause(err)).To(MatchError("error 3"))
+	})
+
+	It("Should back off exponentially up to the cap", func() {
+		var last time.Time
+		var intervals []time.Duration
+		err := retry.DoWithBackoff(context.Background(), retry.Backoff{
+			Steps:    5,
+			Duration: 10 * time.Millisecond,
+			Factor:   2,
+			Cap:      30 * time.Millisecond,
+		}, func() error {
+			if !last.IsZero() {
+				intervals = append(intervals, time.Since(last))
+			}
+			last = time.Now()
+			return fmt.Errorf("Error")
+		})
+		Expect(retry.IsMaxRetries(err)).To(BeTrue())
+		Expect(intervals).To(HaveLen(4))
+		for i, min := range []time.Duration{10, 20, 30, 30} {
+			Expect(intervals[i]).To(BeNumerically(">=", min*time.Millisecond))
+		}
+		Expect(intervals[3]).To(BeNumerically("<", 60*time.Millisecond))
+	})
+
+	It("Should stop on errors which are not retryable", func() {
+		i := 0
+		err := retry.DoWithBackoff(context.Background(), retry.Backoff{
+			Duration:  time.Millisecond,
+			Retryable: retry.UnlessResourceNotFound,
+		}, func() error {
+			i++
+			if i == 2 {
+				return hostnicerrors.NewResourceNotFoundError("vxnet", "vxnet-1")
+			}
+			return fmt.Errorf("Error")
+		})
+		Expect(i).To(Equal(2))
+		Expect(hostnicerrors.IsResourceNotFound(err)).To(BeTrue())
+		Expect(retry.IsMaxRetries(err)).To(BeFalse())
+	})
+
+	It("Should stop when the max elapsed time is spent", func() {
+		err := retry.DoWithBackoff(context.Background(), retry.Backoff{
+			Duration:       20 * time.Millisecond,
+			Jitter:         0.5,
+			MaxElapsedTime: 50 * time.Millisecond,
+		}, func() error {
+			return fmt.Errorf("Error")
+		})
+		Expect(retry.IsMaxRetries(err)).To(BeTrue())
+		Expect(err.(*retry.Error).Attempts).To(BeNumerically("<=", 3))
+	})
+
+	It("Should stop when the context is done", func() {
+		stopCh := make(chan struct{})
+		ctx, cancel := retry.WithStopChannel(stopCh)
+		defer cancel()
+		time.AfterFunc(20*time.Millisecond, func() { close(stopCh) })
+		err := retry.DoWithBackoff(ctx, retry.Backoff{Duration: time.Millisecond}, func() error {
+			return fmt.Errorf("Error")
+		})
+		Expect(retry.IsCanceled(err)).To(BeTrue())
+		Expect(errors.Cause(err)).To(MatchError("Error"))
+	})
 })
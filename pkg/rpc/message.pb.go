@@ -13,6 +13,27 @@
 		AddNetworkReply
 		DelNetworkRequest
 		DelNetworkReply
+		ReadyRequest
+		ReadyReply
+		ListPodsRequest
+		PodIP
+		ListPodsReply
+		ListNICsRequest
+		NICAddress
+		NIC
+		ListNICsReply
+		PoolStatusRequest
+		PoolStatusReply
+		ReleaseIPRequest
+		ReleaseIPReply
+		ReconcileRequest
+		ReconcileReply
+		DumpRulesRequest
+		Rule
+		DumpRulesReply
+		VerifyPodRequest
+		Check
+		VerifyPodReply
 */
 package rpc
 
@@ -44,6 +65,7 @@ type AddNetworkRequest struct {
 	K8S_POD_INFRA_CONTAINER_ID string `protobuf:"bytes,3,opt,name=K8S_POD_INFRA_CONTAINER_ID,json=K8SPODINFRACONTAINERID,proto3" json:"K8S_POD_INFRA_CONTAINER_ID,omitempty"`
 	Netns                      string `protobuf:"bytes,4,opt,name=Netns,proto3" json:"Netns,omitempty"`
 	IfName                     string `protobuf:"bytes,5,opt,name=IfName,proto3" json:"IfName,omitempty"`
+	APIVersion                 int32  `protobuf:"varint,6,opt,name=APIVersion,proto3" json:"APIVersion,omitempty"`
 }
 
 func (m *AddNetworkRequest) Reset()                    { *m = AddNetworkRequest{} }
@@ -86,6 +108,13 @@ func (m *AddNetworkRequest) GetIfName() string {
 	return ""
 }
 
+func (m *AddNetworkRequest) GetAPIVersion() int32 {
+	if m != nil {
+		return m.APIVersion
+	}
+	return 0
+}
+
 type AddNetworkReply struct {
 	Success         bool     `protobuf:"varint,1,opt,name=Success,proto3" json:"Success,omitempty"`
 	IPv4Addr        string   `protobuf:"bytes,2,opt,name=IPv4Addr,proto3" json:"IPv4Addr,omitempty"`
@@ -94,6 +123,9 @@ type AddNetworkReply struct {
 	UseExternalSNAT bool     `protobuf:"varint,5,opt,name=UseExternalSNAT,proto3" json:"UseExternalSNAT,omitempty"`
 	Message         string   `protobuf:"bytes,6,opt,name=Message,proto3" json:"Message,omitempty"`
 	VPCcidrs        []string `protobuf:"bytes,7,rep,name=VPCcidrs" json:"VPCcidrs,omitempty"`
+	PerNICSNAT      bool     `protobuf:"varint,8,opt,name=PerNICSNAT,proto3" json:"PerNICSNAT,omitempty"`
+	MTU             int32    `protobuf:"varint,9,opt,name=MTU,proto3" json:"MTU,omitempty"`
+	ErrorType       string   `protobuf:"bytes,10,opt,name=ErrorType,proto3" json:"ErrorType,omitempty"`
 }
 
 func (m *AddNetworkReply) Reset()                    { *m = AddNetworkReply{} }
@@ -150,12 +182,34 @@ func (m *AddNetworkReply) GetVPCcidrs() []string {
 	return nil
 }
 
+func (m *AddNetworkReply) GetPerNICSNAT() bool {
+	if m != nil {
+		return m.PerNICSNAT
+	}
+	return false
+}
+
+func (m *AddNetworkReply) GetMTU() int32 {
+	if m != nil {
+		return m.MTU
+	}
+	return 0
+}
+
+func (m *AddNetworkReply) GetErrorType() string {
+	if m != nil {
+		return m.ErrorType
+	}
+	return ""
+}
+
 type DelNetworkRequest struct {
 	K8S_POD_NAME               string `protobuf:"bytes,1,opt,name=K8S_POD_NAME,json=K8SPODNAME,proto3" json:"K8S_POD_NAME,omitempty"`
 	K8S_POD_NAMESPACE          string `protobuf:"bytes,2,opt,name=K8S_POD_NAMESPACE,json=K8SPODNAMESPACE,proto3" json:"K8S_POD_NAMESPACE,omitempty"`
 	K8S_POD_INFRA_CONTAINER_ID string `protobuf:"bytes,3,opt,name=K8S_POD_INFRA_CONTAINER_ID,json=K8SPODINFRACONTAINERID,proto3" json:"K8S_POD_INFRA_CONTAINER_ID,omitempty"`
 	IPv4Addr                   string `protobuf:"bytes,4,opt,name=IPv4Addr,proto3" json:"IPv4Addr,omitempty"`
 	Reason                     string `protobuf:"bytes,5,opt,name=Reason,proto3" json:"Reason,omitempty"`
+	APIVersion                 int32  `protobuf:"varint,6,opt,name=APIVersion,proto3" json:"APIVersion,omitempty"`
 }
 
 func (m *DelNetworkRequest) Reset()                    { *m = DelNetworkRequest{} }
@@ -198,11 +252,19 @@ func (m *DelNetworkRequest) GetReason() string {
 	return ""
 }
 
+func (m *DelNetworkRequest) GetAPIVersion() int32 {
+	if m != nil {
+		return m.APIVersion
+	}
+	return 0
+}
+
 type DelNetworkReply struct {
 	Success      bool   `protobuf:"varint,1,opt,name=Success,proto3" json:"Success,omitempty"`
 	IPv4Addr     string `protobuf:"bytes,2,opt,name=IPv4Addr,proto3" json:"IPv4Addr,omitempty"`
 	DeviceNumber int32  `protobuf:"varint,3,opt,name=DeviceNumber,proto3" json:"DeviceNumber,omitempty"`
 	Message      string `protobuf:"bytes,4,opt,name=Message,proto3" json:"Message,omitempty"`
+	ErrorType    string `protobuf:"bytes,5,opt,name=ErrorType,proto3" json:"ErrorType,omitempty"`
 }
 
 func (m *DelNetworkReply) Reset()                    { *m = DelNetworkReply{} }
@@ -238,481 +300,4879 @@ func (m *DelNetworkReply) GetMessage() string {
 	return ""
 }
 
-func init() {
-	proto.RegisterType((*AddNetworkRequest)(nil), "rpc.AddNetworkRequest")
-	proto.RegisterType((*AddNetworkReply)(nil), "rpc.AddNetworkReply")
-	proto.RegisterType((*DelNetworkRequest)(nil), "rpc.DelNetworkRequest")
-	proto.RegisterType((*DelNetworkReply)(nil), "rpc.DelNetworkReply")
+func (m *DelNetworkReply) GetErrorType() string {
+	if m != nil {
+		return m.ErrorType
+	}
+	return ""
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
-
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
+type ReadyRequest struct {
+	APIVersion int32 `protobuf:"varint,1,opt,name=APIVersion,proto3" json:"APIVersion,omitempty"`
+}
 
-// Client API for CNIBackend service
+func (m *ReadyRequest) Reset()                    { *m = ReadyRequest{} }
+func (m *ReadyRequest) String() string            { return proto.CompactTextString(m) }
+func (*ReadyRequest) ProtoMessage()               {}
+func (*ReadyRequest) Descriptor() ([]byte, []int) { return fileDescriptorMessage, []int{4} }
 
-type CNIBackendClient interface {
-	AddNetwork(ctx context.Context, in *AddNetworkRequest, opts ...grpc.CallOption) (*AddNetworkReply, error)
-	DelNetwork(ctx context.Context, in *DelNetworkRequest, opts ...grpc.CallOption) (*DelNetworkReply, error)
+func (m *ReadyRequest) GetAPIVersion() int32 {
+	if m != nil {
+		return m.APIVersion
+	}
+	return 0
 }
 
-type cNIBackendClient struct {
-	cc *grpc.ClientConn
+type ReadyReply struct {
+	Ready      bool   `protobuf:"varint,1,opt,name=Ready,proto3" json:"Ready,omitempty"`
+	Message    string `protobuf:"bytes,2,opt,name=Message,proto3" json:"Message,omitempty"`
+	APIVersion int32  `protobuf:"varint,3,opt,name=APIVersion,proto3" json:"APIVersion,omitempty"`
 }
 
-func NewCNIBackendClient(cc *grpc.ClientConn) CNIBackendClient {
-	return &cNIBackendClient{cc}
-}
+func (m *ReadyReply) Reset()                    { *m = ReadyReply{} }
+func (m *ReadyReply) String() string            { return proto.CompactTextString(m) }
+func (*ReadyReply) ProtoMessage()               {}
+func (*ReadyReply) Descriptor() ([]byte, []int) { return fileDescriptorMessage, []int{5} }
 
-func (c *cNIBackendClient) AddNetwork(ctx context.Context, in *AddNetworkRequest, opts ...grpc.CallOption) (*AddNetworkReply, error) {
-	out := new(AddNetworkReply)
-	err := grpc.Invoke(ctx, "/rpc.CNIBackend/AddNetwork", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
+func (m *ReadyReply) GetReady() bool {
+	if m != nil {
+		return m.Ready
 	}
-	return out, nil
+	return false
 }
 
-func (c *cNIBackendClient) DelNetwork(ctx context.Context, in *DelNetworkRequest, opts ...grpc.CallOption) (*DelNetworkReply, error) {
-	out := new(DelNetworkReply)
-	err := grpc.Invoke(ctx, "/rpc.CNIBackend/DelNetwork", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
+func (m *ReadyReply) GetMessage() string {
+	if m != nil {
+		return m.Message
 	}
-	return out, nil
+	return ""
 }
 
-// Server API for CNIBackend service
+func (m *ReadyReply) GetAPIVersion() int32 {
+	if m != nil {
+		return m.APIVersion
+	}
+	return 0
+}
 
-type CNIBackendServer interface {
-	AddNetwork(context.Context, *AddNetworkRequest) (*AddNetworkReply, error)
-	DelNetwork(context.Context, *DelNetworkRequest) (*DelNetworkReply, error)
+type ListPodsRequest struct {
 }
 
-func RegisterCNIBackendServer(s *grpc.Server, srv CNIBackendServer) {
-	s.RegisterService(&_CNIBackend_serviceDesc, srv)
+func (m *ListPodsRequest) Reset()                    { *m = ListPodsRequest{} }
+func (m *ListPodsRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListPodsRequest) ProtoMessage()               {}
+func (*ListPodsRequest) Descriptor() ([]byte, []int) { return fileDescriptorMessage, []int{6} }
+
+type PodIP struct {
+	Name         string `protobuf:"bytes,1,opt,name=Name,proto3" json:"Name,omitempty"`
+	Namespace    string `protobuf:"bytes,2,opt,name=Namespace,proto3" json:"Namespace,omitempty"`
+	Container    string `protobuf:"bytes,3,opt,name=Container,proto3" json:"Container,omitempty"`
+	IP           string `protobuf:"bytes,4,opt,name=IP,proto3" json:"IP,omitempty"`
+	DeviceNumber int32  `protobuf:"varint,5,opt,name=DeviceNumber,proto3" json:"DeviceNumber,omitempty"`
+	NIC          string `protobuf:"bytes,6,opt,name=NIC,proto3" json:"NIC,omitempty"`
+	Egress       string `protobuf:"bytes,7,opt,name=Egress,proto3" json:"Egress,omitempty"`
 }
 
-func _CNIBackend_AddNetwork_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(AddNetworkRequest)
-	if err := dec(in); err != nil {
-		return nil, err
+func (m *PodIP) Reset()                    { *m = PodIP{} }
+func (m *PodIP) String() string            { return proto.CompactTextString(m) }
+func (*PodIP) ProtoMessage()               {}
+func (*PodIP) Descriptor() ([]byte, []int) { return fileDescriptorMessage, []int{7} }
+
+func (m *PodIP) GetName() string {
+	if m != nil {
+		return m.Name
 	}
-	if interceptor == nil {
-		return srv.(CNIBackendServer).AddNetwork(ctx, in)
+	return ""
+}
+
+func (m *PodIP) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
 	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/rpc.CNIBackend/AddNetwork",
+	return ""
+}
+
+func (m *PodIP) GetContainer() string {
+	if m != nil {
+		return m.Container
 	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(CNIBackendServer).AddNetwork(ctx, req.(*AddNetworkRequest))
+	return ""
+}
+
+func (m *PodIP) GetIP() string {
+	if m != nil {
+		return m.IP
 	}
-	return interceptor(ctx, in, info, handler)
+	return ""
 }
 
-func _CNIBackend_DelNetwork_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(DelNetworkRequest)
-	if err := dec(in); err != nil {
-		return nil, err
+func (m *PodIP) GetDeviceNumber() int32 {
+	if m != nil {
+		return m.DeviceNumber
 	}
-	if interceptor == nil {
-		return srv.(CNIBackendServer).DelNetwork(ctx, in)
+	return 0
+}
+
+func (m *PodIP) GetNIC() string {
+	if m != nil {
+		return m.NIC
 	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/rpc.CNIBackend/DelNetwork",
+	return ""
+}
+
+func (m *PodIP) GetEgress() string {
+	if m != nil {
+		return m.Egress
 	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(CNIBackendServer).DelNetwork(ctx, req.(*DelNetworkRequest))
+	return ""
+}
+
+type ListPodsReply struct {
+	Pods []*PodIP `protobuf:"bytes,1,rep,name=Pods,proto3" json:"Pods,omitempty"`
+}
+
+func (m *ListPodsReply) Reset()                    { *m = ListPodsReply{} }
+func (m *ListPodsReply) String() string            { return proto.CompactTextString(m) }
+func (*ListPodsReply) ProtoMessage()               {}
+func (*ListPodsReply) Descriptor() ([]byte, []int) { return fileDescriptorMessage, []int{8} }
+
+func (m *ListPodsReply) GetPods() []*PodIP {
+	if m != nil {
+		return m.Pods
 	}
-	return interceptor(ctx, in, info, handler)
+	return nil
 }
 
-var _CNIBackend_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "rpc.CNIBackend",
-	HandlerType: (*CNIBackendServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "AddNetwork",
-			Handler:    _CNIBackend_AddNetwork_Handler,
-		},
-		{
-			MethodName: "DelNetwork",
-			Handler:    _CNIBackend_DelNetwork_Handler,
-		},
-	},
-	Streams:  []grpc.StreamDesc{},
-	Metadata: "pkg/rpc/message.proto",
+type ListNICsRequest struct {
 }
 
-func (m *AddNetworkRequest) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
+func (m *ListNICsRequest) Reset()                    { *m = ListNICsRequest{} }
+func (m *ListNICsRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListNICsRequest) ProtoMessage()               {}
+func (*ListNICsRequest) Descriptor() ([]byte, []int) { return fileDescriptorMessage, []int{9} }
+
+type NICAddress struct {
+	IP        string `protobuf:"bytes,1,opt,name=IP,proto3" json:"IP,omitempty"`
+	Secondary bool   `protobuf:"varint,2,opt,name=Secondary,proto3" json:"Secondary,omitempty"`
+	Pod       string `protobuf:"bytes,3,opt,name=Pod,proto3" json:"Pod,omitempty"`
+}
+
+func (m *NICAddress) Reset()                    { *m = NICAddress{} }
+func (m *NICAddress) String() string            { return proto.CompactTextString(m) }
+func (*NICAddress) ProtoMessage()               {}
+func (*NICAddress) Descriptor() ([]byte, []int) { return fileDescriptorMessage, []int{10} }
+
+func (m *NICAddress) GetIP() string {
+	if m != nil {
+		return m.IP
 	}
-	return dAtA[:n], nil
+	return ""
 }
 
-func (m *AddNetworkRequest) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if len(m.K8S_POD_NAME) > 0 {
-		dAtA[i] = 0xa
-		i++
-		i = encodeVarintMessage(dAtA, i, uint64(len(m.K8S_POD_NAME)))
-		i += copy(dAtA[i:], m.K8S_POD_NAME)
+func (m *NICAddress) GetSecondary() bool {
+	if m != nil {
+		return m.Secondary
 	}
-	if len(m.K8S_POD_NAMESPACE) > 0 {
-		dAtA[i] = 0x12
-		i++
-		i = encodeVarintMessage(dAtA, i, uint64(len(m.K8S_POD_NAMESPACE)))
-		i += copy(dAtA[i:], m.K8S_POD_NAMESPACE)
+	return false
+}
+
+func (m *NICAddress) GetPod() string {
+	if m != nil {
+		return m.Pod
 	}
-	if len(m.K8S_POD_INFRA_CONTAINER_ID) > 0 {
-		dAtA[i] = 0x1a
-		i++
-		i = encodeVarintMessage(dAtA, i, uint64(len(m.K8S_POD_INFRA_CONTAINER_ID)))
-		i += copy(dAtA[i:], m.K8S_POD_INFRA_CONTAINER_ID)
+	return ""
+}
+
+type NIC struct {
+	ID            string        `protobuf:"bytes,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	DeviceNumber  int32         `protobuf:"varint,2,opt,name=DeviceNumber,proto3" json:"DeviceNumber,omitempty"`
+	Primary       bool          `protobuf:"varint,3,opt,name=Primary,proto3" json:"Primary,omitempty"`
+	VxNet         string        `protobuf:"bytes,4,opt,name=VxNet,proto3" json:"VxNet,omitempty"`
+	SecurityGroup string        `protobuf:"bytes,5,opt,name=SecurityGroup,proto3" json:"SecurityGroup,omitempty"`
+	Reserved      bool          `protobuf:"varint,6,opt,name=Reserved,proto3" json:"Reserved,omitempty"`
+	Addresses     []*NICAddress `protobuf:"bytes,7,rep,name=Addresses,proto3" json:"Addresses,omitempty"`
+	Routes        []string      `protobuf:"bytes,8,rep,name=Routes,proto3" json:"Routes,omitempty"`
+}
+
+func (m *NIC) Reset()                    { *m = NIC{} }
+func (m *NIC) String() string            { return proto.CompactTextString(m) }
+func (*NIC) ProtoMessage()               {}
+func (*NIC) Descriptor() ([]byte, []int) { return fileDescriptorMessage, []int{11} }
+
+func (m *NIC) GetID() string {
+	if m != nil {
+		return m.ID
 	}
-	if len(m.Netns) > 0 {
-		dAtA[i] = 0x22
-		i++
-		i = encodeVarintMessage(dAtA, i, uint64(len(m.Netns)))
-		i += copy(dAtA[i:], m.Netns)
+	return ""
+}
+
+func (m *NIC) GetDeviceNumber() int32 {
+	if m != nil {
+		return m.DeviceNumber
 	}
-	if len(m.IfName) > 0 {
-		dAtA[i] = 0x2a
-		i++
-		i = encodeVarintMessage(dAtA, i, uint64(len(m.IfName)))
-		i += copy(dAtA[i:], m.IfName)
+	return 0
+}
+
+func (m *NIC) GetPrimary() bool {
+	if m != nil {
+		return m.Primary
 	}
-	return i, nil
+	return false
 }
 
-func (m *AddNetworkReply) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
+func (m *NIC) GetVxNet() string {
+	if m != nil {
+		return m.VxNet
 	}
-	return dAtA[:n], nil
+	return ""
 }
 
-func (m *AddNetworkReply) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if m.Success {
-		dAtA[i] = 0x8
-		i++
-		if m.Success {
-			dAtA[i] = 1
-		} else {
-			dAtA[i] = 0
-		}
-		i++
+func (m *NIC) GetSecurityGroup() string {
+	if m != nil {
+		return m.SecurityGroup
 	}
-	if len(m.IPv4Addr) > 0 {
-		dAtA[i] = 0x12
-		i++
-		i = encodeVarintMessage(dAtA, i, uint64(len(m.IPv4Addr)))
-		i += copy(dAtA[i:], m.IPv4Addr)
+	return ""
+}
+
+func (m *NIC) GetReserved() bool {
+	if m != nil {
+		return m.Reserved
 	}
-	if len(m.IPv4Subnet) > 0 {
-		dAtA[i] = 0x1a
-		i++
-		i = encodeVarintMessage(dAtA, i, uint64(len(m.IPv4Subnet)))
-		i += copy(dAtA[i:], m.IPv4Subnet)
-	}
-	if m.DeviceNumber != 0 {
-		dAtA[i] = 0x20
-		i++
-		i = encodeVarintMessage(dAtA, i, uint64(m.DeviceNumber))
+	return false
+}
+
+func (m *NIC) GetAddresses() []*NICAddress {
+	if m != nil {
+		return m.Addresses
 	}
-	if m.UseExternalSNAT {
-		dAtA[i] = 0x28
-		i++
-		if m.UseExternalSNAT {
-			dAtA[i] = 1
-		} else {
-			dAtA[i] = 0
-		}
-		i++
+	return nil
+}
+
+func (m *NIC) GetRoutes() []string {
+	if m != nil {
+		return m.Routes
 	}
-	if len(m.Message) > 0 {
-		dAtA[i] = 0x32
-		i++
-		i = encodeVarintMessage(dAtA, i, uint64(len(m.Message)))
-		i += copy(dAtA[i:], m.Message)
+	return nil
+}
+
+type ListNICsReply struct {
+	NICs []*NIC `protobuf:"bytes,1,rep,name=NICs,proto3" json:"NICs,omitempty"`
+}
+
+func (m *ListNICsReply) Reset()                    { *m = ListNICsReply{} }
+func (m *ListNICsReply) String() string            { return proto.CompactTextString(m) }
+func (*ListNICsReply) ProtoMessage()               {}
+func (*ListNICsReply) Descriptor() ([]byte, []int) { return fileDescriptorMessage, []int{12} }
+
+func (m *ListNICsReply) GetNICs() []*NIC {
+	if m != nil {
+		return m.NICs
 	}
-	if len(m.VPCcidrs) > 0 {
-		for _, s := range m.VPCcidrs {
-			dAtA[i] = 0x3a
-			i++
-			l = len(s)
-			for l >= 1<<7 {
-				dAtA[i] = uint8(uint64(l)&0x7f | 0x80)
-				l >>= 7
-				i++
-			}
-			dAtA[i] = uint8(l)
-			i++
-			i += copy(dAtA[i:], s)
-		}
+	return nil
+}
+
+type PoolStatusRequest struct {
+}
+
+func (m *PoolStatusRequest) Reset()                    { *m = PoolStatusRequest{} }
+func (m *PoolStatusRequest) String() string            { return proto.CompactTextString(m) }
+func (*PoolStatusRequest) ProtoMessage()               {}
+func (*PoolStatusRequest) Descriptor() ([]byte, []int) { return fileDescriptorMessage, []int{13} }
+
+type PoolStatusReply struct {
+	Ready           bool   `protobuf:"varint,1,opt,name=Ready,proto3" json:"Ready,omitempty"`
+	Message         string `protobuf:"bytes,2,opt,name=Message,proto3" json:"Message,omitempty"`
+	Stage           string `protobuf:"bytes,3,opt,name=Stage,proto3" json:"Stage,omitempty"`
+	VxNet           string `protobuf:"bytes,4,opt,name=VxNet,proto3" json:"VxNet,omitempty"`
+	TotalIPs        int32  `protobuf:"varint,5,opt,name=TotalIPs,proto3" json:"TotalIPs,omitempty"`
+	AssignedIPs     int32  `protobuf:"varint,6,opt,name=AssignedIPs,proto3" json:"AssignedIPs,omitempty"`
+	PoolSize        int32  `protobuf:"varint,7,opt,name=PoolSize,proto3" json:"PoolSize,omitempty"`
+	MaxPoolSize     int32  `protobuf:"varint,8,opt,name=MaxPoolSize,proto3" json:"MaxPoolSize,omitempty"`
+	MaxIPsPerNIC    int32  `protobuf:"varint,9,opt,name=MaxIPsPerNIC,proto3" json:"MaxIPsPerNIC,omitempty"`
+	NICs            int32  `protobuf:"varint,10,opt,name=NICs,proto3" json:"NICs,omitempty"`
+	MaxNICs         int32  `protobuf:"varint,11,opt,name=MaxNICs,proto3" json:"MaxNICs,omitempty"`
+	AllocationError string `protobuf:"bytes,12,opt,name=AllocationError,proto3" json:"AllocationError,omitempty"`
+}
+
+func (m *PoolStatusReply) Reset()                    { *m = PoolStatusReply{} }
+func (m *PoolStatusReply) String() string            { return proto.CompactTextString(m) }
+func (*PoolStatusReply) ProtoMessage()               {}
+func (*PoolStatusReply) Descriptor() ([]byte, []int) { return fileDescriptorMessage, []int{14} }
+
+func (m *PoolStatusReply) GetReady() bool {
+	if m != nil {
+		return m.Ready
 	}
-	return i, nil
+	return false
 }
 
-func (m *DelNetworkRequest) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
+func (m *PoolStatusReply) GetMessage() string {
+	if m != nil {
+		return m.Message
 	}
-	return dAtA[:n], nil
+	return ""
 }
 
-func (m *DelNetworkRequest) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if len(m.K8S_POD_NAME) > 0 {
-		dAtA[i] = 0xa
-		i++
-		i = encodeVarintMessage(dAtA, i, uint64(len(m.K8S_POD_NAME)))
-		i += copy(dAtA[i:], m.K8S_POD_NAME)
+func (m *PoolStatusReply) GetStage() string {
+	if m != nil {
+		return m.Stage
 	}
-	if len(m.K8S_POD_NAMESPACE) > 0 {
-		dAtA[i] = 0x12
-		i++
-		i = encodeVarintMessage(dAtA, i, uint64(len(m.K8S_POD_NAMESPACE)))
-		i += copy(dAtA[i:], m.K8S_POD_NAMESPACE)
+	return ""
+}
+
+func (m *PoolStatusReply) GetVxNet() string {
+	if m != nil {
+		return m.VxNet
 	}
-	if len(m.K8S_POD_INFRA_CONTAINER_ID) > 0 {
-		dAtA[i] = 0x1a
-		i++
-		i = encodeVarintMessage(dAtA, i, uint64(len(m.K8S_POD_INFRA_CONTAINER_ID)))
-		i += copy(dAtA[i:], m.K8S_POD_INFRA_CONTAINER_ID)
+	return ""
+}
+
+func (m *PoolStatusReply) GetTotalIPs() int32 {
+	if m != nil {
+		return m.TotalIPs
 	}
-	if len(m.IPv4Addr) > 0 {
-		dAtA[i] = 0x22
-		i++
-		i = encodeVarintMessage(dAtA, i, uint64(len(m.IPv4Addr)))
-		i += copy(dAtA[i:], m.IPv4Addr)
+	return 0
+}
+
+func (m *PoolStatusReply) GetAssignedIPs() int32 {
+	if m != nil {
+		return m.AssignedIPs
 	}
-	if len(m.Reason) > 0 {
-		dAtA[i] = 0x2a
-		i++
-		i = encodeVarintMessage(dAtA, i, uint64(len(m.Reason)))
-		i += copy(dAtA[i:], m.Reason)
+	return 0
+}
+
+func (m *PoolStatusReply) GetPoolSize() int32 {
+	if m != nil {
+		return m.PoolSize
 	}
-	return i, nil
+	return 0
 }
 
-func (m *DelNetworkReply) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
+func (m *PoolStatusReply) GetMaxPoolSize() int32 {
+	if m != nil {
+		return m.MaxPoolSize
 	}
-	return dAtA[:n], nil
+	return 0
 }
 
-func (m *DelNetworkReply) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if m.Success {
-		dAtA[i] = 0x8
-		i++
-		if m.Success {
-			dAtA[i] = 1
-		} else {
-			dAtA[i] = 0
-		}
-		i++
+func (m *PoolStatusReply) GetMaxIPsPerNIC() int32 {
+	if m != nil {
+		return m.MaxIPsPerNIC
 	}
-	if len(m.IPv4Addr) > 0 {
-		dAtA[i] = 0x12
-		i++
-		i = encodeVarintMessage(dAtA, i, uint64(len(m.IPv4Addr)))
-		i += copy(dAtA[i:], m.IPv4Addr)
+	return 0
+}
+
+func (m *PoolStatusReply) GetNICs() int32 {
+	if m != nil {
+		return m.NICs
 	}
-	if m.DeviceNumber != 0 {
-		dAtA[i] = 0x18
-		i++
-		i = encodeVarintMessage(dAtA, i, uint64(m.DeviceNumber))
+	return 0
+}
+
+func (m *PoolStatusReply) GetMaxNICs() int32 {
+	if m != nil {
+		return m.MaxNICs
 	}
-	if len(m.Message) > 0 {
-		dAtA[i] = 0x22
-		i++
-		i = encodeVarintMessage(dAtA, i, uint64(len(m.Message)))
-		i += copy(dAtA[i:], m.Message)
+	return 0
+}
+
+func (m *PoolStatusReply) GetAllocationError() string {
+	if m != nil {
+		return m.AllocationError
 	}
-	return i, nil
+	return ""
 }
 
-func encodeFixed64Message(dAtA []byte, offset int, v uint64) int {
-	dAtA[offset] = uint8(v)
-	dAtA[offset+1] = uint8(v >> 8)
-	dAtA[offset+2] = uint8(v >> 16)
-	dAtA[offset+3] = uint8(v >> 24)
-	dAtA[offset+4] = uint8(v >> 32)
-	dAtA[offset+5] = uint8(v >> 40)
-	dAtA[offset+6] = uint8(v >> 48)
-	dAtA[offset+7] = uint8(v >> 56)
-	return offset + 8
+type ReleaseIPRequest struct {
+	IP    string `protobuf:"bytes,1,opt,name=IP,proto3" json:"IP,omitempty"`
+	Force bool   `protobuf:"varint,2,opt,name=Force,proto3" json:"Force,omitempty"`
 }
-func encodeFixed32Message(dAtA []byte, offset int, v uint32) int {
-	dAtA[offset] = uint8(v)
-	dAtA[offset+1] = uint8(v >> 8)
-	dAtA[offset+2] = uint8(v >> 16)
-	dAtA[offset+3] = uint8(v >> 24)
-	return offset + 4
+
+func (m *ReleaseIPRequest) Reset()                    { *m = ReleaseIPRequest{} }
+func (m *ReleaseIPRequest) String() string            { return proto.CompactTextString(m) }
+func (*ReleaseIPRequest) ProtoMessage()               {}
+func (*ReleaseIPRequest) Descriptor() ([]byte, []int) { return fileDescriptorMessage, []int{15} }
+
+func (m *ReleaseIPRequest) GetIP() string {
+	if m != nil {
+		return m.IP
+	}
+	return ""
 }
-func encodeVarintMessage(dAtA []byte, offset int, v uint64) int {
-	for v >= 1<<7 {
-		dAtA[offset] = uint8(v&0x7f | 0x80)
-		v >>= 7
-		offset++
+
+func (m *ReleaseIPRequest) GetForce() bool {
+	if m != nil {
+		return m.Force
 	}
-	dAtA[offset] = uint8(v)
-	return offset + 1
+	return false
 }
-func (m *AddNetworkRequest) Size() (n int) {
-	var l int
-	_ = l
-	l = len(m.K8S_POD_NAME)
-	if l > 0 {
-		n += 1 + l + sovMessage(uint64(l))
+
+type ReleaseIPReply struct {
+	IP  string `protobuf:"bytes,1,opt,name=IP,proto3" json:"IP,omitempty"`
+	Pod string `protobuf:"bytes,2,opt,name=Pod,proto3" json:"Pod,omitempty"`
+}
+
+func (m *ReleaseIPReply) Reset()                    { *m = ReleaseIPReply{} }
+func (m *ReleaseIPReply) String() string            { return proto.CompactTextString(m) }
+func (*ReleaseIPReply) ProtoMessage()               {}
+func (*ReleaseIPReply) Descriptor() ([]byte, []int) { return fileDescriptorMessage, []int{16} }
+
+func (m *ReleaseIPReply) GetIP() string {
+	if m != nil {
+		return m.IP
 	}
-	l = len(m.K8S_POD_NAMESPACE)
-	if l > 0 {
-		n += 1 + l + sovMessage(uint64(l))
+	return ""
+}
+
+func (m *ReleaseIPReply) GetPod() string {
+	if m != nil {
+		return m.Pod
 	}
-	l = len(m.K8S_POD_INFRA_CONTAINER_ID)
-	if l > 0 {
-		n += 1 + l + sovMessage(uint64(l))
+	return ""
+}
+
+type ReconcileRequest struct {
+}
+
+func (m *ReconcileRequest) Reset()                    { *m = ReconcileRequest{} }
+func (m *ReconcileRequest) String() string            { return proto.CompactTextString(m) }
+func (*ReconcileRequest) ProtoMessage()               {}
+func (*ReconcileRequest) Descriptor() ([]byte, []int) { return fileDescriptorMessage, []int{17} }
+
+type ReconcileReply struct {
+}
+
+func (m *ReconcileReply) Reset()                    { *m = ReconcileReply{} }
+func (m *ReconcileReply) String() string            { return proto.CompactTextString(m) }
+func (*ReconcileReply) ProtoMessage()               {}
+func (*ReconcileReply) Descriptor() ([]byte, []int) { return fileDescriptorMessage, []int{18} }
+
+type DumpRulesRequest struct {
+}
+
+func (m *DumpRulesRequest) Reset()                    { *m = DumpRulesRequest{} }
+func (m *DumpRulesRequest) String() string            { return proto.CompactTextString(m) }
+func (*DumpRulesRequest) ProtoMessage()               {}
+func (*DumpRulesRequest) Descriptor() ([]byte, []int) { return fileDescriptorMessage, []int{19} }
+
+type Rule struct {
+	Rule      string `protobuf:"bytes,1,opt,name=Rule,proto3" json:"Rule,omitempty"`
+	Owner     string `protobuf:"bytes,2,opt,name=Owner,proto3" json:"Owner,omitempty"`
+	Expected  bool   `protobuf:"varint,3,opt,name=Expected,proto3" json:"Expected,omitempty"`
+	Installed bool   `protobuf:"varint,4,opt,name=Installed,proto3" json:"Installed,omitempty"`
+}
+
+func (m *Rule) Reset()                    { *m = Rule{} }
+func (m *Rule) String() string            { return proto.CompactTextString(m) }
+func (*Rule) ProtoMessage()               {}
+func (*Rule) Descriptor() ([]byte, []int) { return fileDescriptorMessage, []int{20} }
+
+func (m *Rule) GetRule() string {
+	if m != nil {
+		return m.Rule
 	}
-	l = len(m.Netns)
-	if l > 0 {
-		n += 1 + l + sovMessage(uint64(l))
+	return ""
+}
+
+func (m *Rule) GetOwner() string {
+	if m != nil {
+		return m.Owner
 	}
-	l = len(m.IfName)
-	if l > 0 {
-		n += 1 + l + sovMessage(uint64(l))
+	return ""
+}
+
+func (m *Rule) GetExpected() bool {
+	if m != nil {
+		return m.Expected
 	}
-	return n
+	return false
 }
 
-func (m *AddNetworkReply) Size() (n int) {
-	var l int
-	_ = l
-	if m.Success {
-		n += 2
+func (m *Rule) GetInstalled() bool {
+	if m != nil {
+		return m.Installed
 	}
-	l = len(m.IPv4Addr)
-	if l > 0 {
-		n += 1 + l + sovMessage(uint64(l))
+	return false
+}
+
+type DumpRulesReply struct {
+	Rules []*Rule `protobuf:"bytes,1,rep,name=Rules,proto3" json:"Rules,omitempty"`
+}
+
+func (m *DumpRulesReply) Reset()                    { *m = DumpRulesReply{} }
+func (m *DumpRulesReply) String() string            { return proto.CompactTextString(m) }
+func (*DumpRulesReply) ProtoMessage()               {}
+func (*DumpRulesReply) Descriptor() ([]byte, []int) { return fileDescriptorMessage, []int{21} }
+
+func (m *DumpRulesReply) GetRules() []*Rule {
+	if m != nil {
+		return m.Rules
 	}
-	l = len(m.IPv4Subnet)
-	if l > 0 {
-		n += 1 + l + sovMessage(uint64(l))
+	return nil
+}
+
+type VerifyPodRequest struct {
+	Name      string `protobuf:"bytes,1,opt,name=Name,proto3" json:"Name,omitempty"`
+	Namespace string `protobuf:"bytes,2,opt,name=Namespace,proto3" json:"Namespace,omitempty"`
+}
+
+func (m *VerifyPodRequest) Reset()                    { *m = VerifyPodRequest{} }
+func (m *VerifyPodRequest) String() string            { return proto.CompactTextString(m) }
+func (*VerifyPodRequest) ProtoMessage()               {}
+func (*VerifyPodRequest) Descriptor() ([]byte, []int) { return fileDescriptorMessage, []int{22} }
+
+func (m *VerifyPodRequest) GetName() string {
+	if m != nil {
+		return m.Name
 	}
-	if m.DeviceNumber != 0 {
-		n += 1 + sovMessage(uint64(m.DeviceNumber))
+	return ""
+}
+
+func (m *VerifyPodRequest) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
 	}
-	if m.UseExternalSNAT {
-		n += 2
+	return ""
+}
+
+type Check struct {
+	Name    string `protobuf:"bytes,1,opt,name=Name,proto3" json:"Name,omitempty"`
+	OK      bool   `protobuf:"varint,2,opt,name=OK,proto3" json:"OK,omitempty"`
+	Message string `protobuf:"bytes,3,opt,name=Message,proto3" json:"Message,omitempty"`
+}
+
+func (m *Check) Reset()                    { *m = Check{} }
+func (m *Check) String() string            { return proto.CompactTextString(m) }
+func (*Check) ProtoMessage()               {}
+func (*Check) Descriptor() ([]byte, []int) { return fileDescriptorMessage, []int{23} }
+
+func (m *Check) GetName() string {
+	if m != nil {
+		return m.Name
 	}
-	l = len(m.Message)
-	if l > 0 {
-		n += 1 + l + sovMessage(uint64(l))
+	return ""
+}
+
+func (m *Check) GetOK() bool {
+	if m != nil {
+		return m.OK
 	}
-	if len(m.VPCcidrs) > 0 {
-		for _, s := range m.VPCcidrs {
-			l = len(s)
-			n += 1 + l + sovMessage(uint64(l))
-		}
+	return false
+}
+
+func (m *Check) GetMessage() string {
+	if m != nil {
+		return m.Message
 	}
-	return n
+	return ""
 }
 
-func (m *DelNetworkRequest) Size() (n int) {
-	var l int
-	_ = l
-	l = len(m.K8S_POD_NAME)
-	if l > 0 {
-		n += 1 + l + sovMessage(uint64(l))
+type VerifyPodReply struct {
+	OK     bool     `protobuf:"varint,1,opt,name=OK,proto3" json:"OK,omitempty"`
+	Checks []*Check `protobuf:"bytes,2,rep,name=Checks,proto3" json:"Checks,omitempty"`
+}
+
+func (m *VerifyPodReply) Reset()                    { *m = VerifyPodReply{} }
+func (m *VerifyPodReply) String() string            { return proto.CompactTextString(m) }
+func (*VerifyPodReply) ProtoMessage()               {}
+func (*VerifyPodReply) Descriptor() ([]byte, []int) { return fileDescriptorMessage, []int{24} }
+
+func (m *VerifyPodReply) GetOK() bool {
+	if m != nil {
+		return m.OK
 	}
-	l = len(m.K8S_POD_NAMESPACE)
-	if l > 0 {
-		n += 1 + l + sovMessage(uint64(l))
+	return false
+}
+
+func (m *VerifyPodReply) GetChecks() []*Check {
+	if m != nil {
+		return m.Checks
 	}
-	l = len(m.K8S_POD_INFRA_CONTAINER_ID)
-	if l > 0 {
-		n += 1 + l + sovMessage(uint64(l))
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*AddNetworkRequest)(nil), "rpc.AddNetworkRequest")
+	proto.RegisterType((*AddNetworkReply)(nil), "rpc.AddNetworkReply")
+	proto.RegisterType((*DelNetworkRequest)(nil), "rpc.DelNetworkRequest")
+	proto.RegisterType((*DelNetworkReply)(nil), "rpc.DelNetworkReply")
+	proto.RegisterType((*ReadyRequest)(nil), "rpc.ReadyRequest")
+	proto.RegisterType((*ReadyReply)(nil), "rpc.ReadyReply")
+	proto.RegisterType((*ListPodsRequest)(nil), "rpc.ListPodsRequest")
+	proto.RegisterType((*PodIP)(nil), "rpc.PodIP")
+	proto.RegisterType((*ListPodsReply)(nil), "rpc.ListPodsReply")
+	proto.RegisterType((*ListNICsRequest)(nil), "rpc.ListNICsRequest")
+	proto.RegisterType((*NICAddress)(nil), "rpc.NICAddress")
+	proto.RegisterType((*NIC)(nil), "rpc.NIC")
+	proto.RegisterType((*ListNICsReply)(nil), "rpc.ListNICsReply")
+	proto.RegisterType((*PoolStatusRequest)(nil), "rpc.PoolStatusRequest")
+	proto.RegisterType((*PoolStatusReply)(nil), "rpc.PoolStatusReply")
+	proto.RegisterType((*ReleaseIPRequest)(nil), "rpc.ReleaseIPRequest")
+	proto.RegisterType((*ReleaseIPReply)(nil), "rpc.ReleaseIPReply")
+	proto.RegisterType((*ReconcileRequest)(nil), "rpc.ReconcileRequest")
+	proto.RegisterType((*ReconcileReply)(nil), "rpc.ReconcileReply")
+	proto.RegisterType((*DumpRulesRequest)(nil), "rpc.DumpRulesRequest")
+	proto.RegisterType((*Rule)(nil), "rpc.Rule")
+	proto.RegisterType((*DumpRulesReply)(nil), "rpc.DumpRulesReply")
+	proto.RegisterType((*VerifyPodRequest)(nil), "rpc.VerifyPodRequest")
+	proto.RegisterType((*Check)(nil), "rpc.Check")
+	proto.RegisterType((*VerifyPodReply)(nil), "rpc.VerifyPodReply")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for CNIBackend service
+
+type CNIBackendClient interface {
+	AddNetwork(ctx context.Context, in *AddNetworkRequest, opts ...grpc.CallOption) (*AddNetworkReply, error)
+	DelNetwork(ctx context.Context, in *DelNetworkRequest, opts ...grpc.CallOption) (*DelNetworkReply, error)
+	Ready(ctx context.Context, in *ReadyRequest, opts ...grpc.CallOption) (*ReadyReply, error)
+}
+
+type cNIBackendClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewCNIBackendClient(cc *grpc.ClientConn) CNIBackendClient {
+	return &cNIBackendClient{cc}
+}
+
+func (c *cNIBackendClient) AddNetwork(ctx context.Context, in *AddNetworkRequest, opts ...grpc.CallOption) (*AddNetworkReply, error) {
+	out := new(AddNetworkReply)
+	err := grpc.Invoke(ctx, "/rpc.CNIBackend/AddNetwork", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
 	}
-	l = len(m.IPv4Addr)
-	if l > 0 {
-		n += 1 + l + sovMessage(uint64(l))
+	return out, nil
+}
+
+func (c *cNIBackendClient) DelNetwork(ctx context.Context, in *DelNetworkRequest, opts ...grpc.CallOption) (*DelNetworkReply, error) {
+	out := new(DelNetworkReply)
+	err := grpc.Invoke(ctx, "/rpc.CNIBackend/DelNetwork", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
 	}
-	l = len(m.Reason)
-	if l > 0 {
-		n += 1 + l + sovMessage(uint64(l))
+	return out, nil
+}
+
+func (c *cNIBackendClient) Ready(ctx context.Context, in *ReadyRequest, opts ...grpc.CallOption) (*ReadyReply, error) {
+	out := new(ReadyReply)
+	err := grpc.Invoke(ctx, "/rpc.CNIBackend/Ready", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
 	}
-	return n
+	return out, nil
 }
 
-func (m *DelNetworkReply) Size() (n int) {
-	var l int
-	_ = l
-	if m.Success {
-		n += 2
+// Server API for CNIBackend service
+
+type CNIBackendServer interface {
+	AddNetwork(context.Context, *AddNetworkRequest) (*AddNetworkReply, error)
+	DelNetwork(context.Context, *DelNetworkRequest) (*DelNetworkReply, error)
+	Ready(context.Context, *ReadyRequest) (*ReadyReply, error)
+}
+
+func RegisterCNIBackendServer(s *grpc.Server, srv CNIBackendServer) {
+	s.RegisterService(&_CNIBackend_serviceDesc, srv)
+}
+
+func _CNIBackend_AddNetwork_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddNetworkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
 	}
-	l = len(m.IPv4Addr)
-	if l > 0 {
-		n += 1 + l + sovMessage(uint64(l))
+	if interceptor == nil {
+		return srv.(CNIBackendServer).AddNetwork(ctx, in)
 	}
-	if m.DeviceNumber != 0 {
-		n += 1 + sovMessage(uint64(m.DeviceNumber))
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.CNIBackend/AddNetwork",
 	}
-	l = len(m.Message)
-	if l > 0 {
-		n += 1 + l + sovMessage(uint64(l))
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CNIBackendServer).AddNetwork(ctx, req.(*AddNetworkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CNIBackend_DelNetwork_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DelNetworkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CNIBackendServer).DelNetwork(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.CNIBackend/DelNetwork",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CNIBackendServer).DelNetwork(ctx, req.(*DelNetworkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CNIBackend_Ready_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReadyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CNIBackendServer).Ready(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.CNIBackend/Ready",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CNIBackendServer).Ready(ctx, req.(*ReadyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _CNIBackend_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "rpc.CNIBackend",
+	HandlerType: (*CNIBackendServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AddNetwork",
+			Handler:    _CNIBackend_AddNetwork_Handler,
+		},
+		{
+			MethodName: "DelNetwork",
+			Handler:    _CNIBackend_DelNetwork_Handler,
+		},
+		{
+			MethodName: "Ready",
+			Handler:    _CNIBackend_Ready_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pkg/rpc/message.proto",
+}
+
+// Client API for Introspection service
+
+type IntrospectionClient interface {
+	ListPods(ctx context.Context, in *ListPodsRequest, opts ...grpc.CallOption) (*ListPodsReply, error)
+	ListNICs(ctx context.Context, in *ListNICsRequest, opts ...grpc.CallOption) (*ListNICsReply, error)
+	PoolStatus(ctx context.Context, in *PoolStatusRequest, opts ...grpc.CallOption) (*PoolStatusReply, error)
+	ReleaseIP(ctx context.Context, in *ReleaseIPRequest, opts ...grpc.CallOption) (*ReleaseIPReply, error)
+	Reconcile(ctx context.Context, in *ReconcileRequest, opts ...grpc.CallOption) (*ReconcileReply, error)
+	DumpRules(ctx context.Context, in *DumpRulesRequest, opts ...grpc.CallOption) (*DumpRulesReply, error)
+	VerifyPod(ctx context.Context, in *VerifyPodRequest, opts ...grpc.CallOption) (*VerifyPodReply, error)
+}
+
+type introspectionClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewIntrospectionClient(cc *grpc.ClientConn) IntrospectionClient {
+	return &introspectionClient{cc}
+}
+
+func (c *introspectionClient) ListPods(ctx context.Context, in *ListPodsRequest, opts ...grpc.CallOption) (*ListPodsReply, error) {
+	out := new(ListPodsReply)
+	err := grpc.Invoke(ctx, "/rpc.Introspection/ListPods", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *introspectionClient) ListNICs(ctx context.Context, in *ListNICsRequest, opts ...grpc.CallOption) (*ListNICsReply, error) {
+	out := new(ListNICsReply)
+	err := grpc.Invoke(ctx, "/rpc.Introspection/ListNICs", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *introspectionClient) PoolStatus(ctx context.Context, in *PoolStatusRequest, opts ...grpc.CallOption) (*PoolStatusReply, error) {
+	out := new(PoolStatusReply)
+	err := grpc.Invoke(ctx, "/rpc.Introspection/PoolStatus", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *introspectionClient) ReleaseIP(ctx context.Context, in *ReleaseIPRequest, opts ...grpc.CallOption) (*ReleaseIPReply, error) {
+	out := new(ReleaseIPReply)
+	err := grpc.Invoke(ctx, "/rpc.Introspection/ReleaseIP", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *introspectionClient) Reconcile(ctx context.Context, in *ReconcileRequest, opts ...grpc.CallOption) (*ReconcileReply, error) {
+	out := new(ReconcileReply)
+	err := grpc.Invoke(ctx, "/rpc.Introspection/Reconcile", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *introspectionClient) DumpRules(ctx context.Context, in *DumpRulesRequest, opts ...grpc.CallOption) (*DumpRulesReply, error) {
+	out := new(DumpRulesReply)
+	err := grpc.Invoke(ctx, "/rpc.Introspection/DumpRules", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *introspectionClient) VerifyPod(ctx context.Context, in *VerifyPodRequest, opts ...grpc.CallOption) (*VerifyPodReply, error) {
+	out := new(VerifyPodReply)
+	err := grpc.Invoke(ctx, "/rpc.Introspection/VerifyPod", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for Introspection service
+
+type IntrospectionServer interface {
+	ListPods(context.Context, *ListPodsRequest) (*ListPodsReply, error)
+	ListNICs(context.Context, *ListNICsRequest) (*ListNICsReply, error)
+	PoolStatus(context.Context, *PoolStatusRequest) (*PoolStatusReply, error)
+	ReleaseIP(context.Context, *ReleaseIPRequest) (*ReleaseIPReply, error)
+	Reconcile(context.Context, *ReconcileRequest) (*ReconcileReply, error)
+	DumpRules(context.Context, *DumpRulesRequest) (*DumpRulesReply, error)
+	VerifyPod(context.Context, *VerifyPodRequest) (*VerifyPodReply, error)
+}
+
+func RegisterIntrospectionServer(s *grpc.Server, srv IntrospectionServer) {
+	s.RegisterService(&_Introspection_serviceDesc, srv)
+}
+
+func _Introspection_ListPods_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPodsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IntrospectionServer).ListPods(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.Introspection/ListPods",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IntrospectionServer).ListPods(ctx, req.(*ListPodsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Introspection_ListNICs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListNICsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IntrospectionServer).ListNICs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.Introspection/ListNICs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IntrospectionServer).ListNICs(ctx, req.(*ListNICsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Introspection_PoolStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PoolStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IntrospectionServer).PoolStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.Introspection/PoolStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IntrospectionServer).PoolStatus(ctx, req.(*PoolStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Introspection_ReleaseIP_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReleaseIPRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IntrospectionServer).ReleaseIP(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.Introspection/ReleaseIP",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IntrospectionServer).ReleaseIP(ctx, req.(*ReleaseIPRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Introspection_Reconcile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReconcileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IntrospectionServer).Reconcile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.Introspection/Reconcile",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IntrospectionServer).Reconcile(ctx, req.(*ReconcileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Introspection_DumpRules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DumpRulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IntrospectionServer).DumpRules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.Introspection/DumpRules",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IntrospectionServer).DumpRules(ctx, req.(*DumpRulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Introspection_VerifyPod_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyPodRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IntrospectionServer).VerifyPod(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.Introspection/VerifyPod",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IntrospectionServer).VerifyPod(ctx, req.(*VerifyPodRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Introspection_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "rpc.Introspection",
+	HandlerType: (*IntrospectionServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListPods",
+			Handler:    _Introspection_ListPods_Handler,
+		},
+		{
+			MethodName: "ListNICs",
+			Handler:    _Introspection_ListNICs_Handler,
+		},
+		{
+			MethodName: "PoolStatus",
+			Handler:    _Introspection_PoolStatus_Handler,
+		},
+		{
+			MethodName: "ReleaseIP",
+			Handler:    _Introspection_ReleaseIP_Handler,
+		},
+		{
+			MethodName: "Reconcile",
+			Handler:    _Introspection_Reconcile_Handler,
+		},
+		{
+			MethodName: "DumpRules",
+			Handler:    _Introspection_DumpRules_Handler,
+		},
+		{
+			MethodName: "VerifyPod",
+			Handler:    _Introspection_VerifyPod_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pkg/rpc/message.proto",
+}
+
+func (m *AddNetworkRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *AddNetworkRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.K8S_POD_NAME) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.K8S_POD_NAME)))
+		i += copy(dAtA[i:], m.K8S_POD_NAME)
+	}
+	if len(m.K8S_POD_NAMESPACE) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.K8S_POD_NAMESPACE)))
+		i += copy(dAtA[i:], m.K8S_POD_NAMESPACE)
+	}
+	if len(m.K8S_POD_INFRA_CONTAINER_ID) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.K8S_POD_INFRA_CONTAINER_ID)))
+		i += copy(dAtA[i:], m.K8S_POD_INFRA_CONTAINER_ID)
+	}
+	if len(m.Netns) > 0 {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.Netns)))
+		i += copy(dAtA[i:], m.Netns)
+	}
+	if len(m.IfName) > 0 {
+		dAtA[i] = 0x2a
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.IfName)))
+		i += copy(dAtA[i:], m.IfName)
+	}
+	if m.APIVersion != 0 {
+		dAtA[i] = 0x30
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(m.APIVersion))
+	}
+	return i, nil
+}
+
+func (m *AddNetworkReply) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *AddNetworkReply) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Success {
+		dAtA[i] = 0x8
+		i++
+		if m.Success {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if len(m.IPv4Addr) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.IPv4Addr)))
+		i += copy(dAtA[i:], m.IPv4Addr)
+	}
+	if len(m.IPv4Subnet) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.IPv4Subnet)))
+		i += copy(dAtA[i:], m.IPv4Subnet)
+	}
+	if m.DeviceNumber != 0 {
+		dAtA[i] = 0x20
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(m.DeviceNumber))
+	}
+	if m.UseExternalSNAT {
+		dAtA[i] = 0x28
+		i++
+		if m.UseExternalSNAT {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if len(m.Message) > 0 {
+		dAtA[i] = 0x32
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.Message)))
+		i += copy(dAtA[i:], m.Message)
+	}
+	if len(m.VPCcidrs) > 0 {
+		for _, s := range m.VPCcidrs {
+			dAtA[i] = 0x3a
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				dAtA[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			dAtA[i] = uint8(l)
+			i++
+			i += copy(dAtA[i:], s)
+		}
+	}
+	if m.PerNICSNAT {
+		dAtA[i] = 0x40
+		i++
+		if m.PerNICSNAT {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if m.MTU != 0 {
+		dAtA[i] = 0x48
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(m.MTU))
+	}
+	if len(m.ErrorType) > 0 {
+		dAtA[i] = 0x52
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.ErrorType)))
+		i += copy(dAtA[i:], m.ErrorType)
+	}
+	return i, nil
+}
+
+func (m *DelNetworkRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DelNetworkRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.K8S_POD_NAME) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.K8S_POD_NAME)))
+		i += copy(dAtA[i:], m.K8S_POD_NAME)
+	}
+	if len(m.K8S_POD_NAMESPACE) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.K8S_POD_NAMESPACE)))
+		i += copy(dAtA[i:], m.K8S_POD_NAMESPACE)
+	}
+	if len(m.K8S_POD_INFRA_CONTAINER_ID) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.K8S_POD_INFRA_CONTAINER_ID)))
+		i += copy(dAtA[i:], m.K8S_POD_INFRA_CONTAINER_ID)
+	}
+	if len(m.IPv4Addr) > 0 {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.IPv4Addr)))
+		i += copy(dAtA[i:], m.IPv4Addr)
+	}
+	if len(m.Reason) > 0 {
+		dAtA[i] = 0x2a
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.Reason)))
+		i += copy(dAtA[i:], m.Reason)
+	}
+	if m.APIVersion != 0 {
+		dAtA[i] = 0x30
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(m.APIVersion))
+	}
+	return i, nil
+}
+
+func (m *DelNetworkReply) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DelNetworkReply) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Success {
+		dAtA[i] = 0x8
+		i++
+		if m.Success {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if len(m.IPv4Addr) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.IPv4Addr)))
+		i += copy(dAtA[i:], m.IPv4Addr)
+	}
+	if m.DeviceNumber != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(m.DeviceNumber))
+	}
+	if len(m.Message) > 0 {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.Message)))
+		i += copy(dAtA[i:], m.Message)
+	}
+	if len(m.ErrorType) > 0 {
+		dAtA[i] = 0x2a
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.ErrorType)))
+		i += copy(dAtA[i:], m.ErrorType)
+	}
+	return i, nil
+}
+
+func (m *ReadyRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ReadyRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.APIVersion != 0 {
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(m.APIVersion))
+	}
+	return i, nil
+}
+
+func (m *ReadyReply) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ReadyReply) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Ready {
+		dAtA[i] = 0x8
+		i++
+		if m.Ready {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if len(m.Message) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.Message)))
+		i += copy(dAtA[i:], m.Message)
+	}
+	if m.APIVersion != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(m.APIVersion))
+	}
+	return i, nil
+}
+
+func (m *ListPodsRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ListPodsRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	return i, nil
+}
+
+func (m *PodIP) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *PodIP) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Name) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.Name)))
+		i += copy(dAtA[i:], m.Name)
+	}
+	if len(m.Namespace) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.Namespace)))
+		i += copy(dAtA[i:], m.Namespace)
+	}
+	if len(m.Container) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.Container)))
+		i += copy(dAtA[i:], m.Container)
+	}
+	if len(m.IP) > 0 {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.IP)))
+		i += copy(dAtA[i:], m.IP)
+	}
+	if m.DeviceNumber != 0 {
+		dAtA[i] = 0x28
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(m.DeviceNumber))
+	}
+	if len(m.NIC) > 0 {
+		dAtA[i] = 0x32
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.NIC)))
+		i += copy(dAtA[i:], m.NIC)
+	}
+	if len(m.Egress) > 0 {
+		dAtA[i] = 0x3a
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.Egress)))
+		i += copy(dAtA[i:], m.Egress)
+	}
+	return i, nil
+}
+
+func (m *ListPodsReply) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ListPodsReply) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Pods) > 0 {
+		for _, msg := range m.Pods {
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintMessage(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	return i, nil
+}
+
+func (m *ListNICsRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ListNICsRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	return i, nil
+}
+
+func (m *NICAddress) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *NICAddress) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.IP) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.IP)))
+		i += copy(dAtA[i:], m.IP)
+	}
+	if m.Secondary {
+		dAtA[i] = 0x10
+		i++
+		if m.Secondary {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if len(m.Pod) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.Pod)))
+		i += copy(dAtA[i:], m.Pod)
+	}
+	return i, nil
+}
+
+func (m *NIC) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *NIC) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.ID) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.ID)))
+		i += copy(dAtA[i:], m.ID)
+	}
+	if m.DeviceNumber != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(m.DeviceNumber))
+	}
+	if m.Primary {
+		dAtA[i] = 0x18
+		i++
+		if m.Primary {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if len(m.VxNet) > 0 {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.VxNet)))
+		i += copy(dAtA[i:], m.VxNet)
+	}
+	if len(m.SecurityGroup) > 0 {
+		dAtA[i] = 0x2a
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.SecurityGroup)))
+		i += copy(dAtA[i:], m.SecurityGroup)
+	}
+	if m.Reserved {
+		dAtA[i] = 0x30
+		i++
+		if m.Reserved {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if len(m.Addresses) > 0 {
+		for _, msg := range m.Addresses {
+			dAtA[i] = 0x3a
+			i++
+			i = encodeVarintMessage(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if len(m.Routes) > 0 {
+		for _, s := range m.Routes {
+			dAtA[i] = 0x42
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				dAtA[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			dAtA[i] = uint8(l)
+			i++
+			i += copy(dAtA[i:], s)
+		}
+	}
+	return i, nil
+}
+
+func (m *ListNICsReply) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ListNICsReply) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.NICs) > 0 {
+		for _, msg := range m.NICs {
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintMessage(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	return i, nil
+}
+
+func (m *PoolStatusRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *PoolStatusRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	return i, nil
+}
+
+func (m *PoolStatusReply) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *PoolStatusReply) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Ready {
+		dAtA[i] = 0x8
+		i++
+		if m.Ready {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if len(m.Message) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.Message)))
+		i += copy(dAtA[i:], m.Message)
+	}
+	if len(m.Stage) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.Stage)))
+		i += copy(dAtA[i:], m.Stage)
+	}
+	if len(m.VxNet) > 0 {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.VxNet)))
+		i += copy(dAtA[i:], m.VxNet)
+	}
+	if m.TotalIPs != 0 {
+		dAtA[i] = 0x28
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(m.TotalIPs))
+	}
+	if m.AssignedIPs != 0 {
+		dAtA[i] = 0x30
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(m.AssignedIPs))
+	}
+	if m.PoolSize != 0 {
+		dAtA[i] = 0x38
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(m.PoolSize))
+	}
+	if m.MaxPoolSize != 0 {
+		dAtA[i] = 0x40
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(m.MaxPoolSize))
+	}
+	if m.MaxIPsPerNIC != 0 {
+		dAtA[i] = 0x48
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(m.MaxIPsPerNIC))
+	}
+	if m.NICs != 0 {
+		dAtA[i] = 0x50
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(m.NICs))
+	}
+	if m.MaxNICs != 0 {
+		dAtA[i] = 0x58
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(m.MaxNICs))
+	}
+	if len(m.AllocationError) > 0 {
+		dAtA[i] = 0x62
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.AllocationError)))
+		i += copy(dAtA[i:], m.AllocationError)
+	}
+	return i, nil
+}
+
+func (m *ReleaseIPRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ReleaseIPRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.IP) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.IP)))
+		i += copy(dAtA[i:], m.IP)
+	}
+	if m.Force {
+		dAtA[i] = 0x10
+		i++
+		if m.Force {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	return i, nil
+}
+
+func (m *ReleaseIPReply) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ReleaseIPReply) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.IP) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.IP)))
+		i += copy(dAtA[i:], m.IP)
+	}
+	if len(m.Pod) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.Pod)))
+		i += copy(dAtA[i:], m.Pod)
+	}
+	return i, nil
+}
+
+func (m *ReconcileRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ReconcileRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	return i, nil
+}
+
+func (m *ReconcileReply) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ReconcileReply) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	return i, nil
+}
+
+func (m *DumpRulesRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DumpRulesRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	return i, nil
+}
+
+func (m *Rule) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Rule) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Rule) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.Rule)))
+		i += copy(dAtA[i:], m.Rule)
+	}
+	if len(m.Owner) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.Owner)))
+		i += copy(dAtA[i:], m.Owner)
+	}
+	if m.Expected {
+		dAtA[i] = 0x18
+		i++
+		if m.Expected {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if m.Installed {
+		dAtA[i] = 0x20
+		i++
+		if m.Installed {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	return i, nil
+}
+
+func (m *DumpRulesReply) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DumpRulesReply) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Rules) > 0 {
+		for _, msg := range m.Rules {
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintMessage(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	return i, nil
+}
+
+func (m *VerifyPodRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *VerifyPodRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Name) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.Name)))
+		i += copy(dAtA[i:], m.Name)
+	}
+	if len(m.Namespace) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.Namespace)))
+		i += copy(dAtA[i:], m.Namespace)
+	}
+	return i, nil
+}
+
+func (m *Check) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Check) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Name) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.Name)))
+		i += copy(dAtA[i:], m.Name)
+	}
+	if m.OK {
+		dAtA[i] = 0x10
+		i++
+		if m.OK {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if len(m.Message) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.Message)))
+		i += copy(dAtA[i:], m.Message)
+	}
+	return i, nil
+}
+
+func (m *VerifyPodReply) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *VerifyPodReply) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.OK {
+		dAtA[i] = 0x8
+		i++
+		if m.OK {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if len(m.Checks) > 0 {
+		for _, msg := range m.Checks {
+			dAtA[i] = 0x12
+			i++
+			i = encodeVarintMessage(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	return i, nil
+}
+
+func encodeFixed64Message(dAtA []byte, offset int, v uint64) int {
+	dAtA[offset] = uint8(v)
+	dAtA[offset+1] = uint8(v >> 8)
+	dAtA[offset+2] = uint8(v >> 16)
+	dAtA[offset+3] = uint8(v >> 24)
+	dAtA[offset+4] = uint8(v >> 32)
+	dAtA[offset+5] = uint8(v >> 40)
+	dAtA[offset+6] = uint8(v >> 48)
+	dAtA[offset+7] = uint8(v >> 56)
+	return offset + 8
+}
+func encodeFixed32Message(dAtA []byte, offset int, v uint32) int {
+	dAtA[offset] = uint8(v)
+	dAtA[offset+1] = uint8(v >> 8)
+	dAtA[offset+2] = uint8(v >> 16)
+	dAtA[offset+3] = uint8(v >> 24)
+	return offset + 4
+}
+func encodeVarintMessage(dAtA []byte, offset int, v uint64) int {
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return offset + 1
+}
+func (m *AddNetworkRequest) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.K8S_POD_NAME)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	l = len(m.K8S_POD_NAMESPACE)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	l = len(m.K8S_POD_INFRA_CONTAINER_ID)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	l = len(m.Netns)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	l = len(m.IfName)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	if m.APIVersion != 0 {
+		n += 1 + sovMessage(uint64(m.APIVersion))
+	}
+	return n
+}
+
+func (m *AddNetworkReply) Size() (n int) {
+	var l int
+	_ = l
+	if m.Success {
+		n += 2
+	}
+	l = len(m.IPv4Addr)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	l = len(m.IPv4Subnet)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	if m.DeviceNumber != 0 {
+		n += 1 + sovMessage(uint64(m.DeviceNumber))
+	}
+	if m.UseExternalSNAT {
+		n += 2
+	}
+	l = len(m.Message)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	if len(m.VPCcidrs) > 0 {
+		for _, s := range m.VPCcidrs {
+			l = len(s)
+			n += 1 + l + sovMessage(uint64(l))
+		}
+	}
+	if m.PerNICSNAT {
+		n += 2
+	}
+	if m.MTU != 0 {
+		n += 1 + sovMessage(uint64(m.MTU))
+	}
+	l = len(m.ErrorType)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	return n
+}
+
+func (m *DelNetworkRequest) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.K8S_POD_NAME)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	l = len(m.K8S_POD_NAMESPACE)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	l = len(m.K8S_POD_INFRA_CONTAINER_ID)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	l = len(m.IPv4Addr)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	l = len(m.Reason)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	if m.APIVersion != 0 {
+		n += 1 + sovMessage(uint64(m.APIVersion))
+	}
+	return n
+}
+
+func (m *DelNetworkReply) Size() (n int) {
+	var l int
+	_ = l
+	if m.Success {
+		n += 2
+	}
+	l = len(m.IPv4Addr)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	if m.DeviceNumber != 0 {
+		n += 1 + sovMessage(uint64(m.DeviceNumber))
+	}
+	l = len(m.Message)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	l = len(m.ErrorType)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	return n
+}
+
+func (m *ReadyRequest) Size() (n int) {
+	var l int
+	_ = l
+	if m.APIVersion != 0 {
+		n += 1 + sovMessage(uint64(m.APIVersion))
+	}
+	return n
+}
+
+func (m *ReadyReply) Size() (n int) {
+	var l int
+	_ = l
+	if m.Ready {
+		n += 2
+	}
+	l = len(m.Message)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	if m.APIVersion != 0 {
+		n += 1 + sovMessage(uint64(m.APIVersion))
+	}
+	return n
+}
+
+func (m *ListPodsRequest) Size() (n int) {
+	var l int
+	_ = l
+	return n
+}
+
+func (m *PodIP) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	l = len(m.Namespace)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	l = len(m.Container)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	l = len(m.IP)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	if m.DeviceNumber != 0 {
+		n += 1 + sovMessage(uint64(m.DeviceNumber))
+	}
+	l = len(m.NIC)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	l = len(m.Egress)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	return n
+}
+
+func (m *ListPodsReply) Size() (n int) {
+	var l int
+	_ = l
+	if len(m.Pods) > 0 {
+		for _, e := range m.Pods {
+			l = e.Size()
+			n += 1 + l + sovMessage(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *ListNICsRequest) Size() (n int) {
+	var l int
+	_ = l
+	return n
+}
+
+func (m *NICAddress) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.IP)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	if m.Secondary {
+		n += 2
+	}
+	l = len(m.Pod)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	return n
+}
+
+func (m *NIC) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.ID)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	if m.DeviceNumber != 0 {
+		n += 1 + sovMessage(uint64(m.DeviceNumber))
+	}
+	if m.Primary {
+		n += 2
+	}
+	l = len(m.VxNet)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	l = len(m.SecurityGroup)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	if m.Reserved {
+		n += 2
+	}
+	if len(m.Addresses) > 0 {
+		for _, e := range m.Addresses {
+			l = e.Size()
+			n += 1 + l + sovMessage(uint64(l))
+		}
+	}
+	if len(m.Routes) > 0 {
+		for _, s := range m.Routes {
+			l = len(s)
+			n += 1 + l + sovMessage(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *ListNICsReply) Size() (n int) {
+	var l int
+	_ = l
+	if len(m.NICs) > 0 {
+		for _, e := range m.NICs {
+			l = e.Size()
+			n += 1 + l + sovMessage(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *PoolStatusRequest) Size() (n int) {
+	var l int
+	_ = l
+	return n
+}
+
+func (m *PoolStatusReply) Size() (n int) {
+	var l int
+	_ = l
+	if m.Ready {
+		n += 2
+	}
+	l = len(m.Message)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	l = len(m.Stage)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	l = len(m.VxNet)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	if m.TotalIPs != 0 {
+		n += 1 + sovMessage(uint64(m.TotalIPs))
+	}
+	if m.AssignedIPs != 0 {
+		n += 1 + sovMessage(uint64(m.AssignedIPs))
+	}
+	if m.PoolSize != 0 {
+		n += 1 + sovMessage(uint64(m.PoolSize))
+	}
+	if m.MaxPoolSize != 0 {
+		n += 1 + sovMessage(uint64(m.MaxPoolSize))
+	}
+	if m.MaxIPsPerNIC != 0 {
+		n += 1 + sovMessage(uint64(m.MaxIPsPerNIC))
+	}
+	if m.NICs != 0 {
+		n += 1 + sovMessage(uint64(m.NICs))
+	}
+	if m.MaxNICs != 0 {
+		n += 1 + sovMessage(uint64(m.MaxNICs))
+	}
+	l = len(m.AllocationError)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	return n
+}
+
+func (m *ReleaseIPRequest) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.IP)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	if m.Force {
+		n += 2
+	}
+	return n
+}
+
+func (m *ReleaseIPReply) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.IP)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	l = len(m.Pod)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	return n
+}
+
+func (m *ReconcileRequest) Size() (n int) {
+	var l int
+	_ = l
+	return n
+}
+
+func (m *ReconcileReply) Size() (n int) {
+	var l int
+	_ = l
+	return n
+}
+
+func (m *DumpRulesRequest) Size() (n int) {
+	var l int
+	_ = l
+	return n
+}
+
+func (m *Rule) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Rule)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	l = len(m.Owner)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	if m.Expected {
+		n += 2
+	}
+	if m.Installed {
+		n += 2
+	}
+	return n
+}
+
+func (m *DumpRulesReply) Size() (n int) {
+	var l int
+	_ = l
+	if len(m.Rules) > 0 {
+		for _, e := range m.Rules {
+			l = e.Size()
+			n += 1 + l + sovMessage(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *VerifyPodRequest) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	l = len(m.Namespace)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	return n
+}
+
+func (m *Check) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	if m.OK {
+		n += 2
+	}
+	l = len(m.Message)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	return n
+}
+
+func (m *VerifyPodReply) Size() (n int) {
+	var l int
+	_ = l
+	if m.OK {
+		n += 2
+	}
+	if len(m.Checks) > 0 {
+		for _, e := range m.Checks {
+			l = e.Size()
+			n += 1 + l + sovMessage(uint64(l))
+		}
+	}
+	return n
+}
+
+func sovMessage(x uint64) (n int) {
+	for {
+		n++
+		x >>= 7
+		if x == 0 {
+			break
+		}
+	}
+	return n
+}
+func sozMessage(x uint64) (n int) {
+	return sovMessage(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *AddNetworkRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMessage
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: AddNetworkRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: AddNetworkRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field K8S_POD_NAME", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessage
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.K8S_POD_NAME = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field K8S_POD_NAMESPACE", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessage
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.K8S_POD_NAMESPACE = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field K8S_POD_INFRA_CONTAINER_ID", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessage
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.K8S_POD_INFRA_CONTAINER_ID = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Netns", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessage
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Netns = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IfName", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessage
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.IfName = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field APIVersion", wireType)
+			}
+			m.APIVersion = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.APIVersion |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMessage(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMessage
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *AddNetworkReply) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMessage
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: AddNetworkReply: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: AddNetworkReply: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Success", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Success = bool(v != 0)
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IPv4Addr", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessage
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.IPv4Addr = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IPv4Subnet", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessage
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.IPv4Subnet = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DeviceNumber", wireType)
+			}
+			m.DeviceNumber = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.DeviceNumber |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field UseExternalSNAT", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.UseExternalSNAT = bool(v != 0)
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Message", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessage
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Message = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field VPCcidrs", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessage
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.VPCcidrs = append(m.VPCcidrs, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 8:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PerNICSNAT", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.PerNICSNAT = bool(v != 0)
+		case 9:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MTU", wireType)
+			}
+			m.MTU = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MTU |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 10:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ErrorType", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessage
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ErrorType = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMessage(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMessage
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *DelNetworkRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMessage
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: DelNetworkRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: DelNetworkRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field K8S_POD_NAME", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessage
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.K8S_POD_NAME = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field K8S_POD_NAMESPACE", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessage
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.K8S_POD_NAMESPACE = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field K8S_POD_INFRA_CONTAINER_ID", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessage
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.K8S_POD_INFRA_CONTAINER_ID = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IPv4Addr", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessage
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.IPv4Addr = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Reason", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessage
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Reason = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field APIVersion", wireType)
+			}
+			m.APIVersion = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.APIVersion |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMessage(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMessage
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *DelNetworkReply) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMessage
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: DelNetworkReply: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: DelNetworkReply: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Success", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Success = bool(v != 0)
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IPv4Addr", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessage
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.IPv4Addr = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DeviceNumber", wireType)
+			}
+			m.DeviceNumber = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.DeviceNumber |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Message", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessage
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Message = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ErrorType", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessage
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ErrorType = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMessage(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMessage
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ReadyRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMessage
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ReadyRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ReadyRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field APIVersion", wireType)
+			}
+			m.APIVersion = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.APIVersion |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMessage(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMessage
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ReadyReply) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMessage
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ReadyReply: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ReadyReply: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Ready", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Ready = bool(v != 0)
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Message", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessage
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Message = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field APIVersion", wireType)
+			}
+			m.APIVersion = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.APIVersion |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMessage(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMessage
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ListPodsRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMessage
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ListPodsRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ListPodsRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMessage(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMessage
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+func (m *PodIP) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMessage
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: PodIP: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: PodIP: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessage
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Name = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Namespace", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessage
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Namespace = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Container", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessage
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Container = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IP", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessage
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.IP = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DeviceNumber", wireType)
+			}
+			m.DeviceNumber = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.DeviceNumber |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field NIC", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessage
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.NIC = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Egress", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessage
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Egress = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMessage(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMessage
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+func (m *ListPodsReply) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMessage
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ListPodsReply: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ListPodsReply: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Pods", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMessage
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Pods = append(m.Pods, &PodIP{})
+			if err := m.Pods[len(m.Pods)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMessage(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMessage
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+func (m *ListNICsRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMessage
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ListNICsRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ListNICsRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMessage(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMessage
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+func (m *NICAddress) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMessage
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: NICAddress: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: NICAddress: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IP", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessage
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.IP = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Secondary", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Secondary = bool(v != 0)
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Pod", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessage
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Pod = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMessage(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMessage
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+func (m *NIC) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMessage
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: NIC: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: NIC: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ID", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessage
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ID = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DeviceNumber", wireType)
+			}
+			m.DeviceNumber = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.DeviceNumber |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Primary", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Primary = bool(v != 0)
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field VxNet", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessage
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.VxNet = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SecurityGroup", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessage
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.SecurityGroup = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Reserved", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Reserved = bool(v != 0)
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Addresses", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMessage
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Addresses = append(m.Addresses, &NICAddress{})
+			if err := m.Addresses[len(m.Addresses)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Routes", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessage
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Routes = append(m.Routes, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMessage(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMessage
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+func (m *ListNICsReply) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMessage
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ListNICsReply: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ListNICsReply: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field NICs", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMessage
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.NICs = append(m.NICs, &NIC{})
+			if err := m.NICs[len(m.NICs)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMessage(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMessage
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+func (m *PoolStatusRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMessage
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: PoolStatusRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: PoolStatusRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMessage(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMessage
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+func (m *PoolStatusReply) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMessage
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: PoolStatusReply: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: PoolStatusReply: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Ready", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Ready = bool(v != 0)
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Message", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessage
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Message = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Stage", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessage
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Stage = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field VxNet", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessage
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.VxNet = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TotalIPs", wireType)
+			}
+			m.TotalIPs = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.TotalIPs |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AssignedIPs", wireType)
+			}
+			m.AssignedIPs = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.AssignedIPs |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PoolSize", wireType)
+			}
+			m.PoolSize = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.PoolSize |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 8:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxPoolSize", wireType)
+			}
+			m.MaxPoolSize = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxPoolSize |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 9:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxIPsPerNIC", wireType)
+			}
+			m.MaxIPsPerNIC = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxIPsPerNIC |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 10:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field NICs", wireType)
+			}
+			m.NICs = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.NICs |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 11:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxNICs", wireType)
+			}
+			m.MaxNICs = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxNICs |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 12:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AllocationError", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMessage
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.AllocationError = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMessage(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMessage
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
 	}
-	return n
-}
 
-func sovMessage(x uint64) (n int) {
-	for {
-		n++
-		x >>= 7
-		if x == 0 {
-			break
-		}
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
 	}
-	return n
-}
-func sozMessage(x uint64) (n int) {
-	return sovMessage(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+	return nil
 }
-func (m *AddNetworkRequest) Unmarshal(dAtA []byte) error {
+
+func (m *ReleaseIPRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -735,15 +5195,15 @@ func (m *AddNetworkRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: AddNetworkRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: ReleaseIPRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: AddNetworkRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: ReleaseIPRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field K8S_POD_NAME", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field IP", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -768,13 +5228,13 @@ func (m *AddNetworkRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.K8S_POD_NAME = string(dAtA[iNdEx:postIndex])
+			m.IP = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 2:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field K8S_POD_NAMESPACE", wireType)
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Force", wireType)
 			}
-			var stringLen uint64
+			var v int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMessage
@@ -784,24 +5244,66 @@ func (m *AddNetworkRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= (uint64(b) & 0x7F) << shift
+				v |= (int(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+			m.Force = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMessage(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
 				return ErrInvalidLengthMessage
 			}
-			postIndex := iNdEx + intStringLen
-			if postIndex > l {
+			if (iNdEx + skippy) > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.K8S_POD_NAMESPACE = string(dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
-		case 3:
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+func (m *ReleaseIPReply) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMessage
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ReleaseIPReply: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ReleaseIPReply: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field K8S_POD_INFRA_CONTAINER_ID", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field IP", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -826,11 +5328,11 @@ func (m *AddNetworkRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.K8S_POD_INFRA_CONTAINER_ID = string(dAtA[iNdEx:postIndex])
+			m.IP = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
-		case 4:
+		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Netns", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Pod", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -855,37 +5357,110 @@ func (m *AddNetworkRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Netns = string(dAtA[iNdEx:postIndex])
+			m.Pod = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
-		case 5:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field IfName", wireType)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMessage(dAtA[iNdEx:])
+			if err != nil {
+				return err
 			}
-			var stringLen uint64
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowMessage
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				stringLen |= (uint64(b) & 0x7F) << shift
-				if b < 0x80 {
-					break
-				}
+			if skippy < 0 {
+				return ErrInvalidLengthMessage
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+func (m *ReconcileRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMessage
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ReconcileRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ReconcileRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMessage(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
 				return ErrInvalidLengthMessage
 			}
-			postIndex := iNdEx + intStringLen
-			if postIndex > l {
+			if (iNdEx + skippy) > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.IfName = string(dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+func (m *ReconcileReply) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMessage
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ReconcileReply: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ReconcileReply: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
 		default:
 			iNdEx = preIndex
 			skippy, err := skipMessage(dAtA[iNdEx:])
@@ -907,7 +5482,8 @@ func (m *AddNetworkRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *AddNetworkReply) Unmarshal(dAtA []byte) error {
+
+func (m *DumpRulesRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -930,35 +5506,66 @@ func (m *AddNetworkReply) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: AddNetworkReply: wiretype end group for non-group")
+			return fmt.Errorf("proto: DumpRulesRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: AddNetworkReply: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: DumpRulesRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
-		case 1:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Success", wireType)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMessage(dAtA[iNdEx:])
+			if err != nil {
+				return err
 			}
-			var v int
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowMessage
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				v |= (int(b) & 0x7F) << shift
-				if b < 0x80 {
-					break
-				}
+			if skippy < 0 {
+				return ErrInvalidLengthMessage
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+func (m *Rule) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMessage
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
 			}
-			m.Success = bool(v != 0)
-		case 2:
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Rule: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Rule: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field IPv4Addr", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Rule", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -983,11 +5590,11 @@ func (m *AddNetworkReply) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.IPv4Addr = string(dAtA[iNdEx:postIndex])
+			m.Rule = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
-		case 3:
+		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field IPv4Subnet", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Owner", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -1012,13 +5619,13 @@ func (m *AddNetworkReply) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.IPv4Subnet = string(dAtA[iNdEx:postIndex])
+			m.Owner = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
-		case 4:
+		case 3:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field DeviceNumber", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Expected", wireType)
 			}
-			m.DeviceNumber = 0
+			var v int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMessage
@@ -1028,14 +5635,15 @@ func (m *AddNetworkReply) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.DeviceNumber |= (int32(b) & 0x7F) << shift
+				v |= (int(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-		case 5:
+			m.Expected = bool(v != 0)
+		case 4:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field UseExternalSNAT", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Installed", wireType)
 			}
 			var v int
 			for shift := uint(0); ; shift += 7 {
@@ -1052,41 +5660,63 @@ func (m *AddNetworkReply) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
-			m.UseExternalSNAT = bool(v != 0)
-		case 6:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Message", wireType)
-			}
-			var stringLen uint64
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowMessage
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				stringLen |= (uint64(b) & 0x7F) << shift
-				if b < 0x80 {
-					break
-				}
+			m.Installed = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMessage(dAtA[iNdEx:])
+			if err != nil {
+				return err
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+			if skippy < 0 {
 				return ErrInvalidLengthMessage
 			}
-			postIndex := iNdEx + intStringLen
-			if postIndex > l {
+			if (iNdEx + skippy) > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Message = string(dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
-		case 7:
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+func (m *DumpRulesReply) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMessage
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: DumpRulesReply: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: DumpRulesReply: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field VPCcidrs", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Rules", wireType)
 			}
-			var stringLen uint64
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMessage
@@ -1096,20 +5726,22 @@ func (m *AddNetworkReply) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= (uint64(b) & 0x7F) << shift
+				msglen |= (int(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthMessage
 			}
-			postIndex := iNdEx + intStringLen
+			postIndex := iNdEx + msglen
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.VPCcidrs = append(m.VPCcidrs, string(dAtA[iNdEx:postIndex]))
+			m.Rules = append(m.Rules, &Rule{})
+			if err := m.Rules[len(m.Rules)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -1132,7 +5764,8 @@ func (m *AddNetworkReply) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *DelNetworkRequest) Unmarshal(dAtA []byte) error {
+
+func (m *VerifyPodRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1155,15 +5788,15 @@ func (m *DelNetworkRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: DelNetworkRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: VerifyPodRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: DelNetworkRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: VerifyPodRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field K8S_POD_NAME", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -1188,11 +5821,11 @@ func (m *DelNetworkRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.K8S_POD_NAME = string(dAtA[iNdEx:postIndex])
+			m.Name = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field K8S_POD_NAMESPACE", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Namespace", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -1217,11 +5850,62 @@ func (m *DelNetworkRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.K8S_POD_NAMESPACE = string(dAtA[iNdEx:postIndex])
+			m.Namespace = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
-		case 3:
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMessage(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMessage
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+func (m *Check) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMessage
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Check: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Check: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field K8S_POD_INFRA_CONTAINER_ID", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -1246,13 +5930,13 @@ func (m *DelNetworkRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.K8S_POD_INFRA_CONTAINER_ID = string(dAtA[iNdEx:postIndex])
+			m.Name = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
-		case 4:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field IPv4Addr", wireType)
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field OK", wireType)
 			}
-			var stringLen uint64
+			var v int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMessage
@@ -1262,24 +5946,15 @@ func (m *DelNetworkRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= (uint64(b) & 0x7F) << shift
+				v |= (int(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
-				return ErrInvalidLengthMessage
-			}
-			postIndex := iNdEx + intStringLen
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.IPv4Addr = string(dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
-		case 5:
+			m.OK = bool(v != 0)
+		case 3:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Reason", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Message", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -1304,7 +5979,7 @@ func (m *DelNetworkRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Reason = string(dAtA[iNdEx:postIndex])
+			m.Message = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -1327,7 +6002,8 @@ func (m *DelNetworkRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *DelNetworkReply) Unmarshal(dAtA []byte) error {
+
+func (m *VerifyPodReply) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1350,15 +6026,15 @@ func (m *DelNetworkReply) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: DelNetworkReply: wiretype end group for non-group")
+			return fmt.Errorf("proto: VerifyPodReply: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: DelNetworkReply: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: VerifyPodReply: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Success", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field OK", wireType)
 			}
 			var v int
 			for shift := uint(0); ; shift += 7 {
@@ -1375,12 +6051,12 @@ func (m *DelNetworkReply) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
-			m.Success = bool(v != 0)
+			m.OK = bool(v != 0)
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field IPv4Addr", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Checks", wireType)
 			}
-			var stringLen uint64
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowMessage
@@ -1390,68 +6066,22 @@ func (m *DelNetworkReply) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= (uint64(b) & 0x7F) << shift
+				msglen |= (int(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthMessage
 			}
-			postIndex := iNdEx + intStringLen
+			postIndex := iNdEx + msglen
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.IPv4Addr = string(dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
-		case 3:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field DeviceNumber", wireType)
-			}
-			m.DeviceNumber = 0
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowMessage
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				m.DeviceNumber |= (int32(b) & 0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-		case 4:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Message", wireType)
-			}
-			var stringLen uint64
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowMessage
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				stringLen |= (uint64(b) & 0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
-				return ErrInvalidLengthMessage
-			}
-			postIndex := iNdEx + intStringLen
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
+			m.Checks = append(m.Checks, &Check{})
+			if err := m.Checks[len(m.Checks)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
 			}
-			m.Message = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -1582,34 +6212,85 @@ var (
 func init() { proto.RegisterFile("pkg/rpc/message.proto", fileDescriptorMessage) }
 
 var fileDescriptorMessage = []byte{
-	// 451 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xcc, 0x53, 0xcd, 0x6e, 0xd3, 0x40,
-	0x18, 0xcc, 0x92, 0x9f, 0x86, 0x4f, 0x95, 0x42, 0x56, 0x25, 0x5a, 0xe5, 0x60, 0x45, 0x3e, 0x55,
-	0x1c, 0x52, 0x09, 0x38, 0x54, 0x88, 0x8b, 0x1b, 0x07, 0xc9, 0xaa, 0xba, 0xb1, 0xd6, 0x85, 0x6b,
-	0xe4, 0xac, 0x3f, 0xaa, 0x2a, 0x89, 0x63, 0x76, 0x9d, 0x42, 0x1f, 0x00, 0x89, 0x47, 0xe0, 0x91,
-	0x90, 0xb8, 0xc0, 0x1b, 0xa0, 0x70, 0xe4, 0x25, 0x90, 0x37, 0x76, 0xe3, 0x3a, 0x9c, 0x38, 0xf5,
-	0xe6, 0x99, 0xfd, 0x46, 0x9e, 0xf9, 0x66, 0x17, 0x9e, 0x26, 0xf3, 0xab, 0x13, 0x95, 0xc8, 0x93,
-	0x25, 0x6a, 0x1d, 0x5e, 0xe1, 0x30, 0x51, 0xab, 0x74, 0x45, 0xeb, 0x2a, 0x91, 0xf6, 0x77, 0x02,
-	0x5d, 0x27, 0x8a, 0x38, 0xa6, 0x1f, 0x57, 0x6a, 0x2e, 0xf0, 0xc3, 0x1a, 0x75, 0x4a, 0x07, 0x70,
-	0x78, 0x7e, 0x1a, 0x4c, 0xfd, 0x89, 0x3b, 0xe5, 0xce, 0xc5, 0x98, 0x91, 0x01, 0x39, 0x7e, 0x2c,
-	0xe0, 0xfc, 0x34, 0xf0, 0x27, 0x6e, 0xc6, 0xd0, 0x67, 0xd0, 0x2d, 0x4f, 0x04, 0xbe, 0x33, 0x1a,
-	0xb3, 0x47, 0x66, 0xac, 0xb3, 0x1b, 0x33, 0x34, 0x7d, 0x05, 0xfd, 0x62, 0xd6, 0xe3, 0x6f, 0x84,
-	0x33, 0x1d, 0x4d, 0xf8, 0xa5, 0xe3, 0xf1, 0xb1, 0x98, 0x7a, 0x2e, 0xab, 0x1b, 0x51, 0x6f, 0x2b,
-	0x32, 0xe7, 0x77, 0xc7, 0x9e, 0x4b, 0x8f, 0xa0, 0xc9, 0x31, 0x8d, 0x35, 0x6b, 0x98, 0xb1, 0x2d,
-	0xa0, 0x3d, 0x68, 0x79, 0xef, 0x79, 0xb8, 0x44, 0xd6, 0x34, 0x74, 0x8e, 0xec, 0x3f, 0x04, 0x3a,
-	0xe5, 0x34, 0xc9, 0xe2, 0x96, 0x32, 0x38, 0x08, 0xd6, 0x52, 0xa2, 0xd6, 0x26, 0x46, 0x5b, 0x14,
-	0x90, 0xf6, 0xa1, 0xed, 0xf9, 0x37, 0x2f, 0x9d, 0x28, 0x52, 0xb9, 0xf5, 0x3b, 0x4c, 0x2d, 0x80,
-	0xec, 0x3b, 0x58, 0xcf, 0x62, 0x4c, 0x73, 0x8f, 0x25, 0x86, 0xda, 0x70, 0xe8, 0xe2, 0xcd, 0xb5,
-	0x44, 0xbe, 0x5e, 0xce, 0x50, 0x19, 0x7b, 0x4d, 0x71, 0x8f, 0xa3, 0xc7, 0xd0, 0x79, 0xab, 0x71,
-	0xfc, 0x29, 0x45, 0x15, 0x87, 0x8b, 0x80, 0x3b, 0x97, 0xc6, 0x6e, 0x5b, 0x54, 0xe9, 0xcc, 0xe3,
-	0xc5, 0xb6, 0x1b, 0xd6, 0x32, 0xbf, 0x2a, 0x60, 0xe6, 0xf1, 0x9d, 0x3f, 0x92, 0xd7, 0x91, 0xd2,
-	0xec, 0x60, 0x50, 0xcf, 0x3c, 0x16, 0xd8, 0xfe, 0x49, 0xa0, 0xeb, 0xe2, 0xe2, 0xc1, 0x76, 0x57,
-	0xde, 0x6f, 0xa3, 0xb2, 0xdf, 0x1e, 0xb4, 0x04, 0x86, 0x7a, 0x15, 0x17, 0x0d, 0x6e, 0x91, 0xfd,
-	0x99, 0x40, 0xa7, 0x9c, 0xe9, 0xff, 0x1b, 0xac, 0x36, 0x54, 0xff, 0x47, 0x43, 0xa5, 0xbd, 0x37,
-	0xee, 0xed, 0xfd, 0xf9, 0x17, 0x02, 0x30, 0xe2, 0xde, 0x59, 0x28, 0xe7, 0x18, 0x47, 0xf4, 0x35,
-	0xc0, 0xee, 0x5e, 0xd1, 0xde, 0x50, 0x25, 0x72, 0xb8, 0xf7, 0x6c, 0xfa, 0x47, 0x7b, 0x7c, 0xb2,
-	0xb8, 0xb5, 0x6b, 0x99, 0x7a, 0x97, 0x29, 0x57, 0xef, 0x15, 0x97, 0xab, 0x2b, 0xe1, 0xed, 0xda,
-	0xd9, 0x93, 0x6f, 0x1b, 0x8b, 0xfc, 0xd8, 0x58, 0xe4, 0xd7, 0xc6, 0x22, 0x5f, 0x7f, 0x5b, 0xb5,
-	0x59, 0xcb, 0x3c, 0xe0, 0x17, 0x7f, 0x03, 0x00, 0x00, 0xff, 0xff, 0xfb, 0x16, 0x24, 0x9e, 0xd9,
-	0x03, 0x00, 0x00,
+	// 1271 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xcc, 0x57, 0xcf, 0x6e, 0xdb, 0xc6,
+	0x13, 0x0e, 0x25, 0xd1, 0xa1, 0xc6, 0x7f, 0x64, 0xaf, 0x15, 0x83, 0x20, 0x02, 0xfd, 0x04, 0xe2,
+	0x77, 0x30, 0x0a, 0xc4, 0x41, 0xdd, 0xa2, 0x48, 0x8b, 0x5e, 0x14, 0x49, 0x29, 0x08, 0xc7, 0x14,
+	0xb1, 0x72, 0x7c, 0x2a, 0x60, 0x30, 0xe4, 0xc6, 0x65, 0x4d, 0x93, 0x2c, 0x49, 0x25, 0x52, 0x9f,
+	0xa4, 0xf7, 0x9e, 0xfa, 0x00, 0x45, 0x0f, 0x7d, 0x81, 0x1e, 0xfb, 0x08, 0x45, 0x8a, 0x9e, 0x7a,
+	0x6a, 0x9f, 0xa0, 0xd8, 0xd9, 0xe5, 0x1f, 0x51, 0x2e, 0x8a, 0xe6, 0xd4, 0x93, 0x39, 0xdf, 0xee,
+	0xec, 0xce, 0x37, 0xf3, 0xcd, 0xac, 0x0c, 0x0f, 0x92, 0x9b, 0xeb, 0xc7, 0x69, 0xe2, 0x3d, 0xbe,
+	0x65, 0x59, 0xe6, 0x5e, 0xb3, 0x93, 0x24, 0x8d, 0xf3, 0x98, 0xb4, 0xd3, 0xc4, 0x33, 0x7f, 0x57,
+	0xe0, 0x60, 0xe4, 0xfb, 0x36, 0xcb, 0xdf, 0xc4, 0xe9, 0x0d, 0x65, 0x5f, 0x2d, 0x58, 0x96, 0x93,
+	0x21, 0xec, 0x9c, 0x3d, 0x99, 0x5f, 0x39, 0xb3, 0xc9, 0x95, 0x3d, 0x3a, 0x9f, 0xea, 0xca, 0x50,
+	0x39, 0xee, 0x52, 0x38, 0x7b, 0x32, 0x77, 0x66, 0x13, 0x8e, 0x90, 0xf7, 0xe0, 0xa0, 0xbe, 0x63,
+	0xee, 0x8c, 0xc6, 0x53, 0xbd, 0x85, 0xdb, 0x7a, 0xd5, 0x36, 0x84, 0xc9, 0x27, 0x60, 0x14, 0x7b,
+	0x2d, 0xfb, 0x19, 0x1d, 0x5d, 0x8d, 0x67, 0xf6, 0xc5, 0xc8, 0xb2, 0xa7, 0xf4, 0xca, 0x9a, 0xe8,
+	0x6d, 0x74, 0x3a, 0x12, 0x4e, 0xb8, 0x5e, 0x2e, 0x5b, 0x13, 0xd2, 0x07, 0xd5, 0x66, 0x79, 0x94,
+	0xe9, 0x1d, 0xdc, 0x26, 0x0c, 0x72, 0x04, 0x5b, 0xd6, 0x2b, 0xdb, 0xbd, 0x65, 0xba, 0x8a, 0xb0,
+	0xb4, 0xc8, 0x00, 0x60, 0xe4, 0x58, 0x97, 0x2c, 0xcd, 0x82, 0x38, 0xd2, 0xb7, 0x86, 0xca, 0xb1,
+	0x4a, 0x6b, 0x88, 0xf9, 0x63, 0x0b, 0x7a, 0x75, 0xb6, 0x49, 0xb8, 0x22, 0x3a, 0xdc, 0x9f, 0x2f,
+	0x3c, 0x8f, 0x65, 0x19, 0xd2, 0xd4, 0x68, 0x61, 0x12, 0x03, 0x34, 0xcb, 0x79, 0xfd, 0xe1, 0xc8,
+	0xf7, 0x53, 0x49, 0xad, 0xb4, 0xf9, 0x4d, 0xfc, 0x7b, 0xbe, 0x78, 0x19, 0xb1, 0x5c, 0x72, 0xa8,
+	0x21, 0xc4, 0x84, 0x9d, 0x09, 0x7b, 0x1d, 0x78, 0xcc, 0x5e, 0xdc, 0xbe, 0x64, 0x29, 0x86, 0xaf,
+	0xd2, 0x35, 0x8c, 0x1c, 0x43, 0xef, 0x45, 0xc6, 0xa6, 0xcb, 0x9c, 0xa5, 0x91, 0x1b, 0xce, 0xed,
+	0xd1, 0x05, 0xd2, 0xd1, 0x68, 0x13, 0xe6, 0x31, 0x9e, 0x8b, 0xda, 0x21, 0xa9, 0x2e, 0x2d, 0x4c,
+	0x1e, 0xe3, 0xa5, 0x33, 0xf6, 0x02, 0x3f, 0xcd, 0xf4, 0xfb, 0xc3, 0x36, 0x8f, 0xb1, 0xb0, 0x79,
+	0x8c, 0x0e, 0x4b, 0x6d, 0x6b, 0x8c, 0x47, 0x6b, 0x78, 0x74, 0x0d, 0x21, 0xfb, 0xd0, 0x3e, 0xbf,
+	0x78, 0xa1, 0x77, 0x31, 0x34, 0xfe, 0x49, 0x1e, 0x42, 0x77, 0x9a, 0xa6, 0x71, 0x7a, 0xb1, 0x4a,
+	0x98, 0x0e, 0x78, 0x53, 0x05, 0x98, 0x7f, 0x28, 0x70, 0x30, 0x61, 0xe1, 0x7f, 0x56, 0x2b, 0xf5,
+	0x7a, 0x75, 0x1a, 0xf5, 0x3a, 0x82, 0x2d, 0xca, 0xdc, 0x2c, 0x8e, 0x0a, 0xc5, 0x08, 0xeb, 0x1f,
+	0x15, 0xf3, 0xad, 0x02, 0xbd, 0x3a, 0xe7, 0x77, 0x57, 0x4c, 0x53, 0x11, 0xed, 0x3b, 0x14, 0x51,
+	0xab, 0x73, 0x67, 0xbd, 0xce, 0x6b, 0x95, 0x51, 0x9b, 0x95, 0x39, 0x81, 0x1d, 0xca, 0x5c, 0x7f,
+	0x55, 0xd4, 0x64, 0x9d, 0x95, 0xb2, 0xc1, 0xea, 0x73, 0x00, 0xb9, 0x9f, 0xf3, 0xe9, 0x83, 0x8a,
+	0x96, 0x64, 0x23, 0x8c, 0x7a, 0x2c, 0xad, 0xf5, 0x58, 0xd6, 0x4f, 0x6f, 0x6f, 0x9c, 0x7e, 0x00,
+	0xbd, 0xe7, 0x41, 0x96, 0x3b, 0xb1, 0x9f, 0xc9, 0x80, 0xcc, 0xef, 0x15, 0x50, 0x9d, 0xd8, 0xb7,
+	0x1c, 0x42, 0xa0, 0x83, 0x8d, 0x2b, 0x64, 0x82, 0xdf, 0x9c, 0x1c, 0xff, 0x9b, 0x25, 0xae, 0x57,
+	0x5c, 0x56, 0x01, 0x7c, 0x75, 0x1c, 0x47, 0xb9, 0x1b, 0x44, 0x32, 0x6b, 0x5d, 0x5a, 0x01, 0x64,
+	0x0f, 0x5a, 0x96, 0x23, 0xb3, 0xd5, 0xb2, 0x9c, 0x8d, 0x34, 0xab, 0x77, 0xa4, 0x79, 0x1f, 0xda,
+	0xb6, 0x35, 0x96, 0xad, 0xc4, 0x3f, 0xb9, 0x3c, 0xa6, 0xd7, 0x29, 0xaf, 0xe8, 0x7d, 0x21, 0x0f,
+	0x61, 0x99, 0x8f, 0x61, 0xb7, 0xa2, 0xc2, 0x73, 0x35, 0x80, 0x0e, 0x37, 0x74, 0x65, 0xd8, 0x3e,
+	0xde, 0x3e, 0x85, 0x93, 0x34, 0xf1, 0x4e, 0x90, 0x18, 0x45, 0xbc, 0xe0, 0x6e, 0x5b, 0xe3, 0x92,
+	0xfb, 0x73, 0x00, 0xdb, 0x1a, 0x73, 0x0d, 0x70, 0x89, 0x88, 0x78, 0x95, 0x32, 0xde, 0x87, 0xd0,
+	0x9d, 0x33, 0x2f, 0x8e, 0x7c, 0x37, 0x5d, 0x21, 0x77, 0x8d, 0x56, 0x00, 0x8f, 0xd4, 0x89, 0x7d,
+	0xc9, 0x9a, 0x7f, 0x9a, 0x7f, 0x2a, 0x18, 0x3c, 0x9e, 0x33, 0x29, 0xcf, 0x99, 0x6c, 0xf0, 0x6e,
+	0xdd, 0x2d, 0x2f, 0x27, 0x0d, 0x6e, 0xf9, 0x4d, 0x6d, 0x21, 0x5c, 0x69, 0x72, 0x09, 0x5c, 0x2e,
+	0x6d, 0x96, 0x17, 0x63, 0x16, 0x0d, 0xf2, 0x7f, 0xd8, 0x9d, 0x33, 0x6f, 0x91, 0x06, 0xf9, 0xea,
+	0xb3, 0x34, 0x5e, 0x24, 0x52, 0x78, 0xeb, 0x20, 0x17, 0x3d, 0x65, 0x19, 0x4b, 0x5f, 0x33, 0x1f,
+	0x53, 0xaa, 0xd1, 0xd2, 0x26, 0x8f, 0xa0, 0x2b, 0x89, 0x33, 0x31, 0x9f, 0xb6, 0x4f, 0x7b, 0x98,
+	0xb3, 0x2a, 0x23, 0xb4, 0xda, 0x81, 0x5d, 0x1a, 0x2f, 0x72, 0x96, 0xe9, 0x1a, 0xce, 0x32, 0x69,
+	0x99, 0x8f, 0x60, 0xb7, 0xca, 0x2a, 0x2f, 0xc3, 0x43, 0xe8, 0x70, 0x43, 0x96, 0x41, 0x2b, 0x8e,
+	0xa4, 0x88, 0x9a, 0x87, 0x70, 0xe0, 0xc4, 0x71, 0x38, 0xcf, 0xdd, 0x7c, 0x51, 0x96, 0xe1, 0xb7,
+	0x16, 0xf4, 0xea, 0xe8, 0xbb, 0x28, 0xbf, 0x0f, 0xea, 0x3c, 0xe7, 0xb8, 0x28, 0x88, 0x30, 0xfe,
+	0x26, 0x79, 0x06, 0x68, 0x17, 0x71, 0xee, 0x86, 0x96, 0x93, 0x49, 0x11, 0x96, 0x36, 0x19, 0xc2,
+	0xf6, 0x28, 0xcb, 0x82, 0xeb, 0x88, 0xf9, 0x7c, 0x59, 0x8c, 0x9d, 0x3a, 0xc4, 0xbd, 0x31, 0xd8,
+	0xe0, 0x6b, 0x86, 0x92, 0x54, 0x69, 0x69, 0x73, 0xef, 0x73, 0x77, 0x59, 0x2e, 0x6b, 0xc2, 0xbb,
+	0x06, 0x71, 0x31, 0x9c, 0xbb, 0x4b, 0xcb, 0xc9, 0xc4, 0xb4, 0x97, 0x23, 0x7e, 0x0d, 0x23, 0x44,
+	0xa6, 0x10, 0x70, 0x0d, 0xbf, 0x91, 0xb9, 0xbb, 0x44, 0x78, 0x1b, 0xe1, 0xc2, 0xe4, 0x6f, 0xd5,
+	0x28, 0x0c, 0x63, 0xcf, 0xcd, 0x83, 0x38, 0xc2, 0xc1, 0xa3, 0xef, 0x88, 0x09, 0xde, 0x80, 0xcd,
+	0x27, 0xb0, 0x4f, 0x59, 0xc8, 0xdc, 0x8c, 0x59, 0x4e, 0x31, 0x8f, 0x9a, 0xa2, 0xef, 0x83, 0xfa,
+	0x2c, 0x4e, 0x65, 0xb3, 0x6b, 0x54, 0x18, 0xe6, 0x29, 0xec, 0xd5, 0x3c, 0x79, 0x7d, 0x9a, 0x7e,
+	0xb2, 0x1d, 0x5a, 0x55, 0x3b, 0x10, 0x7e, 0x9b, 0x17, 0x47, 0x5e, 0x10, 0xb2, 0xa2, 0xd2, 0xfb,
+	0xb0, 0x57, 0xc3, 0x92, 0x70, 0xc5, 0x77, 0x4d, 0x16, 0xb7, 0x09, 0x5d, 0x84, 0xac, 0xd4, 0xc3,
+	0x97, 0xd0, 0xe1, 0x36, 0x21, 0xe2, 0x6f, 0x31, 0x90, 0x10, 0xeb, 0x83, 0x3a, 0x7b, 0x13, 0xb1,
+	0x62, 0x88, 0x0b, 0x83, 0xd7, 0x64, 0xba, 0x4c, 0x98, 0x97, 0x33, 0x5f, 0xf6, 0x4f, 0x69, 0xf3,
+	0x36, 0xb6, 0xa2, 0x2c, 0x77, 0xc3, 0x90, 0xf9, 0xa8, 0x03, 0x8d, 0x56, 0x80, 0xf9, 0x3e, 0xec,
+	0xd5, 0xee, 0xe7, 0xcc, 0xfe, 0x07, 0x2a, 0x5a, 0x52, 0xc1, 0x5d, 0x54, 0x30, 0x47, 0xa8, 0xc0,
+	0xcd, 0x09, 0xec, 0x5f, 0xb2, 0x34, 0x78, 0xb5, 0x72, 0x62, 0xbf, 0x48, 0xe3, 0xbf, 0x9e, 0x9d,
+	0xe6, 0x14, 0xd4, 0xf1, 0x17, 0xcc, 0xbb, 0xb9, 0xd3, 0x75, 0x0f, 0x5a, 0xb3, 0x33, 0x59, 0x82,
+	0xd6, 0xec, 0xac, 0xae, 0xfb, 0xf6, 0x9a, 0xee, 0xcd, 0x09, 0xec, 0xd5, 0x82, 0x91, 0x95, 0x99,
+	0x9d, 0xe9, 0x4a, 0xe9, 0x6b, 0xc2, 0x16, 0x5e, 0x94, 0xe9, 0xad, 0xda, 0x64, 0x44, 0x88, 0xca,
+	0x95, 0xd3, 0x1f, 0x14, 0x80, 0xb1, 0x6d, 0x3d, 0x75, 0xbd, 0x1b, 0x16, 0xf9, 0xe4, 0x53, 0x80,
+	0xea, 0xb7, 0x18, 0x39, 0x42, 0x87, 0x8d, 0x9f, 0xa2, 0x46, 0x7f, 0x03, 0xe7, 0x05, 0xbd, 0xc7,
+	0xbd, 0xab, 0x77, 0x59, 0x7a, 0x6f, 0xfc, 0x38, 0x31, 0xfa, 0x1b, 0xb8, 0xf0, 0x7e, 0x24, 0x1b,
+	0x9f, 0x1c, 0x88, 0xc4, 0xd7, 0x1e, 0x4f, 0xa3, 0x57, 0x87, 0x70, 0xfb, 0xe9, 0x77, 0x6d, 0xd8,
+	0xb5, 0xa2, 0x3c, 0x8d, 0x33, 0x5e, 0xee, 0x20, 0x8e, 0xc8, 0x47, 0xa0, 0x15, 0x0f, 0x03, 0x11,
+	0x97, 0x34, 0x9e, 0x3c, 0x83, 0x34, 0x50, 0x71, 0xb1, 0xf4, 0xc3, 0x9e, 0xaa, 0xfc, 0x6a, 0xcf,
+	0x85, 0x41, 0x1a, 0x68, 0x49, 0xb7, 0x1a, 0x5e, 0x92, 0xee, 0xc6, 0x8c, 0x33, 0xfa, 0x1b, 0xb8,
+	0xf0, 0xfe, 0x18, 0xba, 0x65, 0x67, 0x91, 0x07, 0x92, 0xdf, 0x7a, 0x8f, 0x1a, 0x87, 0x4d, 0xb8,
+	0xe6, 0x2a, 0x9b, 0xa9, 0x74, 0x5d, 0x6f, 0x38, 0xe3, 0xb0, 0x09, 0x97, 0xae, 0xa5, 0xea, 0xa5,
+	0x6b, 0xb3, 0x0b, 0x8d, 0xc3, 0x26, 0x5c, 0xba, 0x96, 0x82, 0x93, 0xae, 0xcd, 0x6e, 0x30, 0x0e,
+	0x9b, 0x30, 0xba, 0x3e, 0xdd, 0xff, 0xe9, 0xed, 0x40, 0xf9, 0xf9, 0xed, 0x40, 0xf9, 0xe5, 0xed,
+	0x40, 0xf9, 0xe6, 0xd7, 0xc1, 0xbd, 0x97, 0x5b, 0xf8, 0xff, 0xce, 0x07, 0x7f, 0x0d, 0x00, 0x3d,
+	0x77, 0x40, 0x48, 0x08, 0x0d, 0x00, 0x00,
 }
@@ -0,0 +1,12 @@
+package rpc
+
+const (
+	// APIVersion is the version of CNIBackend spoken by this build of the plugin and ipamd. It is bumped when fields
+	// or rpcs are added to CNIBackend, so that each side knows what the other one understands. 1 adds Ready, and 2 adds
+	// ErrorType to the replies.
+	APIVersion = 2
+	// MinAPIVersion is the oldest version of the plugin which ipamd serves, plugins which send no version are of 0.
+	// The plugin is replaced before ipamd starts, so only the calls made by an older plugin before that are refused,
+	// and kubelet retries them with the new one.
+	MinAPIVersion = 1
+)
@@ -29,6 +29,10 @@ type HostNic struct {
 	Address      string `json:"address"`
 	DeviceNumber int    `json:"deviceNumber"`
 	IsPrimary    bool   `json:"IsPrimary"`
+	// SecurityGroup is the id of the security group applied to the nic, empty if there is none
+	SecurityGroup string `json:"securityGroup"`
+	// SecondaryAddresses are the private ips assigned to the nic besides Address
+	SecondaryAddresses []string `json:"secondaryAddresses"`
 }
 
 type VxNet struct {
@@ -62,8 +66,20 @@ const (
 	ResourceTypeNic      ResourceType = "nic"
 	ResourceTypeTag      ResourceType = "tag"
 	ResourceTypeVPC      ResourceType = "vpc"
+	ResourceTypeEIP      ResourceType = "eip"
+
+	ResourceTypeSecurityGroup ResourceType = "security_group"
 )
 
+// EIP is an elastic ip, which is bound to an instance or a nic
+type EIP struct {
+	ID      string
+	Address string
+	// ResourceID is the id of the instance or the nic which the eip is bound to, empty if it is not bound
+	ResourceID string
+	ResourceType
+}
+
 // Tag including resources which have same labels
 type Tag struct {
 	Label           string
@@ -0,0 +1,147 @@
+// Package webhook is a mutating admission webhook which makes pods request the ip resource of hostnic, so that
+// they are only scheduled to the nodes which are able to give them an ip.
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog"
+)
+
+const (
+	// ResourceIP must be the same as the extended resource advertised by ipamd
+	ResourceIP corev1.ResourceName = "hostnic.io/ip"
+	// AnnotationSkip keeps a pod out of the mutation if it is "true"
+	AnnotationSkip = "hostnic.io/skip-ip-resource"
+
+	patchTypeJSONPatch = "JSONPatch"
+)
+
+// The wire format of admission.k8s.io/v1beta1, only the fields used by the webhook are kept
+
+// AdmissionReview is the request and the response of a webhook call
+type AdmissionReview struct {
+	metav1.TypeMeta `json:",inline"`
+	Request         *AdmissionRequest  `json:"request,omitempty"`
+	Response        *AdmissionResponse `json:"response,omitempty"`
+}
+
+// AdmissionRequest carries the object to admit
+type AdmissionRequest struct {
+	UID       types.UID               `json:"uid"`
+	Kind      metav1.GroupVersionKind `json:"kind"`
+	Namespace string                  `json:"namespace,omitempty"`
+	Operation string                  `json:"operation"`
+	Object    json.RawMessage         `json:"object,omitempty"`
+}
+
+// AdmissionResponse carries the patch of the object
+type AdmissionResponse struct {
+	UID       types.UID      `json:"uid"`
+	Allowed   bool           `json:"allowed"`
+	Result    *metav1.Status `json:"status,omitempty"`
+	Patch     []byte         `json:"patch,omitempty"`
+	PatchType *string        `json:"patchType,omitempty"`
+}
+
+// PatchOperation is an operation of json patch
+type PatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// MutatePod returns the json patch which makes the first container of a pod request one ip. Pods on the host
+// network, pods skipped by annotation and pods already requesting an ip are not changed.
+func MutatePod(pod *corev1.Pod) []PatchOperation {
+	if pod.Spec.HostNetwork || pod.Annotations[AnnotationSkip] == "true" || len(pod.Spec.Containers) == 0 {
+		return nil
+	}
+	for _, container := range pod.Spec.Containers {
+		if _, ok := container.Resources.Requests[ResourceIP]; ok {
+			return nil
+		}
+		if _, ok := container.Resources.Limits[ResourceIP]; ok {
+			return nil
+		}
+	}
+	// extended resources must have the same request and limit
+	resources := pod.Spec.Containers[0].Resources
+	return []PatchOperation{
+		addResource("/spec/containers/0/resources/requests", resources.Requests == nil),
+		addResource("/spec/containers/0/resources/limits", resources.Limits == nil),
+	}
+}
+
+func addResource(path string, createList bool) PatchOperation {
+	if createList {
+		return PatchOperation{Op: "add", Path: path, Value: map[string]string{string(ResourceIP): "1"}}
+	}
+	// "/" in the resource name is escaped as "~1" in a json pointer
+	return PatchOperation{Op: "add", Path: path + "/" + strings.Replace(string(ResourceIP), "/", "~1", -1), Value: "1"}
+}
+
+// Admit answers an admission request of a pod
+func Admit(request *AdmissionRequest) *AdmissionResponse {
+	response := &AdmissionResponse{UID: request.UID, Allowed: true}
+	if request.Kind.Kind != "Pod" || request.Operation != "CREATE" {
+		return response
+	}
+	pod := &corev1.Pod{}
+	if err := json.Unmarshal(request.Object, pod); err != nil {
+		// never block pods because of the webhook
+		klog.Errorf("Failed to decode pod of request %s: %v", request.UID, err)
+		return response
+	}
+	patch := MutatePod(pod)
+	if len(patch) == 0 {
+		return response
+	}
+	data, err := json.Marshal(patch)
+	if err != nil {
+		klog.Errorf("Failed to encode patch of request %s: %v", request.UID, err)
+		return response
+	}
+	patchType := patchTypeJSONPatch
+	response.Patch, response.PatchType = data, &patchType
+	klog.V(2).Infof("Pod %s/%s%s requests %s", request.Namespace, pod.Name, pod.GenerateName, ResourceIP)
+	return response
+}
+
+// ServeHTTP handles the AdmissionReview posted by apiserver
+func ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	review := &AdmissionReview{}
+	if err := json.Unmarshal(body, review); err != nil || review.Request == nil {
+		http.Error(w, fmt.Sprintf("invalid admission review: %v", err), http.StatusBadRequest)
+		return
+	}
+	review.Response = Admit(review.Request)
+	review.Request = nil
+	data, err := json.Marshal(review)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// Serve serves the webhook at /mutate with tls, which is required by apiserver
+func Serve(address, certFile, keyFile string) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/mutate", ServeHTTP)
+	klog.V(1).Infof("Serving the webhook of %s on %s", ResourceIP, address)
+	return http.ListenAndServeTLS(address, certFile, keyFile, mux)
+}
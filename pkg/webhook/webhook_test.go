@@ -0,0 +1,72 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+var _ = Describe("Webhook", func() {
+	newPod := func() *corev1.Pod {
+		pod := &corev1.Pod{}
+		pod.Name = "pod1"
+		pod.Spec.Containers = []corev1.Container{{Name: "c1"}, {Name: "c2"}}
+		return pod
+	}
+
+	It("Should make the first container request an ip", func() {
+		pod := newPod()
+		Expect(MutatePod(pod)).To(Equal([]PatchOperation{
+			{Op: "add", Path: "/spec/containers/0/resources/requests", Value: map[string]string{"hostnic.io/ip": "1"}},
+			{Op: "add", Path: "/spec/containers/0/resources/limits", Value: map[string]string{"hostnic.io/ip": "1"}},
+		}))
+
+		pod.Spec.Containers[0].Resources.Requests = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}
+		Expect(MutatePod(pod)[0]).To(Equal(PatchOperation{Op: "add", Path: "/spec/containers/0/resources/requests/hostnic.io~1ip", Value: "1"}))
+	})
+
+	It("Should not change the pods which need no ip or have one", func() {
+		pod := newPod()
+		pod.Spec.HostNetwork = true
+		Expect(MutatePod(pod)).To(BeEmpty())
+
+		pod = newPod()
+		pod.Annotations = map[string]string{AnnotationSkip: "true"}
+		Expect(MutatePod(pod)).To(BeEmpty())
+
+		pod = newPod()
+		pod.Spec.Containers[1].Resources.Limits = corev1.ResourceList{ResourceIP: resource.MustParse("1")}
+		Expect(MutatePod(pod)).To(BeEmpty())
+	})
+
+	It("Should answer admission reviews with a json patch", func() {
+		object, err := json.Marshal(newPod())
+		Expect(err).ShouldNot(HaveOccurred())
+		review := &AdmissionReview{Request: &AdmissionRequest{UID: "uid-1", Operation: "CREATE", Namespace: "default", Object: object}}
+		review.Request.Kind.Kind = "Pod"
+		body, err := json.Marshal(review)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		recorder := httptest.NewRecorder()
+		ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/mutate", bytes.NewReader(body)))
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		result := &AdmissionReview{}
+		Expect(json.Unmarshal(recorder.Body.Bytes(), result)).To(Succeed())
+		Expect(result.Response.UID).To(BeEquivalentTo("uid-1"))
+		Expect(result.Response.Allowed).To(BeTrue())
+		Expect(*result.Response.PatchType).To(Equal("JSONPatch"))
+		var patch []PatchOperation
+		Expect(json.Unmarshal(result.Response.Patch, &patch)).To(Succeed())
+		Expect(patch).To(HaveLen(2))
+
+		recorder = httptest.NewRecorder()
+		ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/mutate", bytes.NewReader([]byte("{}"))))
+		Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+	})
+})
@@ -8,16 +8,42 @@ function CleanUp() {
     rm -f /host/etc/cni/net.d/99-loopback.conf
 }
 
-trap CleanUp EXIT SIGINT SIGQUIT
+# the agent leaves it when it stops gracefully, e.g. when it is upgraded, but not when its DaemonSet is deleted
+CHECKPOINT=/host/var/lib/hostnic/datastore.json
+
+# Restarting tells whether the agent left a checkpoint since the host booted, the plugin and the config are kept
+# then, so that kubelet keeps creating pods, and the plugin waits for the new agent
+function Restarting() {
+    [ -f $CHECKPOINT ] && grep -q "\"bootID\":\"$(cat /proc/sys/kernel/random/boot_id)\"" $CHECKPOINT
+}
+
+trap CleanUp SIGINT SIGQUIT
+
+# run with "uninstall [--force] [--delete-nics] [--release-vxnet]" to remove hostnic from the node
+if [ "$1" = "uninstall" ]; then
+    echo "===== Uninstalling HOSTNIC-CNI ========="
+    shift
+    # the plugin and the config are kept if the agent refuses, e.g. when pods on the node are using hostnic
+    /app/hostnic-agent -v=2 uninstall "$@" || exit $?
+    CleanUp
+    exit 0
+fi
 
 echo "===== Starting installing HOSTNIC-CNI ========="
-CleanUp
+Restarting || CleanUp
+trap 'Restarting || CleanUp' EXIT
 
-cp /app/hostnic /host/opt/cni/bin/
+# the plugin is replaced at once, because kubelet may be running it
+cp /app/hostnic /host/opt/cni/bin/.hostnic.new && mv -f /host/opt/cni/bin/.hostnic.new /host/opt/cni/bin/hostnic
 #cp /app/portmap /host/opt/cni/bin/
 
 
 echo "===== Starting HOSTNIC-AGENT ==========="
-/app/hostnic-agent -v=2
+/app/hostnic-agent -v=2 &
+AGENT_PID=$!
+# forward SIGTERM to the agent so that it shuts down gracefully, then wait for it to exit
+trap 'kill -TERM $AGENT_PID' SIGTERM
+wait $AGENT_PID
+wait $AGENT_PID
 
 